@@ -192,7 +192,7 @@ func PasswordVerify(password string, hash string) (bool, error) {
 //
 // IMPORTANT: passphrase MUST be a high-entropy 32-byte key (e.g., output of a
 // cryptographic random generator), NOT a human-memorizable password. For
-// password-based encryption, derive the key via scrypt or argon2id first.
+// password-based encryption, use EncryptWithPassphrase (argon2id / scrypt).
 // This function uses raw byte-slice truncation, not a key derivation function.
 func AesGcmEncrypt(data string, passphrase string) (string, error) {
 	// ensure data has value
@@ -254,7 +254,7 @@ func AesGcmEncrypt(data string, passphrase string) (string, error) {
 //
 // IMPORTANT: passphrase MUST be a high-entropy 32-byte key (e.g., output of a
 // cryptographic random generator), NOT a human-memorizable password. For
-// password-based encryption, derive the key via scrypt or argon2id first.
+// password-based encryption, use EncryptWithPassphrase (argon2id / scrypt).
 // This function uses raw byte-slice truncation, not a key derivation function.
 func AesGcmDecrypt(data string, passphrase string) (string, error) {
 	// ensure data has value
@@ -0,0 +1,419 @@
+package crypto
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	util "github.com/aldelo/common"
+)
+
+// ================================================================================================================
+// ED25519 AND ECDSA P-256 HELPERS
+// ================================================================================================================
+
+// keys follow the RsaCreateKey conventions: each key is a PEM block, returned hex encoded, and every function
+// accepting a key takes either the PEM text or its hex form; signatures are returned and accepted in hex
+//
+//	Ed25519 private key = PKCS#8 "PRIVATE KEY", public key = PKIX "PUBLIC KEY"
+//	P-256 private key = SEC 1 "EC PRIVATE KEY" (PKCS#8 "PRIVATE KEY" is also accepted), public key = PKIX "PUBLIC KEY"
+//
+// P-256 public keys in PKIX DER form are what kms.KMS.ECDH expects (base64 encoded) and what KMS GetPublicKey returns,
+// see EcdsaP256PublicKeyDerBase64 and EcdsaP256PublicKeyPemFromDer
+
+// Ed25519CreateKey generates the private and public key pair,
+// expressed in hex code value
+func Ed25519CreateKey() (privateKey string, publicKey string, err error) {
+	pub, pvk, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	bytesPrivateKey, err := x509.MarshalPKCS8PrivateKey(pvk)
+	if err != nil {
+		return "", "", err
+	}
+
+	bytesPublicKey, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", "", err
+	}
+
+	return util.ByteToHex(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: bytesPrivateKey})),
+		util.ByteToHex(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: bytesPublicKey})), nil
+}
+
+// Ed25519PrivateKeySign will sign the plaintext data using the given private key,
+// signature is returned via hex
+//
+// privateKeyHexOrPem = can be either HEX or PEM, or the raw 32 byte seed in hex
+func Ed25519PrivateKeySign(data string, privateKeyHexOrPem string) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("Data To Sign is Required")
+	}
+
+	privateKey, err := ed25519PrivateKeyFromHexOrPem(privateKeyHexOrPem)
+	if err != nil {
+		return "", err
+	}
+
+	return util.ByteToHex(ed25519.Sign(privateKey, []byte(data))), nil
+}
+
+// Ed25519PublicKeyVerify will verify the plaintext data using the given public key,
+// if verification is successful, nil is returned, otherwise error is returned
+//
+// publicKeyHexOrPem = can be either HEX or PEM, or the raw 32 byte public key in hex
+func Ed25519PublicKeyVerify(data string, publicKeyHexOrPem string, signatureHex string) error {
+	if len(data) == 0 {
+		return errors.New("Data To Verify is Required")
+	}
+
+	publicKey, err := ed25519PublicKeyFromHexOrPem(publicKeyHexOrPem)
+	if err != nil {
+		return err
+	}
+
+	sig, err := util.HexToByte(signatureHex)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, []byte(data), sig) {
+		return errors.New("Ed25519 Signature Verification Failed")
+	}
+
+	return nil
+}
+
+// EcdsaP256CreateKey generates the private and public key pair,
+// expressed in hex code value
+func EcdsaP256CreateKey() (privateKey string, publicKey string, err error) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	bytesPrivateKey, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	bytesPublicKey, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return util.ByteToHex(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: bytesPrivateKey})),
+		util.ByteToHex(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: bytesPublicKey})), nil
+}
+
+// EcdsaP256PrivateKeyFromHexOrPem parses a P-256 private key, such as one from EcdsaP256CreateKey,
+// for use with kms.KMS.ImportECCP256SignVerifyKey
+//
+// privateKeyHexOrPem = can be either HEX or PEM
+func EcdsaP256PrivateKeyFromHexOrPem(privateKeyHexOrPem string) (*ecdsa.PrivateKey, error) {
+	block, err := pemBlockFromHexOrPem(privateKeyHexOrPem, "ECDSA Private Key")
+	if err != nil {
+		return nil, err
+	}
+
+	var ecKey *ecdsa.PrivateKey
+
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		// SEC 1
+		if ecKey, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+			return nil, err
+		}
+
+	case "PRIVATE KEY":
+		// PKCS#8
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		var ok bool
+		if ecKey, ok = key.(*ecdsa.PrivateKey); !ok {
+			return nil, errors.New("ECDSA Private Key Parse Fail: parsed key is not an ECDSA private key")
+		}
+
+	default:
+		return nil, fmt.Errorf("ECDSA Private Key Parse Fail: Unsupported Key Type: %s", block.Type)
+	}
+
+	if ecKey.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("ECDSA Private Key Parse Fail: Curve Must Be P-256, Got %s", ecKey.Curve.Params().Name)
+	}
+
+	return ecKey, nil
+}
+
+// EcdsaP256PrivateKeySign will sign the SHA-256 digest of the plaintext data using the given private key,
+// the signature is ASN.1 DER encoded (the form KMS ECDSA_SHA_256 produces), returned via hex
+//
+// privateKeyHexOrPem = can be either HEX or PEM
+func EcdsaP256PrivateKeySign(data string, privateKeyHexOrPem string) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("Data To Sign is Required")
+	}
+
+	privateKey, err := EcdsaP256PrivateKeyFromHexOrPem(privateKeyHexOrPem)
+	if err != nil {
+		return "", err
+	}
+
+	d := sha256.Sum256([]byte(data))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, privateKey, d[:])
+	if err != nil {
+		return "", err
+	}
+
+	return util.ByteToHex(signature), nil
+}
+
+// EcdsaP256PublicKeyVerify will verify the ASN.1 DER signature of the plaintext data using the given public key,
+// if verification is successful, nil is returned, otherwise error is returned
+//
+// publicKeyHexOrPem = can be either HEX or PEM
+func EcdsaP256PublicKeyVerify(data string, publicKeyHexOrPem string, signatureHex string) error {
+	if len(data) == 0 {
+		return errors.New("Data To Verify is Required")
+	}
+
+	publicKey, err := ecdsaP256PublicKeyFromHexOrPem(publicKeyHexOrPem)
+	if err != nil {
+		return err
+	}
+
+	sig, err := util.HexToByte(signatureHex)
+	if err != nil {
+		return err
+	}
+
+	d := sha256.Sum256([]byte(data))
+
+	if !ecdsa.VerifyASN1(publicKey, d[:], sig) {
+		return errors.New("ECDSA Signature Verification Failed")
+	}
+
+	return nil
+}
+
+// EcdsaP256ECDH computes the ECDH shared secret between a local private key and a peer public key,
+// the result is the raw 32 byte x coordinate, the same value kms.KMS.ECDH returns for the other side,
+// so it must be passed through a kdf (such as HKDF) before use as a key
+//
+// privateKeyHexOrPem = can be either HEX or PEM
+// peerPublicKeyHexOrPem = can be either HEX or PEM, see EcdsaP256PublicKeyPemFromDer for KMS public keys
+func EcdsaP256ECDH(privateKeyHexOrPem string, peerPublicKeyHexOrPem string) ([]byte, error) {
+	privateKey, err := EcdsaP256PrivateKeyFromHexOrPem(privateKeyHexOrPem)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := ecdsaP256PublicKeyFromHexOrPem(peerPublicKeyHexOrPem)
+	if err != nil {
+		return nil, err
+	}
+
+	ecdhPrivate, err := privateKey.ECDH()
+	if err != nil {
+		return nil, err
+	}
+
+	ecdhPublic, err := publicKey.ECDH()
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdhPrivate.ECDH(ecdhPublic)
+}
+
+// EcdsaP256PublicKeyDerBase64 returns the public key as base64 PKIX DER,
+// the ephemeralPublicKeyB64 form expected by kms.KMS.ECDH
+//
+// publicKeyHexOrPem = can be either HEX or PEM
+func EcdsaP256PublicKeyDerBase64(publicKeyHexOrPem string) (string, error) {
+	publicKey, err := ecdsaP256PublicKeyFromHexOrPem(publicKeyHexOrPem)
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(der), nil
+}
+
+// EcdsaP256PublicKeyPemFromDer converts PKIX DER public key bytes, such as KMS GetPublicKey output,
+// into PEM text usable by EcdsaP256PublicKeyVerify and EcdsaP256ECDH
+func EcdsaP256PublicKeyPemFromDer(der []byte) (string, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return "", err
+	}
+
+	if ecKey, ok := key.(*ecdsa.PublicKey); !ok || ecKey.Curve != elliptic.P256() {
+		return "", errors.New("ECDSA Public Key From Der Fail: parsed key is not a P-256 public key")
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// ecdsaP256PublicKeyFromHexOrPem converts hex or pem public key into ecdsa p-256 public key object
+func ecdsaP256PublicKeyFromHexOrPem(publicKeyHexOrPem string) (*ecdsa.PublicKey, error) {
+	block, err := pemBlockFromHexOrPem(publicKeyHexOrPem, "ECDSA Public Key")
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("ECDSA Public Key Parse Fail: Unsupported Key Type: %s", block.Type)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ecKey, ok := key.(*ecdsa.PublicKey)
+	if !ok || ecKey.Curve != elliptic.P256() {
+		return nil, errors.New("ECDSA Public Key Parse Fail: parsed key is not a P-256 public key")
+	}
+
+	return ecKey, nil
+}
+
+// ed25519PrivateKeyFromHexOrPem converts hex, pem or raw hex seed private key into ed25519 private key object
+func ed25519PrivateKeyFromHexOrPem(privateKeyHexOrPem string) (ed25519.PrivateKey, error) {
+	if raw, ok := eccRawHexKey(privateKeyHexOrPem, ed25519.SeedSize); ok {
+		return ed25519.NewKeyFromSeed(raw), nil
+	}
+
+	block, err := pemBlockFromHexOrPem(privateKeyHexOrPem, "Ed25519 Private Key")
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("Ed25519 Private Key Parse Fail: Unsupported Key Type: %s", block.Type)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("Ed25519 Private Key Parse Fail: parsed key is not an Ed25519 private key")
+	}
+
+	return edKey, nil
+}
+
+// ed25519PublicKeyFromHexOrPem converts hex, pem or raw hex public key into ed25519 public key object
+func ed25519PublicKeyFromHexOrPem(publicKeyHexOrPem string) (ed25519.PublicKey, error) {
+	if raw, ok := eccRawHexKey(publicKeyHexOrPem, ed25519.PublicKeySize); ok {
+		return ed25519.PublicKey(raw), nil
+	}
+
+	block, err := pemBlockFromHexOrPem(publicKeyHexOrPem, "Ed25519 Public Key")
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("Ed25519 Public Key Parse Fail: Unsupported Key Type: %s", block.Type)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("Ed25519 Public Key Parse Fail: parsed key is not an Ed25519 public key")
+	}
+
+	return edKey, nil
+}
+
+// pemBlockFromHexOrPem decodes the pem block from pem text or its hex form, encrypted pem blocks are rejected
+func pemBlockFromHexOrPem(keyHexOrPem string, what string) (*pem.Block, error) {
+	keyHexOrPem = strings.TrimSpace(keyHexOrPem)
+
+	if len(keyHexOrPem) == 0 {
+		return nil, fmt.Errorf("%s is Required", what)
+	}
+
+	var pemBytes []byte
+
+	if strings.HasPrefix(keyHexOrPem, "-----BEGIN ") {
+		pemBytes = []byte(keyHexOrPem)
+	} else {
+		b, err := util.HexToByte(keyHexOrPem)
+		if err != nil {
+			return nil, err
+		}
+		pemBytes = b
+	}
+
+	block, _ := pem.Decode(pemBytes)
+
+	if block == nil {
+		return nil, errors.New(what + " Parse Fail: " + "Pem Block Nil")
+	}
+
+	if _, encrypted := block.Headers["DEK-Info"]; encrypted {
+		return nil, errors.New(what + " Parse Fail: Encrypted PEM blocks are not supported")
+	}
+
+	return block, nil
+}
+
+// eccRawHexKey decodes keyHex when it is exactly size bytes of hex, as raw ed25519 keys are commonly shared
+func eccRawHexKey(keyHex string, size int) ([]byte, bool) {
+	keyHex = strings.TrimSpace(keyHex)
+
+	if len(keyHex) != size*2 {
+		return nil, false
+	}
+
+	b, err := util.HexToByte(keyHex)
+	if err != nil || len(b) != size {
+		return nil, false
+	}
+
+	return b, true
+}
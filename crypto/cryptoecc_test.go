@@ -0,0 +1,157 @@
+package crypto
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"testing"
+)
+
+func testHexToPem(t *testing.T, keyHex string) string {
+	t.Helper()
+
+	b, err := hex.DecodeString(keyHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestEd25519_SignVerify(t *testing.T) {
+	pvk, pub, err := Ed25519CreateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// hex and pem forms are interchangeable
+	sig, err := Ed25519PrivateKeySign("device attestation", testHexToPem(t, pvk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Ed25519PublicKeyVerify("device attestation", pub, sig); err != nil {
+		t.Fatal(err)
+	}
+	if err := Ed25519PublicKeyVerify("device attestation!", testHexToPem(t, pub), sig); err == nil {
+		t.Fatal("expected verification failure for altered data")
+	}
+
+	// raw 32 byte keys in hex, as devices commonly report them
+	rawPub, rawPvk, _ := ed25519.GenerateKey(rand.Reader)
+	sig, err = Ed25519PrivateKeySign("raw", hex.EncodeToString(rawPvk.Seed()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Ed25519PublicKeyVerify("raw", hex.EncodeToString(rawPub), sig); err != nil {
+		t.Fatal(err)
+	}
+
+	// an ecdsa key is not accepted as ed25519
+	ecPvk, _, _ := EcdsaP256CreateKey()
+	if _, err := Ed25519PrivateKeySign("x", ecPvk); err == nil {
+		t.Fatal("expected key type error")
+	}
+}
+
+func TestEcdsaP256_SignVerify(t *testing.T) {
+	pvk, pub, err := EcdsaP256CreateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := EcdsaP256PrivateKeySign("webhook body", pvk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EcdsaP256PublicKeyVerify("webhook body", testHexToPem(t, pub), sig); err != nil {
+		t.Fatal(err)
+	}
+	if err := EcdsaP256PublicKeyVerify("webhook body 2", pub, sig); err == nil {
+		t.Fatal("expected verification failure for altered data")
+	}
+
+	// pkcs#8 private keys are accepted, e.g. for kms.ImportECCP256SignVerifyKey
+	ecKey, err := EcdsaP256PrivateKeyFromHexOrPem(pvk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+	pkcs8 := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if sig, err = EcdsaP256PrivateKeySign("pkcs8", pkcs8); err != nil {
+		t.Fatal(err)
+	}
+	if err := EcdsaP256PublicKeyVerify("pkcs8", pub, sig); err != nil {
+		t.Fatal(err)
+	}
+
+	// other curves are rejected
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	der, _ = x509.MarshalECPrivateKey(p384)
+	if _, err := EcdsaP256PrivateKeyFromHexOrPem(string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))); err == nil {
+		t.Fatal("expected curve error")
+	}
+
+	if _, err := EcdsaP256PrivateKeySign("x", "not a key"); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestEcdsaP256_ECDH(t *testing.T) {
+	alicePvk, alicePub, _ := EcdsaP256CreateKey()
+	bobPvk, bobPub, _ := EcdsaP256CreateKey()
+
+	s1, err := EcdsaP256ECDH(alicePvk, bobPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := EcdsaP256ECDH(bobPvk, alicePub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s1) != 32 || !bytes.Equal(s1, s2) {
+		t.Fatalf("shared secrets differ: %x / %x", s1, s2)
+	}
+
+	// the kms ECDH public key forms round-trip: base64 der out, der (GetPublicKey) in
+	b64, err := EcdsaP256PublicKeyDerBase64(bobPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobPem, err := EcdsaP256PublicKeyPemFromDer(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s3, err := EcdsaP256ECDH(alicePvk, bobPem); err != nil || !bytes.Equal(s1, s3) {
+		t.Fatalf("shared secret via der = %x, %v", s3, err)
+	}
+
+	_, edPub, _ := Ed25519CreateKey()
+	if _, err := EcdsaP256ECDH(alicePvk, edPub); err == nil {
+		t.Fatal("expected key type error")
+	}
+}
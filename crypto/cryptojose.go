@@ -0,0 +1,544 @@
+package crypto
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// ================================================================================================================
+// JOSE (JWS / JWE) HELPERS
+// ================================================================================================================
+
+// compact serialization only:
+//
+//	JWS (RFC 7515) = header . payload . signature, with HS256, RS256, ES256 or EdDSA
+//	JWE (RFC 7516) = header . encrypted key . iv . ciphertext . tag, with RSA-OAEP-256 key wrapping and A256GCM content
+//
+// string keys follow the Rsa / EcdsaP256 / Ed25519 helpers (HEX or PEM), HS256 takes the shared secret itself;
+// verification always names the expected algorithm (or takes it from a Jwk), never trusting the token's alg header alone
+
+// JwsAlgorithm is a jws signing algorithm
+type JwsAlgorithm string
+
+const (
+	JwsHS256 JwsAlgorithm = "HS256"
+	JwsRS256 JwsAlgorithm = "RS256"
+	JwsES256 JwsAlgorithm = "ES256"
+	JwsEdDSA JwsAlgorithm = "EdDSA"
+)
+
+// JweAlgorithm is a jwe key management algorithm
+type JweAlgorithm string
+
+const (
+	JweRsaOaep256 JweAlgorithm = "RSA-OAEP-256"
+)
+
+// JweEncA256Gcm is the jwe content encryption algorithm
+const JweEncA256Gcm = "A256GCM"
+
+var (
+	// ErrJwsSignatureInvalid is returned when a jws signature does not verify
+	ErrJwsSignatureInvalid = errors.New("JWS Signature Verification Failed")
+
+	// ErrJweDecryptFailed is returned for any jwe key unwrap or content decryption failure
+	ErrJweDecryptFailed = errors.New("JWE Decrypt Failed")
+)
+
+// JoseHeader is the protected header of a jws or jwe, Alg and Enc are set by the sign and encrypt functions
+type JoseHeader struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+	Cty string `json:"cty,omitempty"`
+}
+
+// joseHeaderIn decodes a received header, catching the members this package does not implement
+type joseHeaderIn struct {
+	JoseHeader
+	Crit json.RawMessage `json:"crit"`
+	B64  *bool           `json:"b64"`
+	Zip  string          `json:"zip"`
+}
+
+// JwsSign signs payload into a compact jws
+//
+// signingKey = HS256: the shared secret (at least 32 bytes); RS256, ES256, EdDSA: private key in HEX or PEM
+// header = optional kid, typ and cty, may be nil
+func JwsSign(alg JwsAlgorithm, payload []byte, signingKey string, header *JoseHeader) (string, error) {
+	var key interface{}
+	var err error
+
+	switch alg {
+	case JwsHS256:
+		key = []byte(signingKey)
+	case JwsRS256:
+		key, err = rsaPrivateKeyFromHexOrPem(signingKey)
+	case JwsES256:
+		key, err = EcdsaP256PrivateKeyFromHexOrPem(signingKey)
+	case JwsEdDSA:
+		key, err = ed25519PrivateKeyFromHexOrPem(signingKey)
+	default:
+		return "", fmt.Errorf("JWS Algorithm %s Not Supported", alg)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return jwsSign(alg, payload, key, header)
+}
+
+// JwsVerify verifies a compact jws signed with alg, returning its payload and header
+//
+// verifyKey = HS256: the shared secret; RS256, ES256, EdDSA: public key in HEX or PEM
+func JwsVerify(token string, alg JwsAlgorithm, verifyKey string) (payload []byte, header *JoseHeader, err error) {
+	var key interface{}
+
+	switch alg {
+	case JwsHS256:
+		key = []byte(verifyKey)
+	case JwsRS256:
+		key, err = rsaPublicKeyFromHexOrPem(verifyKey)
+	case JwsES256:
+		key, err = ecdsaP256PublicKeyFromHexOrPem(verifyKey)
+	case JwsEdDSA:
+		key, err = ed25519PublicKeyFromHexOrPem(verifyKey)
+	default:
+		return nil, nil, fmt.Errorf("JWS Algorithm %s Not Supported", alg)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return jwsVerify(token, alg, key)
+}
+
+// SignJws signs payload with this private (or oct) key, setting alg and kid from the key
+func (k *Jwk) SignJws(payload []byte, header *JoseHeader) (string, error) {
+	if k.Use != "" && k.Use != "sig" {
+		return "", fmt.Errorf("JWK '%s' Use is %s, Not sig", k.Kid, k.Use)
+	}
+
+	key, err := k.privateKey()
+	if err != nil {
+		return "", err
+	}
+
+	h := JoseHeader{}
+	if header != nil {
+		h = *header
+	}
+	h.Kid = k.Kid
+
+	return jwsSign(JwsAlgorithm(k.algorithm()), payload, key, &h)
+}
+
+// VerifyJws verifies a compact jws with this key, the token alg must match the key's algorithm
+func (k *Jwk) VerifyJws(token string) (payload []byte, header *JoseHeader, err error) {
+	header, err = jwsPeekHeader(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := k.VerificationKey(header.Alg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return jwsVerify(token, JwsAlgorithm(header.Alg), key)
+}
+
+// JweEncrypt encrypts plaintext into a compact jwe for the recipient's rsa public key (RSA-OAEP-256 + A256GCM)
+//
+// recipientPublicKey = rsa public key in HEX or PEM, at least 2048 bits
+// header = optional kid, typ and cty, may be nil
+func JweEncrypt(plaintext []byte, recipientPublicKey string, header *JoseHeader) (string, error) {
+	pub, err := rsaPublicKeyFromHexOrPem(recipientPublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return jweEncrypt(plaintext, pub, header)
+}
+
+// JweDecrypt decrypts a compact jwe with the recipient's rsa private key
+//
+// privateKey = rsa private key in HEX or PEM
+func JweDecrypt(token string, privateKey string) (plaintext []byte, header *JoseHeader, err error) {
+	pvk, err := rsaPrivateKeyFromHexOrPem(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return jweDecrypt(token, pvk)
+}
+
+// EncryptJwe encrypts plaintext for this rsa key, setting kid from the key;
+// the key must be an encryption key (use "enc" or alg RSA-OAEP-256)
+func (k *Jwk) EncryptJwe(plaintext []byte, header *JoseHeader) (string, error) {
+	if err := k.checkEncryptionKey(); err != nil {
+		return "", err
+	}
+
+	pub, err := k.publicKey()
+	if err != nil {
+		return "", err
+	}
+
+	h := JoseHeader{}
+	if header != nil {
+		h = *header
+	}
+	h.Kid = k.Kid
+
+	return jweEncrypt(plaintext, pub.(*rsa.PublicKey), &h)
+}
+
+// DecryptJwe decrypts a compact jwe with this private rsa key
+func (k *Jwk) DecryptJwe(token string) (plaintext []byte, header *JoseHeader, err error) {
+	if err = k.checkEncryptionKey(); err != nil {
+		return nil, nil, err
+	}
+
+	pvk, err := k.privateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return jweDecrypt(token, pvk.(*rsa.PrivateKey))
+}
+
+func (k *Jwk) checkEncryptionKey() error {
+	if k.Kty != "RSA" || k.algorithm() != string(JweRsaOaep256) || (k.Use != "" && k.Use != "enc") {
+		return fmt.Errorf("JWK '%s' is Not an RSA-OAEP-256 Encryption Key", k.Kid)
+	}
+	return nil
+}
+
+// jwsSign signs payload with a parsed key, the key type must match alg
+func jwsSign(alg JwsAlgorithm, payload []byte, key interface{}, header *JoseHeader) (string, error) {
+	h := JoseHeader{}
+	if header != nil {
+		h = *header
+	}
+	h.Alg, h.Enc = string(alg), ""
+
+	protected, err := joseEncodeHeader(&h)
+	if err != nil {
+		return "", err
+	}
+
+	input := protected + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var sig []byte
+
+	switch v := key.(type) {
+	case []byte:
+		if alg != JwsHS256 {
+			break
+		}
+		if len(v) < 32 {
+			return "", errors.New("JWS HS256 Secret Must Be At Least 32 Bytes")
+		}
+
+		mac := hmac.New(sha256.New, v)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+
+	case *rsa.PrivateKey:
+		if alg != JwsRS256 {
+			break
+		}
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, v, crypto.SHA256, digest[:]); err != nil {
+			return "", err
+		}
+
+	case *ecdsa.PrivateKey:
+		if alg != JwsES256 {
+			break
+		}
+
+		r, s, err := ecdsa.Sign(rand.Reader, v, digest[:])
+		if err != nil {
+			return "", err
+		}
+
+		// jws uses fixed width r || s rather than asn.1
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+
+	case ed25519.PrivateKey:
+		if alg != JwsEdDSA {
+			break
+		}
+		sig = ed25519.Sign(v, []byte(input))
+	}
+
+	if sig == nil {
+		return "", fmt.Errorf("JWS Algorithm %s Does Not Match Key Type %T", alg, key)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// jwsVerify verifies token with a parsed key, the header alg must equal alg
+func jwsVerify(token string, alg JwsAlgorithm, key interface{}) ([]byte, *JoseHeader, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, errors.New("JWS Compact Serialization Must Have 3 Parts")
+	}
+
+	header, err := joseDecodeHeader(parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if header.Alg != string(alg) {
+		return nil, nil, fmt.Errorf("JWS Algorithm %s Does Not Match Expected %s", header.Alg, alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, ErrJwsSignatureInvalid
+	}
+
+	input := parts[0] + "." + parts[1]
+	digest := sha256.Sum256([]byte(input))
+	valid := false
+
+	switch v := key.(type) {
+	case []byte:
+		if alg == JwsHS256 && len(v) >= 32 {
+			mac := hmac.New(sha256.New, v)
+			mac.Write([]byte(input))
+			valid = hmac.Equal(sig, mac.Sum(nil))
+		}
+
+	case *rsa.PublicKey:
+		valid = alg == JwsRS256 && rsa.VerifyPKCS1v15(v, crypto.SHA256, digest[:], sig) == nil
+
+	case *ecdsa.PublicKey:
+		if alg == JwsES256 && len(sig) == 64 {
+			valid = ecdsa.Verify(v, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:]))
+		}
+
+	case ed25519.PublicKey:
+		valid = alg == JwsEdDSA && ed25519.Verify(v, []byte(input), sig)
+
+	default:
+		return nil, nil, fmt.Errorf("JWS Key Type %T Not Supported", key)
+	}
+
+	if !valid {
+		return nil, nil, ErrJwsSignatureInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("JWS Payload is Not Base64url: %w", err)
+	}
+
+	return payload, header, nil
+}
+
+// jweEncrypt seals plaintext under a random content key wrapped for pub
+func jweEncrypt(plaintext []byte, pub *rsa.PublicKey, header *JoseHeader) (string, error) {
+	if pub.N.BitLen() < 2048 {
+		return "", errors.New("JWE RSA Key Must Be At Least 2048 Bits")
+	}
+
+	h := JoseHeader{}
+	if header != nil {
+		h = *header
+	}
+	h.Alg, h.Enc = string(JweRsaOaep256), JweEncA256Gcm
+
+	protected, err := joseEncodeHeader(&h)
+	if err != nil {
+		return "", err
+	}
+
+	cek := make([]byte, 32)
+	if _, err = io.ReadFull(rand.Reader, cek); err != nil {
+		return "", err
+	}
+	defer clear(cek)
+
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, cek, nil)
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := newAead(AeadAesGcm, cek)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, iv); err != nil {
+		return "", err
+	}
+
+	// the ascii protected header is the additional authenticated data
+	sealed := aead.Seal(nil, iv, plaintext, []byte(protected))
+	ct, tag := sealed[:len(sealed)-aead.Overhead()], sealed[len(sealed)-aead.Overhead():]
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	return protected + "." + b64(encryptedKey) + "." + b64(iv) + "." + b64(ct) + "." + b64(tag), nil
+}
+
+// jweDecrypt unwraps the content key with pvk and opens the content
+func jweDecrypt(token string, pvk *rsa.PrivateKey) ([]byte, *JoseHeader, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, nil, errors.New("JWE Compact Serialization Must Have 5 Parts")
+	}
+
+	header, err := joseDecodeHeader(parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if header.Alg != string(JweRsaOaep256) || header.Enc != JweEncA256Gcm {
+		return nil, nil, fmt.Errorf("JWE Algorithm %s / %s Not Supported", header.Alg, header.Enc)
+	}
+
+	var raw [4][]byte
+	for i := range raw {
+		if raw[i], err = base64.RawURLEncoding.DecodeString(parts[i+1]); err != nil {
+			return nil, nil, ErrJweDecryptFailed
+		}
+	}
+	encryptedKey, iv, ct, tag := raw[0], raw[1], raw[2], raw[3]
+
+	// an unwrap failure continues with a random key, so it fails exactly like a bad tag (RFC 7516 section 11.5)
+	cek, err := rsa.DecryptOAEP(sha256.New(), nil, pvk, encryptedKey, nil)
+	if err != nil || len(cek) != 32 {
+		cek = make([]byte, 32)
+		_, _ = io.ReadFull(rand.Reader, cek)
+	}
+	defer clear(cek)
+
+	aead, err := newAead(AeadAesGcm, cek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(iv) != aead.NonceSize() || len(tag) != aead.Overhead() {
+		return nil, nil, ErrJweDecryptFailed
+	}
+
+	plaintext, err := aead.Open(nil, iv, append(ct, tag...), []byte(parts[0]))
+	if err != nil {
+		return nil, nil, ErrJweDecryptFailed
+	}
+
+	return plaintext, header, nil
+}
+
+// jwsPeekHeader decodes the header of a compact jws without verifying it, to find its kid and alg
+func jwsPeekHeader(token string) (*JoseHeader, error) {
+	if strings.Count(token, ".") != 2 {
+		return nil, errors.New("JWS Compact Serialization Must Have 3 Parts")
+	}
+
+	return joseDecodeHeader(token[:strings.IndexByte(token, '.')])
+}
+
+// jwePeekHeader decodes the header of a compact jwe, to find its kid
+func jwePeekHeader(token string) (*JoseHeader, error) {
+	if strings.Count(token, ".") != 4 {
+		return nil, errors.New("JWE Compact Serialization Must Have 5 Parts")
+	}
+
+	return joseDecodeHeader(token[:strings.IndexByte(token, '.')])
+}
+
+func joseEncodeHeader(h *JoseHeader) (string, error) {
+	b, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func joseDecodeHeader(part string) (*JoseHeader, error) {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return nil, fmt.Errorf("JOSE Header is Not Base64url: %w", err)
+	}
+
+	var h joseHeaderIn
+	if err = json.Unmarshal(b, &h); err != nil {
+		return nil, fmt.Errorf("JOSE Header Parse Failed: %w", err)
+	}
+
+	// critical extensions, unencoded payloads and compression are not implemented, so must be rejected
+	if h.Crit != nil || h.B64 != nil || h.Zip != "" {
+		return nil, errors.New("JOSE Header Uses Unsupported crit, b64 or zip Member")
+	}
+
+	if h.Alg == "" {
+		return nil, errors.New("JOSE Header alg is Required")
+	}
+
+	return &h.JoseHeader, nil
+}
+
+// rsaPrivateKeyFromHexOrPem converts hex or pem (PKCS#1 or PKCS#8) private key into rsa private key object
+func rsaPrivateKeyFromHexOrPem(privateKeyHexOrPem string) (*rsa.PrivateKey, error) {
+	if strings.HasPrefix(strings.TrimSpace(privateKeyHexOrPem), "-----BEGIN ") {
+		return rsaPrivateKeyFromPem(strings.TrimSpace(privateKeyHexOrPem))
+	}
+
+	return rsaPrivateKeyFromHex(strings.TrimSpace(privateKeyHexOrPem))
+}
+
+// rsaPublicKeyFromHexOrPem converts hex or pem (PKCS#1 or PKIX) public key into rsa public key object
+func rsaPublicKeyFromHexOrPem(publicKeyHexOrPem string) (*rsa.PublicKey, error) {
+	key, err := publicKeyFromHexOrPem(publicKeyHexOrPem)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("RSA Public Key Parse Fail: parsed key is not an RSA public key")
+	}
+
+	return rsaKey, nil
+}
@@ -0,0 +1,177 @@
+package crypto
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testJwsSecret = "0123456789abcdef0123456789abcdef"
+
+func TestJws_SignVerifyAlgorithms(t *testing.T) {
+	rsaPvk, rsaPub, _ := RsaCreateKey()
+	ecPvk, ecPub, _ := EcdsaP256CreateKey()
+	edPvk, edPub, _ := Ed25519CreateKey()
+
+	cases := []struct {
+		alg         JwsAlgorithm
+		sign, check string
+	}{
+		{JwsHS256, testJwsSecret, testJwsSecret},
+		{JwsRS256, rsaPvk, rsaPub},
+		{JwsES256, ecPvk, testHexToPem(t, ecPub)},
+		{JwsEdDSA, testHexToPem(t, edPvk), edPub},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.alg), func(t *testing.T) {
+			token, err := JwsSign(tc.alg, []byte(`{"sub":"device-7"}`), tc.sign, &JoseHeader{Kid: "k1", Typ: "JWT"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			payload, header, err := JwsVerify(token, tc.alg, tc.check)
+			if err != nil || string(payload) != `{"sub":"device-7"}` || header.Kid != "k1" || header.Alg != string(tc.alg) {
+				t.Fatalf("verify = %s, %+v, %v", payload, header, err)
+			}
+
+			parts := strings.Split(token, ".")
+			tampered := parts[0] + "." + parts[1] + "x." + parts[2]
+			if _, _, err := JwsVerify(tampered, tc.alg, tc.check); err == nil {
+				t.Fatal("expected tampered payload to fail")
+			}
+
+			// the expected algorithm is enforced, whatever the header says
+			other := JwsHS256
+			if tc.alg == JwsHS256 {
+				other = JwsRS256
+			}
+			if _, _, err := JwsVerify(token, other, tc.check); err == nil {
+				t.Fatal("expected algorithm mismatch error")
+			}
+		})
+	}
+
+	if _, err := JwsSign(JwsHS256, []byte("x"), "short", nil); err == nil {
+		t.Fatal("expected short secret error")
+	}
+}
+
+func TestJws_RFC7515HS256Vector(t *testing.T) {
+	// RFC 7515 appendix A.1
+	k, err := ParseJwk([]byte(`{"kty":"oct","k":"AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	payload, header, err := k.VerifyJws(token)
+	if err != nil || header.Typ != "JWT" || !bytes.Contains(payload, []byte(`"iss":"joe"`)) {
+		t.Fatalf("verify = %s, %+v, %v", payload, header, err)
+	}
+}
+
+func TestJws_KeySetRejectsAlgorithmConfusion(t *testing.T) {
+	_, rsaPub, _ := RsaCreateKey()
+
+	k, err := JwkFromPublicKey(rsaPub, "", "sig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := &JwkSet{Keys: []*Jwk{k}}
+
+	// an attacker signs HS256 using the published rsa public key as the hmac secret
+	forged, err := JwsSign(JwsHS256, []byte(`{"admin":true}`), testHexToPem(t, rsaPub), &JoseHeader{Kid: k.Kid})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := set.VerifyJws(forged); err == nil {
+		t.Fatal("expected hs256 token against rsa key to be rejected")
+	}
+
+	if _, _, err := set.VerifyJws(strings.Replace(forged, ".", "x.", 1)); err == nil {
+		t.Fatal("expected malformed header error")
+	}
+}
+
+func TestJwe_EncryptDecrypt(t *testing.T) {
+	pvk, pub, _ := RsaCreateKey()
+
+	token, err := JweEncrypt([]byte("card 4111"), pub, &JoseHeader{Kid: "partner-1", Cty: "text/plain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(token, ".") != 4 {
+		t.Fatalf("token = %s", token)
+	}
+
+	plain, header, err := JweDecrypt(token, pvk)
+	if err != nil || string(plain) != "card 4111" || header.Kid != "partner-1" || header.Enc != JweEncA256Gcm {
+		t.Fatalf("decrypt = %q, %+v, %v", plain, header, err)
+	}
+
+	// every failure looks the same: wrong key, altered header, altered ciphertext
+	otherPvk, _, _ := RsaCreateKey()
+	if _, _, err := JweDecrypt(token, otherPvk); !errors.Is(err, ErrJweDecryptFailed) {
+		t.Fatalf("wrong key err = %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	h, _ := joseEncodeHeader(&JoseHeader{Alg: "RSA-OAEP-256", Enc: "A256GCM", Kid: "partner-2"})
+	if _, _, err := JweDecrypt(h+"."+strings.Join(parts[1:], "."), pvk); !errors.Is(err, ErrJweDecryptFailed) {
+		t.Fatalf("altered header err = %v", err)
+	}
+
+	ct := []byte(parts[3])
+	ct[0] ^= 1
+	parts[3] = string(ct)
+	if _, _, err := JweDecrypt(strings.Join(parts, "."), pvk); err == nil {
+		t.Fatal("expected altered ciphertext error")
+	}
+
+	// jwk encryption keys, looked up by kid
+	encKey, err := JwkFromPrivateKey(pvk, "partner-enc", "enc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encKey.Alg != "RSA-OAEP-256" {
+		t.Fatalf("enc key alg = %s", encKey.Alg)
+	}
+
+	token, err = encKey.Public().EncryptJwe([]byte("settlement"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := &JwkSet{Keys: []*Jwk{encKey}}
+	if plain, _, err := set.DecryptJwe(token); err != nil || string(plain) != "settlement" {
+		t.Fatalf("key set decrypt = %q, %v", plain, err)
+	}
+
+	// a signing key is not an encryption key
+	sigKey, _ := JwkFromPublicKey(pub, "", "sig")
+	if _, err := sigKey.EncryptJwe([]byte("x"), nil); err == nil {
+		t.Fatal("expected key use error")
+	}
+}
@@ -0,0 +1,1015 @@
+package crypto
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ================================================================================================================
+// JWK / JWKS HELPERS
+// ================================================================================================================
+
+// JWK (RFC 7517) support covers the key types used by the JWS and JWE helpers:
+//
+//	kty RSA           = RS256 signing or RSA-OAEP-256 encryption, from RsaCreateKey keys (PKCS#1 or PKCS#8/PKIX)
+//	kty EC, crv P-256 = ES256, from EcdsaP256CreateKey keys
+//	kty OKP, crv Ed25519 = EdDSA, from Ed25519CreateKey keys
+//	kty oct           = HS256 shared secrets
+//
+// when no kid is given, the RFC 7638 thumbprint is used so published kids are stable across restarts
+
+var (
+	// ErrJwkNotFound is returned when a key set holds no key with the requested kid
+	ErrJwkNotFound = errors.New("JWK Not Found")
+)
+
+// Jwk is a single json web key, private members are blank for public keys
+type Jwk struct {
+	Kty    string   `json:"kty"`
+	Use    string   `json:"use,omitempty"`
+	KeyOps []string `json:"key_ops,omitempty"`
+	Alg    string   `json:"alg,omitempty"`
+	Kid    string   `json:"kid,omitempty"`
+
+	// RSA
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	D  string `json:"d,omitempty"` // also EC and OKP private key
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	Dp string `json:"dp,omitempty"`
+	Dq string `json:"dq,omitempty"`
+	Qi string `json:"qi,omitempty"`
+
+	// EC and OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+
+	// oct
+	K string `json:"k,omitempty"`
+}
+
+// JwkSet is a json web key set, {"keys": [...]}
+type JwkSet struct {
+	Keys []*Jwk `json:"keys"`
+}
+
+// JwkFromPublicKey converts an RSA, P-256 or Ed25519 public key into a Jwk
+//
+// publicKeyHexOrPem = can be either HEX or PEM
+// kid = key id, blank uses the key thumbprint
+// use = "sig" or "enc", blank for either; for RSA keys, "enc" sets alg RSA-OAEP-256 instead of RS256
+func JwkFromPublicKey(publicKeyHexOrPem string, kid string, use string) (*Jwk, error) {
+	key, err := publicKeyFromHexOrPem(publicKeyHexOrPem)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwkFromKey(key, kid, use)
+}
+
+// JwkFromPrivateKey converts an RSA, P-256 or Ed25519 private key into a Jwk including its private members,
+// publish only Public() of the result
+//
+// privateKeyHexOrPem = can be either HEX or PEM
+func JwkFromPrivateKey(privateKeyHexOrPem string, kid string, use string) (*Jwk, error) {
+	key, err := privateKeyFromHexOrPem(privateKeyHexOrPem)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwkFromKey(key, kid, use)
+}
+
+// JwkFromSecret converts an HS256 shared secret of at least 32 bytes into an oct Jwk
+func JwkFromSecret(secret []byte, kid string) (*Jwk, error) {
+	if len(secret) < 32 {
+		return nil, errors.New("JWK HS256 Secret Must Be At Least 32 Bytes")
+	}
+
+	return jwkFromKey(secret, kid, "sig")
+}
+
+// ParseJwk parses a single json web key, validating its key material
+func ParseJwk(data []byte) (*Jwk, error) {
+	k := new(Jwk)
+	if err := json.Unmarshal(data, k); err != nil {
+		return nil, fmt.Errorf("JWK Parse Failed: %w", err)
+	}
+
+	if _, err := k.publicKey(); err != nil {
+		return nil, err
+	}
+
+	if k.IsPrivate() {
+		if _, err := k.privateKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// ParseJwkSet parses a json web key set, keys of unsupported types or curves are skipped as RFC 7517 advises,
+// while malformed keys of supported types fail the parse
+func ParseJwkSet(data []byte) (*JwkSet, error) {
+	var raw struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("JWK Set Parse Failed: %w", err)
+	}
+
+	if raw.Keys == nil {
+		return nil, errors.New("JWK Set Parse Failed: keys Member is Required")
+	}
+
+	s := &JwkSet{Keys: make([]*Jwk, 0, len(raw.Keys))}
+
+	for i, r := range raw.Keys {
+		k := new(Jwk)
+		if err := json.Unmarshal(r, k); err != nil {
+			return nil, fmt.Errorf("JWK Set Parse Failed: Key %d: %w", i, err)
+		}
+
+		if !k.supported() {
+			continue
+		}
+
+		if _, err := ParseJwk(r); err != nil {
+			return nil, fmt.Errorf("JWK Set Parse Failed: Key %d: %w", i, err)
+		}
+
+		s.Keys = append(s.Keys, k)
+	}
+
+	return s, nil
+}
+
+// IsPrivate returns true when the key carries private (or shared secret) material
+func (k *Jwk) IsPrivate() bool {
+	return k.D != "" || k.K != ""
+}
+
+// Public returns a copy of the key without its private members, nil for oct keys which have no public form
+func (k *Jwk) Public() *Jwk {
+	if k.Kty == "oct" {
+		return nil
+	}
+
+	return &Jwk{Kty: k.Kty, Use: k.Use, KeyOps: k.KeyOps, Alg: k.Alg, Kid: k.Kid, N: k.N, E: k.E, Crv: k.Crv, X: k.X, Y: k.Y}
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint, base64url encoded
+func (k *Jwk) Thumbprint() (string, error) {
+	var members string
+
+	// required members only, in lexicographic order, no whitespace
+	switch k.Kty {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	case "EC":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, k.Crv, k.X, k.Y)
+	case "OKP":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, k.Crv, k.X)
+	case "oct":
+		members = fmt.Sprintf(`{"k":%q,"kty":"oct"}`, k.K)
+	default:
+		return "", fmt.Errorf("JWK Thumbprint Failed: Unsupported Key Type: %s", k.Kty)
+	}
+
+	sum := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// PublicKeyPem returns the public key as PKIX PEM text (without trailing newline, as the Rsa helpers expect),
+// usable with the Rsa, EcdsaP256 and Ed25519 helpers
+func (k *Jwk) PublicKeyPem() (string, error) {
+	key, err := k.publicKey()
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := key.([]byte); ok {
+		return "", errors.New("JWK oct Key Has No Public Key")
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))), nil
+}
+
+// PrivateKeyPem returns the private key as PEM text in the form the matching CreateKey helper produces:
+// PKCS#1 for RSA, SEC 1 for P-256 and PKCS#8 for Ed25519
+func (k *Jwk) PrivateKeyPem() (string, error) {
+	key, err := k.privateKey()
+	if err != nil {
+		return "", err
+	}
+
+	var block *pem.Block
+
+	switch v := key.(type) {
+	case *rsa.PrivateKey:
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(v)}
+
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(v)
+		if err != nil {
+			return "", err
+		}
+		block = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+
+	case ed25519.PrivateKey:
+		der, err := x509.MarshalPKCS8PrivateKey(v)
+		if err != nil {
+			return "", err
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+
+	default:
+		return "", errors.New("JWK oct Key Has No Private Key Pem")
+	}
+
+	return strings.TrimSpace(string(pem.EncodeToMemory(block))), nil
+}
+
+// Key returns the key with kid, nil when not found
+func (s *JwkSet) Key(kid string) *Jwk {
+	if s == nil {
+		return nil
+	}
+
+	for _, k := range s.Keys {
+		if k.Kid == kid {
+			return k
+		}
+	}
+
+	return nil
+}
+
+// Public returns a copy of the set holding only public keys, the form to publish at a jwks endpoint
+func (s *JwkSet) Public() *JwkSet {
+	out := &JwkSet{Keys: make([]*Jwk, 0, len(s.Keys))}
+
+	for _, k := range s.Keys {
+		if p := k.Public(); p != nil {
+			out.Keys = append(out.Keys, p)
+		}
+	}
+
+	return out
+}
+
+// VerifyJws verifies a compact jws with the key named by its kid header,
+// the token alg must match the key's algorithm
+func (s *JwkSet) VerifyJws(token string) (payload []byte, header *JoseHeader, err error) {
+	header, err = jwsPeekHeader(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k := s.Key(header.Kid)
+	if k == nil {
+		return nil, nil, fmt.Errorf("%w: kid '%s'", ErrJwkNotFound, header.Kid)
+	}
+
+	return k.VerifyJws(token)
+}
+
+// DecryptJwe decrypts a compact jwe with the private key named by its kid header
+func (s *JwkSet) DecryptJwe(token string) (plaintext []byte, header *JoseHeader, err error) {
+	header, err = jwePeekHeader(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k := s.Key(header.Kid)
+	if k == nil {
+		return nil, nil, fmt.Errorf("%w: kid '%s'", ErrJwkNotFound, header.Kid)
+	}
+
+	return k.DecryptJwe(token)
+}
+
+// VerificationKey returns the key object for verifying alg with this jwk:
+// []byte for HS256, *rsa.PublicKey for RS256, *ecdsa.PublicKey for ES256 and ed25519.PublicKey for EdDSA,
+// the types jwt libraries such as golang-jwt expect from a key func
+func (k *Jwk) VerificationKey(alg string) (interface{}, error) {
+	if k.Use != "" && k.Use != "sig" {
+		return nil, fmt.Errorf("JWK '%s' Use is %s, Not sig", k.Kid, k.Use)
+	}
+
+	if want := k.algorithm(); alg != want {
+		return nil, fmt.Errorf("JWK '%s' Algorithm is %s, Token Uses %s", k.Kid, want, alg)
+	}
+
+	return k.publicKey()
+}
+
+// algorithm returns the jws or jwe algorithm this key is used with
+func (k *Jwk) algorithm() string {
+	if k.Alg != "" {
+		return k.Alg
+	}
+
+	switch k.Kty {
+	case "RSA":
+		if k.Use == "enc" {
+			return string(JweRsaOaep256)
+		}
+		return string(JwsRS256)
+	case "EC":
+		return string(JwsES256)
+	case "OKP":
+		return string(JwsEdDSA)
+	case "oct":
+		return string(JwsHS256)
+	}
+
+	return ""
+}
+
+// supported reports whether the key type and curve are handled by this package
+func (k *Jwk) supported() bool {
+	switch k.Kty {
+	case "RSA", "oct":
+		return true
+	case "EC":
+		return k.Crv == "P-256"
+	case "OKP":
+		return k.Crv == "Ed25519"
+	}
+
+	return false
+}
+
+// publicKey decodes the public key, or the shared secret for oct keys
+func (k *Jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := jwkBigInt(k.N, "n")
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := jwkBigInt(k.E, "e")
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("JWK RSA Exponent Invalid")
+		}
+
+		if n.BitLen() < 2048 {
+			return nil, errors.New("JWK RSA Key Must Be At Least 2048 Bits")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("JWK EC Curve %s Not Supported", k.Crv)
+		}
+
+		x, errX := jwkBytes(k.X, "x", 32)
+		y, errY := jwkBytes(k.Y, "y", 32)
+		if err := errors.Join(errX, errY); err != nil {
+			return nil, err
+		}
+
+		return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(append([]byte{4}, x...), y...))
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("JWK OKP Curve %s Not Supported", k.Crv)
+		}
+
+		x, err := jwkBytes(k.X, "x", ed25519.PublicKeySize)
+		if err != nil {
+			return nil, err
+		}
+
+		return ed25519.PublicKey(x), nil
+
+	case "oct":
+		secret, err := jwkBytes(k.K, "k", 0)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(secret) < 32 {
+			return nil, errors.New("JWK HS256 Secret Must Be At Least 32 Bytes")
+		}
+
+		return secret, nil
+	}
+
+	return nil, fmt.Errorf("JWK Key Type %s Not Supported", k.Kty)
+}
+
+// privateKey decodes the private key, or the shared secret for oct keys
+func (k *Jwk) privateKey() (interface{}, error) {
+	if k.Kty == "oct" {
+		return k.publicKey()
+	}
+
+	if k.D == "" {
+		return nil, fmt.Errorf("JWK '%s' is Not a Private Key", k.Kid)
+	}
+
+	pub, err := k.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := pub.(type) {
+	case *rsa.PublicKey:
+		d, errD := jwkBigInt(k.D, "d")
+		p, errP := jwkBigInt(k.P, "p")
+		q, errQ := jwkBigInt(k.Q, "q")
+		if err := errors.Join(errD, errP, errQ); err != nil {
+			return nil, err
+		}
+
+		key := &rsa.PrivateKey{PublicKey: *v, D: d, Primes: []*big.Int{p, q}}
+		if err := key.Validate(); err != nil {
+			return nil, fmt.Errorf("JWK RSA Private Key Invalid: %w", err)
+		}
+		key.Precompute()
+
+		return key, nil
+
+	case *ecdsa.PublicKey:
+		d, err := jwkBytes(k.D, "d", 32)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), d)
+		if err != nil {
+			return nil, err
+		}
+
+		if !key.PublicKey.Equal(v) {
+			return nil, errors.New("JWK EC Private Key Does Not Match Its Public Key")
+		}
+
+		return key, nil
+
+	case ed25519.PublicKey:
+		seed, err := jwkBytes(k.D, "d", ed25519.SeedSize)
+		if err != nil {
+			return nil, err
+		}
+
+		key := ed25519.NewKeyFromSeed(seed)
+		if !key.Public().(ed25519.PublicKey).Equal(v) {
+			return nil, errors.New("JWK OKP Private Key Does Not Match Its Public Key")
+		}
+
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("JWK Key Type %s Not Supported", k.Kty)
+}
+
+// jwkFromKey builds a jwk from a parsed key object
+func jwkFromKey(key interface{}, kid string, use string) (*Jwk, error) {
+	if use != "" && use != "sig" && use != "enc" {
+		return nil, fmt.Errorf("JWK Use Must Be sig or enc, Got %s", use)
+	}
+
+	k := &Jwk{Use: use}
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	switch v := key.(type) {
+	case *rsa.PrivateKey:
+		pub, err := jwkFromKey(&v.PublicKey, kid, use)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(v.Primes) != 2 {
+			return nil, errors.New("JWK Multi-Prime RSA Keys Not Supported")
+		}
+
+		v.Precompute()
+
+		pub.D = b64(v.D.Bytes())
+		pub.P = b64(v.Primes[0].Bytes())
+		pub.Q = b64(v.Primes[1].Bytes())
+		pub.Dp = b64(v.Precomputed.Dp.Bytes())
+		pub.Dq = b64(v.Precomputed.Dq.Bytes())
+		pub.Qi = b64(v.Precomputed.Qinv.Bytes())
+		return pub, nil
+
+	case *rsa.PublicKey:
+		if v.N.BitLen() < 2048 {
+			return nil, errors.New("JWK RSA Key Must Be At Least 2048 Bits")
+		}
+
+		k.Kty = "RSA"
+		k.N = b64(v.N.Bytes())
+		k.E = b64(big.NewInt(int64(v.E)).Bytes())
+
+	case *ecdsa.PrivateKey:
+		pub, err := jwkFromKey(&v.PublicKey, kid, use)
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := v.Bytes()
+		if err != nil {
+			return nil, err
+		}
+
+		pub.D = b64(d)
+		return pub, nil
+
+	case *ecdsa.PublicKey:
+		if v.Curve != elliptic.P256() {
+			return nil, errors.New("JWK EC Key Must Be P-256")
+		}
+
+		b, err := v.Bytes()
+		if err != nil {
+			return nil, err
+		}
+
+		k.Kty, k.Crv = "EC", "P-256"
+		k.X, k.Y = b64(b[1:33]), b64(b[33:])
+
+	case ed25519.PrivateKey:
+		pub, err := jwkFromKey(v.Public(), kid, use)
+		if err != nil {
+			return nil, err
+		}
+
+		pub.D = b64(v.Seed())
+		return pub, nil
+
+	case ed25519.PublicKey:
+		k.Kty, k.Crv = "OKP", "Ed25519"
+		k.X = b64(v)
+
+	case []byte:
+		k.Kty = "oct"
+		k.K = b64(v)
+
+	default:
+		return nil, fmt.Errorf("JWK Key Type %T Not Supported", key)
+	}
+
+	k.Alg = k.algorithm()
+
+	if kid == "" {
+		var err error
+		if kid, err = k.Thumbprint(); err != nil {
+			return nil, err
+		}
+	}
+	k.Kid = kid
+
+	return k, nil
+}
+
+func jwkBytes(v string, name string, size int) ([]byte, error) {
+	if v == "" {
+		return nil, fmt.Errorf("JWK Member %s is Required", name)
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("JWK Member %s is Not Base64url: %w", name, err)
+	}
+
+	if size > 0 && len(b) != size {
+		return nil, fmt.Errorf("JWK Member %s Must Be %d Bytes", name, size)
+	}
+
+	return b, nil
+}
+
+func jwkBigInt(v string, name string) (*big.Int, error) {
+	b, err := jwkBytes(v, name, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// ================================================================================================================
+// JWKS CACHE
+// ================================================================================================================
+
+// JwksCache keeps a local copy of a remote jwks (such as a partner's /.well-known/jwks.json),
+// refreshing it every RefreshInterval, and early when a token names an unknown kid (rotation),
+// at most once per MinRefreshInterval; a scheduled refresh runs in the background while the stale set keeps
+// serving, and when it fails the last good key set keeps serving, retried after MinRefreshInterval
+type JwksCache struct {
+	// URL of the jwks document, required
+	URL string
+
+	// HttpClient used to fetch URL, nil = client with a 10 second timeout
+	HttpClient *http.Client
+
+	// RefreshInterval between scheduled refreshes, 0 = 1 hour
+	RefreshInterval time.Duration
+
+	// MinRefreshInterval between refreshes triggered by an unknown kid, and between attempts after a failure, 0 = 1 minute
+	MinRefreshInterval time.Duration
+
+	mu          sync.RWMutex
+	set         *JwkSet
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	lastErr     error
+
+	// fetchSem is a one slot semaphore serializing fetches, a channel so waiting honors ctx
+	fetchOnce sync.Once
+	fetchSem  chan struct{}
+
+	// now is overridden by tests
+	now func() time.Time
+}
+
+const (
+	// jwksMaxBytes caps the size of a fetched jwks document
+	jwksMaxBytes = 1 << 20
+
+	// jwksFetchTimeout bounds a fetch by the default client and a background refresh
+	jwksFetchTimeout = 10 * time.Second
+)
+
+// NewJwksCache returns a jwks cache for url with default intervals, keys are fetched on first use
+func NewJwksCache(url string) *JwksCache {
+	return &JwksCache{URL: url}
+}
+
+// KeySet returns the cached key set, fetching it when missing; once older than RefreshInterval
+// the cached set is returned at once while a background refresh replaces it
+func (c *JwksCache) KeySet(ctx context.Context) (*JwkSet, error) {
+	c.mu.RLock()
+	set, fetchedAt := c.set, c.fetchedAt
+	c.mu.RUnlock()
+
+	if set != nil {
+		if c.clock().Sub(fetchedAt) >= c.refreshInterval() {
+			c.refreshInBackground()
+		}
+		return set, nil
+	}
+
+	if err := c.refresh(ctx, jwksRefreshInitial); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.set, nil
+}
+
+// Key returns the key with kid, refreshing the key set once when kid is unknown
+func (c *JwksCache) Key(ctx context.Context, kid string) (*Jwk, error) {
+	set, err := c.KeySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if k := set.Key(kid); k != nil {
+		return k, nil
+	}
+
+	if err = c.refresh(ctx, jwksRefreshUnknownKid); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	k := c.set.Key(kid)
+	c.mu.RUnlock()
+
+	if k == nil {
+		return nil, fmt.Errorf("%w: kid '%s'", ErrJwkNotFound, kid)
+	}
+
+	return k, nil
+}
+
+// Refresh fetches the key set now, waiting for a fetch already running
+func (c *JwksCache) Refresh(ctx context.Context) error {
+	return c.refresh(ctx, jwksRefreshForce)
+}
+
+// VerifyJws verifies a compact jws with the cached key named by its kid header
+func (c *JwksCache) VerifyJws(ctx context.Context, token string) (payload []byte, header *JoseHeader, err error) {
+	header, err = jwsPeekHeader(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k, err := c.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return k.VerifyJws(token)
+}
+
+// VerificationKey returns the key object for verifying a token with kid and alg, see Jwk.VerificationKey,
+// this is the shape of a jwt library key func, as used by the gin wrapper's GinJwt.VerifyKeySet
+func (c *JwksCache) VerificationKey(ctx context.Context, kid string, alg string) (interface{}, error) {
+	k, err := c.Key(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.VerificationKey(alg)
+}
+
+// jwksRefreshMode selects when refresh may skip the fetch
+type jwksRefreshMode int
+
+const (
+	jwksRefreshForce      jwksRefreshMode = iota // always fetch
+	jwksRefreshInitial                           // no set yet, skipped while another caller's fetch succeeded or failed recently
+	jwksRefreshUnknownKid                        // skipped when fetched or attempted within MinRefreshInterval
+	jwksRefreshScheduled                         // same as unknown kid, and only when the set is still stale
+)
+
+// refreshInBackground starts a scheduled refresh unless a fetch is already running, it never blocks
+func (c *JwksCache) refreshInBackground() {
+	if !c.tryLockFetch() {
+		return
+	}
+
+	go func() {
+		defer c.unlockFetch()
+
+		ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+		defer cancel()
+
+		_ = c.refreshLocked(ctx, jwksRefreshScheduled)
+	}()
+}
+
+// refresh waits for the fetch slot (or ctx), then fetches the key set unless mode allows skipping it
+func (c *JwksCache) refresh(ctx context.Context, mode jwksRefreshMode) error {
+	if err := c.lockFetch(ctx); err != nil {
+		return err
+	}
+	defer c.unlockFetch()
+
+	return c.refreshLocked(ctx, mode)
+}
+
+// refreshLocked re-checks the state under the fetch slot, since another caller may have fetched while this one waited,
+// then fetches; a failed attempt is remembered so callers back off for MinRefreshInterval
+func (c *JwksCache) refreshLocked(ctx context.Context, mode jwksRefreshMode) error {
+	now := c.clock()
+
+	c.mu.RLock()
+	set, fetchedAt, lastAttempt, lastErr := c.set, c.fetchedAt, c.lastAttempt, c.lastErr
+	c.mu.RUnlock()
+
+	recent := now.Sub(lastAttempt) < c.minRefreshInterval()
+
+	switch mode {
+	case jwksRefreshInitial:
+		if set != nil {
+			return nil
+		}
+		if recent && lastErr != nil {
+			return lastErr
+		}
+	case jwksRefreshUnknownKid:
+		if recent {
+			return nil
+		}
+	case jwksRefreshScheduled:
+		if recent || now.Sub(fetchedAt) < c.refreshInterval() {
+			return nil
+		}
+	}
+
+	set, err := c.fetch(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastAttempt, c.lastErr = now, err
+	if err != nil {
+		return err
+	}
+
+	c.set, c.fetchedAt = set, now
+	return nil
+}
+
+// lockFetch takes the fetch slot, giving up when ctx is done
+func (c *JwksCache) lockFetch(ctx context.Context) error {
+	c.fetchOnce.Do(c.initFetchSem)
+
+	select {
+	case c.fetchSem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tryLockFetch takes the fetch slot if it is free
+func (c *JwksCache) tryLockFetch() bool {
+	c.fetchOnce.Do(c.initFetchSem)
+
+	select {
+	case c.fetchSem <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *JwksCache) unlockFetch() {
+	<-c.fetchSem
+}
+
+func (c *JwksCache) initFetchSem() {
+	c.fetchSem = make(chan struct{}, 1)
+}
+
+func (c *JwksCache) fetch(ctx context.Context) (*JwkSet, error) {
+	if c.URL == "" {
+		return nil, errors.New("JWKS Cache URL is Required")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("JWKS Fetch Failed: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := c.HttpClient
+	if client == nil {
+		client = &http.Client{Timeout: jwksFetchTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("JWKS Fetch Failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS Fetch Failed: HTTP Status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, jwksMaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("JWKS Fetch Failed: %w", err)
+	}
+
+	if len(data) > jwksMaxBytes {
+		return nil, errors.New("JWKS Fetch Failed: Document Too Large")
+	}
+
+	return ParseJwkSet(data)
+}
+
+func (c *JwksCache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *JwksCache) refreshInterval() time.Duration {
+	if c.RefreshInterval > 0 {
+		return c.RefreshInterval
+	}
+	return time.Hour
+}
+
+func (c *JwksCache) minRefreshInterval() time.Duration {
+	if c.MinRefreshInterval > 0 {
+		return c.MinRefreshInterval
+	}
+	return time.Minute
+}
+
+// ================================================================================================================
+// KEY PARSING
+// ================================================================================================================
+
+// publicKeyFromHexOrPem parses an RSA (PKCS#1 or PKIX), P-256 or Ed25519 public key
+func publicKeyFromHexOrPem(publicKeyHexOrPem string) (crypto.PublicKey, error) {
+	block, err := pemBlockFromHexOrPem(publicKeyHexOrPem, "Public Key")
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		if ecKey, ok := key.(*ecdsa.PublicKey); ok && ecKey.Curve != elliptic.P256() {
+			return nil, errors.New("Public Key Parse Fail: EC Curve Must Be P-256")
+		}
+
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("Public Key Parse Fail: Unsupported Key Type: %s", block.Type)
+}
+
+// privateKeyFromHexOrPem parses an RSA (PKCS#1 or PKCS#8), P-256 (SEC 1 or PKCS#8) or Ed25519 private key
+func privateKeyFromHexOrPem(privateKeyHexOrPem string) (crypto.Signer, error) {
+	block, err := pemBlockFromHexOrPem(privateKeyHexOrPem, "Private Key")
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		if key.Curve != elliptic.P256() {
+			return nil, errors.New("Private Key Parse Fail: EC Curve Must Be P-256")
+		}
+		return key, nil
+
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		switch v := key.(type) {
+		case *rsa.PrivateKey:
+			return v, nil
+		case ed25519.PrivateKey:
+			return v, nil
+		case *ecdsa.PrivateKey:
+			if v.Curve != elliptic.P256() {
+				return nil, errors.New("Private Key Parse Fail: EC Curve Must Be P-256")
+			}
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Private Key Parse Fail: Unsupported Key Type: %s", block.Type)
+}
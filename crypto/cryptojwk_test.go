@@ -0,0 +1,333 @@
+package crypto
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJwk_RFC7638Thumbprint(t *testing.T) {
+	k := &Jwk{
+		Kty: "RSA",
+		E:   "AQAB",
+		N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMst" +
+			"n64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91Cb" +
+			"OpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+	}
+
+	if tp, err := k.Thumbprint(); err != nil || tp != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Fatalf("thumbprint = %s, %v", tp, err)
+	}
+}
+
+func TestJwk_ExistingKeyFormatsRoundTrip(t *testing.T) {
+	rsaPvk, rsaPub, _ := RsaCreateKey()
+
+	// an rsa private jwk converts back to pem that the Rsa helpers sign with,
+	// and its public jwk converts back to pem that they verify with
+	pvkJwk, err := JwkFromPrivateKey(rsaPvk, "", "sig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubJwk, _ := JwkFromPublicKey(rsaPub, "", "sig")
+
+	if pvkJwk.Kid != pubJwk.Kid || pvkJwk.Alg != "RS256" || !pvkJwk.IsPrivate() || pubJwk.IsPrivate() {
+		t.Fatalf("private %+v / public %+v", pvkJwk, pubJwk)
+	}
+
+	data, _ := json.Marshal(pvkJwk)
+	parsed, err := ParseJwk(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pvkPem, err := parsed.PrivateKeyPem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPem, err := parsed.Public().PublicKeyPem()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := RsaPrivateKeySign("order 1001", pvkPem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RsaPublicKeyVerify("order 1001", pubPem, sig); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same for P-256 and Ed25519
+	ecPvk, _, _ := EcdsaP256CreateKey()
+	edPvk, _, _ := Ed25519CreateKey()
+
+	for _, pvk := range []string{ecPvk, edPvk} {
+		k, err := JwkFromPrivateKey(pvk, "dev-1", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		token, err := k.SignJws([]byte("attest"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pem, err := k.PublicKeyPem()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := JwsVerify(token, JwsAlgorithm(k.Alg), pem); err != nil {
+			t.Fatalf("%s verify with converted pem: %v", k.Kty, err)
+		}
+
+		if _, err := k.PrivateKeyPem(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestJwkSet_ParseAndPublic(t *testing.T) {
+	_, rsaPub, _ := RsaCreateKey()
+	edPvk, _, _ := Ed25519CreateKey()
+
+	rsaKey, _ := JwkFromPublicKey(rsaPub, "rsa-1", "sig")
+	edKey, _ := JwkFromPrivateKey(edPvk, "ed-1", "sig")
+	secret, _ := JwkFromSecret([]byte(testJwsSecret), "hs-1")
+
+	set := &JwkSet{Keys: []*Jwk{rsaKey, edKey, secret}}
+
+	public := set.Public()
+	if len(public.Keys) != 2 || public.Key("ed-1").IsPrivate() || public.Key("hs-1") != nil {
+		t.Fatalf("public set = %+v", public.Keys)
+	}
+
+	// unknown key types and curves are skipped, malformed supported keys fail
+	doc := `{"keys":[{"kty":"EC","crv":"P-521","x":"AA","y":"AA"},{"kty":"PQ","kid":"future"},` +
+		`{"kty":"OKP","crv":"Ed25519","kid":"ed-1","x":"` + edKey.X + `"}]}`
+
+	parsed, err := ParseJwkSet([]byte(doc))
+	if err != nil || len(parsed.Keys) != 1 || parsed.Key("ed-1") == nil {
+		t.Fatalf("parse = %+v, %v", parsed, err)
+	}
+
+	token, _ := edKey.SignJws([]byte("hello"), nil)
+	if payload, _, err := parsed.VerifyJws(token); err != nil || string(payload) != "hello" {
+		t.Fatalf("verify = %s, %v", payload, err)
+	}
+
+	if _, err := ParseJwkSet([]byte(`{"keys":[{"kty":"OKP","crv":"Ed25519","x":"short"}]}`)); err == nil {
+		t.Fatal("expected malformed key error")
+	}
+
+	if _, err := JwkFromSecret([]byte("short"), ""); err == nil {
+		t.Fatal("expected short secret error")
+	}
+}
+
+func TestJwksCache_RefreshAndRotation(t *testing.T) {
+	k1Pvk, _, _ := EcdsaP256CreateKey()
+	k2Pvk, _, _ := EcdsaP256CreateKey()
+
+	k1, _ := JwkFromPrivateKey(k1Pvk, "k1", "sig")
+	k2, _ := JwkFromPrivateKey(k2Pvk, "k2", "sig")
+
+	var mu sync.Mutex
+	served := &JwkSet{Keys: []*Jwk{k1.Public()}}
+	fetches, failing := 0, false
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		fetches++
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(served)
+	}))
+	defer srv.Close()
+
+	now := time.Unix(1700000000, 0)
+	cache := NewJwksCache(srv.URL)
+	cache.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	token1, _ := k1.SignJws([]byte("one"), nil)
+
+	if payload, _, err := cache.VerifyJws(ctx, token1); err != nil || string(payload) != "one" {
+		t.Fatalf("verify = %s, %v", payload, err)
+	}
+	if _, _, err := cache.VerifyJws(ctx, token1); err != nil || fetches != 1 {
+		t.Fatalf("cached verify fetches = %d, %v", fetches, err)
+	}
+
+	// the issuer rotates to k2: the unknown kid triggers one refresh
+	mu.Lock()
+	served = &JwkSet{Keys: []*Jwk{k1.Public(), k2.Public()}}
+	mu.Unlock()
+
+	now = now.Add(2 * time.Minute)
+	token2, _ := k2.SignJws([]byte("two"), nil)
+	if _, _, err := cache.VerifyJws(ctx, token2); err != nil || fetches != 2 {
+		t.Fatalf("rotated verify fetches = %d, %v", fetches, err)
+	}
+
+	// unknown kids cannot force a refresh more than once per MinRefreshInterval
+	token3, _ := JwsSign(JwsES256, []byte("three"), k1Pvk, &JoseHeader{Kid: "k3"})
+	for range 3 {
+		if _, _, err := cache.VerifyJws(ctx, token3); !errors.Is(err, ErrJwkNotFound) {
+			t.Fatalf("unknown kid err = %v", err)
+		}
+	}
+	if fetches != 2 {
+		t.Fatalf("unknown kid fetches = %d", fetches)
+	}
+
+	// after RefreshInterval the stale set keeps serving while a background refresh runs, and a failing endpoint
+	// is retried only after MinRefreshInterval
+	mu.Lock()
+	failing = true
+	mu.Unlock()
+
+	now = now.Add(2 * time.Hour)
+	if _, _, err := cache.VerifyJws(ctx, token2); err != nil {
+		t.Fatalf("stale verify = %v", err)
+	}
+	waitJwksFetches(t, &mu, &fetches, 3)
+
+	for range 3 {
+		if _, _, err := cache.VerifyJws(ctx, token2); err != nil {
+			t.Fatalf("stale verify after failure = %v", err)
+		}
+	}
+	if waitJwksIdle(cache); fetchCount(&mu, &fetches) != 3 {
+		t.Fatalf("failed refresh was retried without back-off, fetches = %d", fetchCount(&mu, &fetches))
+	}
+
+	// the gin key func shape: key object by kid and alg
+	key, err := cache.VerificationKey(ctx, "k1", "ES256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := key.(*rsa.PublicKey); ok {
+		t.Fatal("expected ecdsa key")
+	}
+	if _, err := cache.VerificationKey(ctx, "k1", "HS256"); err == nil {
+		t.Fatal("expected algorithm mismatch error")
+	}
+
+	if err := NewJwksCache(srv.URL).Refresh(ctx); err == nil {
+		t.Fatal("expected fetch error from failing endpoint")
+	}
+
+	// without any key set, a failed first fetch is returned to callers for MinRefreshInterval without refetching
+	empty := NewJwksCache(srv.URL)
+	empty.now = func() time.Time { return now }
+
+	before := fetchCount(&mu, &fetches)
+	for range 3 {
+		if _, err := empty.KeySet(ctx); err == nil {
+			t.Fatal("expected fetch error without a key set")
+		}
+	}
+	if got := fetchCount(&mu, &fetches); got != before+1 {
+		t.Fatalf("initial fetch attempts = %d, want 1", got-before)
+	}
+}
+
+func TestJwksCache_StaleSetDoesNotBlock(t *testing.T) {
+	k1Pvk, _, _ := EcdsaP256CreateKey()
+	k1, _ := JwkFromPrivateKey(k1Pvk, "k1", "sig")
+
+	release := make(chan struct{})
+	var slow atomic.Bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slow.Load() {
+			<-release
+		}
+		_ = json.NewEncoder(w).Encode(&JwkSet{Keys: []*Jwk{k1.Public()}})
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	now := time.Unix(1700000000, 0)
+	cache := NewJwksCache(srv.URL)
+	cache.now = func() time.Time { return now }
+
+	if _, err := cache.KeySet(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the endpoint hangs: stale reads return at once instead of queueing behind the fetch
+	slow.Store(true)
+	now = now.Add(2 * time.Hour)
+
+	start := time.Now()
+	for range 20 {
+		if set, err := cache.KeySet(context.Background()); err != nil || set.Key("k1") == nil {
+			t.Fatalf("stale key set = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("stale reads blocked for %s", elapsed)
+	}
+
+	// a forced refresh waiting on the running fetch honors its context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := cache.Refresh(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("refresh while fetch running = %v", err)
+	}
+}
+
+// fetchCount reads a counter guarded by mu
+func fetchCount(mu *sync.Mutex, n *int) int {
+	mu.Lock()
+	defer mu.Unlock()
+	return *n
+}
+
+// waitJwksFetches waits for a background refresh to reach want fetches
+func waitJwksFetches(t *testing.T, mu *sync.Mutex, n *int, want int) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); fetchCount(mu, n) < want; {
+		if time.Now().After(deadline) {
+			t.Fatalf("fetches = %d, want %d", fetchCount(mu, n), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitJwksIdle waits until no fetch holds the cache's fetch slot
+func waitJwksIdle(c *JwksCache) {
+	_ = c.lockFetch(context.Background())
+	c.unlockFetch()
+}
@@ -0,0 +1,516 @@
+package crypto
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+
+	util "github.com/aldelo/common"
+)
+
+// ================================================================================================================
+// PASSPHRASE (KDF) ENCRYPTION HELPERS
+// ================================================================================================================
+//
+// EncryptWithPassphrase / EncryptBytesWithPassphrase derive a 256 bit key from the passphrase with argon2id or
+// scrypt and a random salt, then encrypt with aes-256-gcm or xchacha20-poly1305; unlike AesGcmEncrypt, any
+// passphrase length is accepted and never truncated.
+//
+// the output is self-describing, so decryption needs only the passphrase (and associated data, if used):
+//
+//	magic "ACK" | version (1) | kdf id | kdf params (9 bytes) | cipher id | salt length | salt | nonce | ciphertext+tag
+//
+//	argon2id params = time uint32 | memory KiB uint32 | threads uint8
+//	scrypt params   = log2 N uint8 | r uint32 | p uint32
+//
+// the whole header is authenticated as part of the aead additional data, so tampering with the algorithm or
+// kdf parameters fails decryption; the header is only authenticated after the key is derived, so its kdf
+// parameters are bounded first by their actual cost (argon2id memory x passes, scrypt 128*r*N bytes and p passes),
+// so a crafted header cannot make the caller allocate gigabytes or burn cpu for hours. the same bounds apply
+// when encrypting, so anything encrypted here can also be decrypted here. services decrypting untrusted input
+// use DecryptBytesWithPassphraseOptions, whose PassphraseDecryptLimits default to the encrypt default costs.
+
+// KdfAlgorithm identifies the passphrase key derivation function
+type KdfAlgorithm byte
+
+// supported key derivation functions
+const (
+	KdfArgon2id KdfAlgorithm = 1
+	KdfScrypt   KdfAlgorithm = 2
+)
+
+// AeadAlgorithm identifies the authenticated cipher
+type AeadAlgorithm byte
+
+// supported authenticated ciphers
+const (
+	AeadAesGcm            AeadAlgorithm = 1 // aes-256-gcm, 12 byte random nonce
+	AeadXChaCha20Poly1305 AeadAlgorithm = 2 // xchacha20-poly1305, 24 byte random nonce
+)
+
+const (
+	kdfFormatVersion = 1
+	kdfSaltSize      = 16
+	kdfKeySize       = 32
+
+	kdfMaxArgon2Time      = 10
+	kdfMaxArgon2MemoryKiB = 1024 * 1024     // 1 GiB
+	kdfMaxArgon2WorkKiB   = 2 * 1024 * 1024 // memory KiB x passes, 2 GiB passes
+	kdfMaxArgon2Threads   = 64
+	kdfMaxScryptLogN      = 20
+	kdfMaxScryptR         = 32
+	kdfMaxScryptP         = 16
+	kdfMaxScryptMemory    = 256 * 1024 * 1024  // 128*r*N bytes
+	kdfMaxScryptWork      = 1024 * 1024 * 1024 // 128*r*N*p bytes mixed
+)
+
+var kdfMagic = []byte("ACK")
+
+// ErrPassphraseDecryptFailed is returned when the passphrase or associated data is wrong, or the data was altered
+var ErrPassphraseDecryptFailed = errors.New("Decrypt Failed: Wrong Passphrase, Associated Data Mismatch, or Data Altered")
+
+// PassphraseEncryptOptions selects the key derivation function, its cost parameters, and the cipher,
+// zero fields take the defaults of DefaultPassphraseEncryptOptions
+type PassphraseEncryptOptions struct {
+	Kdf    KdfAlgorithm  // default KdfArgon2id
+	Cipher AeadAlgorithm // default AeadAesGcm
+
+	Argon2Time      uint32 // argon2id passes, default 3
+	Argon2MemoryKiB uint32 // argon2id memory in KiB, default 65536 (64 MiB)
+	Argon2Threads   uint8  // argon2id parallelism, default 4
+
+	ScryptLogN uint8  // scrypt cost as log2 N, default 15 (N = 32768)
+	ScryptR    uint32 // scrypt block size, default 8
+	ScryptP    uint32 // scrypt parallelism, default 1
+}
+
+// DefaultPassphraseEncryptOptions returns argon2id (RFC 9106 second recommended option: t=3, m=64 MiB, p=4) with aes-256-gcm
+func DefaultPassphraseEncryptOptions() PassphraseEncryptOptions {
+	return PassphraseEncryptOptions{
+		Kdf:             KdfArgon2id,
+		Cipher:          AeadAesGcm,
+		Argon2Time:      3,
+		Argon2MemoryKiB: 64 * 1024,
+		Argon2Threads:   4,
+		ScryptLogN:      15,
+		ScryptR:         8,
+		ScryptP:         1,
+	}
+}
+
+// withDefaults fills zero fields from DefaultPassphraseEncryptOptions
+func (o PassphraseEncryptOptions) withDefaults() PassphraseEncryptOptions {
+	d := DefaultPassphraseEncryptOptions()
+
+	if o.Kdf == 0 {
+		o.Kdf = d.Kdf
+	}
+	if o.Cipher == 0 {
+		o.Cipher = d.Cipher
+	}
+	if o.Argon2Time == 0 {
+		o.Argon2Time = d.Argon2Time
+	}
+	if o.Argon2MemoryKiB == 0 {
+		o.Argon2MemoryKiB = d.Argon2MemoryKiB
+	}
+	if o.Argon2Threads == 0 {
+		o.Argon2Threads = d.Argon2Threads
+	}
+	if o.ScryptLogN == 0 {
+		o.ScryptLogN = d.ScryptLogN
+	}
+	if o.ScryptR == 0 {
+		o.ScryptR = d.ScryptR
+	}
+	if o.ScryptP == 0 {
+		o.ScryptP = d.ScryptP
+	}
+
+	return o
+}
+
+// EncryptWithPassphrase encrypts data with a key derived from passphrase, see EncryptBytesWithPassphrase,
+// the result is represented in hex; associatedData may be blank, and must be given again to decrypt
+func EncryptWithPassphrase(data string, passphrase string, associatedData string, opts *PassphraseEncryptOptions) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("Data to Encrypt is Required")
+	}
+
+	sealed, err := EncryptBytesWithPassphrase([]byte(data), []byte(passphrase), []byte(associatedData), opts)
+	if err != nil {
+		return "", err
+	}
+
+	return util.ByteToHex(sealed), nil
+}
+
+// DecryptWithPassphrase decrypts hex data produced by EncryptWithPassphrase
+func DecryptWithPassphrase(data string, passphrase string, associatedData string) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("Data to Decrypt is Required")
+	}
+
+	sealed, err := util.HexToByte(data)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := DecryptBytesWithPassphrase(sealed, []byte(passphrase), []byte(associatedData))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// EncryptBytesWithPassphrase derives a 256 bit key from passphrase (any length) with a random salt,
+// and encrypts plaintext with the selected aead cipher, authenticating associatedData (may be nil) and the header;
+// opts nil = DefaultPassphraseEncryptOptions
+func EncryptBytesWithPassphrase(plaintext []byte, passphrase []byte, associatedData []byte, opts *PassphraseEncryptOptions) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("Passphrase is Required")
+	}
+
+	o := DefaultPassphraseEncryptOptions()
+	if opts != nil {
+		o = opts.withDefaults()
+	}
+
+	salt := make([]byte, kdfSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	header, err := kdfBuildHeader(o, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := kdfDeriveKey(o, passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	aead, err := newAead(o.Cipher, key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+
+	return aead.Seal(out, nonce, plaintext, kdfAdditionalData(header, associatedData)), nil
+}
+
+// DecryptBytesWithPassphrase decrypts data produced by EncryptBytesWithPassphrase, reading the kdf, its parameters
+// and the cipher from the header; a wrong passphrase or associated data, or altered data returns ErrPassphraseDecryptFailed;
+// the kdf cost is bounded only by the global maximum, use DecryptBytesWithPassphraseOptions for untrusted input
+func DecryptBytesWithPassphrase(sealed []byte, passphrase []byte, associatedData []byte) ([]byte, error) {
+	return decryptBytesWithPassphrase(sealed, passphrase, associatedData, nil)
+}
+
+// PassphraseDecryptLimits caps the kdf cost a header may ask for, below the global maximum, as the header is only
+// authenticated after the key is derived; zero fields take the defaults of DefaultPassphraseDecryptLimits
+type PassphraseDecryptLimits struct {
+	MaxArgon2Time      uint32 // argon2id passes
+	MaxArgon2MemoryKiB uint32 // argon2id memory in KiB
+	MaxScryptMemory    uint64 // scrypt 128*r*N bytes
+	MaxScryptP         uint32 // scrypt parallelism
+}
+
+// DefaultPassphraseDecryptLimits returns limits matching DefaultPassphraseEncryptOptions,
+// argon2id t=3 with 64 MiB, and scrypt 32 MiB (N = 32768, r = 8) with p=1
+func DefaultPassphraseDecryptLimits() PassphraseDecryptLimits {
+	d := DefaultPassphraseEncryptOptions()
+
+	return PassphraseDecryptLimits{
+		MaxArgon2Time:      d.Argon2Time,
+		MaxArgon2MemoryKiB: d.Argon2MemoryKiB,
+		MaxScryptMemory:    128 * uint64(d.ScryptR) << d.ScryptLogN,
+		MaxScryptP:         d.ScryptP,
+	}
+}
+
+// withDefaults fills zero fields from DefaultPassphraseDecryptLimits
+func (l PassphraseDecryptLimits) withDefaults() PassphraseDecryptLimits {
+	d := DefaultPassphraseDecryptLimits()
+
+	if l.MaxArgon2Time == 0 {
+		l.MaxArgon2Time = d.MaxArgon2Time
+	}
+	if l.MaxArgon2MemoryKiB == 0 {
+		l.MaxArgon2MemoryKiB = d.MaxArgon2MemoryKiB
+	}
+	if l.MaxScryptMemory == 0 {
+		l.MaxScryptMemory = d.MaxScryptMemory
+	}
+	if l.MaxScryptP == 0 {
+		l.MaxScryptP = d.MaxScryptP
+	}
+
+	return l
+}
+
+// check returns an error when o costs more than l allows
+func (l PassphraseDecryptLimits) check(o PassphraseEncryptOptions) error {
+	switch o.Kdf {
+	case KdfArgon2id:
+		if o.Argon2Time > l.MaxArgon2Time || o.Argon2MemoryKiB > l.MaxArgon2MemoryKiB {
+			return fmt.Errorf("Argon2id Cost t=%d m=%d KiB Exceeds Decrypt Limit t=%d m=%d KiB",
+				o.Argon2Time, o.Argon2MemoryKiB, l.MaxArgon2Time, l.MaxArgon2MemoryKiB)
+		}
+	case KdfScrypt:
+		if mem := uint64(128) * uint64(o.ScryptR) << o.ScryptLogN; mem > l.MaxScryptMemory || o.ScryptP > l.MaxScryptP {
+			return fmt.Errorf("Scrypt Cost %d Bytes p=%d Exceeds Decrypt Limit %d Bytes p=%d", mem, o.ScryptP, l.MaxScryptMemory, l.MaxScryptP)
+		}
+	}
+
+	return nil
+}
+
+// DecryptBytesWithPassphraseOptions is DecryptBytesWithPassphrase with the kdf cost capped by limits,
+// nil = DefaultPassphraseDecryptLimits; services decrypting untrusted input use it so a crafted header
+// cannot make them derive a key more expensive than they expect, data above the limits is rejected
+func DecryptBytesWithPassphraseOptions(sealed []byte, passphrase []byte, associatedData []byte, limits *PassphraseDecryptLimits) ([]byte, error) {
+	l := DefaultPassphraseDecryptLimits()
+	if limits != nil {
+		l = limits.withDefaults()
+	}
+
+	return decryptBytesWithPassphrase(sealed, passphrase, associatedData, &l)
+}
+
+// decryptBytesWithPassphrase decrypts sealed, rejecting kdf parameters beyond limits (nil = global maximum only)
+// before any key derivation
+func decryptBytesWithPassphrase(sealed []byte, passphrase []byte, associatedData []byte, limits *PassphraseDecryptLimits) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("Passphrase is Required")
+	}
+
+	o, salt, headerLen, err := kdfParseHeader(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = kdfValidateParams(o); err != nil {
+		return nil, err
+	}
+
+	if limits != nil {
+		if err = limits.check(o); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := kdfDeriveKey(o, passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	aead, err := newAead(o.Cipher, key)
+	if err != nil {
+		return nil, err
+	}
+
+	body := sealed[headerLen:]
+	if len(body) < aead.NonceSize()+aead.Overhead() {
+		return nil, errors.New("Cipher Text Smaller Than Nonce Size")
+	}
+
+	nonce, ciphertext := body[:aead.NonceSize()], body[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, kdfAdditionalData(sealed[:headerLen], associatedData))
+	if err != nil {
+		return nil, ErrPassphraseDecryptFailed
+	}
+
+	return plaintext, nil
+}
+
+// newAead returns the aead cipher of algorithm alg for a 32 byte key
+func newAead(alg AeadAlgorithm, key []byte) (cipher.AEAD, error) {
+	switch alg {
+	case AeadAesGcm:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case AeadXChaCha20Poly1305:
+		return chacha20poly1305.NewX(key)
+	default:
+		return nil, fmt.Errorf("Aead Algorithm %d Not Supported", alg)
+	}
+}
+
+// kdfDeriveKey derives the 32 byte key from passphrase and salt per o
+func kdfDeriveKey(o PassphraseEncryptOptions, passphrase []byte, salt []byte) ([]byte, error) {
+	if err := kdfValidateParams(o); err != nil {
+		return nil, err
+	}
+
+	switch o.Kdf {
+	case KdfArgon2id:
+		return argon2.IDKey(passphrase, salt, o.Argon2Time, o.Argon2MemoryKiB, o.Argon2Threads, kdfKeySize), nil
+	case KdfScrypt:
+		return scrypt.Key(passphrase, salt, 1<<o.ScryptLogN, int(o.ScryptR), int(o.ScryptP), kdfKeySize)
+	default:
+		return nil, fmt.Errorf("Kdf Algorithm %d Not Supported", o.Kdf)
+	}
+}
+
+// kdfValidateParams bounds the memory and cpu cost of kdf parameters, for both encrypt options and
+// untrusted decrypt headers, it must pass before any key derivation runs
+func kdfValidateParams(o PassphraseEncryptOptions) error {
+	switch o.Kdf {
+	case KdfArgon2id:
+		if o.Argon2Time < 1 || o.Argon2Time > kdfMaxArgon2Time {
+			return fmt.Errorf("Argon2id Time Must Be 1 to %d", kdfMaxArgon2Time)
+		}
+		if o.Argon2Threads < 1 || o.Argon2Threads > kdfMaxArgon2Threads {
+			return fmt.Errorf("Argon2id Threads Must Be 1 to %d", kdfMaxArgon2Threads)
+		}
+		if o.Argon2MemoryKiB < 8*uint32(o.Argon2Threads) || o.Argon2MemoryKiB > kdfMaxArgon2MemoryKiB {
+			return fmt.Errorf("Argon2id Memory Must Be %d to %d KiB", 8*uint32(o.Argon2Threads), kdfMaxArgon2MemoryKiB)
+		}
+		if uint64(o.Argon2MemoryKiB)*uint64(o.Argon2Time) > kdfMaxArgon2WorkKiB {
+			return fmt.Errorf("Argon2id Memory x Time Must Not Exceed %d KiB", kdfMaxArgon2WorkKiB)
+		}
+	case KdfScrypt:
+		if o.ScryptLogN < 1 || o.ScryptLogN > kdfMaxScryptLogN {
+			return fmt.Errorf("Scrypt Log2 N Must Be 1 to %d", kdfMaxScryptLogN)
+		}
+		if o.ScryptR < 1 || o.ScryptR > kdfMaxScryptR {
+			return fmt.Errorf("Scrypt R Must Be 1 to %d", kdfMaxScryptR)
+		}
+		if o.ScryptP < 1 || o.ScryptP > kdfMaxScryptP {
+			return fmt.Errorf("Scrypt P Must Be 1 to %d", kdfMaxScryptP)
+		}
+
+		// scrypt allocates 128*r*N bytes, and mixes them p times
+		mem := uint64(128) * uint64(o.ScryptR) << o.ScryptLogN
+		if mem > kdfMaxScryptMemory {
+			return fmt.Errorf("Scrypt Memory 128*R*N Must Not Exceed %d Bytes", kdfMaxScryptMemory)
+		}
+		if mem*uint64(o.ScryptP) > kdfMaxScryptWork {
+			return fmt.Errorf("Scrypt Cost 128*R*N*P Must Not Exceed %d Bytes", kdfMaxScryptWork)
+		}
+	default:
+		return fmt.Errorf("Kdf Algorithm %d Not Supported", o.Kdf)
+	}
+
+	return nil
+}
+
+// kdfBuildHeader encodes the self-describing header, ending before the nonce
+func kdfBuildHeader(o PassphraseEncryptOptions, salt []byte) ([]byte, error) {
+	if err := kdfValidateParams(o); err != nil {
+		return nil, err
+	}
+	if o.Cipher != AeadAesGcm && o.Cipher != AeadXChaCha20Poly1305 {
+		return nil, fmt.Errorf("Aead Algorithm %d Not Supported", o.Cipher)
+	}
+
+	h := make([]byte, 0, len(kdfMagic)+14+len(salt))
+	h = append(h, kdfMagic...)
+	h = append(h, kdfFormatVersion, byte(o.Kdf))
+
+	switch o.Kdf {
+	case KdfArgon2id:
+		h = binary.BigEndian.AppendUint32(h, o.Argon2Time)
+		h = binary.BigEndian.AppendUint32(h, o.Argon2MemoryKiB)
+		h = append(h, o.Argon2Threads)
+	case KdfScrypt:
+		h = append(h, o.ScryptLogN)
+		h = binary.BigEndian.AppendUint32(h, o.ScryptR)
+		h = binary.BigEndian.AppendUint32(h, o.ScryptP)
+	}
+
+	h = append(h, byte(o.Cipher), byte(len(salt)))
+	return append(h, salt...), nil
+}
+
+// kdfParseHeader decodes the header of sealed, returning its options, salt and length
+func kdfParseHeader(sealed []byte) (o PassphraseEncryptOptions, salt []byte, headerLen int, err error) {
+	const fixedLen = 3 + 2 + 9 + 2 // magic, version + kdf, params, cipher + salt length
+
+	if len(sealed) < fixedLen || !bytes.Equal(sealed[:3], kdfMagic) {
+		return o, nil, 0, errors.New("Data Is Not Passphrase Encrypted Format")
+	}
+
+	if sealed[3] != kdfFormatVersion {
+		return o, nil, 0, fmt.Errorf("Passphrase Encrypted Format Version %d Not Supported", sealed[3])
+	}
+
+	o.Kdf = KdfAlgorithm(sealed[4])
+	p := sealed[5:14]
+
+	switch o.Kdf {
+	case KdfArgon2id:
+		o.Argon2Time = binary.BigEndian.Uint32(p[0:4])
+		o.Argon2MemoryKiB = binary.BigEndian.Uint32(p[4:8])
+		o.Argon2Threads = p[8]
+	case KdfScrypt:
+		o.ScryptLogN = p[0]
+		o.ScryptR = binary.BigEndian.Uint32(p[1:5])
+		o.ScryptP = binary.BigEndian.Uint32(p[5:9])
+	default:
+		return o, nil, 0, fmt.Errorf("Kdf Algorithm %d Not Supported", o.Kdf)
+	}
+
+	o.Cipher = AeadAlgorithm(sealed[14])
+	if o.Cipher != AeadAesGcm && o.Cipher != AeadXChaCha20Poly1305 {
+		return o, nil, 0, fmt.Errorf("Aead Algorithm %d Not Supported", o.Cipher)
+	}
+
+	saltLen := int(sealed[15])
+
+	if saltLen < 8 || len(sealed) < fixedLen+saltLen {
+		return o, nil, 0, errors.New("Passphrase Encrypted Header Salt Invalid")
+	}
+
+	return o, sealed[fixedLen : fixedLen+saltLen], fixedLen + saltLen, nil
+}
+
+// kdfAdditionalData binds the header and the caller's associated data into the aead additional data,
+// the caller data is length prefixed so header and data boundaries cannot shift
+func kdfAdditionalData(header []byte, associatedData []byte) []byte {
+	ad := make([]byte, 0, len(header)+8+len(associatedData))
+	ad = append(ad, header...)
+	ad = binary.BigEndian.AppendUint64(ad, uint64(len(associatedData)))
+	return append(ad, associatedData...)
+}
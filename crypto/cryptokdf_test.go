@@ -0,0 +1,197 @@
+package crypto
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// light kdf costs keep the tests fast, production callers use the defaults
+var (
+	testArgon2Options = &PassphraseEncryptOptions{Kdf: KdfArgon2id, Argon2Time: 1, Argon2MemoryKiB: 64, Argon2Threads: 1}
+	testScryptOptions = &PassphraseEncryptOptions{Kdf: KdfScrypt, ScryptLogN: 10, Cipher: AeadXChaCha20Poly1305}
+)
+
+func TestPassphraseEncrypt_RoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		opts *PassphraseEncryptOptions
+	}{
+		{"argon2id aes-gcm", testArgon2Options},
+		{"argon2id xchacha", &PassphraseEncryptOptions{Argon2Time: 1, Argon2MemoryKiB: 64, Argon2Threads: 1, Cipher: AeadXChaCha20Poly1305}},
+		{"scrypt xchacha", testScryptOptions},
+		{"scrypt aes-gcm", &PassphraseEncryptOptions{Kdf: KdfScrypt, ScryptLogN: 10}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// short passphrases are accepted, the kdf stretches them
+			enc, err := EncryptWithPassphrase("card vault export", "pw", "tenant-42", tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dec, err := DecryptWithPassphrase(enc, "pw", "tenant-42")
+			if err != nil || dec != "card vault export" {
+				t.Fatalf("decrypt = %q, %v", dec, err)
+			}
+
+			if _, err := DecryptWithPassphrase(enc, "pw2", "tenant-42"); !errors.Is(err, ErrPassphraseDecryptFailed) {
+				t.Fatalf("wrong passphrase err = %v", err)
+			}
+			if _, err := DecryptWithPassphrase(enc, "pw", "tenant-43"); !errors.Is(err, ErrPassphraseDecryptFailed) {
+				t.Fatalf("wrong associated data err = %v", err)
+			}
+		})
+	}
+}
+
+func TestPassphraseEncrypt_BytesAndRandomSalt(t *testing.T) {
+	plain := []byte{0, 1, 2, 0xff}
+
+	a, err := EncryptBytesWithPassphrase(plain, []byte("secret"), nil, testArgon2Options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := EncryptBytesWithPassphrase(plain, []byte("secret"), nil, testArgon2Options)
+
+	if bytes.Equal(a, b) {
+		t.Fatal("two encryptions of the same input must differ (random salt and nonce)")
+	}
+
+	got, err := DecryptBytesWithPassphrase(a, []byte("secret"), nil)
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("decrypt = %v, %v", got, err)
+	}
+
+	empty, err := EncryptBytesWithPassphrase(nil, []byte("secret"), nil, testScryptOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DecryptBytesWithPassphrase(empty, []byte("secret"), nil); err != nil || len(got) != 0 {
+		t.Fatalf("empty plaintext = %v, %v", got, err)
+	}
+}
+
+func TestPassphraseEncrypt_HeaderTamperingAndLimits(t *testing.T) {
+	sealed, err := EncryptBytesWithPassphrase([]byte("data"), []byte("secret"), nil, testArgon2Options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bump argon2 time in the header: parameters are authenticated, so decryption fails
+	tampered := append([]byte(nil), sealed...)
+	tampered[8]++
+	if _, err := DecryptBytesWithPassphrase(tampered, []byte("secret"), nil); !errors.Is(err, ErrPassphraseDecryptFailed) {
+		t.Fatalf("tampered header err = %v", err)
+	}
+
+	// a crafted header asking for absurd memory is rejected before any key derivation
+	huge := append([]byte(nil), sealed...)
+	copy(huge[9:13], []byte{0xff, 0xff, 0xff, 0xff})
+	if _, err := DecryptBytesWithPassphrase(huge, []byte("secret"), nil); err == nil || errors.Is(err, ErrPassphraseDecryptFailed) {
+		t.Fatalf("oversized memory param err = %v", err)
+	}
+
+	// crafted headers within the field widths but with ruinous actual cost are rejected before key derivation,
+	// a regression here allocates up to 2^48 bytes or runs for hours instead of failing fast
+	scrypted, err := EncryptBytesWithPassphrase([]byte("data"), []byte("secret"), nil, testScryptOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crafted := map[string][]byte{
+		"argon2 4 GiB x 32":   kdfCraftParams(sealed, []byte{0, 0, 0, 32, 0, 0x40, 0, 0, 4}),
+		"argon2 1 GiB x 10":   kdfCraftParams(sealed, []byte{0, 0, 0, 10, 0, 0x10, 0, 0, 4}),
+		"scrypt 2^22 r 2^19":  kdfCraftParams(scrypted, []byte{22, 0, 0x08, 0, 0, 0, 0, 0, 1}),
+		"scrypt 2^20 r 8":     kdfCraftParams(scrypted, []byte{20, 0, 0, 0, 8, 0, 0, 0, 1}),
+		"scrypt p 2^20-1":     kdfCraftParams(scrypted, []byte{10, 0, 0, 0, 1, 0, 0x0f, 0xff, 0xff}),
+		"scrypt 2^18 r 8 p 8": kdfCraftParams(scrypted, []byte{18, 0, 0, 0, 8, 0, 0, 0, 8}),
+	}
+
+	for name, data := range crafted {
+		start := time.Now()
+		_, err := DecryptBytesWithPassphrase(data, []byte("secret"), nil)
+
+		if err == nil || errors.Is(err, ErrPassphraseDecryptFailed) {
+			t.Errorf("%s: err = %v", name, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: rejected after %s, key derivation ran", name, elapsed)
+		}
+	}
+
+	// an unknown cipher id is rejected with the header, before the kdf runs
+	badCipher := append([]byte(nil), sealed...)
+	badCipher[14] = 9
+	if _, err := DecryptBytesWithPassphrase(badCipher, []byte("secret"), nil); err == nil || errors.Is(err, ErrPassphraseDecryptFailed) {
+		t.Fatalf("unknown cipher err = %v", err)
+	}
+
+	if _, err := DecryptBytesWithPassphrase([]byte("not encrypted"), []byte("secret"), nil); err == nil {
+		t.Fatal("expected format error")
+	}
+
+	if _, err := EncryptBytesWithPassphrase([]byte("x"), nil, nil, nil); err == nil {
+		t.Fatal("expected passphrase required error")
+	}
+
+	if _, err := EncryptBytesWithPassphrase([]byte("x"), []byte("p"), nil, &PassphraseEncryptOptions{Cipher: 9}); err == nil {
+		t.Fatal("expected unsupported cipher error")
+	}
+}
+
+// kdfCraftParams returns a copy of sealed with its 9 kdf parameter bytes replaced
+func kdfCraftParams(sealed []byte, params []byte) []byte {
+	out := append([]byte(nil), sealed...)
+	copy(out[5:14], params)
+	return out
+}
+
+func TestPassphraseDecrypt_Limits(t *testing.T) {
+	light, _ := EncryptBytesWithPassphrase([]byte("data"), []byte("secret"), nil, testArgon2Options)
+	fourPasses, _ := EncryptBytesWithPassphrase([]byte("data"), []byte("secret"), nil,
+		&PassphraseEncryptOptions{Argon2Time: 4, Argon2MemoryKiB: 64, Argon2Threads: 1})
+	scrypted, _ := EncryptBytesWithPassphrase([]byte("data"), []byte("secret"), nil, testScryptOptions)
+
+	// nil limits = the encrypt defaults, t=3 and 64 MiB for argon2id, 32 MiB and p=1 for scrypt
+	if plain, err := DecryptBytesWithPassphraseOptions(light, []byte("secret"), nil, nil); err != nil || string(plain) != "data" {
+		t.Fatalf("light decrypt = %q, %v", plain, err)
+	}
+	if plain, err := DecryptBytesWithPassphraseOptions(scrypted, []byte("secret"), nil, nil); err != nil || string(plain) != "data" {
+		t.Fatalf("scrypt decrypt = %q, %v", plain, err)
+	}
+	if _, err := DecryptBytesWithPassphraseOptions(fourPasses, []byte("secret"), nil, nil); err == nil || errors.Is(err, ErrPassphraseDecryptFailed) {
+		t.Fatalf("over default limit err = %v", err)
+	}
+
+	// callers may raise or lower the limits, zero fields keep the defaults
+	if _, err := DecryptBytesWithPassphraseOptions(fourPasses, []byte("secret"), nil, &PassphraseDecryptLimits{MaxArgon2Time: 4}); err != nil {
+		t.Fatalf("raised limit err = %v", err)
+	}
+	if _, err := DecryptBytesWithPassphraseOptions(scrypted, []byte("secret"), nil, &PassphraseDecryptLimits{MaxScryptMemory: 512 * 1024}); err == nil {
+		t.Fatal("expected scrypt memory limit error")
+	}
+
+	// the plain decrypt keeps accepting anything within the global maximum
+	if _, err := DecryptBytesWithPassphrase(fourPasses, []byte("secret"), nil); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,490 @@
+package crypto
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	util "github.com/aldelo/common"
+)
+
+// ================================================================================================================
+// KEYRING (KEY ID + ROTATION) HELPERS
+// ================================================================================================================
+//
+// a Keyring holds several 32 byte keys by id, one of which is active: Encrypt always uses the active key and
+// embeds its id in the output, Decrypt picks the key by the embedded id, so data encrypted under older keys
+// stays readable after rotation, and Rewrap moves it to the active key at the caller's own pace.
+//
+//	magic "ACR" | version (1) | cipher id | key id length | key id | nonce | ciphertext+tag
+//
+// the header is authenticated together with the caller's associated data.
+
+const keyringFormatVersion = 1
+
+var keyringMagic = []byte("ACR")
+
+var (
+	// ErrKeyringKeyNotFound is returned when the data names a key id the keyring does not hold
+	ErrKeyringKeyNotFound = errors.New("Keyring Key ID Not Found")
+
+	// ErrKeyringDecryptFailed is returned when the key or associated data is wrong, or the data was altered
+	ErrKeyringDecryptFailed = errors.New("Keyring Decrypt Failed: Associated Data Mismatch or Data Altered")
+)
+
+// KeyringKeyDecrypter unwraps an encrypted data key into its 32 byte plaintext form,
+// implemented by the kms wrapper's KMS.DecryptDataKey for keys from GenerateDataKeyAes256
+type KeyringKeyDecrypter interface {
+	DecryptDataKey(cipherKey string) ([]byte, error)
+}
+
+// Keyring holds versioned 32 byte keys by id with one active key, and is safe for concurrent use
+type Keyring struct {
+	// Cipher used by Encrypt and Rewrap, 0 = AeadAesGcm; Decrypt reads the cipher from the data
+	Cipher AeadAlgorithm
+
+	mu     sync.RWMutex
+	keys   map[string][]byte
+	active string
+}
+
+// keyringFile is the json layout read by LoadFromFile
+type keyringFile struct {
+	Active string            `json:"active"`
+	Keys   map[string]string `json:"keys"`
+}
+
+// NewKeyring returns an empty keyring
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string][]byte)}
+}
+
+// AddKey adds or replaces the 32 byte key with id (1 to 255 printable ascii characters),
+// the first key added becomes active
+func (kr *Keyring) AddKey(id string, key []byte) error {
+	if err := keyringValidateID(id); err != nil {
+		return err
+	}
+	if len(key) != 32 {
+		return fmt.Errorf("Keyring Key '%s' Must Be 32 Bytes", id)
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	if kr.keys == nil {
+		kr.keys = make(map[string][]byte)
+	}
+
+	if old, ok := kr.keys[id]; ok {
+		clear(old)
+	}
+
+	kr.keys[id] = append([]byte(nil), key...)
+
+	if kr.active == "" {
+		kr.active = id
+	}
+
+	return nil
+}
+
+// AddWrappedKey unwraps cipherKey with decrypter, such as a kms.KMS holding the cmk that generated the
+// data key, and adds the plaintext key with id
+func (kr *Keyring) AddWrappedKey(id string, cipherKey string, decrypter KeyringKeyDecrypter) error {
+	if decrypter == nil {
+		return errors.New("Keyring Key Decrypter is Required")
+	}
+
+	key, err := decrypter.DecryptDataKey(cipherKey)
+	if err != nil {
+		return fmt.Errorf("Keyring Unwrap Key '%s' Failed: %w", id, err)
+	}
+	defer clear(key)
+
+	return kr.AddKey(id, key)
+}
+
+// RemoveKey removes the key with id, the active key cannot be removed
+func (kr *Keyring) RemoveKey(id string) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	key, ok := kr.keys[id]
+	if !ok {
+		return ErrKeyringKeyNotFound
+	}
+	if id == kr.active {
+		return fmt.Errorf("Keyring Active Key '%s' Cannot Be Removed", id)
+	}
+
+	clear(key)
+	delete(kr.keys, id)
+	return nil
+}
+
+// SetActive selects the key used by Encrypt and Rewrap
+func (kr *Keyring) SetActive(id string) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	if _, ok := kr.keys[id]; !ok {
+		return ErrKeyringKeyNotFound
+	}
+
+	kr.active = id
+	return nil
+}
+
+// ActiveKeyID returns the id of the active key, blank when the keyring is empty
+func (kr *Keyring) ActiveKeyID() string {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	return kr.active
+}
+
+// KeyIDs returns the ids of all keys, sorted
+func (kr *Keyring) KeyIDs() []string {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	ids := make([]string, 0, len(kr.keys))
+	for id := range kr.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// EncryptBytes encrypts plaintext with the active key, authenticating associatedData (may be nil)
+func (kr *Keyring) EncryptBytes(plaintext []byte, associatedData []byte) ([]byte, error) {
+	alg := kr.Cipher
+	if alg == 0 {
+		alg = AeadAesGcm
+	}
+
+	id, aead, err := kr.aeadFor("", alg)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(keyringMagic)+3+len(id))
+	header = append(header, keyringMagic...)
+	header = append(header, keyringFormatVersion, byte(alg), byte(len(id)))
+	header = append(header, id...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+
+	return aead.Seal(out, nonce, plaintext, kdfAdditionalData(header, associatedData)), nil
+}
+
+// DecryptBytes decrypts data produced by EncryptBytes with the key named in its header
+func (kr *Keyring) DecryptBytes(sealed []byte, associatedData []byte) ([]byte, error) {
+	id, alg, headerLen, err := keyringParseHeader(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	_, aead, err := kr.aeadFor(id, alg)
+	if err != nil {
+		return nil, err
+	}
+
+	body := sealed[headerLen:]
+	if len(body) < aead.NonceSize()+aead.Overhead() {
+		return nil, errors.New("Cipher Text Smaller Than Nonce Size")
+	}
+
+	plaintext, err := aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], kdfAdditionalData(sealed[:headerLen], associatedData))
+	if err != nil {
+		return nil, ErrKeyringDecryptFailed
+	}
+
+	return plaintext, nil
+}
+
+// aeadFor returns the aead cipher of alg for the key with id, blank id = the active key, along with the key id;
+// the cipher is built under the lock, as AddKey and RemoveKey zero replaced and removed keys in place
+func (kr *Keyring) aeadFor(id string, alg AeadAlgorithm) (string, cipher.AEAD, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	if id == "" {
+		if id = kr.active; id == "" {
+			return "", nil, errors.New("Keyring Has No Active Key")
+		}
+	}
+
+	key, ok := kr.keys[id]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: '%s'", ErrKeyringKeyNotFound, id)
+	}
+
+	aead, err := newAead(alg, key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return id, aead, nil
+}
+
+// Encrypt encrypts data with the active key, the result is represented in hex
+func (kr *Keyring) Encrypt(data string, associatedData string) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("Data to Encrypt is Required")
+	}
+
+	sealed, err := kr.EncryptBytes([]byte(data), []byte(associatedData))
+	if err != nil {
+		return "", err
+	}
+
+	return util.ByteToHex(sealed), nil
+}
+
+// Decrypt decrypts hex data produced by Encrypt
+func (kr *Keyring) Decrypt(data string, associatedData string) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("Data to Decrypt is Required")
+	}
+
+	sealed, err := util.HexToByte(data)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := kr.DecryptBytes(sealed, []byte(associatedData))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// RewrapBytes re-encrypts sealed under the active key, rewrapped is false (and sealed returned as is)
+// when it already uses the active key; decrypting with the old key verifies the data first
+func (kr *Keyring) RewrapBytes(sealed []byte, associatedData []byte) (out []byte, rewrapped bool, err error) {
+	id, _, _, err := keyringParseHeader(sealed)
+	if err != nil {
+		return nil, false, err
+	}
+
+	plaintext, err := kr.DecryptBytes(sealed, associatedData)
+	if err != nil {
+		return nil, false, err
+	}
+	defer clear(plaintext)
+
+	if id == kr.ActiveKeyID() {
+		return sealed, false, nil
+	}
+
+	if out, err = kr.EncryptBytes(plaintext, associatedData); err != nil {
+		return nil, false, err
+	}
+
+	return out, true, nil
+}
+
+// Rewrap is RewrapBytes for hex data produced by Encrypt
+func (kr *Keyring) Rewrap(data string, associatedData string) (out string, rewrapped bool, err error) {
+	sealed, err := util.HexToByte(data)
+	if err != nil {
+		return "", false, err
+	}
+
+	b, rewrapped, err := kr.RewrapBytes(sealed, []byte(associatedData))
+	if err != nil {
+		return "", false, err
+	}
+
+	if !rewrapped {
+		return data, false, nil
+	}
+
+	return util.ByteToHex(b), true, nil
+}
+
+// KeyIDOf returns the key id embedded in data produced by EncryptBytes, without decrypting
+func KeyIDOf(sealed []byte) (string, error) {
+	id, _, _, err := keyringParseHeader(sealed)
+	return id, err
+}
+
+// LoadFromEnv adds keys from environment variables named prefix + key id, such as APP_KEY_2026A with
+// prefix APP_KEY_, each holding a 32 byte key in hex or base64; prefix + ACTIVE names the active key id,
+// and may be omitted when exactly one key is found
+func (kr *Keyring) LoadFromEnv(prefix string) error {
+	if prefix == "" {
+		return errors.New("Keyring Env Prefix is Required")
+	}
+
+	active := ""
+	found := 0
+
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+
+		id, ok := strings.CutPrefix(name, prefix)
+		if !ok || id == "" {
+			continue
+		}
+
+		if id == "ACTIVE" {
+			active = strings.TrimSpace(value)
+			continue
+		}
+
+		key, err := keyringDecodeKey(value)
+		if err != nil {
+			return fmt.Errorf("Keyring Env %s: %w", name, err)
+		}
+
+		err = kr.AddKey(id, key)
+		clear(key)
+		if err != nil {
+			return err
+		}
+		found++
+	}
+
+	if found == 0 {
+		return fmt.Errorf("Keyring Env Has No Keys With Prefix '%s'", prefix)
+	}
+
+	return kr.loadActive(active, found)
+}
+
+// LoadFromFile adds keys from a json file, {"active": "2026a", "keys": {"2025b": "<hex or base64>", "2026a": "..."}},
+// read with util.FileReadBytes; active may be omitted when the file holds exactly one key
+func (kr *Keyring) LoadFromFile(path string) error {
+	data, err := util.FileReadBytes(path)
+	if err != nil {
+		return err
+	}
+	defer clear(data)
+
+	var f keyringFile
+	if err = json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("Keyring File Parse Failed: %w", err)
+	}
+
+	if len(f.Keys) == 0 {
+		return errors.New("Keyring File Has No Keys")
+	}
+
+	for id, v := range f.Keys {
+		key, err := keyringDecodeKey(v)
+		if err != nil {
+			return fmt.Errorf("Keyring File Key '%s': %w", id, err)
+		}
+
+		err = kr.AddKey(id, key)
+		clear(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return kr.loadActive(f.Active, len(f.Keys))
+}
+
+// loadActive applies the active key id named by a loader, required when more than one key was loaded
+func (kr *Keyring) loadActive(active string, loaded int) error {
+	if active != "" {
+		return kr.SetActive(active)
+	}
+
+	// a single key loaded into an empty keyring was already made active by AddKey
+	if loaded > 1 {
+		return errors.New("Keyring Active Key ID is Required When Loading Multiple Keys")
+	}
+
+	return nil
+}
+
+// keyringDecodeKey decodes a 32 byte key from hex or base64 (standard or url, padded or raw)
+func keyringDecodeKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+
+	if len(s) == 64 {
+		if b, err := hex.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil && len(b) == 32 {
+			return b, nil
+		}
+	}
+
+	return nil, errors.New("Key Must Be 32 Bytes in Hex or Base64")
+}
+
+func keyringValidateID(id string) error {
+	if len(id) == 0 || len(id) > 255 {
+		return errors.New("Keyring Key ID Must Be 1 to 255 Characters")
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return fmt.Errorf("Keyring Key ID '%s' Must Be Printable ASCII Without Spaces", id)
+		}
+	}
+
+	return nil
+}
+
+// keyringParseHeader decodes the header of sealed
+func keyringParseHeader(sealed []byte) (id string, alg AeadAlgorithm, headerLen int, err error) {
+	if len(sealed) < 6 || !bytes.Equal(sealed[:3], keyringMagic) {
+		return "", 0, 0, errors.New("Data Is Not Keyring Encrypted Format")
+	}
+
+	if sealed[3] != keyringFormatVersion {
+		return "", 0, 0, fmt.Errorf("Keyring Encrypted Format Version %d Not Supported", sealed[3])
+	}
+
+	idLen := int(sealed[5])
+	if idLen == 0 || len(sealed) < 6+idLen {
+		return "", 0, 0, errors.New("Keyring Encrypted Header Key ID Invalid")
+	}
+
+	return string(sealed[6 : 6+idLen]), AeadAlgorithm(sealed[4]), 6 + idLen, nil
+}
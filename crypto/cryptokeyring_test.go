@@ -0,0 +1,293 @@
+package crypto
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// fakeKeyDecrypter stands in for kms.KMS, unwrapping "wrapped" keys by hex decoding them
+type fakeKeyDecrypter struct {
+	calls int
+}
+
+func (f *fakeKeyDecrypter) DecryptDataKey(cipherKey string) ([]byte, error) {
+	f.calls++
+	if cipherKey == "" {
+		return nil, errors.New("CipherKey is Required")
+	}
+	return hex.DecodeString(cipherKey)
+}
+
+func TestKeyring_RotationAndRewrap(t *testing.T) {
+	kr := NewKeyring()
+	if err := kr.AddKey("2025b", testStreamKey(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	old, err := kr.Encrypt("4111111111111111", "merchant-9")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rotate: new data uses the new key, old data still decrypts
+	if err := kr.AddKey("2026a", testStreamKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	if kr.ActiveKeyID() != "2025b" {
+		t.Fatalf("adding a key must not change the active key, got %s", kr.ActiveKeyID())
+	}
+	if err := kr.SetActive("2026a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if dec, err := kr.Decrypt(old, "merchant-9"); err != nil || dec != "4111111111111111" {
+		t.Fatalf("old data decrypt = %q, %v", dec, err)
+	}
+
+	rewrapped, changed, err := kr.Rewrap(old, "merchant-9")
+	if err != nil || !changed {
+		t.Fatalf("rewrap = %v, %v", changed, err)
+	}
+
+	sealed, _ := hex.DecodeString(rewrapped)
+	if id, _ := KeyIDOf(sealed); id != "2026a" {
+		t.Fatalf("rewrapped key id = %s", id)
+	}
+
+	// already current: returned unchanged
+	if again, changed, err := kr.Rewrap(rewrapped, "merchant-9"); err != nil || changed || again != rewrapped {
+		t.Fatalf("second rewrap = %v, %v", changed, err)
+	}
+
+	// once everything is rewrapped the old key can be retired
+	if err := kr.RemoveKey("2026a"); err == nil {
+		t.Fatal("expected active key removal error")
+	}
+	if err := kr.RemoveKey("2025b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kr.Decrypt(old, "merchant-9"); !errors.Is(err, ErrKeyringKeyNotFound) {
+		t.Fatalf("retired key err = %v", err)
+	}
+	if dec, err := kr.Decrypt(rewrapped, "merchant-9"); err != nil || dec != "4111111111111111" {
+		t.Fatalf("rewrapped decrypt = %q, %v", dec, err)
+	}
+
+	if ids := kr.KeyIDs(); len(ids) != 1 || ids[0] != "2026a" {
+		t.Fatalf("key ids = %v", ids)
+	}
+}
+
+func TestKeyring_TamperingAndCiphers(t *testing.T) {
+	key := testStreamKey(t)
+
+	for _, alg := range []AeadAlgorithm{AeadAesGcm, AeadXChaCha20Poly1305} {
+		kr := NewKeyring()
+		kr.Cipher = alg
+		_ = kr.AddKey("k1", key)
+
+		sealed, err := kr.EncryptBytes([]byte("payload"), []byte("ad"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := kr.DecryptBytes(sealed, []byte("other")); !errors.Is(err, ErrKeyringDecryptFailed) {
+			t.Fatalf("cipher %d associated data err = %v", alg, err)
+		}
+
+		// the key id is authenticated: relabelling to another held key fails
+		_ = kr.AddKey("k2", key)
+		relabelled := append([]byte(nil), sealed...)
+		relabelled[7] = '2'
+		if _, err := kr.DecryptBytes(relabelled, []byte("ad")); !errors.Is(err, ErrKeyringDecryptFailed) {
+			t.Fatalf("cipher %d relabelled err = %v", alg, err)
+		}
+
+		if got, err := kr.DecryptBytes(sealed, []byte("ad")); err != nil || !bytes.Equal(got, []byte("payload")) {
+			t.Fatalf("cipher %d decrypt = %q, %v", alg, got, err)
+		}
+	}
+
+	kr := NewKeyring()
+	if _, err := kr.EncryptBytes([]byte("x"), nil); err == nil {
+		t.Fatal("expected no active key error")
+	}
+	if err := kr.AddKey("bad id", key); err == nil {
+		t.Fatal("expected key id error")
+	}
+	if err := kr.AddKey("k", key[:16]); err == nil {
+		t.Fatal("expected key size error")
+	}
+	if _, err := KeyIDOf([]byte("not keyring data")); err == nil {
+		t.Fatal("expected format error")
+	}
+}
+
+func TestKeyring_LoadFromEnv(t *testing.T) {
+	k1, k2 := testStreamKey(t), testStreamKey(t)
+
+	t.Setenv("KRTEST_KEY_2025B", hex.EncodeToString(k1))
+	t.Setenv("KRTEST_KEY_2026A", base64.StdEncoding.EncodeToString(k2))
+
+	kr := NewKeyring()
+	if err := kr.LoadFromEnv("KRTEST_KEY_"); err == nil {
+		t.Fatal("expected active key id required error")
+	}
+
+	t.Setenv("KRTEST_KEY_ACTIVE", "2026A")
+
+	kr = NewKeyring()
+	if err := kr.LoadFromEnv("KRTEST_KEY_"); err != nil {
+		t.Fatal(err)
+	}
+	if kr.ActiveKeyID() != "2026A" || len(kr.KeyIDs()) != 2 {
+		t.Fatalf("active = %s, ids = %v", kr.ActiveKeyID(), kr.KeyIDs())
+	}
+
+	// data from a keyring holding only the old key decrypts after the env load
+	old := NewKeyring()
+	_ = old.AddKey("2025B", k1)
+	enc, _ := old.Encrypt("hello", "")
+	if dec, err := kr.Decrypt(enc, ""); err != nil || dec != "hello" {
+		t.Fatalf("decrypt = %q, %v", dec, err)
+	}
+
+	if err := NewKeyring().LoadFromEnv("KRTEST_NONE_"); err == nil {
+		t.Fatal("expected no keys error")
+	}
+}
+
+func TestKeyring_LoadFromFileAndWrappedKey(t *testing.T) {
+	k1 := testStreamKey(t)
+	path := filepath.Join(t.TempDir(), "keyring.json")
+
+	content := `{"keys": {"v1": "` + base64.RawURLEncoding.EncodeToString(k1) + `"}}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	kr := NewKeyring()
+	if err := kr.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if kr.ActiveKeyID() != "v1" {
+		t.Fatalf("single key file active = %s", kr.ActiveKeyID())
+	}
+
+	enc, err := kr.Encrypt("settlement batch", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// add a kms wrapped key and rotate to it
+	dec := &fakeKeyDecrypter{}
+	if err := kr.AddWrappedKey("v2", hex.EncodeToString(testStreamKey(t)), dec); err != nil || dec.calls != 1 {
+		t.Fatalf("add wrapped key = %v, calls %d", err, dec.calls)
+	}
+	if err := kr.AddWrappedKey("v3", "", dec); err == nil {
+		t.Fatal("expected unwrap error")
+	}
+	_ = kr.SetActive("v2")
+
+	rewrapped, changed, err := kr.Rewrap(enc, "")
+	if err != nil || !changed {
+		t.Fatalf("rewrap = %v, %v", changed, err)
+	}
+	if got, err := kr.Decrypt(rewrapped, ""); err != nil || got != "settlement batch" {
+		t.Fatalf("decrypt = %q, %v", got, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"keys": {"v1": "short"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewKeyring().LoadFromFile(path); err == nil {
+		t.Fatal("expected key decode error")
+	}
+}
+
+func TestKeyring_ConcurrentRotation(t *testing.T) {
+	key0, key1, tmp := testStreamKey(t), testStreamKey(t), testStreamKey(t)
+
+	kr := NewKeyring()
+	_ = kr.AddKey("k0", key0)
+	_ = kr.AddKey("k1", key1)
+
+	// run with -race: replacing and removing keys zeroes them in place while other goroutines encrypt and decrypt,
+	// k0 and k1 keep the same bytes throughout, so every round trip must succeed
+	stop := make(chan struct{})
+	var rotator sync.WaitGroup
+
+	rotator.Add(1)
+	go func() {
+		defer rotator.Done()
+
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			_ = kr.AddKey("k0", key0)
+			_ = kr.AddKey("k1", key1)
+			_ = kr.SetActive([]string{"k0", "k1"}[i%2])
+			_ = kr.AddKey("tmp", tmp)
+			_ = kr.RemoveKey("tmp")
+		}
+	}()
+
+	var workers sync.WaitGroup
+	errs := make(chan error, 8)
+
+	for w := 0; w < 8; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+
+			for i := 0; i < 200; i++ {
+				sealed, err := kr.EncryptBytes([]byte("order 1001"), nil)
+				if err == nil {
+					var plain []byte
+					if plain, err = kr.DecryptBytes(sealed, nil); err == nil && string(plain) != "order 1001" {
+						err = errors.New("round trip mismatch")
+					}
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	workers.Wait()
+	close(stop)
+	rotator.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,400 @@
+package crypto
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	util "github.com/aldelo/common"
+)
+
+// ================================================================================================================
+// STREAMING (CHUNKED AEAD) ENCRYPTION HELPERS
+// ================================================================================================================
+//
+// NewEncryptWriter / NewDecryptReader encrypt arbitrarily large data in fixed size chunks with constant memory,
+// following the STREAM construction (Hoang, Reyhanitabar, Rogaway, Vizar 2015):
+//
+//	header = magic "ACS" | version (1) | cipher id | chunk size uint32 | salt (32 bytes)
+//	chunk  = aead seal of up to chunk size plaintext bytes, nonce = zeros | chunk counter uint32 | final flag byte
+//
+// every stream uses its own key, derived with hkdf-sha256 from the caller's key and the random salt, so nonces
+// never repeat across streams; the header and the caller's associated data are authenticated in every chunk.
+// the final flag makes truncation at a chunk boundary detectable, and the counter makes reordered or dropped
+// chunks fail; plaintext is only released by the reader after its chunk authenticates.
+
+const (
+	streamFormatVersion    = 1
+	streamSaltSize         = 32
+	streamHeaderSize       = 3 + 1 + 1 + 4 + streamSaltSize
+	streamDefaultChunkSize = 64 * 1024
+	streamMaxChunkSize     = 16 * 1024 * 1024
+	streamKeyInfo          = "aldelo/common crypto stream v1"
+)
+
+var streamMagic = []byte("ACS")
+
+var (
+	// ErrStreamAuthFailed is returned by the decrypt reader when a chunk fails authentication:
+	// wrong key or associated data, or altered, reordered or dropped chunks
+	ErrStreamAuthFailed = errors.New("Stream Decrypt Failed: Wrong Key, Associated Data Mismatch, or Data Altered")
+
+	// ErrStreamTruncated is returned by the decrypt reader when the stream ends before its final chunk
+	ErrStreamTruncated = errors.New("Stream Decrypt Failed: Data Truncated")
+)
+
+// StreamEncryptOptions configures NewEncryptWriter, zero fields take defaults
+type StreamEncryptOptions struct {
+	Cipher         AeadAlgorithm // default AeadAesGcm
+	ChunkSize      int           // plaintext bytes per chunk, default 64 KiB, max 16 MiB
+	AssociatedData []byte        // authenticated but not encrypted, must be given again to decrypt
+}
+
+// encryptWriter is the io.WriteCloser returned by NewEncryptWriter
+type encryptWriter struct {
+	w         io.Writer
+	aead      cipher.AEAD
+	ad        []byte
+	nonce     []byte
+	counter   uint64
+	chunkSize int
+	buf       []byte
+	out       []byte
+	closed    bool
+	err       error
+}
+
+// NewEncryptWriter returns a writer that encrypts everything written to it into w with the 32 byte key,
+// Close must be called to write the final chunk, and does not close w; the header is written immediately
+func NewEncryptWriter(w io.Writer, key []byte, opts *StreamEncryptOptions) (io.WriteCloser, error) {
+	if w == nil {
+		return nil, errors.New("Stream Writer is Required")
+	}
+	if len(key) != 32 {
+		return nil, errors.New("Key Must Be 32 Bytes")
+	}
+
+	var o StreamEncryptOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Cipher == 0 {
+		o.Cipher = AeadAesGcm
+	}
+	if o.ChunkSize == 0 {
+		o.ChunkSize = streamDefaultChunkSize
+	}
+	if o.ChunkSize < 1 || o.ChunkSize > streamMaxChunkSize {
+		return nil, fmt.Errorf("Chunk Size Must Be 1 to %d Bytes", streamMaxChunkSize)
+	}
+
+	header := make([]byte, 0, streamHeaderSize)
+	header = append(header, streamMagic...)
+	header = append(header, streamFormatVersion, byte(o.Cipher))
+	header = binary.BigEndian.AppendUint32(header, uint32(o.ChunkSize))
+
+	salt := make([]byte, streamSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	header = append(header, salt...)
+
+	aead, err := streamAead(o.Cipher, key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		w:         w,
+		aead:      aead,
+		ad:        kdfAdditionalData(header, o.AssociatedData),
+		nonce:     make([]byte, aead.NonceSize()),
+		chunkSize: o.ChunkSize,
+		buf:       make([]byte, 0, o.ChunkSize),
+		out:       make([]byte, 0, o.ChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// Write encrypts p, buffering at most one chunk
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	if e.closed {
+		return 0, errors.New("Stream Encrypt Writer Already Closed")
+	}
+
+	n := 0
+
+	for len(p) > 0 {
+		// a full chunk is only sealed once more data arrives, so the final chunk is never left empty needlessly
+		if len(e.buf) == e.chunkSize {
+			if e.err = e.seal(false); e.err != nil {
+				return n, e.err
+			}
+		}
+
+		k := min(e.chunkSize-len(e.buf), len(p))
+		e.buf = append(e.buf, p[:k]...)
+		p = p[k:]
+		n += k
+	}
+
+	return n, nil
+}
+
+// Close seals the final chunk, the underlying writer is not closed
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return e.err
+	}
+	e.closed = true
+
+	if e.err != nil {
+		return e.err
+	}
+
+	e.err = e.seal(true)
+	return e.err
+}
+
+// seal encrypts and writes the buffered chunk
+func (e *encryptWriter) seal(final bool) error {
+	if e.counter > math.MaxUint32 {
+		return errors.New("Stream Too Long: Chunk Counter Exhausted")
+	}
+
+	streamNonce(e.nonce, e.counter, final)
+	e.out = e.aead.Seal(e.out[:0], e.nonce, e.buf, e.ad)
+	e.counter++
+	e.buf = e.buf[:0]
+
+	_, err := e.w.Write(e.out)
+	return err
+}
+
+// decryptReader is the io.Reader returned by NewDecryptReader
+type decryptReader struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	ad      []byte
+	nonce   []byte
+	counter uint64
+	enc     []byte // one sealed chunk plus one look-ahead byte
+	pending int    // look-ahead bytes carried into enc
+	plain   []byte
+	off     int
+	done    bool
+	err     error
+}
+
+// NewDecryptReader returns a reader of the plaintext of a stream written by NewEncryptWriter,
+// associatedData must match what was given to encrypt; the header is read and validated immediately,
+// and Read returns ErrStreamAuthFailed or ErrStreamTruncated when the stream was altered or cut short
+func NewDecryptReader(r io.Reader, key []byte, associatedData []byte) (io.Reader, error) {
+	if r == nil {
+		return nil, errors.New("Stream Reader is Required")
+	}
+	if len(key) != 32 {
+		return nil, errors.New("Key Must Be 32 Bytes")
+	}
+
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrStreamTruncated
+		}
+		return nil, err
+	}
+
+	if !bytes.Equal(header[:3], streamMagic) {
+		return nil, errors.New("Data Is Not Stream Encrypted Format")
+	}
+	if header[3] != streamFormatVersion {
+		return nil, fmt.Errorf("Stream Encrypted Format Version %d Not Supported", header[3])
+	}
+
+	chunkSize := int(binary.BigEndian.Uint32(header[5:9]))
+	if chunkSize < 1 || chunkSize > streamMaxChunkSize {
+		return nil, fmt.Errorf("Stream Chunk Size %d Out of Range", chunkSize)
+	}
+
+	aead, err := streamAead(AeadAlgorithm(header[4]), key, header[9:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{
+		r:     r,
+		aead:  aead,
+		ad:    kdfAdditionalData(header, associatedData),
+		nonce: make([]byte, aead.NonceSize()),
+		enc:   make([]byte, chunkSize+aead.Overhead()+1),
+		plain: make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// Read returns authenticated plaintext
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for d.off == len(d.plain) {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+
+		if d.err = d.open(); d.err != nil {
+			return 0, d.err
+		}
+	}
+
+	n := copy(p, d.plain[d.off:])
+	d.off += n
+	return n, nil
+}
+
+// open reads and authenticates the next chunk, a short read (end of stream) marks the final chunk
+func (d *decryptReader) open() error {
+	n, err := io.ReadFull(d.r, d.enc[d.pending:])
+	total := d.pending + n
+
+	final := false
+	switch {
+	case err == nil:
+		// a full chunk plus one more byte, so this is not the final chunk
+		total--
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	default:
+		return err
+	}
+
+	if total < d.aead.Overhead() {
+		return ErrStreamTruncated
+	}
+
+	if d.counter > math.MaxUint32 {
+		return errors.New("Stream Too Long: Chunk Counter Exhausted")
+	}
+
+	streamNonce(d.nonce, d.counter, final)
+
+	plain, openErr := d.aead.Open(d.plain[:0], d.nonce, d.enc[:total], d.ad)
+	if openErr != nil {
+		if final {
+			// a non-final chunk read as final means the stream was cut at a chunk boundary
+			streamNonce(d.nonce, d.counter, false)
+			if _, probe := d.aead.Open(nil, d.nonce, d.enc[:total], d.ad); probe == nil {
+				return ErrStreamTruncated
+			}
+		}
+		return ErrStreamAuthFailed
+	}
+
+	d.plain, d.off = plain, 0
+	d.counter++
+
+	if final {
+		d.done = true
+	} else {
+		d.enc[0] = d.enc[total]
+		d.pending = 1
+	}
+
+	return nil
+}
+
+// streamAead derives the per stream key from key and salt, and returns its aead cipher
+func streamAead(alg AeadAlgorithm, key []byte, salt []byte) (cipher.AEAD, error) {
+	streamKey, err := hkdf.Key(sha256.New, key, salt, streamKeyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(streamKey)
+
+	return newAead(alg, streamKey)
+}
+
+// streamNonce sets nonce to zeros | counter (4 bytes big endian) | final flag
+func streamNonce(nonce []byte, counter uint64, final bool) {
+	clear(nonce)
+
+	n := len(nonce)
+	binary.BigEndian.PutUint32(nonce[n-5:n-1], uint32(counter))
+
+	if final {
+		nonce[n-1] = 1
+	}
+}
+
+// EncryptFile encrypts the file at srcPath into dstPath with the 32 byte key, streaming with constant memory;
+// dstPath is written atomically (util.FileWriteAtomicFunc) with 0600 permission for a new file,
+// and source reads use util.FileOpenRead symlink guards
+func EncryptFile(srcPath string, dstPath string, key []byte, opts *StreamEncryptOptions) error {
+	src, err := util.FileOpenRead(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return util.FileWriteAtomicFunc(dstPath, 0o600, func(w io.Writer) error {
+		ew, err := NewEncryptWriter(w, key, opts)
+		if err != nil {
+			return err
+		}
+
+		if _, err = io.Copy(ew, src); err != nil {
+			return err
+		}
+
+		return ew.Close()
+	})
+}
+
+// DecryptFile decrypts a file written by EncryptFile into dstPath, since dstPath is written atomically,
+// a stream that fails authentication or is truncated leaves no partial plaintext behind
+func DecryptFile(srcPath string, dstPath string, key []byte, associatedData []byte) error {
+	src, err := util.FileOpenRead(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return util.FileWriteAtomicFunc(dstPath, 0o600, func(w io.Writer) error {
+		dr, err := NewDecryptReader(src, key, associatedData)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(w, dr)
+		return err
+	})
+}
@@ -0,0 +1,191 @@
+package crypto
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+)
+
+func testStreamKey(t *testing.T) []byte {
+	t.Helper()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func testStreamEncrypt(t *testing.T, key []byte, plain []byte, opts *StreamEncryptOptions) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, key, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// write in uneven pieces to cross chunk boundaries mid-write
+	for len(plain) > 0 {
+		n := min(7, len(plain))
+		if _, err := w.Write(plain[:n]); err != nil {
+			t.Fatal(err)
+		}
+		plain = plain[n:]
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestStreamEncrypt_RoundTripSizes(t *testing.T) {
+	key := testStreamKey(t)
+
+	for _, cipherAlg := range []AeadAlgorithm{AeadAesGcm, AeadXChaCha20Poly1305} {
+		opts := &StreamEncryptOptions{Cipher: cipherAlg, ChunkSize: 16, AssociatedData: []byte("export-7")}
+
+		for _, size := range []int{0, 1, 15, 16, 17, 48, 100} {
+			plain := bytes.Repeat([]byte{'a'}, size)
+			for i := range plain {
+				plain[i] = byte(i)
+			}
+
+			sealed := testStreamEncrypt(t, key, plain, opts)
+
+			r, err := NewDecryptReader(iotest.OneByteReader(bytes.NewReader(sealed)), key, []byte("export-7"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := io.ReadAll(r)
+			if err != nil || !bytes.Equal(got, plain) {
+				t.Fatalf("cipher %d size %d: got %d bytes, %v", cipherAlg, size, len(got), err)
+			}
+		}
+	}
+}
+
+func TestStreamEncrypt_DetectsTamperingAndTruncation(t *testing.T) {
+	key := testStreamKey(t)
+	opts := &StreamEncryptOptions{ChunkSize: 16}
+	plain := bytes.Repeat([]byte("0123456789"), 5) // 50 bytes = 3 full chunks + 2 byte final chunk
+
+	sealed := testStreamEncrypt(t, key, plain, opts)
+	sealedChunk := 16 + 16 // chunk size + gcm tag
+
+	readAll := func(data []byte, k []byte, ad []byte) error {
+		r, err := NewDecryptReader(bytes.NewReader(data), k, ad)
+		if err != nil {
+			return err
+		}
+		_, err = io.ReadAll(r)
+		return err
+	}
+
+	// cut exactly after the second chunk: every remaining chunk authenticates, only the final flag is missing
+	cut := sealed[:streamHeaderSize+2*sealedChunk]
+	if err := readAll(cut, key, nil); !errors.Is(err, ErrStreamTruncated) {
+		t.Fatalf("chunk boundary truncation err = %v", err)
+	}
+
+	// cut inside a chunk: the partial chunk cannot authenticate, or is shorter than a tag
+	if err := readAll(sealed[:streamHeaderSize+sealedChunk+20], key, nil); !errors.Is(err, ErrStreamAuthFailed) {
+		t.Fatalf("mid chunk truncation err = %v", err)
+	}
+	if err := readAll(sealed[:len(sealed)-3], key, nil); !errors.Is(err, ErrStreamTruncated) {
+		t.Fatalf("short final chunk err = %v", err)
+	}
+
+	flipped := append([]byte(nil), sealed...)
+	flipped[streamHeaderSize+sealedChunk+3] ^= 1
+	if err := readAll(flipped, key, nil); !errors.Is(err, ErrStreamAuthFailed) {
+		t.Fatalf("bit flip err = %v", err)
+	}
+
+	// swap the first two chunks
+	swapped := append([]byte(nil), sealed...)
+	c0 := streamHeaderSize
+	copy(swapped[c0:c0+sealedChunk], sealed[c0+sealedChunk:c0+2*sealedChunk])
+	copy(swapped[c0+sealedChunk:c0+2*sealedChunk], sealed[c0:c0+sealedChunk])
+	if err := readAll(swapped, key, nil); !errors.Is(err, ErrStreamAuthFailed) {
+		t.Fatalf("reordered chunks err = %v", err)
+	}
+
+	if err := readAll(sealed, key, []byte("other")); !errors.Is(err, ErrStreamAuthFailed) {
+		t.Fatalf("associated data err = %v", err)
+	}
+
+	if err := readAll(sealed, testStreamKey(t), nil); !errors.Is(err, ErrStreamAuthFailed) {
+		t.Fatalf("wrong key err = %v", err)
+	}
+
+	if err := readAll(sealed[:10], key, nil); !errors.Is(err, ErrStreamTruncated) {
+		t.Fatalf("short header err = %v", err)
+	}
+
+	if _, err := NewEncryptWriter(io.Discard, key[:16], nil); err == nil {
+		t.Fatal("expected key size error")
+	}
+}
+
+func TestEncryptFile_DecryptFile(t *testing.T) {
+	dir := t.TempDir()
+	key := testStreamKey(t)
+
+	src := filepath.Join(dir, "export.csv")
+	plain := bytes.Repeat([]byte("id,amount\n1,10.00\n"), 10000)
+	if err := os.WriteFile(src, plain, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	enc := filepath.Join(dir, "export.csv.enc")
+	if err := EncryptFile(src, enc, key, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := filepath.Join(dir, "export.out.csv")
+	if err := DecryptFile(enc, dec, key, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := os.ReadFile(dec); !bytes.Equal(got, plain) {
+		t.Fatal("decrypted file differs")
+	}
+
+	// a corrupted file leaves no partial plaintext at the destination
+	data, _ := os.ReadFile(enc)
+	data[len(data)-1] ^= 1
+	_ = os.WriteFile(enc, data, 0o600)
+
+	bad := filepath.Join(dir, "bad.csv")
+	if err := DecryptFile(enc, bad, key, nil); !errors.Is(err, ErrStreamAuthFailed) {
+		t.Fatalf("corrupted file err = %v", err)
+	}
+	if _, err := os.Stat(bad); !os.IsNotExist(err) {
+		t.Fatal("partial plaintext left behind")
+	}
+}
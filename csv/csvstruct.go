@@ -0,0 +1,521 @@
+package csv
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	util "github.com/aldelo/common"
+)
+
+// utf8BOM is the byte order mark some spreadsheet exports prepend to csv files
+const utf8BOM = "\xEF\xBB\xBF"
+
+// StructReaderOptions configures NewStructReader / OpenStructReader
+type StructReaderOptions struct {
+	Delimiter        rune // field delimiter, defaults to ','
+	Comment          rune // if not 0, lines beginning with this rune are ignored
+	LazyQuotes       bool // if true, a quote may appear in an unquoted field and a non-doubled quote may appear in a quoted field
+	TrimLeadingSpace bool // if true, leading white space in a field is ignored
+
+	// HeaderRow indicates the first row holds column titles,
+	// columns are then matched to struct fields by name instead of by the field's pos tag
+	HeaderRow bool
+
+	// HeaderTagName is the struct tag holding the column title of a field (defaults to json),
+	// fields without the tag are matched by field name, matching is case-insensitive
+	HeaderTagName string
+}
+
+// StructWriterOptions configures NewStructWriter / CreateStructWriter
+type StructWriterOptions struct {
+	Delimiter rune // field delimiter, defaults to ','
+	UseCRLF   bool // if true, lines end with \r\n instead of \n
+
+	// HeaderRow writes a title row before the first record, titles come from HeaderTagName
+	HeaderRow bool
+
+	// HeaderTagName is the struct tag holding the column title of a field (defaults to json),
+	// fields without the tag use the field name
+	HeaderTagName string
+}
+
+// RowError describes a csv row that could not be parsed or unmarshaled into the target struct,
+// StructReader returns RowError for the failing row only, reading may continue with the next row
+type RowError struct {
+	Line   int      // 1-based line number in the csv source where the row starts
+	Record []string // raw csv elements of the row, nil if the row could not be parsed
+	Err    error
+}
+
+// Error implements the error interface
+func (e *RowError) Error() string {
+	return fmt.Sprintf("Csv Row at Line %d Failed: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying parse or unmarshal error
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// =====================================================================================================================
+// Struct Reader
+// =====================================================================================================================
+
+// StructReader streams csv rows into struct T using util.UnmarshalCSVToStruct,
+// so all pos, type, size, range, def, setter and validate struct tags apply per row
+type StructReader[T any] struct {
+	mu     sync.Mutex
+	closed bool
+
+	f  *os.File // set when opened via OpenStructReader, closed by Close
+	cr *csv.Reader
+
+	header  []string
+	columns []int // columns[pos] = csv column index feeding that pos, nil when rows are positional
+
+	ParsedCount int // data rows successfully unmarshaled into T
+	TriedCount  int // data rows read, including failed rows
+}
+
+// NewStructReader creates a StructReader over r, T must be a struct type with pos tagged fields;
+// a leading UTF-8 BOM is skipped, and if opts.HeaderRow is true the header row is consumed and mapped here
+func NewStructReader[T any](r io.Reader, opts *StructReaderOptions) (*StructReader[T], error) {
+	if r == nil {
+		return nil, errors.New("New Struct Reader Failed: " + "Reader Nil")
+	}
+
+	if opts == nil {
+		opts = &StructReaderOptions{}
+	}
+
+	names, err := structColumnNames(reflect.TypeOf((*T)(nil)).Elem(), opts.HeaderTagName)
+	if err != nil {
+		return nil, fmt.Errorf("New Struct Reader Failed: %w", err)
+	}
+
+	br := bufio.NewReader(r)
+
+	if b, e := br.Peek(len(utf8BOM)); e == nil && string(b) == utf8BOM {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+
+	sr := &StructReader[T]{
+		cr: csv.NewReader(br),
+	}
+
+	if opts.Delimiter != 0 {
+		sr.cr.Comma = opts.Delimiter
+	}
+
+	sr.cr.Comment = opts.Comment
+	sr.cr.LazyQuotes = opts.LazyQuotes
+	sr.cr.TrimLeadingSpace = opts.TrimLeadingSpace
+	sr.cr.FieldsPerRecord = -1
+
+	if opts.HeaderRow {
+		header, e := sr.cr.Read()
+		if e != nil {
+			if e == io.EOF {
+				return nil, errors.New("New Struct Reader Failed: " + "Header Row Missing")
+			}
+			return nil, fmt.Errorf("New Struct Reader Failed: Read Header Row: %w", e)
+		}
+
+		sr.header = header
+		sr.columns = make([]int, len(names))
+
+		index := make(map[string]int, len(header))
+		for i, h := range header {
+			h = strings.ToLower(strings.TrimSpace(h))
+			if _, dup := index[h]; !dup {
+				index[h] = i
+			}
+		}
+
+		matched := false
+
+		for pos, n := range names {
+			sr.columns[pos] = -1
+
+			if i, ok := index[strings.ToLower(n)]; ok && len(n) > 0 {
+				sr.columns[pos] = i
+				matched = true
+			}
+		}
+
+		if !matched {
+			return nil, errors.New("New Struct Reader Failed: " + "Header Row Matches No Struct Fields")
+		}
+	}
+
+	return sr, nil
+}
+
+// OpenStructReader opens the csv file at path and creates a StructReader over it, Close releases the file
+func OpenStructReader[T any](path string, opts *StructReaderOptions) (*StructReader[T], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Open File Failed: %w", err)
+	}
+
+	sr, err := NewStructReader[T](f, opts)
+	if err != nil {
+		_ = f.Close() // close file handle on error to prevent leak
+		return nil, err
+	}
+
+	sr.f = f
+	return sr, nil
+}
+
+// Header returns the header row read by NewStructReader, nil when opts.HeaderRow was false
+func (r *StructReader[T]) Header() []string {
+	if r == nil {
+		return nil
+	}
+
+	return r.header
+}
+
+// Read returns the next row unmarshaled into a new T,
+// at end of input io.EOF is returned, a malformed or invalid row returns *RowError and the next Read continues after it
+func (r *StructReader[T]) Read() (*T, error) {
+	if r == nil {
+		return nil, errors.New("Read Struct Row Failed: " + "Struct Reader Nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil, errors.New("Read Struct Row Failed: " + "Struct Reader Closed")
+	}
+
+	record, err := r.cr.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	r.TriedCount++
+
+	if err != nil {
+		line := 0
+
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			line = pe.StartLine
+		}
+
+		return nil, &RowError{Line: line, Err: err}
+	}
+
+	line, _ := r.cr.FieldPos(0)
+
+	elements := record
+
+	if r.columns != nil {
+		elements = make([]string, len(r.columns))
+
+		for pos, col := range r.columns {
+			if col >= 0 && col < len(record) {
+				elements[pos] = record[col]
+			}
+		}
+	}
+
+	row := new(T)
+
+	// the record is already split by encoding/csv, so hand the elements straight to the unmarshaler;
+	// the joined payload only satisfies its non-blank check
+	if err = util.UnmarshalCSVToStruct(row, strings.Join(record, string(r.cr.Comma)), "", func(string) []string {
+		return elements
+	}); err != nil {
+		return nil, &RowError{Line: line, Record: record, Err: err}
+	}
+
+	r.ParsedCount++
+	return row, nil
+}
+
+// All returns an iterator over the remaining rows for use with range,
+// each failed row yields a nil row and its *RowError, iteration stops at end of input or a non-row error
+func (r *StructReader[T]) All() iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		for {
+			row, err := r.Read()
+			if err == io.EOF {
+				return
+			}
+
+			var re *RowError
+			if err != nil && !errors.As(err, &re) {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(row, err) {
+				return
+			}
+		}
+	}
+}
+
+// Close closes the struct reader, and the underlying file when opened via OpenStructReader
+func (r *StructReader[T]) Close() error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+
+	r.closed = true
+	r.cr = nil
+
+	var err error
+
+	if r.f != nil {
+		err = r.f.Close()
+		r.f = nil
+	}
+
+	return err
+}
+
+// =====================================================================================================================
+// Struct Writer
+// =====================================================================================================================
+
+// StructWriter streams struct T values as csv rows using util.MarshalStructToCSVFields,
+// with quoting of elements that contain the delimiter, quotes or line breaks
+type StructWriter[T any] struct {
+	mu     sync.Mutex
+	closed bool
+
+	f  *os.File // set when created via CreateStructWriter, closed by Close
+	cw *csv.Writer
+
+	WrittenCount int // data rows written, excluding the header row
+}
+
+// NewStructWriter creates a StructWriter over w, T must be a struct type with pos tagged fields;
+// if opts.HeaderRow is true the header row is written here
+func NewStructWriter[T any](w io.Writer, opts *StructWriterOptions) (*StructWriter[T], error) {
+	if w == nil {
+		return nil, errors.New("New Struct Writer Failed: " + "Writer Nil")
+	}
+
+	if opts == nil {
+		opts = &StructWriterOptions{}
+	}
+
+	names, err := structColumnNames(reflect.TypeOf((*T)(nil)).Elem(), opts.HeaderTagName)
+	if err != nil {
+		return nil, fmt.Errorf("New Struct Writer Failed: %w", err)
+	}
+
+	sw := &StructWriter[T]{
+		cw: csv.NewWriter(w),
+	}
+
+	if opts.Delimiter != 0 {
+		sw.cw.Comma = opts.Delimiter
+	}
+
+	sw.cw.UseCRLF = opts.UseCRLF
+
+	if opts.HeaderRow {
+		if err = sw.cw.Write(names); err != nil {
+			return nil, fmt.Errorf("New Struct Writer Failed: Write Header Row: %w", err)
+		}
+	}
+
+	return sw, nil
+}
+
+// CreateStructWriter creates (or truncates) the csv file at path and creates a StructWriter over it,
+// Close flushes and releases the file
+func CreateStructWriter[T any](path string, opts *StructWriterOptions) (*StructWriter[T], error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("Create File Failed: %w", err)
+	}
+
+	sw, err := NewStructWriter[T](f, opts)
+	if err != nil {
+		_ = f.Close() // close file handle on error to prevent leak
+		return nil, err
+	}
+
+	sw.f = f
+	return sw, nil
+}
+
+// Write marshals row and writes it as the next csv row, output is buffered until Flush or Close
+func (w *StructWriter[T]) Write(row *T) error {
+	if w == nil {
+		return errors.New("Write Struct Row Failed: " + "Struct Writer Nil")
+	}
+
+	if row == nil {
+		return errors.New("Write Struct Row Failed: " + "Row Nil")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return errors.New("Write Struct Row Failed: " + "Struct Writer Closed")
+	}
+
+	fields, err := util.MarshalStructToCSVFields(row)
+	if err != nil {
+		return fmt.Errorf("Write Struct Row Failed: %w", err)
+	}
+
+	if err = w.cw.Write(fields); err != nil {
+		return fmt.Errorf("Write Struct Row Failed: %w", err)
+	}
+
+	w.WrittenCount++
+	return nil
+}
+
+// Flush writes any buffered rows to the underlying writer
+func (w *StructWriter[T]) Flush() error {
+	if w == nil {
+		return errors.New("Flush Struct Writer Failed: " + "Struct Writer Nil")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return errors.New("Flush Struct Writer Failed: " + "Struct Writer Closed")
+	}
+
+	w.cw.Flush()
+
+	if err := w.cw.Error(); err != nil {
+		return fmt.Errorf("Flush Struct Writer Failed: %w", err)
+	}
+
+	return nil
+}
+
+// Close flushes buffered rows, and closes the underlying file when created via CreateStructWriter
+func (w *StructWriter[T]) Close() error {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	w.cw.Flush()
+
+	err := w.cw.Error()
+	w.cw = nil
+
+	if w.f != nil {
+		if e := w.f.Close(); e != nil && err == nil {
+			err = e
+		}
+		w.f = nil
+	}
+
+	return err
+}
+
+// =====================================================================================================================
+// Helpers
+// =====================================================================================================================
+
+// structColumnNames returns the column title of each pos in struct type t, indexed by pos;
+// titles come from headerTagName (defaults to json, options such as omitempty are dropped) or the field name,
+// when several fields share a pos (uniqueid groups) the first field wins
+func structColumnNames(t reflect.Type, headerTagName string) ([]string, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Struct Type Required, %s Given", t.Kind())
+	}
+
+	if len(strings.TrimSpace(headerTagName)) == 0 {
+		headerTagName = "json"
+	}
+
+	maxPos := -1
+	posNames := make(map[int]string)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		pos, ok := util.ParseInt32(field.Tag.Get("pos"))
+		if !ok || pos < 0 {
+			continue
+		}
+
+		if _, exists := posNames[pos]; exists {
+			continue
+		}
+
+		name := field.Name
+
+		if tag, _, _ := strings.Cut(field.Tag.Get(headerTagName), ","); len(tag) > 0 && tag != "-" {
+			name = tag
+		}
+
+		posNames[pos] = name
+
+		if pos > maxPos {
+			maxPos = pos
+		}
+	}
+
+	if maxPos < 0 {
+		return nil, fmt.Errorf("%s Has No Fields Tagged With pos", t.Name())
+	}
+
+	names := make([]string, maxPos+1)
+
+	for pos, n := range posNames {
+		names[pos] = n
+	}
+
+	return names, nil
+}
@@ -0,0 +1,170 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type settleRow struct {
+	MerchantID string  `pos:"0" json:"merchant_id" req:"true"`
+	Amount     float64 `pos:"1" json:"amount"`
+	Note       string  `pos:"2" json:"note"`
+	Approved   bool    `pos:"3" json:"approved" booltrue:"Y" boolfalse:"N"`
+}
+
+// TestStructReaderHeaderMapping verifies header columns are matched to fields by name
+// regardless of column order, with BOM, quoting and per-row errors that do not abort reading.
+func TestStructReaderHeaderMapping(t *testing.T) {
+	content := utf8BOM + "note,Approved,amount,merchant_id,extra\n" +
+		"\"hello, world\",Y,12.5,M1,x\n" +
+		"bad,N,1,,x\n" +
+		"\"multi\nline\",N,3,M3,x\n"
+
+	sr, err := NewStructReader[settleRow](strings.NewReader(content), &StructReaderOptions{HeaderRow: true})
+	if err != nil {
+		t.Fatalf("NewStructReader() failed: %v", err)
+	}
+	defer sr.Close()
+
+	if h := sr.Header(); len(h) != 5 || h[0] != "note" {
+		t.Fatalf("Header() = %v, expected BOM stripped header", h)
+	}
+
+	var rows []*settleRow
+	var rowErrs []*RowError
+
+	for row, err := range sr.All() {
+		var re *RowError
+		if errors.As(err, &re) {
+			rowErrs = append(rowErrs, re)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("All() failed: %v", err)
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("parsed %d rows, expected 2", len(rows))
+	}
+	if r := rows[0]; r.MerchantID != "M1" || r.Amount != 12.5 || r.Note != "hello, world" || !r.Approved {
+		t.Errorf("row 1 = %+v", r)
+	}
+	if r := rows[1]; r.MerchantID != "M3" || r.Note != "multi\nline" || r.Approved {
+		t.Errorf("row 2 = %+v", r)
+	}
+
+	if len(rowErrs) != 1 || rowErrs[0].Line != 3 || rowErrs[0].Record[0] != "bad" {
+		t.Fatalf("row errors = %v, expected one error at line 3", rowErrs)
+	}
+
+	if sr.TriedCount != 3 || sr.ParsedCount != 2 {
+		t.Errorf("TriedCount/ParsedCount = %d/%d, expected 3/2", sr.TriedCount, sr.ParsedCount)
+	}
+}
+
+// TestStructReaderPositional verifies rows without a header row map by pos tag, using an alternate delimiter.
+func TestStructReaderPositional(t *testing.T) {
+	sr, err := NewStructReader[settleRow](strings.NewReader("M1;1.25;a;Y\nM2;2;\"b;c\";N\n"), &StructReaderOptions{Delimiter: ';'})
+	if err != nil {
+		t.Fatalf("NewStructReader() failed: %v", err)
+	}
+
+	row, err := sr.Read()
+	if err != nil || row.MerchantID != "M1" || row.Amount != 1.25 {
+		t.Fatalf("Read() row 1 = %+v, %v", row, err)
+	}
+
+	row, err = sr.Read()
+	if err != nil || row.Note != "b;c" {
+		t.Fatalf("Read() row 2 = %+v, %v", row, err)
+	}
+
+	if _, err = sr.Read(); err != io.EOF {
+		t.Fatalf("Read() at end = %v, expected io.EOF", err)
+	}
+
+	_ = sr.Close()
+
+	if _, err = sr.Read(); err == nil {
+		t.Error("Read() after Close() expected error")
+	}
+}
+
+// TestStructReaderHeaderNoMatch verifies a header row sharing no column names with the struct is rejected.
+func TestStructReaderHeaderNoMatch(t *testing.T) {
+	if _, err := NewStructReader[settleRow](strings.NewReader("a,b\n1,2\n"), &StructReaderOptions{HeaderRow: true}); err == nil {
+		t.Fatal("NewStructReader() expected error for unmatched header")
+	}
+
+	if _, err := NewStructReader[string](strings.NewReader("a\n"), nil); err == nil {
+		t.Fatal("NewStructReader() expected error for non struct type")
+	}
+}
+
+// TestStructWriterRoundTrip verifies written rows are quoted as needed and read back identically.
+func TestStructWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+
+	sw, err := NewStructWriter[settleRow](&buf, &StructWriterOptions{HeaderRow: true})
+	if err != nil {
+		t.Fatalf("NewStructWriter() failed: %v", err)
+	}
+
+	in := []*settleRow{
+		{MerchantID: "M1", Amount: 12.5, Note: "hello, \"world\"", Approved: true},
+		{MerchantID: "M2", Amount: 3, Note: "plain"},
+	}
+
+	for _, r := range in {
+		if err = sw.Write(r); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+
+	if err = sw.Write(&settleRow{}); err == nil {
+		t.Fatal("Write() expected error for missing required field")
+	}
+
+	if err = sw.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	want := "merchant_id,amount,note,approved\nM1,12.5,\"hello, \"\"world\"\"\",Y\nM2,3,plain,N\n"
+	if buf.String() != want {
+		t.Fatalf("output = %q, expected %q", buf.String(), want)
+	}
+
+	path := filepath.Join(t.TempDir(), "rt.csv")
+
+	fw, err := CreateStructWriter[settleRow](path, &StructWriterOptions{HeaderRow: true, Delimiter: '\t'})
+	if err != nil {
+		t.Fatalf("CreateStructWriter() failed: %v", err)
+	}
+	for _, r := range in {
+		if err = fw.Write(r); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+	if err = fw.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	sr, err := OpenStructReader[settleRow](path, &StructReaderOptions{HeaderRow: true, Delimiter: '\t'})
+	if err != nil {
+		t.Fatalf("OpenStructReader() failed: %v", err)
+	}
+	defer sr.Close()
+
+	for i, want := range in {
+		got, err := sr.Read()
+		if err != nil || *got != *want {
+			t.Fatalf("row %d = %+v, %v, expected %+v", i, got, err, want)
+		}
+	}
+}
@@ -4,7 +4,7 @@
 // common project source directories and brief description:
 // + /ascii = helper types and/or functions related to ascii manipulations.
 // + /crypto = helper types and/or functions related to encryption, decryption, hashing, such as rsa, aes, sha, tls etc.
-// + /csv = helper types and/or functions related to csv file manipulations.
+// + /csv = helper types and/or functions related to csv file manipulations, including typed streaming struct reader / writer.
 // + /rest = helper types and/or functions related to http rest api GET, POST, PUT, DELETE actions invoked from client side.
 // + /tcp = helper types providing wrapped tcp client and tcp server logic.
 // - /wrapper = wrappers provides a simpler usage path to third party packages, as well as adding additional enhancements.
@@ -31,15 +31,28 @@
 //
 // /helper-conv.go = helpers for data conversion operations.
 // /helper-db.go = helpers for database data type operations.
+// /helper-db-null.go = generic Null[T] with sql scan / value, json (null vs absent) and dynamodb attribute marshaling.
 // /helper-emv.go = helpers for emv chip card related operations.
+// /helper-emv-tlv.go = BER-TLV tree parser / encoder with constructed templates, and the emv tag dictionary.
+// /helper-generic.go = generic (type-parameterized) siblings of the slice and variadic helpers.
 // /helper-io.go = helpers for io related operations.
+// /helper-io-archive.go = tar.gz and zip create / extract with zip-slip protection and size caps.
+// /helper-mask.go = masking of card numbers, track data, emv card data tags and secrets for logs and payloads.
+// /helper-money.go = exact monetary amount type (int64 minor units + ISO-4217 currency).
 // /helper-net.go = helpers for network related operations.
+// /helper-net-dns.go = context-aware, ttl cached dns resolution with pluggable resolvers and RFC 2782 srv ordering.
+// /helper-net-http.go = re-readable request bodies, trusted proxy client ip resolution and webhook signature verification.
 // /helper-num.go = helpers for numeric related operations.
 // /helper-other.go = helpers for misc. uncategorized operations.
 // /helper-reflect.go = helpers for reflection based operations.
 // /helper-regex.go = helpers for regular express related operations.
 // /helper-str.go = helpers for string operations.
+// /helper-str-runes.go = rune, grapheme and display-width aware string helpers.
 // /helper-struct.go = helpers for struct related operations.
+// /helper-struct-diff.go = StructDiff and ApplyStructPatch (change lists and RFC 6902 JSON Patch) for audit trails.
+// /helper-struct-fixedwidth.go = fixed width (positional) record marshal / unmarshal driven by pos and size tags.
+// /helper-struct-plan.go = per-type compiled struct plans (tags, parsed field configs, method lookups) cache.
+// /helper-struct-validate.go = ValidateStruct, struct tag rule evaluation with multi-error reporting.
 // /helper-time.go = helpers for time related operations.
 // /helper-uuid.go = helpers for generating globally unique ids.
 package helper
@@ -421,8 +434,8 @@ func Float64ToFixedString(f float64, decimals int) string {
 // ARITHMETIC — float64 is IEEE-754 binary floating point and accumulates rounding
 // errors over repeated addition, subtraction, multiplication, or division. The
 // same monetary total reached via different paths (e.g., sum-of-line-items vs.
-// subtotal+tax) may produce values that fail == comparison. Use int64 cents or
-// github.com/shopspring/decimal for monetary arithmetic, and call this helper
+// subtotal+tax) may produce values that fail == comparison. Use Money (see
+// helper-money.go) or int64 cents for monetary arithmetic, and call this helper
 // only at the final render step.
 //
 // Example safe use:  fmt.Println(Float64ToCurrencyString(cents.ToFloat64()))
@@ -0,0 +1,225 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// =====================================================================================================================
+// Generic Nullable Value
+// =====================================================================================================================
+
+// Null holds a value of type T that may be null, so one model struct can be used with the mysql, sqlserver
+// and sqlite wrappers (sql.Scanner / driver.Valuer), with json, and with the dynamodb wrapper (dynamodbattribute).
+//
+// besides Valid, Null tracks whether a value was supplied at all: a json field that is absent leaves Present false,
+// while an explicit json null sets Present true with Valid false; with the `json:",omitzero"` tag an absent Null
+// is omitted on marshal, and an explicit null is written as null, so patch style payloads round trip
+type Null[T any] struct {
+	V     T    // the value, zero value when not Valid
+	Valid bool // true when V is not null
+
+	present bool
+}
+
+// NullOf returns a valid (not null) Null holding v
+func NullOf[T any](v T) Null[T] {
+	return Null[T]{V: v, Valid: true, present: true}
+}
+
+// NullFrom returns a Null holding v, when emptyAsNull is true, a zero value
+// (or a blank / whitespace only string, same as ToNullString) is stored as null instead
+func NullFrom[T any](v T, emptyAsNull bool) Null[T] {
+	if emptyAsNull && nullIsEmpty(v) {
+		return NullValue[T]()
+	}
+
+	return NullOf(v)
+}
+
+// NullFromPtr returns a Null holding *p, or null when p is nil
+func NullFromPtr[T any](p *T) Null[T] {
+	if p == nil {
+		return NullValue[T]()
+	}
+
+	return NullOf(*p)
+}
+
+// NullValue returns an explicit null, Present is true and Valid is false
+func NullValue[T any]() Null[T] {
+	return Null[T]{present: true}
+}
+
+// Get returns the value and whether it is valid (not null)
+func (n Null[T]) Get() (T, bool) {
+	return n.V, n.Valid
+}
+
+// ValueOr returns the value, or def when null
+func (n Null[T]) ValueOr(def T) T {
+	if !n.Valid {
+		return def
+	}
+
+	return n.V
+}
+
+// Ptr returns a pointer to a copy of the value, or nil when null
+func (n Null[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+
+	v := n.V
+	return &v
+}
+
+// Present returns true when a value or an explicit null was supplied, by a constructor, setter, Scan or unmarshal;
+// it is false only for the zero Null, such as a json field that was absent from the payload
+func (n Null[T]) Present() bool {
+	return n.present || n.Valid
+}
+
+// IsZero reports whether n was never supplied, used by the json omitzero tag option to omit absent fields
+func (n Null[T]) IsZero() bool {
+	return !n.Present()
+}
+
+// Set stores v as a valid value
+func (n *Null[T]) Set(v T) {
+	*n = NullOf(v)
+}
+
+// SetNull stores an explicit null
+func (n *Null[T]) SetNull() {
+	*n = NullValue[T]()
+}
+
+// String returns the value formatted with %v, or blank when null
+func (n Null[T]) String() string {
+	if !n.Valid {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", n.V)
+}
+
+// SqlNull returns n as the standard library sql.Null[T]
+func (n Null[T]) SqlNull() sql.Null[T] {
+	return sql.Null[T]{V: n.V, Valid: n.Valid}
+}
+
+// Scan implements sql.Scanner, a database NULL sets Valid false,
+// other values are converted to T with the same rules as database/sql Rows.Scan
+func (n *Null[T]) Scan(src any) error {
+	var s sql.Null[T]
+
+	if err := s.Scan(src); err != nil {
+		return fmt.Errorf("Null[%T] Scan Failed: %w", n.V, err)
+	}
+
+	*n = Null[T]{V: s.V, Valid: s.Valid, present: true}
+	return nil
+}
+
+// Value implements driver.Valuer, null is written as database NULL, and the value is converted to a driver.Value,
+// so T may be any type database/sql accepts as a query argument (integer and float kinds, string, []byte, bool, time.Time)
+func (n Null[T]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	return driver.DefaultParameterConverter.ConvertValue(n.V)
+}
+
+// MarshalJSON implements json.Marshaler, null is written as json null
+func (n Null[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(n.V)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, json null sets Valid false, in both cases Present becomes true
+func (n *Null[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*n = NullValue[T]()
+		return nil
+	}
+
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*n = NullOf(v)
+	return nil
+}
+
+// MarshalDynamoDBAttributeValue implements dynamodbattribute.Marshaler, null is written as a NULL attribute
+func (n Null[T]) MarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	if !n.Valid {
+		av.SetNULL(true)
+		return nil
+	}
+
+	v, err := dynamodbattribute.Marshal(n.V)
+	if err != nil {
+		return err
+	}
+
+	*av = *v
+	return nil
+}
+
+// UnmarshalDynamoDBAttributeValue implements dynamodbattribute.Unmarshaler, a NULL attribute sets Valid false
+func (n *Null[T]) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	if av == nil || aws.BoolValue(av.NULL) {
+		*n = NullValue[T]()
+		return nil
+	}
+
+	var v T
+	if err := dynamodbattribute.Unmarshal(av, &v); err != nil {
+		return err
+	}
+
+	*n = NullOf(v)
+	return nil
+}
+
+// nullIsEmpty reports whether v is the zero value of its type, or a blank string
+func nullIsEmpty(v any) bool {
+	if s, ok := v.(string); ok {
+		return LenTrim(s) == 0
+	}
+
+	rv := reflect.ValueOf(v)
+	return !rv.IsValid() || rv.IsZero()
+}
@@ -0,0 +1,141 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for the generic Null[T] type (helper-db-null.go): sql scan / value, json null vs absent,
+// and dynamodbattribute round trips.
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+type nullTestModel struct {
+	ID    string          `json:"id" dynamodbav:"id"`
+	Name  Null[string]    `json:"name,omitzero" dynamodbav:"name"`
+	Qty   Null[int32]     `json:"qty,omitzero" dynamodbav:"qty"`
+	Price Null[float64]   `json:"price,omitzero" dynamodbav:"price"`
+	When  Null[time.Time] `json:"when,omitzero" dynamodbav:"when"`
+}
+
+func TestNull_Constructors(t *testing.T) {
+	if n := NullFrom("  ", true); n.Valid || !n.Present() {
+		t.Fatalf("blank emptyAsNull = %+v", n)
+	}
+	if n := NullFrom("", false); !n.Valid {
+		t.Fatal("blank without emptyAsNull should be valid")
+	}
+	if n := NullFrom(0, true); n.Valid {
+		t.Fatal("zero int emptyAsNull should be null")
+	}
+
+	var p *int
+	if n := NullFromPtr(p); n.Valid || n.Ptr() != nil {
+		t.Fatal("nil pointer should be null")
+	}
+
+	n := NullOf(7)
+	if v, ok := n.Get(); !ok || v != 7 || *n.Ptr() != 7 || n.String() != "7" {
+		t.Fatalf("NullOf = %+v", n)
+	}
+
+	n.SetNull()
+	if n.Valid || n.ValueOr(3) != 3 || n.String() != "" {
+		t.Fatalf("SetNull = %+v", n)
+	}
+
+	var zero Null[int]
+	if zero.Present() || !zero.IsZero() {
+		t.Fatal("zero Null should not be present")
+	}
+}
+
+func TestNull_SqlScanValue(t *testing.T) {
+	var q Null[int32]
+	if err := q.Scan(int64(42)); err != nil || !q.Valid || q.V != 42 {
+		t.Fatalf("scan int64 = %+v, %v", q, err)
+	}
+	if err := q.Scan(nil); err != nil || q.Valid || !q.Present() {
+		t.Fatalf("scan nil = %+v, %v", q, err)
+	}
+	if err := q.Scan("not a number"); err == nil {
+		t.Fatal("expected scan conversion error")
+	}
+
+	var s Null[string]
+	if err := s.Scan([]byte("abc")); err != nil || s.V != "abc" {
+		t.Fatalf("scan bytes = %+v, %v", s, err)
+	}
+
+	if v, err := NullOf(int32(5)).Value(); err != nil || v != int64(5) {
+		t.Fatalf("Value int32 = %v (%T), %v", v, v, err)
+	}
+	if v, err := (Null[string]{}).Value(); err != nil || v != nil {
+		t.Fatalf("Value null = %v, %v", v, err)
+	}
+
+	if sn := NullOf("x").SqlNull(); !sn.Valid || sn.V != "x" {
+		t.Fatalf("SqlNull = %+v", sn)
+	}
+}
+
+func TestNull_JsonNullVersusAbsent(t *testing.T) {
+	var m nullTestModel
+	if err := json.Unmarshal([]byte(`{"id":"a","name":null,"qty":3}`), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	if !m.Name.Present() || m.Name.Valid {
+		t.Fatalf("explicit null name = %+v", m.Name)
+	}
+	if !m.Qty.Valid || m.Qty.V != 3 {
+		t.Fatalf("qty = %+v", m.Qty)
+	}
+	if m.Price.Present() {
+		t.Fatal("absent price should not be present")
+	}
+
+	out, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"id":"a","name":null,"qty":3}` {
+		t.Fatalf("round trip = %s", out)
+	}
+
+	if err := json.Unmarshal([]byte(`{"qty":"x"}`), &m); err == nil {
+		t.Fatal("expected type error")
+	}
+}
+
+func TestNull_DynamoDBAttribute(t *testing.T) {
+	when := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	in := nullTestModel{ID: "a", Name: NullOf("widget"), Qty: NullValue[int32](), When: NullOf(when)}
+
+	av, err := dynamodbattribute.MarshalMap(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if aws.StringValue(av["name"].S) != "widget" || !aws.BoolValue(av["qty"].NULL) || !aws.BoolValue(av["price"].NULL) {
+		t.Fatalf("marshal = %v", av)
+	}
+
+	var out nullTestModel
+	if err := dynamodbattribute.UnmarshalMap(av, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Name.V != "widget" || !out.Name.Valid || out.Qty.Valid || out.Price.Valid || !out.When.V.Equal(when) {
+		t.Fatalf("unmarshal = %+v", out)
+	}
+}
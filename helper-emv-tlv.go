@@ -0,0 +1,557 @@
+package helper
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// =====================================================================================================================
+// BER-TLV Tree
+// =====================================================================================================================
+
+// maxBerTlvDepth limits template nesting, real EMV data nests at most 4 to 5 levels (6F > A5 > BF0C > 61 > ...)
+const maxBerTlvDepth = 16
+
+// EmvTlvNode is one BER-TLV data object (EMV Book 3 Annex B),
+// constructed templates (such as 6F, 70, 77, A5, BF0C) carry their nested objects in Children,
+// primitive objects carry their raw value bytes in Value
+type EmvTlvNode struct {
+	Tag      string // upper case hex, such as 9F02
+	Value    []byte // primitive value; for constructed nodes parsed from data, the raw bytes of all children
+	Children []*EmvTlvNode
+}
+
+// NewEmvTlvPrimitive creates a primitive node, tag is hex (such as 9F02)
+func NewEmvTlvPrimitive(tag string, value []byte) *EmvTlvNode {
+	return &EmvTlvNode{
+		Tag:   strings.ToUpper(strings.TrimSpace(tag)),
+		Value: value,
+	}
+}
+
+// NewEmvTlvConstructed creates a constructed template node holding children, tag is hex (such as 70)
+func NewEmvTlvConstructed(tag string, children ...*EmvTlvNode) *EmvTlvNode {
+	return &EmvTlvNode{
+		Tag:      strings.ToUpper(strings.TrimSpace(tag)),
+		Children: children,
+	}
+}
+
+// IsConstructed returns true if the tag's first byte has the constructed bit (b6) set
+func (n *EmvTlvNode) IsConstructed() bool {
+	if n == nil || len(n.Tag) < 2 {
+		return false
+	}
+
+	b, err := hex.DecodeString(n.Tag[:2])
+	if err != nil {
+		return false
+	}
+
+	return b[0]&0x20 != 0
+}
+
+// ValueHex returns the node value as upper case hex
+func (n *EmvTlvNode) ValueHex() string {
+	if n == nil {
+		return ""
+	}
+
+	return strings.ToUpper(hex.EncodeToString(n.Value))
+}
+
+// Find returns the first node with the given tag in n's subtree (depth first, n itself included), nil if not found
+func (n *EmvTlvNode) Find(tag string) *EmvTlvNode {
+	if n == nil {
+		return nil
+	}
+
+	if strings.EqualFold(n.Tag, tag) {
+		return n
+	}
+
+	return FindBerTlv(n.Children, tag)
+}
+
+// FindBerTlv returns the first node with the given tag in nodes and their subtrees (depth first), nil if not found
+func FindBerTlv(nodes []*EmvTlvNode, tag string) *EmvTlvNode {
+	for _, c := range nodes {
+		if f := c.Find(tag); f != nil {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// FindAllBerTlv returns every node with the given tag in nodes and their subtrees, in depth first order
+func FindAllBerTlv(nodes []*EmvTlvNode, tag string) (found []*EmvTlvNode) {
+	for _, c := range nodes {
+		if c == nil {
+			continue
+		}
+
+		if strings.EqualFold(c.Tag, tag) {
+			found = append(found, c)
+		}
+
+		found = append(found, FindAllBerTlv(c.Children, tag)...)
+	}
+
+	return found
+}
+
+// ParseBerTlvHex parses a hex payload of BER-TLV data into a tree, see ParseBerTlv
+func ParseBerTlvHex(berTlvPayload string) ([]*EmvTlvNode, error) {
+	payload, err := normalizeHexPayload(berTlvPayload, 2, "BER-TLV Payload")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := hex.DecodeString(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBerTlv(data)
+}
+
+// ParseBerTlv parses BER-TLV data into a tree of nodes,
+// any tag is accepted (1 to 4 byte tags), constructed templates are parsed recursively into Children,
+// lengths use short or long form (up to 3 length bytes), 00 / FF padding between objects is skipped;
+// unlike ParseEmvTlvTags, malformed data is an error rather than skipped
+func ParseBerTlv(data []byte) ([]*EmvTlvNode, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("BER-TLV Data is Required")
+	}
+
+	if len(data) > maxTLVBytes {
+		return nil, fmt.Errorf("BER-TLV Data exceeds max %d bytes", maxTLVBytes)
+	}
+
+	// node values reference the parsed buffer, so detach it from the caller's slice
+	return parseBerTlvLevel(append([]byte(nil), data...), 0, 0)
+}
+
+// parseBerTlvLevel parses the objects of one nesting level, base is the offset of data within the whole payload
+func parseBerTlvLevel(data []byte, base int, depth int) ([]*EmvTlvNode, error) {
+	if depth > maxBerTlvDepth {
+		return nil, fmt.Errorf("BER-TLV nesting exceeds max depth %d at offset %d", maxBerTlvDepth, base)
+	}
+
+	var nodes []*EmvTlvNode
+
+	for i := 0; i < len(data); {
+		// padding before, between or after data objects
+		if data[i] == 0x00 || data[i] == 0xFF {
+			i++
+			continue
+		}
+
+		tagLen, err := berTlvTagLength(data[i:])
+		if err != nil {
+			return nil, fmt.Errorf("BER-TLV tag at offset %d: %w", base+i, err)
+		}
+
+		tag := data[i : i+tagLen]
+		i += tagLen
+
+		valLen, lenLen, err := berTlvDecodeLength(data[i:])
+		if err != nil {
+			return nil, fmt.Errorf("BER-TLV tag %X length at offset %d: %w", tag, base+i, err)
+		}
+
+		i += lenLen
+
+		if valLen > len(data)-i {
+			return nil, fmt.Errorf("BER-TLV tag %X value length %d exceeds remaining payload at offset %d", tag, valLen, base+i)
+		}
+
+		node := &EmvTlvNode{
+			Tag:   strings.ToUpper(hex.EncodeToString(tag)),
+			Value: data[i : i+valLen : i+valLen],
+		}
+
+		if tag[0]&0x20 != 0 && valLen > 0 {
+			if node.Children, err = parseBerTlvLevel(node.Value, base+i, depth+1); err != nil {
+				return nil, err
+			}
+		}
+
+		nodes = append(nodes, node)
+		i += valLen
+	}
+
+	return nodes, nil
+}
+
+// berTlvTagLength returns the byte length of the tag at the start of b,
+// a first byte with b1-b5 all set is followed by subsequent bytes until one with b8 clear
+func berTlvTagLength(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, fmt.Errorf("tag missing")
+	}
+
+	if b[0]&0x1F != 0x1F {
+		return 1, nil
+	}
+
+	for n := 1; n < len(b); n++ {
+		if n >= 4 {
+			return 0, fmt.Errorf("tag longer than 4 bytes")
+		}
+
+		if b[n]&0x80 == 0 {
+			return n + 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("tag truncated")
+}
+
+// berTlvDecodeLength decodes the length field at the start of b, returning the value length and the length field size
+func berTlvDecodeLength(b []byte) (valLen int, lenLen int, err error) {
+	if len(b) == 0 {
+		return 0, 0, fmt.Errorf("length missing")
+	}
+
+	if b[0] < 0x80 {
+		return int(b[0]), 1, nil
+	}
+
+	if b[0] == 0x80 {
+		return 0, 0, fmt.Errorf("indefinite length not supported")
+	}
+
+	n := int(b[0] & 0x7F)
+	if n > 3 {
+		return 0, 0, fmt.Errorf("length uses %d bytes; max 3 supported", n)
+	}
+
+	if len(b) < 1+n {
+		return 0, 0, fmt.Errorf("length truncated")
+	}
+
+	for _, v := range b[1 : 1+n] {
+		valLen = valLen<<8 | int(v)
+	}
+
+	if valLen > maxTLVBytes {
+		return 0, 0, fmt.Errorf("length %d exceeds max %d bytes", valLen, maxTLVBytes)
+	}
+
+	return valLen, 1 + n, nil
+}
+
+// berTlvEncodeLength encodes a value length in the shortest BER form
+func berTlvEncodeLength(n int) []byte {
+	switch {
+	case n < 0x80:
+		return []byte{byte(n)}
+	case n <= 0xFF:
+		return []byte{0x81, byte(n)}
+	case n <= 0xFFFF:
+		return []byte{0x82, byte(n >> 8), byte(n)}
+	default:
+		return []byte{0x83, byte(n >> 16), byte(n >> 8), byte(n)}
+	}
+}
+
+// EncodeBerTlv encodes nodes into BER-TLV data,
+// constructed nodes with Children are encoded from their children (Value is ignored),
+// all other nodes are encoded from Value; tags are validated, lengths use the shortest form
+func EncodeBerTlv(nodes ...*EmvTlvNode) ([]byte, error) {
+	var out []byte
+
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+
+		tag, err := hex.DecodeString(strings.TrimSpace(n.Tag))
+		if err != nil || len(tag) == 0 {
+			return nil, fmt.Errorf("BER-TLV Encode Tag '%s' Must Be HEX", n.Tag)
+		}
+
+		if l, e := berTlvTagLength(tag); e != nil || l != len(tag) {
+			return nil, fmt.Errorf("BER-TLV Encode Tag '%s' is Not a Valid BER Tag", n.Tag)
+		}
+
+		value := n.Value
+
+		if len(n.Children) > 0 {
+			if !n.IsConstructed() {
+				return nil, fmt.Errorf("BER-TLV Encode Tag '%s' is Primitive But Has Children", n.Tag)
+			}
+
+			if value, err = EncodeBerTlv(n.Children...); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(value) > 0xFFFFFF {
+			return nil, fmt.Errorf("BER-TLV Encode Tag '%s' Value Too Long", n.Tag)
+		}
+
+		out = append(out, tag...)
+		out = append(out, berTlvEncodeLength(len(value))...)
+		out = append(out, value...)
+	}
+
+	return out, nil
+}
+
+// EncodeBerTlvHex encodes nodes into an upper case hex BER-TLV payload, see EncodeBerTlv
+func EncodeBerTlvHex(nodes ...*EmvTlvNode) (string, error) {
+	b, err := EncodeBerTlv(nodes...)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// FlattenBerTlv lists the primitive nodes of the tree as EmvTlvTag (the ParseEmvTlvTags result type),
+// in depth first order, with TagDecodedValue rendered according to the tag dictionary format
+func FlattenBerTlv(nodes []*EmvTlvNode) (flat []*EmvTlvTag) {
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+
+		if n.IsConstructed() {
+			flat = append(flat, FlattenBerTlv(n.Children)...)
+			continue
+		}
+
+		flat = append(flat, &EmvTlvTag{
+			TagName:          n.Tag,
+			TagHexValueCount: len(n.Value),
+			TagHexValue:      n.ValueHex(),
+			TagDecodedValue:  FormatEmvTagValue(n.Tag, n.Value),
+		})
+	}
+
+	return flat
+}
+
+// FormatBerTlvTree renders the tree as indented text, one object per line with its dictionary name and formatted value,
+// for logs and support tooling; note sensitive tags (PAN, track data) are rendered as is
+func FormatBerTlvTree(nodes []*EmvTlvNode) string {
+	var sb strings.Builder
+	formatBerTlvTree(&sb, nodes, 0)
+	return sb.String()
+}
+
+func formatBerTlvTree(sb *strings.Builder, nodes []*EmvTlvNode, depth int) {
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+
+		sb.WriteString(strings.Repeat("  ", depth))
+		sb.WriteString(n.Tag)
+
+		if info, ok := LookupEmvTag(n.Tag); ok {
+			sb.WriteString(" ")
+			sb.WriteString(info.Name)
+		}
+
+		if n.IsConstructed() && len(n.Children) > 0 {
+			sb.WriteString("\n")
+			formatBerTlvTree(sb, n.Children, depth+1)
+			continue
+		}
+
+		sb.WriteString(": ")
+		sb.WriteString(FormatEmvTagValue(n.Tag, n.Value))
+		sb.WriteString("\n")
+	}
+}
+
+// =====================================================================================================================
+// EMV Tag Dictionary
+// =====================================================================================================================
+
+// EmvTagInfo describes an EMV tag, Format follows EMV Book 3 data element notation:
+// a = alphabetic, an = alphanumeric, ans = alphanumeric special (these render as text),
+// n = numeric BCD, cn = compressed numeric (F padded), b = binary, t = template (constructed)
+type EmvTagInfo struct {
+	Tag    string
+	Name   string
+	Format string
+}
+
+// emvTagDictionary holds the tags known to LookupEmvTag, keyed by upper case hex tag
+var emvTagDictionary = map[string]EmvTagInfo{}
+
+func init() {
+	for _, v := range []EmvTagInfo{
+		{"42", "Issuer Identification Number (IIN)", "n"},
+		{"4F", "Application Identifier (AID) - card", "b"},
+		{"50", "Application Label", "ans"},
+		{"56", "Track 1 Data", "ans"},
+		{"57", "Track 2 Equivalent Data", "b"},
+		{"5A", "Application Primary Account Number (PAN)", "cn"},
+		{"5F20", "Cardholder Name", "ans"},
+		{"5F24", "Application Expiration Date", "n"},
+		{"5F25", "Application Effective Date", "n"},
+		{"5F28", "Issuer Country Code", "n"},
+		{"5F2A", "Transaction Currency Code", "n"},
+		{"5F2D", "Language Preference", "an"},
+		{"5F30", "Service Code", "n"},
+		{"5F34", "Application PAN Sequence Number", "n"},
+		{"5F36", "Transaction Currency Exponent", "n"},
+		{"5F50", "Issuer URL", "ans"},
+		{"5F53", "International Bank Account Number (IBAN)", "b"},
+		{"5F54", "Bank Identifier Code (BIC)", "b"},
+		{"5F55", "Issuer Country Code (alpha2 format)", "a"},
+		{"5F56", "Issuer Country Code (alpha3 format)", "a"},
+		{"61", "Application Template", "t"},
+		{"6F", "File Control Information (FCI) Template", "t"},
+		{"70", "READ RECORD Response Message Template", "t"},
+		{"71", "Issuer Script Template 1", "t"},
+		{"72", "Issuer Script Template 2", "t"},
+		{"73", "Directory Discretionary Template", "t"},
+		{"77", "Response Message Template Format 2", "t"},
+		{"80", "Response Message Template Format 1", "b"},
+		{"81", "Amount, Authorised (Binary)", "b"},
+		{"82", "Application Interchange Profile", "b"},
+		{"83", "Command Template", "b"},
+		{"84", "Dedicated File (DF) Name", "b"},
+		{"86", "Issuer Script Command", "b"},
+		{"87", "Application Priority Indicator", "b"},
+		{"88", "Short File Identifier (SFI)", "b"},
+		{"89", "Authorisation Code", "an"},
+		{"8A", "Authorisation Response Code", "an"},
+		{"8C", "Card Risk Management Data Object List 1 (CDOL1)", "b"},
+		{"8D", "Card Risk Management Data Object List 2 (CDOL2)", "b"},
+		{"8E", "Cardholder Verification Method (CVM) List", "b"},
+		{"8F", "Certification Authority Public Key Index", "b"},
+		{"90", "Issuer Public Key Certificate", "b"},
+		{"91", "Issuer Authentication Data", "b"},
+		{"92", "Issuer Public Key Remainder", "b"},
+		{"93", "Signed Static Application Data", "b"},
+		{"94", "Application File Locator (AFL)", "b"},
+		{"95", "Terminal Verification Results", "b"},
+		{"97", "Transaction Certificate Data Object List (TDOL)", "b"},
+		{"98", "Transaction Certificate (TC) Hash Value", "b"},
+		{"99", "Transaction Personal Identification Number (PIN) Data", "b"},
+		{"9A", "Transaction Date", "n"},
+		{"9B", "Transaction Status Information", "b"},
+		{"9C", "Transaction Type", "n"},
+		{"9D", "Directory Definition File (DDF) Name", "b"},
+		{"A5", "File Control Information (FCI) Proprietary Template", "t"},
+		{"BF0C", "File Control Information (FCI) Issuer Discretionary Data", "t"},
+		{"9F01", "Acquirer Identifier", "n"},
+		{"9F02", "Amount, Authorised (Numeric)", "n"},
+		{"9F03", "Amount, Other (Numeric)", "n"},
+		{"9F04", "Amount, Other (Binary)", "b"},
+		{"9F05", "Application Discretionary Data", "b"},
+		{"9F06", "Application Identifier (AID) - terminal", "b"},
+		{"9F07", "Application Usage Control", "b"},
+		{"9F08", "Application Version Number - card", "b"},
+		{"9F09", "Application Version Number - terminal", "b"},
+		{"9F0D", "Issuer Action Code - Default", "b"},
+		{"9F0E", "Issuer Action Code - Denial", "b"},
+		{"9F0F", "Issuer Action Code - Online", "b"},
+		{"9F10", "Issuer Application Data", "b"},
+		{"9F11", "Issuer Code Table Index", "n"},
+		{"9F12", "Application Preferred Name", "ans"},
+		{"9F13", "Last Online Application Transaction Counter (ATC) Register", "b"},
+		{"9F14", "Lower Consecutive Offline Limit", "b"},
+		{"9F15", "Merchant Category Code", "n"},
+		{"9F16", "Merchant Identifier", "ans"},
+		{"9F17", "Personal Identification Number (PIN) Try Counter", "b"},
+		{"9F1A", "Terminal Country Code", "n"},
+		{"9F1B", "Terminal Floor Limit", "b"},
+		{"9F1C", "Terminal Identification", "an"},
+		{"9F1D", "Terminal Risk Management Data", "b"},
+		{"9F1E", "Interface Device (IFD) Serial Number", "an"},
+		{"9F1F", "Track 1 Discretionary Data", "ans"},
+		{"9F20", "Track 2 Discretionary Data", "cn"},
+		{"9F21", "Transaction Time", "n"},
+		{"9F26", "Application Cryptogram", "b"},
+		{"9F27", "Cryptogram Information Data", "b"},
+		{"9F2D", "ICC PIN Encipherment Public Key Certificate", "b"},
+		{"9F32", "Issuer Public Key Exponent", "b"},
+		{"9F33", "Terminal Capabilities", "b"},
+		{"9F34", "Cardholder Verification Method (CVM) Results", "b"},
+		{"9F35", "Terminal Type", "n"},
+		{"9F36", "Application Transaction Counter (ATC)", "b"},
+		{"9F37", "Unpredictable Number", "b"},
+		{"9F38", "Processing Options Data Object List (PDOL)", "b"},
+		{"9F39", "Point-of-Service (POS) Entry Mode", "n"},
+		{"9F40", "Additional Terminal Capabilities", "b"},
+		{"9F41", "Transaction Sequence Counter", "n"},
+		{"9F42", "Application Currency Code", "n"},
+		{"9F44", "Application Currency Exponent", "n"},
+		{"9F45", "Data Authentication Code", "b"},
+		{"9F46", "ICC Public Key Certificate", "b"},
+		{"9F47", "ICC Public Key Exponent", "b"},
+		{"9F48", "ICC Public Key Remainder", "b"},
+		{"9F49", "Dynamic Data Authentication Data Object List (DDOL)", "b"},
+		{"9F4A", "Static Data Authentication Tag List", "b"},
+		{"9F4B", "Signed Dynamic Application Data", "b"},
+		{"9F4C", "ICC Dynamic Number", "b"},
+		{"9F4D", "Log Entry", "b"},
+		{"9F4E", "Merchant Name and Location", "ans"},
+		{"9F53", "Transaction Category Code", "an"},
+		{"9F5B", "Issuer Script Results", "b"},
+		{"9F66", "Terminal Transaction Qualifiers (TTQ)", "b"},
+		{"9F6B", "Track 2 Data", "b"},
+		{"9F6C", "Card Transaction Qualifiers (CTQ)", "b"},
+		{"9F6E", "Form Factor Indicator / Third Party Data", "b"},
+		{"9F7C", "Customer Exclusive Data", "b"},
+	} {
+		emvTagDictionary[v.Tag] = v
+	}
+}
+
+// LookupEmvTag returns the dictionary entry of an EMV tag (hex, case-insensitive)
+func LookupEmvTag(tag string) (EmvTagInfo, bool) {
+	info, ok := emvTagDictionary[strings.ToUpper(strings.TrimSpace(tag))]
+	return info, ok
+}
+
+// FormatEmvTagValue renders a tag value for display according to its dictionary format,
+// text formats (a, an, ans) render as text, cn drops the F padding, everything else renders as upper case hex
+func FormatEmvTagValue(tag string, value []byte) string {
+	h := strings.ToUpper(hex.EncodeToString(value))
+
+	info, ok := LookupEmvTag(tag)
+	if !ok {
+		return h
+	}
+
+	switch info.Format {
+	case "a", "an", "ans":
+		for _, c := range value {
+			if c < 0x20 || c > 0x7E {
+				return h // not printable, keep hex rather than emit control characters
+			}
+		}
+		return string(value)
+	case "cn":
+		return strings.TrimRight(h, "F")
+	default:
+		return h
+	}
+}
@@ -0,0 +1,123 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for the BER-TLV codec (helper-emv-tlv.go).
+//
+// The PPSE sample is the one referenced in the ParseEmvTlvTags doc comment
+// (without its trailing 9000 status word); it nests four templates deep.
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const berTlvPPSESample = "6F2F840E325041592E5359532E4444463031A51DBF0C1A61184F07A0000000031010500A564953412044454249548701019000"
+
+func TestParseBerTlv_ConstructedTree(t *testing.T) {
+	nodes, err := ParseBerTlvHex(strings.TrimSuffix(berTlvPPSESample, "9000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 1 || nodes[0].Tag != "6F" || !nodes[0].IsConstructed() {
+		t.Fatalf("expected single 6F template, got %+v", nodes)
+	}
+
+	app := FindBerTlv(nodes, "61")
+	if app == nil || len(app.Children) != 3 {
+		t.Fatalf("61 application template = %+v", app)
+	}
+
+	if aid := app.Find("4f"); aid == nil || aid.ValueHex() != "A0000000031010" {
+		t.Fatalf("4F = %+v", aid)
+	}
+
+	if FindBerTlv(nodes, "BF0C") == nil || FindBerTlv(nodes, "9F02") != nil {
+		t.Fatal("FindBerTlv on multi-byte tags failed")
+	}
+
+	tree := FormatBerTlvTree(nodes)
+	if !strings.Contains(tree, "      50 Application Label: VISA DEBIT\n") {
+		t.Errorf("FormatBerTlvTree =\n%s", tree)
+	}
+
+	flat := FlattenBerTlv(nodes)
+	if len(flat) != 4 || flat[0].TagName != "84" || flat[0].TagDecodedValue != "325041592E5359532E4444463031" || flat[2].TagDecodedValue != "VISA DEBIT" {
+		t.Errorf("FlattenBerTlv = %+v", flat)
+	}
+}
+
+func TestEncodeBerTlv_RoundTrip(t *testing.T) {
+	long := bytes.Repeat([]byte{0xAB}, 300)
+
+	tree := []*EmvTlvNode{
+		NewEmvTlvConstructed("70",
+			NewEmvTlvPrimitive("5A", []byte{0x47, 0x61, 0x73, 0x90, 0x01, 0x01, 0x01, 0x0F}),
+			NewEmvTlvPrimitive("9F02", []byte{0x00, 0x00, 0x00, 0x01, 0x23, 0x45}),
+			NewEmvTlvPrimitive("90", long),
+		),
+		NewEmvTlvPrimitive("DF8101", []byte{0x01}),
+	}
+
+	payload, err := EncodeBerTlvHex(tree...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 300 byte value needs the 82 long form length, and the enclosing template the same
+	if !strings.HasPrefix(payload, "708201435A08476173900101010F9F02060000000123459082012C") {
+		t.Fatalf("EncodeBerTlvHex = %s", payload[:60])
+	}
+
+	nodes, err := ParseBerTlvHex(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := EncodeBerTlvHex(nodes...)
+	if err != nil || again != payload {
+		t.Fatalf("round trip mismatch: %v", err)
+	}
+
+	if n := FindBerTlv(nodes, "DF8101"); n == nil || n.ValueHex() != "01" {
+		t.Fatalf("3 byte tag = %+v", n)
+	}
+
+	if v := FormatEmvTagValue("5A", FindBerTlv(nodes, "5A").Value); v != "476173900101010" {
+		t.Errorf("cn format = %s", v)
+	}
+}
+
+func TestParseBerTlv_Errors(t *testing.T) {
+	for _, p := range []string{
+		"9F02",            // length missing
+		"9F0206000001",    // value truncated
+		"9F",              // tag truncated
+		"5A80",            // indefinite length
+		"7003" + "9F0201", // template content truncated
+	} {
+		if _, err := ParseBerTlvHex(p); err == nil {
+			t.Errorf("ParseBerTlvHex(%s) expected error", p)
+		}
+	}
+
+	// padding between objects is skipped
+	if nodes, err := ParseBerTlvHex("00009A03260307FF9C0100"); err != nil || len(nodes) != 2 {
+		t.Errorf("padding parse = %v, %v", nodes, err)
+	}
+
+	if _, err := EncodeBerTlv(NewEmvTlvPrimitive("5A", nil), &EmvTlvNode{Tag: "9F", Value: []byte{1}}); err == nil {
+		t.Error("EncodeBerTlv expected invalid tag error")
+	}
+
+	if _, err := EncodeBerTlv(NewEmvTlvConstructed("5A", NewEmvTlvPrimitive("9C", []byte{0}))); err == nil {
+		t.Error("EncodeBerTlv expected primitive with children error")
+	}
+}
@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"encoding/hex"
 	"fmt"
 	"strconv"
 	"strings"
@@ -540,3 +541,510 @@ func ParseEncryptedTlvTags(encryptedTlvTagsPayload string) (foundList []*EmvTlvT
 	// parsing completed
 	return foundList, nil
 }
+
+// =====================================================================================================================
+// EMV Value Decoders
+// =====================================================================================================================
+
+// emvBitDef names one bit of a bit-mapped EMV data element, byteNo is 1-based, mask selects the bit (0x80 = b8)
+type emvBitDef struct {
+	byteNo int
+	mask   byte
+	text   string
+}
+
+// decodeEmvFixedHex normalizes a hex value and requires exactly size bytes
+func decodeEmvFixedHex(raw string, size int, label string) ([]byte, string, error) {
+	h, err := normalizeHexPayload(raw, 1, label)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(h) != size*2 {
+		return nil, "", fmt.Errorf("%s Must Be %d Bytes", label, size)
+	}
+
+	b, err := hex.DecodeString(h)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return b, h, nil
+}
+
+// explainEmvBits lists the text of every set bit in defs
+func explainEmvBits(b []byte, defs []emvBitDef) (explanations []string) {
+	for _, d := range defs {
+		if d.byteNo <= len(b) && b[d.byteNo-1]&d.mask != 0 {
+			explanations = append(explanations, d.text)
+		}
+	}
+
+	return explanations
+}
+
+// emvTVRBits per EMV Book 3 Annex C5, the bits that are set explain why a terminal declined or went online
+var emvTVRBits = []emvBitDef{
+	{1, 0x80, "Offline data authentication was not performed"},
+	{1, 0x40, "SDA failed"},
+	{1, 0x20, "ICC data missing"},
+	{1, 0x10, "Card appears on terminal exception file"},
+	{1, 0x08, "DDA failed"},
+	{1, 0x04, "CDA failed"},
+	{1, 0x02, "SDA selected"},
+	{2, 0x80, "ICC and terminal have different application versions"},
+	{2, 0x40, "Expired application"},
+	{2, 0x20, "Application not yet effective"},
+	{2, 0x10, "Requested service not allowed for card product"},
+	{2, 0x08, "New card"},
+	{3, 0x80, "Cardholder verification was not successful"},
+	{3, 0x40, "Unrecognised CVM"},
+	{3, 0x20, "PIN Try Limit exceeded"},
+	{3, 0x10, "PIN entry required and PIN pad not present or not working"},
+	{3, 0x08, "PIN entry required, PIN pad present, but PIN was not entered"},
+	{3, 0x04, "Online PIN entered"},
+	{4, 0x80, "Transaction exceeds floor limit"},
+	{4, 0x40, "Lower consecutive offline limit exceeded"},
+	{4, 0x20, "Upper consecutive offline limit exceeded"},
+	{4, 0x10, "Transaction selected randomly for online processing"},
+	{4, 0x08, "Merchant forced transaction online"},
+	{5, 0x80, "Default TDOL used"},
+	{5, 0x40, "Issuer authentication failed"},
+	{5, 0x20, "Script processing failed before final GENERATE AC"},
+	{5, 0x10, "Script processing failed after final GENERATE AC"},
+}
+
+// EmvTVR is the decoded Terminal Verification Results (tag 95, 5 bytes)
+type EmvTVR struct {
+	Raw string
+
+	// byte 1
+	OfflineDataAuthNotPerformed bool
+	SDAFailed                   bool
+	ICCDataMissing              bool
+	CardOnExceptionFile         bool
+	DDAFailed                   bool
+	CDAFailed                   bool
+	SDASelected                 bool
+
+	// byte 2
+	DifferentAppVersions       bool
+	ExpiredApplication         bool
+	ApplicationNotYetEffective bool
+	ServiceNotAllowed          bool
+	NewCard                    bool
+
+	// byte 3
+	CardholderVerificationFailed bool
+	UnrecognisedCVM              bool
+	PINTryLimitExceeded          bool
+	PINPadNotPresent             bool
+	PINNotEntered                bool
+	OnlinePINEntered             bool
+
+	// byte 4
+	ExceedsFloorLimit                    bool
+	LowerConsecutiveOfflineLimitExceeded bool
+	UpperConsecutiveOfflineLimitExceeded bool
+	RandomlySelectedOnline               bool
+	MerchantForcedOnline                 bool
+
+	// byte 5
+	DefaultTDOLUsed                   bool
+	IssuerAuthenticationFailed        bool
+	ScriptFailedBeforeFinalGenerateAC bool
+	ScriptFailedAfterFinalGenerateAC  bool
+
+	// Explanations lists a readable sentence for every bit that is set, in EMV bit order
+	Explanations []string
+}
+
+// DecodeEmvTVR decodes the hex value of tag 95 (Terminal Verification Results)
+func DecodeEmvTVR(tvrHex string) (*EmvTVR, error) {
+	b, h, err := decodeEmvFixedHex(tvrHex, 5, "EMV TVR")
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmvTVR{
+		Raw: h,
+
+		OfflineDataAuthNotPerformed: b[0]&0x80 != 0,
+		SDAFailed:                   b[0]&0x40 != 0,
+		ICCDataMissing:              b[0]&0x20 != 0,
+		CardOnExceptionFile:         b[0]&0x10 != 0,
+		DDAFailed:                   b[0]&0x08 != 0,
+		CDAFailed:                   b[0]&0x04 != 0,
+		SDASelected:                 b[0]&0x02 != 0,
+
+		DifferentAppVersions:       b[1]&0x80 != 0,
+		ExpiredApplication:         b[1]&0x40 != 0,
+		ApplicationNotYetEffective: b[1]&0x20 != 0,
+		ServiceNotAllowed:          b[1]&0x10 != 0,
+		NewCard:                    b[1]&0x08 != 0,
+
+		CardholderVerificationFailed: b[2]&0x80 != 0,
+		UnrecognisedCVM:              b[2]&0x40 != 0,
+		PINTryLimitExceeded:          b[2]&0x20 != 0,
+		PINPadNotPresent:             b[2]&0x10 != 0,
+		PINNotEntered:                b[2]&0x08 != 0,
+		OnlinePINEntered:             b[2]&0x04 != 0,
+
+		ExceedsFloorLimit:                    b[3]&0x80 != 0,
+		LowerConsecutiveOfflineLimitExceeded: b[3]&0x40 != 0,
+		UpperConsecutiveOfflineLimitExceeded: b[3]&0x20 != 0,
+		RandomlySelectedOnline:               b[3]&0x10 != 0,
+		MerchantForcedOnline:                 b[3]&0x08 != 0,
+
+		DefaultTDOLUsed:                   b[4]&0x80 != 0,
+		IssuerAuthenticationFailed:        b[4]&0x40 != 0,
+		ScriptFailedBeforeFinalGenerateAC: b[4]&0x20 != 0,
+		ScriptFailedAfterFinalGenerateAC:  b[4]&0x10 != 0,
+
+		Explanations: explainEmvBits(b, emvTVRBits),
+	}, nil
+}
+
+// emvTSIBits per EMV Book 3 Annex C6
+var emvTSIBits = []emvBitDef{
+	{1, 0x80, "Offline data authentication was performed"},
+	{1, 0x40, "Cardholder verification was performed"},
+	{1, 0x20, "Card risk management was performed"},
+	{1, 0x10, "Issuer authentication was performed"},
+	{1, 0x08, "Terminal risk management was performed"},
+	{1, 0x04, "Script processing was performed"},
+}
+
+// EmvTSI is the decoded Transaction Status Information (tag 9B, 2 bytes)
+type EmvTSI struct {
+	Raw string
+
+	OfflineDataAuthPerformed        bool
+	CardholderVerificationPerformed bool
+	CardRiskManagementPerformed     bool
+	IssuerAuthenticationPerformed   bool
+	TerminalRiskManagementPerformed bool
+	ScriptProcessingPerformed       bool
+
+	// Explanations lists a readable sentence for every bit that is set, in EMV bit order
+	Explanations []string
+}
+
+// DecodeEmvTSI decodes the hex value of tag 9B (Transaction Status Information)
+func DecodeEmvTSI(tsiHex string) (*EmvTSI, error) {
+	b, h, err := decodeEmvFixedHex(tsiHex, 2, "EMV TSI")
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmvTSI{
+		Raw: h,
+
+		OfflineDataAuthPerformed:        b[0]&0x80 != 0,
+		CardholderVerificationPerformed: b[0]&0x40 != 0,
+		CardRiskManagementPerformed:     b[0]&0x20 != 0,
+		IssuerAuthenticationPerformed:   b[0]&0x10 != 0,
+		TerminalRiskManagementPerformed: b[0]&0x08 != 0,
+		ScriptProcessingPerformed:       b[0]&0x04 != 0,
+
+		Explanations: explainEmvBits(b, emvTSIBits),
+	}, nil
+}
+
+// emvAIPBits per EMV Book 3 Annex C1, plus the contactless kernel bits commonly set by cards
+var emvAIPBits = []emvBitDef{
+	{1, 0x40, "SDA supported"},
+	{1, 0x20, "DDA supported"},
+	{1, 0x10, "Cardholder verification is supported"},
+	{1, 0x08, "Terminal risk management is to be performed"},
+	{1, 0x04, "Issuer authentication is supported"},
+	{1, 0x02, "On device cardholder verification is supported (contactless)"},
+	{1, 0x01, "CDA supported"},
+	{2, 0x80, "EMV mode is supported (contactless)"},
+}
+
+// EmvAIP is the decoded Application Interchange Profile (tag 82, 2 bytes)
+type EmvAIP struct {
+	Raw string
+
+	SDASupported                    bool
+	DDASupported                    bool
+	CardholderVerificationSupported bool
+	TerminalRiskManagementRequired  bool
+	IssuerAuthenticationSupported   bool
+	OnDeviceCardholderVerification  bool
+	CDASupported                    bool
+	ContactlessEMVModeSupported     bool
+
+	// Explanations lists a readable sentence for every bit that is set, in EMV bit order
+	Explanations []string
+}
+
+// DecodeEmvAIP decodes the hex value of tag 82 (Application Interchange Profile)
+func DecodeEmvAIP(aipHex string) (*EmvAIP, error) {
+	b, h, err := decodeEmvFixedHex(aipHex, 2, "EMV AIP")
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmvAIP{
+		Raw: h,
+
+		SDASupported:                    b[0]&0x40 != 0,
+		DDASupported:                    b[0]&0x20 != 0,
+		CardholderVerificationSupported: b[0]&0x10 != 0,
+		TerminalRiskManagementRequired:  b[0]&0x08 != 0,
+		IssuerAuthenticationSupported:   b[0]&0x04 != 0,
+		OnDeviceCardholderVerification:  b[0]&0x02 != 0,
+		CDASupported:                    b[0]&0x01 != 0,
+		ContactlessEMVModeSupported:     b[1]&0x80 != 0,
+
+		Explanations: explainEmvBits(b, emvAIPBits),
+	}, nil
+}
+
+// EmvCVMResults is the decoded Cardholder Verification Method Results (tag 9F34, 3 bytes)
+type EmvCVMResults struct {
+	Raw string
+
+	// MethodCode is byte 1 b6-b1, the CVM performed, such as 0x02 enciphered PIN verified online, 0x1E signature
+	MethodCode byte
+	Method     string
+
+	// ApplySucceedingOnFail is byte 1 b7, apply the succeeding CV rule if this CVM is unsuccessful
+	ApplySucceedingOnFail bool
+
+	// ConditionCode is byte 2, the CVM list condition under which the method applied
+	ConditionCode byte
+	Condition     string
+
+	// ResultCode is byte 3: 0 = unknown, 1 = failed, 2 = successful
+	ResultCode byte
+	Result     string
+	Successful bool
+
+	// Explanations holds the method, condition and result sentences
+	Explanations []string
+}
+
+// DecodeEmvCVMResults decodes the hex value of tag 9F34 (CVM Results)
+func DecodeEmvCVMResults(cvmHex string) (*EmvCVMResults, error) {
+	b, h, err := decodeEmvFixedHex(cvmHex, 3, "EMV CVM Results")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &EmvCVMResults{
+		Raw:                   h,
+		MethodCode:            b[0] & 0x3F,
+		ApplySucceedingOnFail: b[0]&0x40 != 0,
+		ConditionCode:         b[1],
+		ResultCode:            b[2],
+	}
+
+	switch r.MethodCode {
+	case 0x00:
+		r.Method = "Fail CVM processing"
+	case 0x01:
+		r.Method = "Plaintext PIN verification performed by ICC"
+	case 0x02:
+		r.Method = "Enciphered PIN verified online"
+	case 0x03:
+		r.Method = "Plaintext PIN verification performed by ICC and signature (paper)"
+	case 0x04:
+		r.Method = "Enciphered PIN verification performed by ICC"
+	case 0x05:
+		r.Method = "Enciphered PIN verification performed by ICC and signature (paper)"
+	case 0x1E:
+		r.Method = "Signature (paper)"
+	case 0x1F:
+		r.Method = "No CVM required"
+	case 0x3F:
+		r.Method = "No CVM performed"
+	default:
+		if r.MethodCode >= 0x20 && r.MethodCode <= 0x2F {
+			r.Method = fmt.Sprintf("Payment system specific CVM %02X", r.MethodCode)
+		} else if r.MethodCode >= 0x30 && r.MethodCode <= 0x3E {
+			r.Method = fmt.Sprintf("Issuer specific CVM %02X", r.MethodCode)
+		} else {
+			r.Method = fmt.Sprintf("Unknown CVM %02X", r.MethodCode)
+		}
+	}
+
+	switch r.ConditionCode {
+	case 0x00:
+		r.Condition = "Always"
+	case 0x01:
+		r.Condition = "If unattended cash"
+	case 0x02:
+		r.Condition = "If not unattended cash and not manual cash and not purchase with cashback"
+	case 0x03:
+		r.Condition = "If terminal supports the CVM"
+	case 0x04:
+		r.Condition = "If manual cash"
+	case 0x05:
+		r.Condition = "If purchase with cashback"
+	case 0x06:
+		r.Condition = "If transaction is in the application currency and is under X value"
+	case 0x07:
+		r.Condition = "If transaction is in the application currency and is over X value"
+	case 0x08:
+		r.Condition = "If transaction is in the application currency and is under Y value"
+	case 0x09:
+		r.Condition = "If transaction is in the application currency and is over Y value"
+	default:
+		r.Condition = fmt.Sprintf("Unknown condition %02X", r.ConditionCode)
+	}
+
+	switch r.ResultCode {
+	case 0x00:
+		r.Result = "Unknown"
+	case 0x01:
+		r.Result = "Failed"
+	case 0x02:
+		r.Result = "Successful"
+		r.Successful = true
+	default:
+		r.Result = fmt.Sprintf("Unknown result %02X", r.ResultCode)
+	}
+
+	r.Explanations = []string{
+		"CVM: " + r.Method,
+		"Condition: " + r.Condition,
+		"Result: " + r.Result,
+	}
+
+	if r.ApplySucceedingOnFail {
+		r.Explanations = append(r.Explanations, "Apply succeeding CV rule if this CVM is unsuccessful")
+	}
+
+	return r, nil
+}
+
+// DecodeEmvCurrencyCode decodes the hex value of tag 5F2A (Transaction Currency Code) or 9F42 (Application Currency Code),
+// a 2 byte BCD ISO-4217 numeric code such as 0840, into its currency info
+func DecodeEmvCurrencyCode(currencyHex string) (CurrencyInfo, error) {
+	_, h, err := decodeEmvFixedHex(currencyHex, 2, "EMV Currency Code")
+	if err != nil {
+		return CurrencyInfo{}, err
+	}
+
+	if !IsNumericIntOnly(h) {
+		return CurrencyInfo{}, fmt.Errorf("EMV Currency Code Must Be BCD Digits")
+	}
+
+	c, ok := LookupCurrencyByNumeric(h)
+	if !ok {
+		return CurrencyInfo{}, fmt.Errorf("EMV Currency Code %s is Not Supported", h)
+	}
+
+	return c, nil
+}
+
+// DecodeEmvAmount decodes the hex value of tag 9F02 (Amount, Authorised) or 9F03 (Amount, Other),
+// a 6 byte BCD amount in the currency's minor units, into Money of the currency given by tag 5F2A hex (such as 0840)
+func DecodeEmvAmount(amountHex string, currencyHex string) (Money, error) {
+	_, h, err := decodeEmvFixedHex(amountHex, 6, "EMV Amount")
+	if err != nil {
+		return Money{}, err
+	}
+
+	if !IsNumericIntOnly(h) {
+		return Money{}, fmt.Errorf("EMV Amount Must Be BCD Digits")
+	}
+
+	c, err := DecodeEmvCurrencyCode(currencyHex)
+	if err != nil {
+		return Money{}, err
+	}
+
+	minor, err := strconv.ParseInt(h, 10, 64)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return NewMoney(minor, c.Code)
+}
+
+// ExplainEmvTlvTags returns readable explanation lines for the decodable tags in a ParseEmvTlvTags result:
+// 95 TVR, 9B TSI, 82 AIP, 9F34 CVM Results, and 9F02 / 9F03 amounts when 5F2A is present;
+// tags that fail to decode are explained with their decode error, other tags are ignored
+func ExplainEmvTlvTags(tags []*EmvTlvTag) (lines []string) {
+	currencyHex := ""
+	for _, t := range tags {
+		if t != nil && strings.EqualFold(t.TagName, "5F2A") {
+			currencyHex = t.TagHexValue
+		}
+	}
+
+	for _, t := range tags {
+		if t == nil {
+			continue
+		}
+
+		var label string
+		var explanations []string
+		var err error
+
+		switch strings.ToUpper(t.TagName) {
+		case "95":
+			label = "TVR"
+			var v *EmvTVR
+			if v, err = DecodeEmvTVR(t.TagHexValue); err == nil {
+				explanations = v.Explanations
+			}
+		case "9B":
+			label = "TSI"
+			var v *EmvTSI
+			if v, err = DecodeEmvTSI(t.TagHexValue); err == nil {
+				explanations = v.Explanations
+			}
+		case "82":
+			label = "AIP"
+			var v *EmvAIP
+			if v, err = DecodeEmvAIP(t.TagHexValue); err == nil {
+				explanations = v.Explanations
+			}
+		case "9F34":
+			label = "CVM Results"
+			var v *EmvCVMResults
+			if v, err = DecodeEmvCVMResults(t.TagHexValue); err == nil {
+				explanations = v.Explanations
+			}
+		case "9F02", "9F03":
+			if len(currencyHex) == 0 {
+				continue
+			}
+
+			label = "Amount, Authorised"
+			if strings.EqualFold(t.TagName, "9F03") {
+				label = "Amount, Other"
+			}
+
+			var m Money
+			if m, err = DecodeEmvAmount(t.TagHexValue, currencyHex); err == nil {
+				explanations = []string{m.String()}
+			}
+		default:
+			continue
+		}
+
+		prefix := strings.ToUpper(t.TagName) + " " + label + ": "
+
+		if err != nil {
+			lines = append(lines, prefix+err.Error())
+			continue
+		}
+
+		if len(explanations) == 0 {
+			lines = append(lines, prefix+"No bits set")
+			continue
+		}
+
+		for _, e := range explanations {
+			lines = append(lines, prefix+e)
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,170 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for the EMV value decoders in helper-emv.go (TVR, TSI, AIP, CVM Results, amounts).
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeEmvTVR(t *testing.T) {
+	// offline data auth not performed, new card, online PIN entered, exceeds floor limit, issuer auth failed
+	v, err := DecodeEmvTVR("8008048040")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !v.OfflineDataAuthNotPerformed || !v.NewCard || !v.OnlinePINEntered || !v.ExceedsFloorLimit || !v.IssuerAuthenticationFailed {
+		t.Fatalf("expected flags not set: %+v", v)
+	}
+
+	if v.SDAFailed || v.ExpiredApplication || v.PINTryLimitExceeded || v.MerchantForcedOnline || v.DefaultTDOLUsed {
+		t.Fatalf("unexpected flags set: %+v", v)
+	}
+
+	want := []string{
+		"Offline data authentication was not performed",
+		"New card",
+		"Online PIN entered",
+		"Transaction exceeds floor limit",
+		"Issuer authentication failed",
+	}
+	if strings.Join(v.Explanations, "|") != strings.Join(want, "|") {
+		t.Fatalf("explanations = %q", v.Explanations)
+	}
+
+	if z, err := DecodeEmvTVR("0000000000"); err != nil || len(z.Explanations) != 0 {
+		t.Fatalf("zero tvr: %+v, %v", z, err)
+	}
+
+	for _, bad := range []string{"", "00000000", "000000000000", "ZZ00000000"} {
+		if _, err := DecodeEmvTVR(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestDecodeEmvTSI(t *testing.T) {
+	v, err := DecodeEmvTSI("e800")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !v.OfflineDataAuthPerformed || !v.CardholderVerificationPerformed || !v.CardRiskManagementPerformed || !v.TerminalRiskManagementPerformed {
+		t.Fatalf("expected flags not set: %+v", v)
+	}
+
+	if v.IssuerAuthenticationPerformed || v.ScriptProcessingPerformed || len(v.Explanations) != 4 {
+		t.Fatalf("unexpected decode: %+v", v)
+	}
+
+	if v.Raw != "E800" {
+		t.Fatalf("raw = %s", v.Raw)
+	}
+}
+
+func TestDecodeEmvAIP(t *testing.T) {
+	v, err := DecodeEmvAIP("1980")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !v.CardholderVerificationSupported || !v.TerminalRiskManagementRequired || !v.CDASupported || !v.ContactlessEMVModeSupported {
+		t.Fatalf("expected flags not set: %+v", v)
+	}
+
+	if v.SDASupported || v.DDASupported || v.IssuerAuthenticationSupported {
+		t.Fatalf("unexpected flags set: %+v", v)
+	}
+}
+
+func TestDecodeEmvCVMResults(t *testing.T) {
+	v, err := DecodeEmvCVMResults("420302")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v.MethodCode != 0x02 || v.Method != "Enciphered PIN verified online" || !v.ApplySucceedingOnFail {
+		t.Fatalf("method decode: %+v", v)
+	}
+
+	if v.ConditionCode != 0x03 || v.Condition != "If terminal supports the CVM" || !v.Successful || v.Result != "Successful" {
+		t.Fatalf("condition / result decode: %+v", v)
+	}
+
+	v, err = DecodeEmvCVMResults("1E0001")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Method != "Signature (paper)" || v.Condition != "Always" || v.Successful || v.Result != "Failed" {
+		t.Fatalf("signature decode: %+v", v)
+	}
+}
+
+func TestDecodeEmvAmount(t *testing.T) {
+	m, err := DecodeEmvAmount("000000012345", "0840")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.MinorUnits() != 12345 || m.Currency() != "USD" || m.String() != "123.45 USD" {
+		t.Fatalf("amount = %s", m.String())
+	}
+
+	// JPY has no minor units
+	if m, err = DecodeEmvAmount("000000001500", "0392"); err != nil || m.String() != "1500 JPY" {
+		t.Fatalf("jpy amount = %s, %v", m.String(), err)
+	}
+
+	if _, err = DecodeEmvAmount("00000001234A", "0840"); err == nil {
+		t.Fatal("expected error for non bcd amount")
+	}
+
+	if _, err = DecodeEmvAmount("000000012345", "0999"); err == nil {
+		t.Fatal("expected error for unknown currency")
+	}
+
+	c, err := DecodeEmvCurrencyCode("0978")
+	if err != nil || c.Code != "EUR" {
+		t.Fatalf("currency = %+v, %v", c, err)
+	}
+}
+
+func TestExplainEmvTlvTags(t *testing.T) {
+	tags := []*EmvTlvTag{
+		{TagName: "9F02", TagHexValue: "000000001000"},
+		{TagName: "5F2A", TagHexValue: "0840"},
+		{TagName: "95", TagHexValue: "0000008000"},
+		{TagName: "9B", TagHexValue: "0000"},
+		{TagName: "9F34", TagHexValue: "1F0002"},
+		{TagName: "82", TagHexValue: "00"},
+		{TagName: "4F", TagHexValue: "A0000000031010"},
+	}
+
+	got := strings.Join(ExplainEmvTlvTags(tags), "\n")
+
+	for _, want := range []string{
+		"9F02 Amount, Authorised: 10.00 USD",
+		"95 TVR: Transaction exceeds floor limit",
+		"9B TSI: No bits set",
+		"9F34 CVM Results: CVM: No CVM required",
+		"9F34 CVM Results: Result: Successful",
+		"82 AIP: EMV AIP Must Be 2 Bytes",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "4F") {
+		t.Fatalf("undecodable tag should be skipped:\n%s", got)
+	}
+}
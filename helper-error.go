@@ -1,17 +1,22 @@
 package helper
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path/filepath"
 	"reflect"
 	"regexp"
 	"runtime"
+	"strings"
+	"sync"
 	"time"
 )
 
 var logEPrefixRE = regexp.MustCompile(`(?m)^[\s\n]*LogE:\s\d{4}-\d{2}-\d{2}\s\d{2}:\d{2}:\d{2}\.\d{3}\s`)
 
+// ErrAddLineTimeFileInfo prefixes err with the caller file, line and utc time, once per unwrap chain,
+// new code should prefer WrapError, which keeps the same info in structured fields instead of the message
 func ErrAddLineTimeFileInfo(err error) error {
 	if err == nil {
 		return nil
@@ -22,6 +27,8 @@ func ErrAddLineTimeFileInfo(err error) error {
 	return fmt.Errorf("%s%w", logPrefix(0), err) // prefix once, preserve cause
 }
 
+// ErrNewAddLineTimeFileInfo returns a new error with msg prefixed by the caller file, line and utc time,
+// new code should prefer NewError
 func ErrNewAddLineTimeFileInfo(msg string) error {
 	return errors.New(logPrefix(0) + msg)
 }
@@ -109,16 +116,347 @@ func logPrefix(skip int) string { // new helper for shared caller/time logic
 		line = 0
 	}
 
+	return fmt.Sprintf("\nLogE: %v %v:%v: ",
+		time.Now().UTC().Format("2006-01-02 15:04:05.000"),
+		shortCallerFile(file),
+		line)
+}
+
+// shortCallerFile trims a source path to its parent dir and file name, such as "common/helper-error.go"
+func shortCallerFile(file string) string {
 	file = filepath.ToSlash(file)
 	base := filepath.Base(file)
 	dir := filepath.Base(filepath.Dir(file))
-	shortFile := base
 	if dir != "." && dir != "/" && dir != "" {
-		shortFile = dir + "/" + base
+		return dir + "/" + base
 	}
+	return base
+}
 
-	return fmt.Sprintf("\nLogE: %v %v:%v: ",
-		time.Now().UTC().Format("2006-01-02 15:04:05.000"),
-		shortFile,
-		line)
+// =====================================================================================================================
+// Structured Error
+// =====================================================================================================================
+
+// ErrorCodeUnknown is the code given to plain errors converted by ToStructuredError
+const ErrorCodeUnknown = "UNKNOWN"
+
+// ErrorResponseGenericMessage is the ToErrorResponse message of unknown and blank message errors,
+// whose only detail is cause text such as driver, sql or network errors that must not reach clients
+const ErrorResponseGenericMessage = "An Internal Error Occurred"
+
+// maxErrorStackDepth caps the program counters captured per StructuredError
+const maxErrorStackDepth = 32
+
+// StructuredError is an error with a machine readable code, message, caller frame, utc timestamp,
+// key / value fields and an optional cause, it works with errors.Is / errors.As through Unwrap and Is,
+// and renders as json via MarshalJSON (full detail, for logs) or ToErrorResponse (client safe subset, for api responses).
+//
+// Fields are added with With before the error is returned, a StructuredError should not be modified once shared.
+type StructuredError struct {
+	Code    string
+	Message string
+
+	// Caller is the "dir/file.go:line" and Func the function name where the error was created
+	Caller string
+	Func   string
+
+	// Time is when the error was created, in utc
+	Time time.Time
+
+	Fields map[string]interface{}
+	Cause  error
+
+	pcs        []uintptr
+	clientKeys map[string]bool // Fields keys added with WithClient
+}
+
+// ErrorResponse is the client facing json shape of an error, without caller, stack or cause detail,
+// Fields holds only the fields added with WithClient
+type ErrorResponse struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// ErrorAdapter converts a foreign error type into a StructuredError, returning nil when err is not of its type
+type ErrorAdapter func(err error) *StructuredError
+
+// StructuredErrorConverter is implemented by error types that convert themselves, such as dynamodb.DynamoDBError
+type StructuredErrorConverter interface {
+	ToStructuredError() *StructuredError
+}
+
+var (
+	errorAdaptersMu sync.RWMutex
+	errorAdapters   []ErrorAdapter
+)
+
+// RegisterErrorAdapter adds an adapter consulted by ToStructuredError, wrapper packages register theirs in init,
+// such as wrapper/aws for awserr.Error values
+func RegisterErrorAdapter(adapter ErrorAdapter) {
+	if adapter == nil {
+		return
+	}
+
+	errorAdaptersMu.Lock()
+	errorAdapters = append(errorAdapters, adapter)
+	errorAdaptersMu.Unlock()
+}
+
+// NewError returns a StructuredError with code and message, capturing the caller frame and time
+func NewError(code string, message string) *StructuredError {
+	return newStructuredError(code, message, nil, 1)
+}
+
+// NewErrorf is NewError with a formatted message
+func NewErrorf(code string, format string, args ...interface{}) *StructuredError {
+	return newStructuredError(code, fmt.Sprintf(format, args...), nil, 1)
+}
+
+// WrapError returns a StructuredError with code and message caused by err, capturing the caller frame and time,
+// nil err returns nil; blank code inherits the code of the first StructuredError in err's chain
+func WrapError(err error, code string, message string) *StructuredError {
+	if err == nil {
+		return nil
+	}
+
+	if len(code) == 0 {
+		code = ErrorCode(err)
+	}
+
+	return newStructuredError(code, message, err, 1)
+}
+
+// ToStructuredError converts any error into a StructuredError: the first StructuredError in the chain is returned as is,
+// then StructuredErrorConverter implementations and registered adapters are tried,
+// and anything else is wrapped with ErrorCodeUnknown, a blank message and err as cause; nil err returns nil
+func ToStructuredError(err error) *StructuredError {
+	if err == nil {
+		return nil
+	}
+
+	var se *StructuredError
+	if errors.As(err, &se) && se != nil {
+		return se
+	}
+
+	var conv StructuredErrorConverter
+	if errors.As(err, &conv) && conv != nil {
+		if se = conv.ToStructuredError(); se != nil {
+			return se
+		}
+	}
+
+	errorAdaptersMu.RLock()
+	adapters := errorAdapters
+	errorAdaptersMu.RUnlock()
+
+	for _, a := range adapters {
+		if se = a(err); se != nil {
+			return se
+		}
+	}
+
+	return newStructuredError(ErrorCodeUnknown, "", err, 1)
+}
+
+// ErrorCode returns the code of the first StructuredError in err's chain, or blank when there is none
+func ErrorCode(err error) string {
+	var se *StructuredError
+	if errors.As(err, &se) && se != nil {
+		return se.Code
+	}
+
+	return ""
+}
+
+// ToErrorResponse returns the client facing representation of err, converting it with ToStructuredError,
+// ErrorCodeUnknown and blank message errors get ErrorResponseGenericMessage, cause text is only rendered by MarshalJSON
+func ToErrorResponse(err error) ErrorResponse {
+	se := ToStructuredError(err)
+	if se == nil {
+		return ErrorResponse{}
+	}
+
+	msg := se.Message
+	if se.Code == ErrorCodeUnknown || len(strings.TrimSpace(msg)) == 0 {
+		msg = ErrorResponseGenericMessage
+	}
+
+	resp := ErrorResponse{
+		Code:    se.Code,
+		Message: msg,
+	}
+
+	for k := range se.clientKeys {
+		if v, ok := se.Fields[k]; ok {
+			if resp.Fields == nil {
+				resp.Fields = make(map[string]interface{})
+			}
+			resp.Fields[k] = v
+		}
+	}
+
+	return resp
+}
+
+// newStructuredError captures the frame skip levels above its caller
+func newStructuredError(code string, message string, cause error, skip int) *StructuredError {
+	e := &StructuredError{
+		Code:    code,
+		Message: message,
+		Time:    time.Now().UTC(),
+		Cause:   cause,
+	}
+
+	pcs := make([]uintptr, maxErrorStackDepth)
+	if n := runtime.Callers(skip+2, pcs); n > 0 {
+		e.pcs = pcs[:n]
+
+		frame, _ := runtime.CallersFrames(e.pcs).Next()
+		e.Caller = fmt.Sprintf("%s:%d", shortCallerFile(frame.File), frame.Line)
+		e.Func = frame.Function
+	}
+
+	return e
+}
+
+// With adds a key / value field and returns e for chaining, the field is logged but not returned to clients
+func (e *StructuredError) With(key string, value interface{}) *StructuredError {
+	if e == nil {
+		return nil
+	}
+
+	if e.Fields == nil {
+		e.Fields = make(map[string]interface{})
+	}
+
+	e.Fields[key] = value
+	return e
+}
+
+// WithClient adds a key / value field like With, and also returns it to clients in ToErrorResponse,
+// such as the name of an invalid input field
+func (e *StructuredError) WithClient(key string, value interface{}) *StructuredError {
+	if e = e.With(key, value); e != nil {
+		if e.clientKeys == nil {
+			e.clientKeys = make(map[string]bool)
+		}
+		e.clientKeys[key] = true
+	}
+
+	return e
+}
+
+// Error returns "[code] message: cause", omitting the parts that are blank
+func (e *StructuredError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	if len(e.Code) > 0 {
+		b.WriteString("[" + e.Code + "]")
+	}
+
+	if len(e.Message) > 0 {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(e.Message)
+	}
+
+	if e.Cause != nil {
+		if b.Len() > 0 {
+			b.WriteString(": ")
+		}
+		b.WriteString(e.Cause.Error())
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the cause
+func (e *StructuredError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+
+	return e.Cause
+}
+
+// Is reports whether target is a StructuredError with the same non-blank code,
+// so a code-only value such as NewError("NOT_FOUND", "") works as a sentinel with errors.Is
+func (e *StructuredError) Is(target error) bool {
+	t, ok := target.(*StructuredError)
+	if !ok || e == nil || t == nil {
+		return false
+	}
+
+	return e == t || (len(t.Code) > 0 && e.Code == t.Code)
+}
+
+// StackTrace returns the captured call stack, one "function file:line" entry per frame
+func (e *StructuredError) StackTrace() []string {
+	if e == nil || len(e.pcs) == 0 {
+		return nil
+	}
+
+	var out []string
+	frames := runtime.CallersFrames(e.pcs)
+
+	for {
+		f, more := frames.Next()
+		out = append(out, fmt.Sprintf("%s %s:%d", f.Function, shortCallerFile(f.File), f.Line))
+
+		if !more {
+			break
+		}
+	}
+
+	return out
+}
+
+// structuredErrorJSON is the MarshalJSON shape of StructuredError
+type structuredErrorJSON struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Caller  string                 `json:"caller,omitempty"`
+	Func    string                 `json:"func,omitempty"`
+	Time    string                 `json:"time,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Cause   interface{}            `json:"cause,omitempty"`
+	Stack   []string               `json:"stack,omitempty"`
+}
+
+// MarshalJSON renders the full error detail, a StructuredError cause is nested as an object and any other cause as its text
+func (e *StructuredError) MarshalJSON() ([]byte, error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+
+	j := structuredErrorJSON{
+		Code:    e.Code,
+		Message: e.Message,
+		Caller:  e.Caller,
+		Func:    e.Func,
+		Fields:  e.Fields,
+		Stack:   e.StackTrace(),
+	}
+
+	if !e.Time.IsZero() {
+		j.Time = e.Time.Format(time.RFC3339Nano)
+	}
+
+	if e.Cause != nil {
+		var se *StructuredError
+		if errors.As(e.Cause, &se) && se != nil {
+			j.Cause = se
+		} else {
+			j.Cause = e.Cause.Error()
+		}
+	}
+
+	return json.Marshal(j)
 }
@@ -0,0 +1,177 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for StructuredError (helper-error.go): message shape, caller capture,
+// errors.Is / errors.As through wrapping layers, json output and adapters.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStructuredError_ErrorAndCaller(t *testing.T) {
+	e := NewError("NOT_FOUND", "order not found").With("order_id", 42)
+
+	if e.Error() != "[NOT_FOUND] order not found" {
+		t.Fatalf("Error() = %q", e.Error())
+	}
+
+	if !strings.HasSuffix(strings.SplitN(e.Caller, ":", 2)[0], "helper-error_test.go") {
+		t.Fatalf("caller = %q", e.Caller)
+	}
+
+	if !strings.HasSuffix(e.Func, "TestStructuredError_ErrorAndCaller") {
+		t.Fatalf("func = %q", e.Func)
+	}
+
+	if e.Time.IsZero() || e.Time.Location().String() != "UTC" {
+		t.Fatalf("time = %v", e.Time)
+	}
+
+	if st := e.StackTrace(); len(st) == 0 || !strings.Contains(st[0], "TestStructuredError_ErrorAndCaller") {
+		t.Fatalf("stack = %v", st)
+	}
+}
+
+func TestStructuredError_IsAs(t *testing.T) {
+	errNotFound := NewError("NOT_FOUND", "")
+
+	wrapped := fmt.Errorf("load: %w", WrapError(io.EOF, "NOT_FOUND", "order not found"))
+
+	if !errors.Is(wrapped, errNotFound) {
+		t.Fatal("expected errors.Is to match by code")
+	}
+
+	if !errors.Is(wrapped, io.EOF) {
+		t.Fatal("expected errors.Is to reach the cause")
+	}
+
+	if errors.Is(wrapped, NewError("CONFLICT", "")) {
+		t.Fatal("different code must not match")
+	}
+
+	var se *StructuredError
+	if !errors.As(wrapped, &se) || se.Code != "NOT_FOUND" {
+		t.Fatalf("errors.As = %+v", se)
+	}
+
+	if ErrorCode(wrapped) != "NOT_FOUND" || ErrorCode(io.EOF) != "" {
+		t.Fatal("ErrorCode mismatch")
+	}
+
+	// blank code inherits from the chain
+	if outer := WrapError(wrapped, "", "api call failed"); outer.Code != "NOT_FOUND" {
+		t.Fatalf("inherited code = %q", outer.Code)
+	}
+
+	if WrapError(nil, "X", "y") != nil {
+		t.Fatal("WrapError(nil) must return nil")
+	}
+}
+
+func TestStructuredError_JSON(t *testing.T) {
+	inner := NewError("DB", "timeout")
+	e := WrapError(inner, "UNAVAILABLE", "try later").WithClient("attempt", 3).With("request_sql", "select 1")
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m map[string]interface{}
+	if err = json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	if m["code"] != "UNAVAILABLE" || m["message"] != "try later" || m["caller"] == nil || m["time"] == nil {
+		t.Fatalf("json = %s", b)
+	}
+
+	if cause, ok := m["cause"].(map[string]interface{}); !ok || cause["code"] != "DB" {
+		t.Fatalf("nested cause = %v", m["cause"])
+	}
+
+	resp := ToErrorResponse(e)
+	if resp.Code != "UNAVAILABLE" || resp.Message != "try later" || resp.Fields["attempt"] != 3 {
+		t.Fatalf("response = %+v", resp)
+	}
+
+	b, _ = json.Marshal(resp)
+	if strings.Contains(string(b), "caller") || strings.Contains(string(b), "stack") || strings.Contains(string(b), "request_sql") {
+		t.Fatalf("response leaks internals: %s", b)
+	}
+}
+
+type testConvertibleError struct{}
+
+func (testConvertibleError) Error() string { return "convertible" }
+
+func (testConvertibleError) ToStructuredError() *StructuredError {
+	return NewError("CONVERTED", "converted")
+}
+
+type testAdaptedError struct{}
+
+func (testAdaptedError) Error() string { return "adapted" }
+
+func TestToStructuredError(t *testing.T) {
+	if ToStructuredError(nil) != nil {
+		t.Fatal("nil must convert to nil")
+	}
+
+	if se := ToStructuredError(fmt.Errorf("x: %w", testConvertibleError{})); se.Code != "CONVERTED" {
+		t.Fatalf("converter code = %q", se.Code)
+	}
+
+	RegisterErrorAdapter(func(err error) *StructuredError {
+		if errors.As(err, new(testAdaptedError)) {
+			return NewError("ADAPTED", "adapted")
+		}
+		return nil
+	})
+
+	if se := ToStructuredError(testAdaptedError{}); se.Code != "ADAPTED" {
+		t.Fatalf("adapter code = %q", se.Code)
+	}
+
+	se := ToStructuredError(io.EOF)
+	if se.Code != ErrorCodeUnknown || se.Message != "" || se.Error() != "[UNKNOWN]: EOF" || !errors.Is(se, io.EOF) {
+		t.Fatalf("plain error = %+v", se)
+	}
+}
+
+func TestToErrorResponse_HidesCauseText(t *testing.T) {
+	driverErr := errors.New("pq: password authentication failed for user \"app\" at 10.0.0.5:5432")
+
+	for _, err := range []error{driverErr, WrapError(driverErr, "DB", ""), WrapError(driverErr, "", "  ")} {
+		resp := ToErrorResponse(err)
+
+		if resp.Message != ErrorResponseGenericMessage {
+			t.Errorf("response message = %q", resp.Message)
+		}
+
+		b, _ := json.Marshal(resp)
+		if strings.Contains(string(b), "10.0.0.5") {
+			t.Errorf("response leaks cause: %s", b)
+		}
+
+		// the log rendering keeps the cause
+		if b, _ = json.Marshal(ToStructuredError(err)); !strings.Contains(string(b), "10.0.0.5") {
+			t.Errorf("log json lost cause: %s", b)
+		}
+	}
+
+	if resp := ToErrorResponse(WrapError(driverErr, "DB", "database unavailable")); resp.Code != "DB" || resp.Message != "database unavailable" {
+		t.Errorf("explicit message response = %+v", resp)
+	}
+}
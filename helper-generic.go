@@ -0,0 +1,227 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Generic (type-parameterized) siblings of the helpers in helper-other.go.
+//
+// These are ADDITIVE per the README deprecation policy: the reflection and
+// per-type helpers (GetFirstIntOrDefault, IntSliceContains, SliceSeekElement,
+// SliceDeleteElement, ...) keep their pinned v1.x contracts and remain
+// callable. New code should prefer the generic forms below, which avoid
+// reflection cost and keep compile-time type safety.
+//
+// Where a generic sibling intentionally differs from the helper it shadows,
+// the difference is called out in its godoc (e.g. SliceContains is an exact
+// == comparison, whereas StringSliceContains is case-insensitive).
+
+// ================================================================================================================
+// Variadic Optional Value Helpers (generic)
+// ================================================================================================================
+
+// GetFirstOrDefaultT will select the first variadic value from paramValue,
+// if no paramValue variadic, then defaultValue is used as return value.
+//
+// Generic sibling of GetFirstIntOrDefault, GetFirstStringOrDefault, GetFirstBoolOrDefault, etc.
+func GetFirstOrDefaultT[T any](defaultValue T, paramValue ...T) T {
+	if len(paramValue) > 0 {
+		return paramValue[0]
+	}
+
+	return defaultValue
+}
+
+// ================================================================================================================
+// slice helpers (generic)
+// ================================================================================================================
+
+// SliceContains checks if value is contained within slice, using == comparison.
+//
+// Note: unlike StringSliceContains, string comparison here is exact (case-sensitive);
+// use SliceContainsFunc with strings.EqualFold for case-insensitive matching.
+func SliceContains[T comparable](slice []T, value T) bool {
+	for _, v := range slice {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SliceContainsFunc checks if any element of slice satisfies matchFunc.
+// nil matchFunc returns false.
+func SliceContainsFunc[T any](slice []T, matchFunc func(T) bool) bool {
+	_, found := SliceSeek(slice, matchFunc)
+	return found
+}
+
+// SliceSeek returns the first element of slice for which filterFunc returns true,
+// found is false (and element is T's zero value) when no element matches or filterFunc is nil.
+//
+// Generic sibling of SliceSeekElement; no SliceObjectsToSliceInterface conversion is needed.
+func SliceSeek[T any](slice []T, filterFunc func(T) bool) (element T, found bool) {
+	if filterFunc == nil {
+		return element, false
+	}
+
+	for _, v := range slice {
+		if filterFunc(v) {
+			return v, true
+		}
+	}
+
+	return element, false
+}
+
+// SliceIndex returns the 0-based index of the first occurrence of value in slice, or -1 if not found.
+func SliceIndex[T comparable](slice []T, value T) int {
+	for i, v := range slice {
+		if v == value {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// SliceDelete removes the element at removalIndex and returns the result in a FRESH slice,
+// the caller's slice and its backing array are never modified.
+//
+// removalIndex follows the SliceDeleteElement convention:
+//
+//	positive number = element removal index position (0-based index)
+//	negative number = removal index from right, -1 = last element, -2 = second to the last, and so on
+//	out of bound    = returns a copy of the original slice unchanged
+//
+// Unlike SliceDeleteElement, element ordering IS preserved. nil or empty input returns nil.
+func SliceDelete[T any](slice []T, removalIndex int) []T {
+	length := len(slice)
+	if length == 0 {
+		return nil
+	}
+
+	idx := removalIndex
+	if idx < 0 {
+		idx = length + idx
+	}
+
+	if idx < 0 || idx >= length {
+		return append([]T(nil), slice...)
+	}
+
+	if length == 1 {
+		return nil
+	}
+
+	result := make([]T, 0, length-1)
+	result = append(result, slice[:idx]...)
+	return append(result, slice[idx+1:]...)
+}
+
+// SliceUnique returns the unique elements of slice in first-seen order,
+// nil input returns an empty (non-nil) slice, matching StringSliceExtractUnique.
+//
+// Note: unlike StringSliceExtractUnique, string comparison here is exact (case-sensitive).
+func SliceUnique[T comparable](slice []T) []T {
+	if slice == nil {
+		return []T{}
+	}
+
+	seen := make(map[T]struct{}, len(slice))
+	result := make([]T, 0, len(slice))
+
+	for _, v := range slice {
+		if _, exists := seen[v]; !exists {
+			seen[v] = struct{}{}
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// SliceMap returns a new slice holding mapFunc applied to each element of slice, in order.
+// nil slice or nil mapFunc returns nil.
+func SliceMap[T any, R any](slice []T, mapFunc func(T) R) []R {
+	if slice == nil || mapFunc == nil {
+		return nil
+	}
+
+	result := make([]R, len(slice))
+	for i, v := range slice {
+		result[i] = mapFunc(v)
+	}
+
+	return result
+}
+
+// SliceFilter returns a new slice holding the elements of slice for which keepFunc returns true, in order.
+// nil slice or nil keepFunc returns nil.
+func SliceFilter[T any](slice []T, keepFunc func(T) bool) []T {
+	if slice == nil || keepFunc == nil {
+		return nil
+	}
+
+	result := make([]T, 0, len(slice))
+	for _, v := range slice {
+		if keepFunc(v) {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// SliceChunk splits slice into consecutive chunks of at most chunkSize elements each,
+// the last chunk holds the remainder. chunkSize <= 0 or empty slice returns nil.
+//
+// Each chunk is a copy, so mutating a chunk does not affect the source slice or other chunks.
+func SliceChunk[T any](slice []T, chunkSize int) [][]T {
+	if chunkSize <= 0 || len(slice) == 0 {
+		return nil
+	}
+
+	result := make([][]T, 0, (len(slice)+chunkSize-1)/chunkSize)
+
+	for start := 0; start < len(slice); start += chunkSize {
+		end := start + chunkSize
+		if end > len(slice) {
+			end = len(slice)
+		}
+
+		result = append(result, append([]T(nil), slice[start:end]...))
+	}
+
+	return result
+}
+
+// SliceGroupBy groups the elements of slice by the key returned from keyFunc,
+// element order within each group follows the source slice order.
+// nil keyFunc returns nil; empty slice returns an empty (non-nil) map.
+func SliceGroupBy[T any, K comparable](slice []T, keyFunc func(T) K) map[K][]T {
+	if keyFunc == nil {
+		return nil
+	}
+
+	result := make(map[K][]T)
+	for _, v := range slice {
+		k := keyFunc(v)
+		result[k] = append(result[k], v)
+	}
+
+	return result
+}
@@ -0,0 +1,230 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Executable specification for the generic siblings in helper-generic.go.
+//
+// The reflection-based helpers these shadow (SliceDeleteElement,
+// SliceSeekElement, StringSliceContains, ...) keep their own pinned
+// contracts in helper-other_test.go; the tests here pin where the generic
+// forms intentionally differ (order preservation, exact comparison).
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetFirstOrDefaultT(t *testing.T) {
+	if got := GetFirstOrDefaultT(5); got != 5 {
+		t.Errorf("no variadic: got %d, want 5", got)
+	}
+	if got := GetFirstOrDefaultT(5, 7, 9); got != 7 {
+		t.Errorf("with variadic: got %d, want 7", got)
+	}
+	if got := GetFirstOrDefaultT("def", ""); got != "" {
+		t.Errorf("zero-value variadic must win over default, got %q", got)
+	}
+
+	now := time.Now()
+	if got := GetFirstOrDefaultT(time.Time{}, now); !got.Equal(now) {
+		t.Errorf("time variadic: got %v, want %v", got, now)
+	}
+}
+
+func TestSliceContains(t *testing.T) {
+	if !SliceContains([]int{1, 2, 3}, 2) {
+		t.Error("expected 2 to be found")
+	}
+	if SliceContains([]int{1, 2, 3}, 4) {
+		t.Error("expected 4 not to be found")
+	}
+	if SliceContains[int](nil, 0) {
+		t.Error("nil slice must not contain anything")
+	}
+
+	// exact comparison, unlike StringSliceContains
+	if SliceContains([]string{"ABC"}, "abc") {
+		t.Error("SliceContains must be case-sensitive")
+	}
+	if !SliceContainsFunc([]string{"ABC"}, func(s string) bool { return strings.EqualFold(s, "abc") }) {
+		t.Error("SliceContainsFunc with EqualFold should match")
+	}
+	if SliceContainsFunc([]string{"ABC"}, nil) {
+		t.Error("nil matchFunc must return false")
+	}
+}
+
+func TestSliceSeekAndIndex(t *testing.T) {
+	type item struct {
+		ID   int
+		Name string
+	}
+
+	items := []item{{1, "a"}, {2, "b"}, {3, "b"}}
+
+	got, found := SliceSeek(items, func(i item) bool { return i.Name == "b" })
+	if !found || got.ID != 2 {
+		t.Errorf("expected first match ID 2, got %+v found=%v", got, found)
+	}
+
+	if _, found = SliceSeek(items, func(i item) bool { return i.Name == "z" }); found {
+		t.Error("expected no match")
+	}
+
+	if _, found = SliceSeek(items, nil); found {
+		t.Error("nil filterFunc must return found=false")
+	}
+
+	if idx := SliceIndex([]string{"x", "y", "z"}, "z"); idx != 2 {
+		t.Errorf("SliceIndex = %d, want 2", idx)
+	}
+	if idx := SliceIndex([]string{"x"}, "q"); idx != -1 {
+		t.Errorf("SliceIndex = %d, want -1", idx)
+	}
+}
+
+func TestSliceDelete_PreservesOrder(t *testing.T) {
+	cases := []struct {
+		name  string
+		index int
+		want  []int
+	}{
+		{"first", 0, []int{2, 3, 4}},
+		{"middle", 1, []int{1, 3, 4}},
+		{"last", 3, []int{1, 2, 3}},
+		{"neg-one", -1, []int{1, 2, 3}},
+		{"neg-full", -4, []int{2, 3, 4}},
+		{"oob-pos", 9, []int{1, 2, 3, 4}},
+		{"oob-neg", -9, []int{1, 2, 3, 4}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			in := []int{1, 2, 3, 4}
+			got := SliceDelete(in, c.index)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("SliceDelete(%v, %d) = %v, want %v", in, c.index, got, c.want)
+			}
+			if !reflect.DeepEqual(in, []int{1, 2, 3, 4}) {
+				t.Errorf("caller slice was mutated: %v", in)
+			}
+		})
+	}
+}
+
+func TestSliceDelete_ResultBackingIsDisjoint(t *testing.T) {
+	in := []int{1, 2, 3}
+
+	out := SliceDelete(in, 9) // out of bounds still returns a copy
+	out[0] = 100
+	if in[0] != 1 {
+		t.Errorf("out-of-bounds result aliases caller backing array")
+	}
+}
+
+func TestSliceDelete_EdgeCases(t *testing.T) {
+	if got := SliceDelete[int](nil, 0); got != nil {
+		t.Errorf("nil input: got %v, want nil", got)
+	}
+	if got := SliceDelete([]int{}, 0); got != nil {
+		t.Errorf("empty input: got %v, want nil", got)
+	}
+	if got := SliceDelete([]int{42}, -1); got != nil {
+		t.Errorf("single element: got %v, want nil", got)
+	}
+}
+
+func TestSliceUnique(t *testing.T) {
+	got := SliceUnique([]string{"b", "a", "b", "A", "a"})
+	if !reflect.DeepEqual(got, []string{"b", "a", "A"}) {
+		t.Errorf("SliceUnique = %v, want [b a A]", got)
+	}
+
+	if got := SliceUnique[int](nil); got == nil || len(got) != 0 {
+		t.Errorf("nil input: got %#v, want empty non-nil slice", got)
+	}
+}
+
+func TestSliceMapFilter(t *testing.T) {
+	mapped := SliceMap([]int{1, 2, 3}, Itoa)
+	if !reflect.DeepEqual(mapped, []string{"1", "2", "3"}) {
+		t.Errorf("SliceMap = %v", mapped)
+	}
+
+	if SliceMap[int, string](nil, Itoa) != nil {
+		t.Error("SliceMap nil slice must return nil")
+	}
+	if SliceMap[int, string]([]int{1}, nil) != nil {
+		t.Error("SliceMap nil mapFunc must return nil")
+	}
+
+	even := SliceFilter([]int{1, 2, 3, 4}, func(i int) bool { return i%2 == 0 })
+	if !reflect.DeepEqual(even, []int{2, 4}) {
+		t.Errorf("SliceFilter = %v", even)
+	}
+
+	if got := SliceFilter([]int{1, 3}, func(i int) bool { return i%2 == 0 }); got == nil || len(got) != 0 {
+		t.Errorf("SliceFilter with no matches: got %#v, want empty non-nil slice", got)
+	}
+}
+
+func TestSliceChunk(t *testing.T) {
+	got := SliceChunk([]int{1, 2, 3, 4, 5}, 2)
+	want := [][]int{{1, 2}, {3, 4}, {5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SliceChunk = %v, want %v", got, want)
+	}
+
+	src := []int{1, 2, 3}
+	chunks := SliceChunk(src, 3)
+	chunks[0][0] = 100
+	if src[0] != 1 {
+		t.Error("chunk aliases source slice")
+	}
+
+	if SliceChunk([]int{1}, 0) != nil {
+		t.Error("chunkSize 0 must return nil")
+	}
+	if SliceChunk([]int{}, 2) != nil {
+		t.Error("empty slice must return nil")
+	}
+}
+
+func TestSliceGroupBy(t *testing.T) {
+	words := []string{"apple", "avocado", "banana", "blueberry", "cherry"}
+
+	got := SliceGroupBy(words, func(s string) byte { return s[0] })
+	want := map[byte][]string{
+		'a': {"apple", "avocado"},
+		'b': {"banana", "blueberry"},
+		'c': {"cherry"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SliceGroupBy = %v, want %v", got, want)
+	}
+
+	if SliceGroupBy[string, int](words, nil) != nil {
+		t.Error("nil keyFunc must return nil")
+	}
+}
+
+func BenchmarkSliceDelete_Generic(b *testing.B) {
+	src := make([]int, 1024)
+	for i := 0; i < b.N; i++ {
+		_ = SliceDelete(src, 512)
+	}
+}
+
+func BenchmarkSliceDeleteElement_Reflect(b *testing.B) {
+	src := make([]int, 1024)
+	for i := 0; i < b.N; i++ {
+		_ = SliceDeleteElement(src, 512)
+	}
+}
@@ -0,0 +1,590 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// =====================================================================================================================
+// Archive Helpers (tar.gz / zip)
+// =====================================================================================================================
+
+// default archive extraction caps, applied when the matching ArchiveOptions field is 0
+const (
+	defaultArchiveMaxEntryBytes = int64(1 << 30) // 1 GiB per file
+	defaultArchiveMaxTotalBytes = int64(4 << 30) // 4 GiB per archive
+	defaultArchiveMaxEntries    = 100000
+)
+
+// ErrArchiveLimitExceeded is returned when extraction passes one of the ArchiveOptions size or entry caps
+var ErrArchiveLimitExceeded = errors.New("archive limit exceeded")
+
+// ArchiveOptions configures the archive create and extract helpers
+type ArchiveOptions struct {
+	// Include / Exclude filter the entries by slash separated relative path, same pattern rules as CopyDirOptions
+	Include []string
+	Exclude []string
+
+	// extraction caps guarding against decompression bombs, 0 uses the default (1 GiB per file, 4 GiB total, 100000 entries)
+	MaxEntryBytes int64
+	MaxTotalBytes int64
+	MaxEntries    int
+}
+
+// limits returns the caps with defaults applied
+func (o ArchiveOptions) limits() (maxEntry int64, maxTotal int64, maxEntries int) {
+	maxEntry, maxTotal, maxEntries = o.MaxEntryBytes, o.MaxTotalBytes, o.MaxEntries
+
+	if maxEntry <= 0 {
+		maxEntry = defaultArchiveMaxEntryBytes
+	}
+	if maxTotal <= 0 {
+		maxTotal = defaultArchiveMaxTotalBytes
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultArchiveMaxEntries
+	}
+
+	return maxEntry, maxTotal, maxEntries
+}
+
+// TarGzCreate archives the regular files and directories under srcDir into a gzip compressed tar at archivePath,
+// the archive is written atomically (see FileWriteAtomic) and must not be inside srcDir; symlinks are not archived
+func TarGzCreate(srcDir string, archivePath string, opts ArchiveOptions) error {
+	if err := archiveCreateGuard(srcDir, archivePath); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(archivePath, 0o644, func(w io.Writer) error {
+		return TarGzWrite(w, srcDir, opts)
+	})
+}
+
+// TarGzWrite streams a gzip compressed tar of the regular files and directories under srcDir to w
+func TarGzWrite(w io.Writer, srcDir string, opts ArchiveOptions) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := walkArchiveSource(srcDir, opts, func(rel string, full string, info fs.FileInfo) error {
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+
+		hdr.Name = rel
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+
+		// drop host specific owner names
+		hdr.Uname, hdr.Gname = "", ""
+
+		if err = tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		return copyArchiveSourceFile(tw, full, info)
+	})
+
+	if err != nil {
+		_ = tw.Close()
+		_ = gz.Close()
+		return err
+	}
+
+	if err = tw.Close(); err != nil {
+		_ = gz.Close()
+		return err
+	}
+
+	return gz.Close()
+}
+
+// TarGzExtract extracts the gzip compressed tar at archivePath into dstDir, see TarGzExtractReader
+func TarGzExtract(archivePath string, dstDir string, opts ArchiveOptions) error {
+	if err := ensureNoSymlinkDirs(filepath.Dir(archivePath)); err != nil {
+		return err
+	}
+
+	f, _, err := openRegularRead(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return TarGzExtractReader(f, dstDir, opts)
+}
+
+// TarGzExtractReader extracts a gzip compressed tar stream into dstDir,
+// entries resolving outside dstDir (zip-slip) are rejected, symlink, hardlink and device entries are skipped,
+// and extraction stops with ErrArchiveLimitExceeded when a size or entry cap is passed
+func TarGzExtractReader(r io.Reader, dstDir string, opts ArchiveOptions) error {
+	dstAbs, err := archiveExtractRoot(dstDir)
+	if err != nil {
+		return err
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	ex := newArchiveExtractor(dstAbs, opts)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return ex.finish()
+		}
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err = ex.dir(hdr.Name, hdr.FileInfo().Mode(), hdr.ModTime); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = ex.file(hdr.Name, hdr.Size, hdr.FileInfo().Mode(), hdr.ModTime, tr); err != nil {
+				return err
+			}
+		default:
+			// symlinks, hardlinks, devices and pax metadata are never materialized
+			if err = ex.count(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ZipCreate archives the regular files and directories under srcDir into a zip at archivePath,
+// the archive is written atomically (see FileWriteAtomic) and must not be inside srcDir; symlinks are not archived
+func ZipCreate(srcDir string, archivePath string, opts ArchiveOptions) error {
+	if err := archiveCreateGuard(srcDir, archivePath); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(archivePath, 0o644, func(w io.Writer) error {
+		return ZipWrite(w, srcDir, opts)
+	})
+}
+
+// ZipWrite streams a deflate compressed zip of the regular files and directories under srcDir to w
+func ZipWrite(w io.Writer, srcDir string, opts ArchiveOptions) error {
+	zw := zip.NewWriter(w)
+
+	err := walkArchiveSource(srcDir, opts, func(rel string, full string, info fs.FileInfo) error {
+		hdr, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+
+		hdr.Name = rel
+		if info.IsDir() {
+			hdr.Name += "/"
+			hdr.Method = zip.Store
+		} else {
+			hdr.Method = zip.Deflate
+		}
+
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		return copyArchiveSourceFile(fw, full, info)
+	})
+
+	if err != nil {
+		_ = zw.Close()
+		return err
+	}
+
+	return zw.Close()
+}
+
+// ZipExtract extracts the zip at archivePath into dstDir,
+// entries resolving outside dstDir (zip-slip) are rejected, symlink and other special entries are skipped,
+// and extraction stops with ErrArchiveLimitExceeded when a size or entry cap is passed
+func ZipExtract(archivePath string, dstDir string, opts ArchiveOptions) error {
+	if err := ensureNoSymlinkDirs(filepath.Dir(archivePath)); err != nil {
+		return err
+	}
+
+	f, finfo, err := openRegularRead(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zr, err := zip.NewReader(f, finfo.Size())
+	if err != nil {
+		return err
+	}
+
+	dstAbs, err := archiveExtractRoot(dstDir)
+	if err != nil {
+		return err
+	}
+
+	ex := newArchiveExtractor(dstAbs, opts)
+
+	for _, zf := range zr.File {
+		mode := zf.Mode()
+
+		switch {
+		case mode.IsDir():
+			err = ex.dir(zf.Name, mode, zf.Modified)
+		case mode.IsRegular():
+			err = extractZipFile(ex, zf)
+		default:
+			err = ex.count()
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return ex.finish()
+}
+
+// extractZipFile extracts one regular zip entry, the declared size is checked before decompressing
+func extractZipFile(ex *archiveExtractor, zf *zip.File) error {
+	if zf.UncompressedSize64 > uint64(ex.maxEntry) {
+		return fmt.Errorf("%w: %s is %d bytes (limit %d)", ErrArchiveLimitExceeded, zf.Name, zf.UncompressedSize64, ex.maxEntry)
+	}
+
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return ex.file(zf.Name, int64(zf.UncompressedSize64), zf.Mode(), zf.Modified, rc)
+}
+
+// archiveCreateGuard rejects an archive path inside the directory being archived, which would archive itself
+func archiveCreateGuard(srcDir string, archivePath string) error {
+	if strings.TrimSpace(srcDir) == "" || strings.TrimSpace(archivePath) == "" {
+		return fmt.Errorf("source or archive path is empty")
+	}
+
+	srcAbs, err := filepath.Abs(srcDir)
+	if err != nil {
+		return err
+	}
+	archiveAbs, err := filepath.Abs(archivePath)
+	if err != nil {
+		return err
+	}
+
+	if pathWithin(archiveAbs, srcAbs) {
+		return fmt.Errorf("archive path is within the source: %s -> %s", srcDir, archivePath)
+	}
+
+	return nil
+}
+
+// walkArchiveSource visits the filtered directories and regular files under srcDir in lexical order,
+// passing the slash separated relative path; symlinks are skipped and other special files are rejected
+func walkArchiveSource(srcDir string, opts ArchiveOptions, visit func(rel string, full string, info fs.FileInfo) error) error {
+	if err := ensureNoSymlinkDirs(filepath.Dir(srcDir)); err != nil {
+		return err
+	}
+
+	rootInfo, err := os.Lstat(srcDir)
+	if err != nil {
+		return err
+	}
+	if !rootInfo.IsDir() {
+		return fmt.Errorf("source is not a directory: %s", srcDir)
+	}
+
+	return filepath.WalkDir(srcDir, func(full string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if full == srcDir {
+			return nil
+		}
+
+		rel, err := filepath.Rel(srcDir, full)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if !pathFilterAllows(rel, d.IsDir(), opts.Include, opts.Exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return fmt.Errorf("unsupported file type at %s", full)
+		}
+
+		return visit(rel, full, info)
+	})
+}
+
+// copyArchiveSourceFile copies a source file into the archive, refusing files that changed type or size meanwhile
+func copyArchiveSourceFile(w io.Writer, full string, info fs.FileInfo) error {
+	f, finfo, err := openRegularRead(full)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if !os.SameFile(info, finfo) {
+		return fmt.Errorf("source changed during archive: %s", full)
+	}
+
+	// the header already declared info.Size() bytes, copy exactly that many
+	n, err := io.Copy(w, io.LimitReader(f, info.Size()))
+	if err != nil {
+		return err
+	}
+	if n != info.Size() {
+		return fmt.Errorf("source changed size during archive: %s", full)
+	}
+
+	return nil
+}
+
+// archiveExtractRoot creates dstDir when missing and returns its absolute path, rejecting symlinked ancestors
+func archiveExtractRoot(dstDir string) (string, error) {
+	if strings.TrimSpace(dstDir) == "" {
+		return "", fmt.Errorf("destination path is empty")
+	}
+
+	dstAbs, err := filepath.Abs(dstDir)
+	if err != nil {
+		return "", err
+	}
+
+	if err = ensureNoSymlinkDirs(dstAbs); err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(dstAbs, 0o755); err != nil {
+		return "", err
+	}
+
+	return dstAbs, nil
+}
+
+// archiveExtractor writes archive entries below root while enforcing the path and size guards
+type archiveExtractor struct {
+	root string
+	opts ArchiveOptions
+
+	maxEntry   int64
+	maxTotal   int64
+	maxEntries int
+
+	entries int
+	total   int64
+
+	// directory mtimes are applied last, since extracting children bumps them
+	dirTimes map[string]time.Time
+}
+
+// newArchiveExtractor prepares an extractor rooted at the absolute dstAbs
+func newArchiveExtractor(dstAbs string, opts ArchiveOptions) *archiveExtractor {
+	maxEntry, maxTotal, maxEntries := opts.limits()
+
+	return &archiveExtractor{
+		root:       dstAbs,
+		opts:       opts,
+		maxEntry:   maxEntry,
+		maxTotal:   maxTotal,
+		maxEntries: maxEntries,
+		dirTimes:   make(map[string]time.Time),
+	}
+}
+
+// count enforces the entry cap
+func (ex *archiveExtractor) count() error {
+	ex.entries++
+	if ex.entries > ex.maxEntries {
+		return fmt.Errorf("%w: more than %d entries", ErrArchiveLimitExceeded, ex.maxEntries)
+	}
+	return nil
+}
+
+// target resolves an entry name below root, rejecting absolute names and names escaping root (zip-slip)
+func (ex *archiveExtractor) target(name string) (rel string, full string, err error) {
+	clean := strings.ReplaceAll(name, "\\", "/")
+
+	if strings.HasPrefix(clean, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", "", fmt.Errorf("archive entry has an absolute path: %s", name)
+	}
+
+	rel = path.Clean(clean)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", "", fmt.Errorf("archive entry escapes the destination: %s", name)
+	}
+
+	full = filepath.Join(ex.root, filepath.FromSlash(rel))
+	if !pathWithin(full, ex.root) || pathsEqual(full, ex.root) {
+		return "", "", fmt.Errorf("archive entry escapes the destination: %s", name)
+	}
+
+	return rel, full, nil
+}
+
+// dir creates a directory entry
+func (ex *archiveExtractor) dir(name string, mode fs.FileMode, modTime time.Time) error {
+	if err := ex.count(); err != nil {
+		return err
+	}
+
+	rel, full, err := ex.target(name)
+	if err != nil {
+		return err
+	}
+
+	if !pathFilterAllows(rel, true, ex.opts.Include, ex.opts.Exclude) {
+		return nil
+	}
+
+	if err = ensureNoSymlinkDirs(full); err != nil {
+		return err
+	}
+
+	perm := mode.Perm() | 0o700 // the owner must be able to extract children
+	if err = os.MkdirAll(full, perm); err != nil {
+		return err
+	}
+
+	if !modTime.IsZero() {
+		ex.dirTimes[full] = modTime
+	}
+
+	return nil
+}
+
+// file writes a regular file entry from r, declared is the size stated in the archive header
+func (ex *archiveExtractor) file(name string, declared int64, mode fs.FileMode, modTime time.Time, r io.Reader) error {
+	if err := ex.count(); err != nil {
+		return err
+	}
+
+	rel, full, err := ex.target(name)
+	if err != nil {
+		return err
+	}
+
+	if !pathFilterAllows(rel, false, ex.opts.Include, ex.opts.Exclude) {
+		return nil
+	}
+
+	if declared > ex.maxEntry {
+		return fmt.Errorf("%w: %s is %d bytes (limit %d)", ErrArchiveLimitExceeded, name, declared, ex.maxEntry)
+	}
+
+	dir := filepath.Dir(full)
+	if err = ensureNoSymlinkDirs(dir); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	f, _, err := openRegularFile(full, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	// same bounded read approach as readAllWithLimit: read one byte past the cap to detect overflow
+	limit := ex.maxEntry
+	if remaining := ex.maxTotal - ex.total; remaining < limit {
+		limit = remaining
+	}
+
+	n, err := io.Copy(f, &io.LimitedReader{R: r, N: limit + 1})
+	ex.total += n
+
+	if err == nil && n > limit {
+		err = fmt.Errorf("%w: %s exceeds the size cap", ErrArchiveLimitExceeded, name)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(full)
+		return err
+	}
+
+	// strip setuid / setgid / sticky, keep owner read / write so the file stays usable
+	if err = os.Chmod(full, mode.Perm()|0o600); err != nil {
+		return err
+	}
+
+	if !modTime.IsZero() {
+		if err = os.Chtimes(full, modTime, modTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// finish applies the deferred directory mtimes
+func (ex *archiveExtractor) finish() error {
+	for dir, t := range ex.dirTimes {
+		if err := os.Chtimes(dir, t, t); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// lockFileHandle is not supported on this platform
+func lockFileHandle(f *os.File) (bool, error) {
+	return false, fmt.Errorf("file lock is not supported on %s", runtime.GOOS)
+}
+
+// unlockFileHandle is not supported on this platform
+func unlockFileHandle(f *os.File) error {
+	return fmt.Errorf("file lock is not supported on %s", runtime.GOOS)
+}
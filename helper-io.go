@@ -22,9 +22,13 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 const maxReadBytes = int64(64 << 20) // cap file reads at 64MiB
@@ -86,134 +90,66 @@ func FileReadBytes(path string) ([]byte, error) {
 }
 
 // FileWrite will write data into file at the given path,
-// if successful, no error is returned (nil)
+// if successful, no error is returned (nil),
+// the write is atomic as in FileWriteAtomic, and a new file is created with 0600 permission
 func FileWrite(path string, data string) error {
-	if strings.TrimSpace(path) == "" {
-		return fmt.Errorf("path is empty")
-	}
-	dir := filepath.Dir(path)
-	if err := ensureNoSymlinkDirs(dir); err != nil {
+	return writeFileAtomic(path, 0, func(w io.Writer) error {
+		_, err := io.WriteString(w, data)
 		return err
-	}
-
-	// default to preserving existing file mode when present; otherwise keep temp's umask-respecting default
-	var mode os.FileMode
-	var haveMode bool
-
-	if info, err := os.Lstat(path); err == nil {
-		if info.Mode()&os.ModeSymlink != 0 {
-			return fmt.Errorf("path is a symlink: %s", path)
-		}
-		if info.IsDir() {
-			return fmt.Errorf("path is a directory: %s", path)
-		}
-		if !info.Mode().IsRegular() { // forbid writing over special files
-			return fmt.Errorf("path is not a regular file: %s", path)
-		}
-		mode, haveMode = info.Mode(), true
-	} else if !os.IsNotExist(err) {
-		return err
-	}
+	})
+}
 
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return err
-	}
-	if err := ensureNoSymlinkDirs(dir); err != nil { // re-validate parent to shrink TOCTTOU window
+// FileWriteBytes will write byte data into file at the given path,
+// if successful, no error is returned (nil),
+// the write is atomic as in FileWriteAtomic, and a new file is created with 0600 permission
+func FileWriteBytes(path string, data []byte) error {
+	return writeFileAtomic(path, 0, func(w io.Writer) error {
+		_, err := w.Write(data)
 		return err
-	}
+	})
+}
 
-	// use unique temp file in target directory to avoid races/symlink attacks
-	base := filepath.Base(path)
-	tmpFile, err := os.CreateTemp(dir, "."+base+".tmp-*")
-	if err != nil {
+// FileWriteAtomic writes data to path so readers and crashes only ever see the old or the new content:
+// data goes to a temp file in the same directory, which is fsynced, renamed over path, and the directory is fsynced;
+// an existing file keeps its mode, a new file is created with perm (0 means 0600),
+// and symlinked parent directories or a symlink / non-regular file at path are rejected
+func FileWriteAtomic(path string, data []byte, perm os.FileMode) error {
+	return writeFileAtomic(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
 		return err
-	}
-	tmp := tmpFile.Name()
-	cleanup := true
-	defer func() {
-		if cleanup {
-			_ = os.Remove(tmp)
-		}
-	}()
+	})
+}
 
-	if err := ensureNoSymlinkDirs(dir); err != nil { // re-validate immediately after temp creation
-		_ = tmpFile.Close()
-		return err
+// FileWriteAtomicFunc is FileWriteAtomic for content streamed by write instead of held in memory,
+// write receives the temp file writer, and an error from write leaves path untouched
+func FileWriteAtomicFunc(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	if write == nil {
+		return fmt.Errorf("write func is nil")
 	}
 
-	if _, err := io.WriteString(tmpFile, data); err != nil {
-		_ = tmpFile.Close()
-		return err
-	}
-	if err := tmpFile.Sync(); err != nil {
-		_ = tmpFile.Close()
-		return err
-	}
-	if err := tmpFile.Close(); err != nil {
-		return err
-	}
-	if haveMode {
-		const mask = os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky
-		if err := os.Chmod(tmp, mode&mask); err != nil { // ensure final mode
-			return err
-		}
-	}
+	return writeFileAtomic(path, perm, write)
+}
 
-	if err := ensureNoSymlinkDirs(dir); err != nil { // re-validate just before rename
-		return err
+// FileOpenRead opens the regular file at path for streaming reads, with the same symlink and
+// non-regular file guards as FileRead but without its 64MiB size cap, the caller closes the file
+func FileOpenRead(path string) (*os.File, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("path is empty")
 	}
-
-	if err := os.Rename(tmp, path); err != nil {
-		// surface failure to remove old destination before retry
-		if errors.Is(err, fs.ErrExist) || runtime.GOOS == "windows" {
-			// revalidate destination before removing to avoid deleting dirs/symlinks
-			if info, statErr := os.Lstat(path); statErr == nil {
-				if info.Mode()&os.ModeSymlink != 0 {
-					return fmt.Errorf("destination is a symlink: %s", path)
-				}
-				if info.IsDir() {
-					return fmt.Errorf("destination is a directory: %s", path)
-				}
-				if !info.Mode().IsRegular() {
-					return fmt.Errorf("destination is not a regular file: %s", path)
-				}
-			} else if !os.IsNotExist(statErr) {
-				return statErr
-			}
-
-			_ = os.Chmod(path, 0o666) // best-effort; ignore error
-			if remErr := os.Remove(path); remErr != nil && !os.IsNotExist(remErr) {
-				return fmt.Errorf("rename failed: %w; cleanup failed: %w", err, remErr)
-			}
-			if err2 := os.Rename(tmp, path); err2 != nil {
-				return err2
-			}
-		} else {
-			return err
-		}
+	if err := ensureNoSymlinkDirs(filepath.Dir(path)); err != nil {
+		return nil, err
 	}
 
-	// fsync parent directory to make rename durable
-	// skip directory fsync on Windows (not supported)
-	if runtime.GOOS != "windows" {
-		if dir, err := os.Open(filepath.Dir(path)); err == nil {
-			if syncErr := dir.Sync(); syncErr != nil {
-				_ = dir.Close()
-				return syncErr
-			}
-			_ = dir.Close()
-		} else {
-			return err
-		}
+	f, _, err := openRegularRead(path)
+	if err != nil {
+		return nil, err
 	}
 
-	cleanup = false
-	return nil
+	return f, nil
 }
 
-// FileWriteBytes will write byte data into file at the given path,
-// if successful, no error is returned (nil)
-func FileWriteBytes(path string, data []byte) error {
+// writeFileAtomic is the shared temp file, fsync, rename, dir fsync sequence of the FileWrite helpers
+func writeFileAtomic(path string, perm os.FileMode, write func(w io.Writer) error) error {
 	if strings.TrimSpace(path) == "" {
 		return fmt.Errorf("path is empty")
 	}
@@ -222,7 +158,7 @@ func FileWriteBytes(path string, data []byte) error {
 		return err
 	}
 
-	// default to preserving existing file mode when present; otherwise keep temp's umask-respecting default
+	// default to preserving existing file mode when present; otherwise use perm, or keep temp's 0600 default
 	var mode os.FileMode
 	var haveMode bool
 
@@ -239,9 +175,11 @@ func FileWriteBytes(path string, data []byte) error {
 		mode, haveMode = info.Mode(), true
 	} else if !os.IsNotExist(err) {
 		return err
+	} else if perm != 0 {
+		mode, haveMode = perm, true
 	}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return err
 	}
 	if err := ensureNoSymlinkDirs(dir); err != nil { // re-validate parent to shrink TOCTTOU window
@@ -267,7 +205,7 @@ func FileWriteBytes(path string, data []byte) error {
 		return err
 	}
 
-	if _, err := tmpFile.Write(data); err != nil {
+	if err := write(tmpFile); err != nil {
 		_ = tmpFile.Close()
 		return err
 	}
@@ -319,22 +257,30 @@ func FileWriteBytes(path string, data []byte) error {
 		}
 	}
 
+	cleanup = false
+
 	// fsync parent directory to make rename durable
-	// skip directory fsync on Windows (not supported)
-	if runtime.GOOS != "windows" {
-		if dirFd, err := os.Open(dir); err == nil {
-			if syncErr := dirFd.Sync(); syncErr != nil {
-				_ = dirFd.Close()
-				return syncErr
-			}
-			_ = dirFd.Close()
-		} else {
-			return err
-		}
+	return syncDir(dir)
+}
+
+// syncDir fsyncs a directory so renames and creates inside it are durable,
+// skipped on Windows where directory fsync is not supported
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
 	}
 
-	cleanup = false
-	return nil
+	dirFd, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	if syncErr := dirFd.Sync(); syncErr != nil {
+		_ = dirFd.Close()
+		return syncErr
+	}
+
+	return dirFd.Close()
 }
 
 // FileExists checks if input file in path exists
@@ -564,8 +510,9 @@ func CopyFile(src string, dst string) (err error) { // named return for close er
 	return nil
 }
 
-// CopyDir - Dir copies a whole directory recursively
-func CopyDir(src string, dst string) error {
+// copyDirGuard validates the CopyDir source and destination: no symlinked ancestors,
+// no copy into self (raw or symlink-resolved), and no symlink or non-directory destination
+func copyDirGuard(src string, dst string) error {
 	if strings.TrimSpace(src) == "" || strings.TrimSpace(dst) == "" {
 		return fmt.Errorf("source or destination path is empty")
 	}
@@ -626,6 +573,15 @@ func CopyDir(src string, dst string) error {
 		}
 	}
 
+	return nil
+}
+
+// CopyDir - Dir copies a whole directory recursively
+func CopyDir(src string, dst string) error {
+	if err := copyDirGuard(src, dst); err != nil {
+		return err
+	}
+
 	srcinfo, err := os.Lstat(src)
 	if err != nil {
 		return err
@@ -736,6 +692,590 @@ func CopyDir(src string, dst string) error {
 	return nil
 }
 
+// CopyDirOptions configures CopyDirWithOptions, the zero value copies every file without preserving mode or mtime
+type CopyDirOptions struct {
+	// Include limits the copy to files matching any of these glob patterns, empty includes every file;
+	// Exclude skips matching files and directories (with their subtree);
+	// patterns follow filepath.Match against either the slash separated path relative to src or the base name,
+	// and a trailing "/**" matches everything under that relative directory
+	Include []string
+	Exclude []string
+
+	// PreserveMode keeps the source permission bits, otherwise files get 0644 and directories 0755
+	PreserveMode bool
+
+	// PreserveModTime sets the modification time of copied files and directories to the source's
+	PreserveModTime bool
+
+	// SkipExisting leaves destination files that already exist untouched
+	SkipExisting bool
+
+	// Progress, when set, is called after each file is copied or skipped, a non-nil return aborts the copy with that error
+	Progress func(p CopyDirProgress) error
+}
+
+// CopyDirProgress reports the file just handled by CopyDirWithOptions and the running totals
+type CopyDirProgress struct {
+	RelPath string // slash separated path relative to src
+	Size    int64  // size of this file
+	Skipped bool   // true when SkipExisting left the destination in place
+
+	FilesCopied  int
+	FilesSkipped int
+	BytesCopied  int64
+}
+
+// CopyDirWithOptions copies directory src into dst recursively like CopyDir,
+// with include / exclude filters, mode and mtime preservation, skip existing and a progress callback;
+// symlinks are recreated, not followed, and other special files are rejected
+func CopyDirWithOptions(src string, dst string, opts CopyDirOptions) error {
+	if err := copyDirGuard(src, dst); err != nil {
+		return err
+	}
+
+	srcinfo, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if srcinfo.Mode()&os.ModeSymlink != 0 || !srcinfo.IsDir() {
+		return fmt.Errorf("source is not a directory: %s", src)
+	}
+
+	progress := &CopyDirProgress{}
+	return copyDirTree(src, dst, "", srcinfo, &opts, progress)
+}
+
+// copyDirTree copies one directory level of CopyDirWithOptions, rel is the slash separated path of src below the root
+func copyDirTree(src string, dst string, rel string, srcinfo os.FileInfo, opts *CopyDirOptions, progress *CopyDirProgress) error {
+	dirMode := os.FileMode(0o755)
+	if opts.PreserveMode {
+		dirMode = srcinfo.Mode().Perm()
+	}
+
+	if err := ensureNoSymlinkDirs(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, dirMode); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		entryRel := name
+		if len(rel) > 0 {
+			entryRel = rel + "/" + name
+		}
+		srcfp := filepath.Join(src, name)
+		dstfp := filepath.Join(dst, name)
+
+		info, err := os.Lstat(srcfp)
+		if err != nil {
+			return err
+		}
+		mode := info.Mode()
+
+		if !pathFilterAllows(entryRel, mode.IsDir(), opts.Include, opts.Exclude) {
+			continue
+		}
+
+		switch {
+		case mode&os.ModeSymlink != 0:
+			if opts.SkipExisting {
+				if _, err := os.Lstat(dstfp); err == nil {
+					continue
+				}
+			}
+			// CopyFile recreates top-level symlinks instead of following them
+			if err := CopyFile(srcfp, dstfp); err != nil {
+				return err
+			}
+
+		case mode.IsDir():
+			if err := copyDirTree(srcfp, dstfp, entryRel, info, opts, progress); err != nil {
+				return err
+			}
+
+		case mode.IsRegular():
+			if err := copyDirFile(srcfp, dstfp, entryRel, info, opts, progress); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("unsupported file type at %s", srcfp)
+		}
+	}
+
+	if err := os.Chmod(dst, dirMode); err != nil {
+		return err
+	}
+	if opts.PreserveModTime {
+		// applied after the children, whose creation would otherwise bump the directory mtime
+		if err := os.Chtimes(dst, srcinfo.ModTime(), srcinfo.ModTime()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyDirFile copies a single regular file for CopyDirWithOptions and reports progress
+func copyDirFile(srcfp string, dstfp string, rel string, info os.FileInfo, opts *CopyDirOptions, progress *CopyDirProgress) error {
+	skipped := false
+
+	if opts.SkipExisting {
+		if _, err := os.Lstat(dstfp); err == nil {
+			skipped = true
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if !skipped {
+		if err := CopyFile(srcfp, dstfp); err != nil {
+			return err
+		}
+		if !opts.PreserveMode {
+			if err := os.Chmod(dstfp, 0o644); err != nil {
+				return err
+			}
+		}
+		if opts.PreserveModTime {
+			// atime is not portable, mirror mtime
+			if err := os.Chtimes(dstfp, info.ModTime(), info.ModTime()); err != nil {
+				return err
+			}
+		}
+
+		progress.FilesCopied++
+		progress.BytesCopied += info.Size()
+	} else {
+		progress.FilesSkipped++
+	}
+
+	if opts.Progress == nil {
+		return nil
+	}
+
+	progress.RelPath = rel
+	progress.Size = info.Size()
+	progress.Skipped = skipped
+
+	return opts.Progress(*progress)
+}
+
+// pathFilterAllows applies include / exclude glob patterns to a slash separated relative path,
+// excludes apply to files and directories, includes only to files so directories are always descended
+func pathFilterAllows(rel string, isDir bool, include []string, exclude []string) bool {
+	for _, p := range exclude {
+		if pathFilterMatch(p, rel) {
+			return false
+		}
+	}
+
+	if isDir || len(include) == 0 {
+		return true
+	}
+
+	for _, p := range include {
+		if pathFilterMatch(p, rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pathFilterMatch matches pattern against the relative path or its base name, "dir/**" matches anything below dir
+func pathFilterMatch(pattern string, rel string) bool {
+	pattern = filepath.ToSlash(strings.TrimSpace(pattern))
+	if len(pattern) == 0 {
+		return false
+	}
+
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return rel == prefix || strings.HasPrefix(rel, prefix+"/")
+	}
+
+	if ok, _ := path.Match(pattern, rel); ok {
+		return true
+	}
+
+	ok, _ := path.Match(pattern, path.Base(rel))
+	return ok
+}
+
+// =====================================================================================================================
+// File Lock
+// =====================================================================================================================
+
+// ErrFileLockTimeout is returned by LockFile when the lock is still held by another process after the timeout
+var ErrFileLockTimeout = errors.New("file lock timeout")
+
+// fileLockPollInterval is how often LockFile retries a held lock
+const fileLockPollInterval = 25 * time.Millisecond
+
+// FileLock is an exclusive advisory lock on a lock file (flock on unix, LockFileEx on windows),
+// it coordinates processes, or goroutines holding separate FileLock values, that agree to lock the same path;
+// it does not stop other code from reading or writing the file
+type FileLock struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+}
+
+// LockFile acquires an exclusive lock on path, creating the lock file (and its directory) when missing,
+// a held lock is retried until timeout elapses, then ErrFileLockTimeout is returned; timeout <= 0 tries once
+func LockFile(path string, timeout time.Duration) (*FileLock, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		l, ok, err := tryLockFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return l, nil
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("%w: %s", ErrFileLockTimeout, path)
+		}
+
+		time.Sleep(fileLockPollInterval)
+	}
+}
+
+// TryLockFile attempts to lock path once, returning nil and false when another holder has it
+func TryLockFile(path string) (*FileLock, bool, error) {
+	return tryLockFile(path)
+}
+
+// tryLockFile opens the lock file with the symlink guards and attempts a non-blocking lock
+func tryLockFile(path string) (*FileLock, bool, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, false, fmt.Errorf("path is empty")
+	}
+	dir := filepath.Dir(path)
+	if err := ensureNoSymlinkDirs(dir); err != nil {
+		return nil, false, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, false, err
+	}
+
+	f, _, err := openRegularFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ok, err := lockFileHandle(f)
+	if err != nil || !ok {
+		_ = f.Close()
+		return nil, false, err
+	}
+
+	return &FileLock{path: path, f: f}, true, nil
+}
+
+// Path returns the lock file path
+func (l *FileLock) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+// Unlock releases the lock and closes the lock file, the lock file itself is left in place,
+// calling Unlock more than once is a no-op
+func (l *FileLock) Unlock() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.f == nil {
+		return nil
+	}
+
+	err := unlockFileHandle(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	l.f = nil
+
+	return err
+}
+
+// =====================================================================================================================
+// Rotating File Writer
+// =====================================================================================================================
+
+// RotatingFileWriter is an io.WriteCloser appending to a file that is rotated when it would exceed MaxSizeBytes
+// or is older than MaxAge, rotated files are renamed in place to name-yyyymmddThhmmss.fff.ext
+// and only the newest MaxBackups are kept; it is safe for concurrent use
+type RotatingFileWriter struct {
+	path         string
+	maxSizeBytes int64
+	maxAge       time.Duration
+	maxBackups   int
+
+	mu       sync.Mutex
+	f        *os.File
+	size     int64
+	openedAt time.Time
+
+	now    func() time.Time
+	rename func(oldPath string, newPath string) error // test hook, os.Rename
+}
+
+// NewRotatingFileWriter opens (or creates) path for appending,
+// maxSizeBytes <= 0 disables size rotation, maxAge <= 0 disables age rotation and maxBackups <= 0 keeps every backup;
+// the age of a pre-existing file counts from its last modification time
+func NewRotatingFileWriter(path string, maxSizeBytes int64, maxAge time.Duration, maxBackups int) (*RotatingFileWriter, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("path is empty")
+	}
+
+	w := &RotatingFileWriter{
+		path:         path,
+		maxSizeBytes: maxSizeBytes,
+		maxAge:       maxAge,
+		maxBackups:   maxBackups,
+		now:          time.Now,
+		rename:       os.Rename,
+	}
+
+	if err := w.openLocked(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Write appends p, rotating first when p would push the file past MaxSizeBytes or the file is past MaxAge,
+// a single write larger than MaxSizeBytes still goes to one file;
+// when rotation fails, p is still appended to the current file and the rotation error is returned
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return 0, os.ErrClosed
+	}
+
+	var rotateErr error
+	if w.shouldRotateLocked(int64(len(p))) {
+		if rotateErr = w.rotateLocked(); rotateErr != nil && w.f == nil {
+			return 0, rotateErr
+		}
+	}
+
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+
+	if err == nil {
+		err = rotateErr
+	}
+
+	return n, err
+}
+
+// Rotate closes the current file, renames it to a timestamped backup and opens a fresh file
+func (w *RotatingFileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return os.ErrClosed
+	}
+
+	return w.rotateLocked()
+}
+
+// Sync commits the current file to stable storage
+func (w *RotatingFileWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return os.ErrClosed
+	}
+
+	return w.f.Sync()
+}
+
+// Close closes the current file, further writes return os.ErrClosed
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.f == nil {
+		return nil
+	}
+
+	err := w.f.Close()
+	w.f = nil
+
+	return err
+}
+
+// shouldRotateLocked reports whether the size or age limit requires a rotation before writing n bytes
+func (w *RotatingFileWriter) shouldRotateLocked(n int64) bool {
+	if w.size == 0 {
+		return false
+	}
+
+	if w.maxSizeBytes > 0 && w.size+n > w.maxSizeBytes {
+		return true
+	}
+
+	return w.maxAge > 0 && w.now().Sub(w.openedAt) >= w.maxAge
+}
+
+// openLocked opens the file for appending with the symlink guards and records its size and age
+func (w *RotatingFileWriter) openLocked() error {
+	dir := filepath.Dir(w.path)
+	if err := ensureNoSymlinkDirs(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	f, finfo, err := openRegularFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	w.f = f
+	w.size = finfo.Size()
+	w.openedAt = w.now()
+
+	if w.size > 0 {
+		w.openedAt = finfo.ModTime()
+	}
+
+	return nil
+}
+
+// rotateLocked renames the current file to its backup name, reopens a fresh file and prunes old backups;
+// if the rename or reopen fails, the current path is reopened for appending so later writes are not lost
+func (w *RotatingFileWriter) rotateLocked() error {
+	closeErr := w.f.Close()
+	w.f = nil
+
+	if closeErr != nil {
+		return w.reopenAfterFailedRotateLocked(closeErr)
+	}
+
+	if err := w.renameAndOpenLocked(); err != nil {
+		return w.reopenAfterFailedRotateLocked(err)
+	}
+
+	_ = syncDir(filepath.Dir(w.path)) // best-effort; rotation itself already succeeded
+
+	return w.pruneLocked()
+}
+
+// reopenAfterFailedRotateLocked reopens the current path after rotation failed with err, returning err,
+// joined with the reopen error when the writer is left closed
+func (w *RotatingFileWriter) reopenAfterFailedRotateLocked(err error) error {
+	if w.f != nil {
+		return err
+	}
+
+	if reopenErr := w.openLocked(); reopenErr != nil {
+		return errors.Join(err, reopenErr)
+	}
+
+	return err
+}
+
+// renameAndOpenLocked renames the closed current file to a new backup name and opens a fresh file at the path
+func (w *RotatingFileWriter) renameAndOpenLocked() error {
+	dir := filepath.Dir(w.path)
+	if err := ensureNoSymlinkDirs(dir); err != nil {
+		return err
+	}
+
+	prefix, ext := w.backupNameParts()
+	stamp := w.now().UTC().Format("20060102T150405.000")
+	backup := filepath.Join(dir, prefix+stamp+ext)
+
+	for i := 1; FileExists(backup); i++ {
+		backup = filepath.Join(dir, fmt.Sprintf("%s%s-%d%s", prefix, stamp, i, ext))
+	}
+
+	if err := w.rename(w.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := w.openLocked(); err != nil {
+		return err
+	}
+	w.openedAt = w.now()
+
+	return nil
+}
+
+// backupNameParts returns the "name-" prefix and ".ext" suffix that surround a backup timestamp
+func (w *RotatingFileWriter) backupNameParts() (prefix string, ext string) {
+	base := filepath.Base(w.path)
+	ext = filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// pruneLocked removes the oldest backups beyond maxBackups, the timestamp format sorts chronologically by name
+func (w *RotatingFileWriter) pruneLocked() error {
+	if w.maxBackups <= 0 {
+		return nil
+	}
+
+	dir := filepath.Dir(w.path)
+	prefix, ext := w.backupNameParts()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var backups []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if len(stamp) < len("20060102T150405.000") {
+			continue
+		}
+		if _, perr := time.Parse("20060102T150405.000", stamp[:len("20060102T150405.000")]); perr != nil {
+			continue
+		}
+
+		backups = append(backups, name)
+	}
+
+	sort.Strings(backups)
+
+	for len(backups) > w.maxBackups {
+		if rerr := os.Remove(filepath.Join(dir, backups[0])); rerr != nil && !os.IsNotExist(rerr) {
+			return rerr
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
 // helper functions for safe, case-aware path comparison
 func normPath(p string) string {
 	p = filepath.Clean(p)
@@ -762,9 +1302,35 @@ func pathWithin(path, parent string) bool {
 
 // helper to open a regular file safely without following swapped-in symlinks.
 func openRegularRead(path string) (*os.File, os.FileInfo, error) {
+	return openRegularFile(path, os.O_RDONLY, 0)
+}
+
+// openRegularFile opens path with flag, refusing symlinks and non-regular files,
+// with os.O_CREATE a missing file is created exclusively so a symlink swapped in meanwhile is never followed
+func openRegularFile(path string, flag int, perm os.FileMode) (*os.File, os.FileInfo, error) {
 	linfo, err := os.Lstat(path)
 	if err != nil {
-		return nil, nil, err
+		if !os.IsNotExist(err) || flag&os.O_CREATE == 0 {
+			return nil, nil, err
+		}
+
+		f, cerr := os.OpenFile(path, flag|os.O_EXCL, perm)
+		if cerr == nil {
+			finfo, serr := f.Stat()
+			if serr != nil {
+				_ = f.Close()
+				return nil, nil, serr
+			}
+			return f, finfo, nil
+		}
+		if !os.IsExist(cerr) {
+			return nil, nil, cerr
+		}
+
+		// created concurrently, validate what is there now
+		if linfo, err = os.Lstat(path); err != nil {
+			return nil, nil, err
+		}
 	}
 	if linfo.Mode()&os.ModeSymlink != 0 {
 		return nil, nil, fmt.Errorf("path is a symlink: %s", path)
@@ -776,7 +1342,7 @@ func openRegularRead(path string) (*os.File, os.FileInfo, error) {
 		return nil, nil, fmt.Errorf("path is not a regular file: %s", path)
 	}
 
-	f, err := os.Open(path)
+	f, err := os.OpenFile(path, flag&^(os.O_CREATE|os.O_EXCL), 0)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,422 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for FileWriteAtomic, LockFile, RotatingFileWriter and CopyDirWithOptions (helper-io.go)
+// and the tar.gz / zip helpers (helper-io-archive.go).
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sub", "config.json")
+
+	if err := FileWriteAtomic(path, []byte(`{"a":1}`), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := FileRead(path); err != nil || got != `{"a":1}` {
+		t.Fatalf("read back = %q, %v", got, err)
+	}
+
+	if runtime.GOOS != "windows" {
+		if info, _ := os.Stat(path); info.Mode().Perm() != 0o640 {
+			t.Fatalf("mode = %v", info.Mode().Perm())
+		}
+
+		// existing mode is preserved over perm
+		if err := FileWriteAtomic(path, []byte(`{"a":2}`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if info, _ := os.Stat(path); info.Mode().Perm() != 0o640 {
+			t.Fatalf("mode after overwrite = %v", info.Mode().Perm())
+		}
+	}
+
+	// no temp files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("expected only the target file, got %d entries", len(entries))
+	}
+
+	if runtime.GOOS != "windows" {
+		link := filepath.Join(dir, "link.json")
+		if err := os.Symlink(path, link); err == nil {
+			if err = FileWriteAtomic(link, []byte("x"), 0); err == nil {
+				t.Fatal("expected symlink target to be rejected")
+			}
+		}
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "job.lock")
+
+	l1, err := LockFile(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := TryLockFile(path); err != nil || ok {
+		t.Fatalf("second try lock = %v, %v", ok, err)
+	}
+
+	start := time.Now()
+	if _, err = LockFile(path, 60*time.Millisecond); !errors.Is(err, ErrFileLockTimeout) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Fatal("lock returned before the timeout elapsed")
+	}
+
+	// release while a waiter is polling
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_ = l1.Unlock()
+	}()
+
+	l2, err := LockFile(path, 2*time.Second)
+	if err != nil {
+		t.Fatalf("waiter did not acquire released lock: %v", err)
+	}
+
+	if err = l2.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err = l2.Unlock(); err != nil {
+		t.Fatal("second unlock must be a no-op")
+	}
+}
+
+func TestRotatingFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w, err := NewRotatingFileWriter(path, 10, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	w.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	for _, line := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		if _, err = w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = w.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("write after close = %v", err)
+	}
+
+	current, _ := FileRead(path)
+	if current != "dddddd\n" {
+		t.Fatalf("current = %q", current)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 backups after pruning, got %v", matches)
+	}
+
+	// the oldest backup (aaaaaa) was pruned
+	for _, m := range matches {
+		if b, _ := FileRead(m); strings.Contains(b, "aaaaaa") {
+			t.Fatalf("oldest backup should be pruned: %s", m)
+		}
+	}
+}
+
+func TestRotatingFileWriter_MaxAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "age.log")
+
+	w, err := NewRotatingFileWriter(path, 0, time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	clock := time.Now()
+	w.now = func() time.Time { return clock }
+	w.openedAt = clock
+
+	_, _ = w.Write([]byte("first\n"))
+
+	clock = clock.Add(2 * time.Hour)
+	_, _ = w.Write([]byte("second\n"))
+
+	if current, _ := FileRead(path); current != "second\n" {
+		t.Fatalf("expected age rotation, current = %q", current)
+	}
+}
+
+func TestRotatingFileWriter_FailedRotationKeepsWriting(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fail.log")
+
+	w, err := NewRotatingFileWriter(path, 10, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	renameErr := errors.New("rename refused")
+	w.rename = func(string, string) error { return renameErr }
+
+	if _, err = w.Write([]byte("aaaaaa\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the rotation fails, the line still lands in the current file and the error is reported
+	if n, err := w.Write([]byte("bbbbbb\n")); n != 7 || !errors.Is(err, renameErr) {
+		t.Fatalf("write with failed rotation = %d, %v", n, err)
+	}
+
+	if current, _ := FileRead(path); current != "aaaaaa\nbbbbbb\n" {
+		t.Fatalf("current = %q", current)
+	}
+
+	// once renames work again the next write rotates normally
+	w.rename = os.Rename
+
+	if _, err = w.Write([]byte("cccccc\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if current, _ := FileRead(path); current != "cccccc\n" {
+		t.Fatalf("current after recovery = %q", current)
+	}
+
+	if matches, _ := filepath.Glob(filepath.Join(dir, "fail-*.log")); len(matches) != 1 {
+		t.Fatalf("expected 1 backup, got %v", matches)
+	}
+}
+
+// writeTestTree creates files under dir from a rel path -> content map
+func writeTestTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for rel, content := range files {
+		if err := FileWriteAtomic(filepath.Join(dir, filepath.FromSlash(rel)), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCopyDirWithOptions(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	dst := filepath.Join(t.TempDir(), "dst")
+
+	writeTestTree(t, src, map[string]string{
+		"a.log":          "a",
+		"b.txt":          "b",
+		"logs/c.log":     "c",
+		"tmp/d.log":      "d",
+		"logs/old/e.log": "e",
+	})
+
+	old := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(src, "a.log"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// an existing destination file is kept when SkipExisting is set
+	writeTestTree(t, dst, map[string]string{"logs/c.log": "keep"})
+
+	var seen []string
+	err := CopyDirWithOptions(src, dst, CopyDirOptions{
+		Include:         []string{"*.log"},
+		Exclude:         []string{"tmp", "logs/old/**"},
+		PreserveModTime: true,
+		SkipExisting:    true,
+		Progress: func(p CopyDirProgress) error {
+			seen = append(seen, p.RelPath)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(seen, ","); got != "a.log,logs/c.log" {
+		t.Fatalf("progress = %s", got)
+	}
+
+	if FileExists(filepath.Join(dst, "b.txt")) || FileExists(filepath.Join(dst, "tmp", "d.log")) || FileExists(filepath.Join(dst, "logs", "old", "e.log")) {
+		t.Fatal("filtered files were copied")
+	}
+
+	if c, _ := FileRead(filepath.Join(dst, "logs", "c.log")); c != "keep" {
+		t.Fatalf("existing file overwritten: %q", c)
+	}
+
+	if info, err := os.Stat(filepath.Join(dst, "a.log")); err != nil || !info.ModTime().Equal(old) {
+		t.Fatalf("mtime not preserved: %v, %v", info, err)
+	}
+
+	// progress errors abort the copy
+	stop := errors.New("stop")
+	err = CopyDirWithOptions(src, filepath.Join(t.TempDir(), "dst2"), CopyDirOptions{
+		Progress: func(CopyDirProgress) error { return stop },
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected progress error, got %v", err)
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	writeTestTree(t, src, map[string]string{
+		"a.txt":         "alpha",
+		"sub/b.txt":     "bravo",
+		"sub/skip.tmp":  "x",
+		"deep/er/c.txt": strings.Repeat("c", 1000),
+	})
+
+	opts := ArchiveOptions{Exclude: []string{"*.tmp"}}
+	out := t.TempDir()
+
+	for name, fns := range map[string]struct {
+		create  func(string, string, ArchiveOptions) error
+		extract func(string, string, ArchiveOptions) error
+	}{
+		"tar.gz": {TarGzCreate, TarGzExtract},
+		"zip":    {ZipCreate, ZipExtract},
+	} {
+		archive := filepath.Join(out, "bundle."+name)
+		if err := fns.create(src, archive, opts); err != nil {
+			t.Fatalf("%s create: %v", name, err)
+		}
+
+		dst := filepath.Join(out, "x-"+name)
+		if err := fns.extract(archive, dst, ArchiveOptions{}); err != nil {
+			t.Fatalf("%s extract: %v", name, err)
+		}
+
+		for rel, want := range map[string]string{"a.txt": "alpha", "sub/b.txt": "bravo", "deep/er/c.txt": strings.Repeat("c", 1000)} {
+			if got, err := FileRead(filepath.Join(dst, filepath.FromSlash(rel))); err != nil || got != want {
+				t.Fatalf("%s: %s = %q, %v", name, rel, got, err)
+			}
+		}
+
+		if FileExists(filepath.Join(dst, "sub", "skip.tmp")) {
+			t.Fatalf("%s: excluded file archived", name)
+		}
+
+		// size caps stop extraction
+		err := fns.extract(archive, filepath.Join(out, "cap-"+name), ArchiveOptions{MaxEntryBytes: 100})
+		if !errors.Is(err, ErrArchiveLimitExceeded) {
+			t.Fatalf("%s: expected limit error, got %v", name, err)
+		}
+	}
+
+	if err := TarGzCreate(src, filepath.Join(src, "self.tar.gz"), ArchiveOptions{}); err == nil {
+		t.Fatal("expected archive inside source to be rejected")
+	}
+}
+
+func TestArchiveZipSlip(t *testing.T) {
+	dir := t.TempDir()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("../evil.txt")
+	_, _ = w.Write([]byte("evil"))
+	_ = zw.Close()
+
+	archive := filepath.Join(dir, "evil.zip")
+	if err := FileWriteBytes(archive, buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "out")
+	if err := ZipExtract(archive, dst, ArchiveOptions{}); err == nil || !strings.Contains(err.Error(), "escapes") {
+		t.Fatalf("expected zip-slip rejection, got %v", err)
+	}
+
+	if FileExists(filepath.Join(dir, "evil.txt")) {
+		t.Fatal("zip-slip entry was written")
+	}
+
+	buf.Reset()
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	_ = tw.WriteHeader(&tar.Header{Name: "/etc/evil", Mode: 0o644, Size: 1, Typeflag: tar.TypeReg})
+	_, _ = tw.Write([]byte("x"))
+	_ = tw.Close()
+	_ = gz.Close()
+
+	if err := TarGzExtractReader(&buf, dst, ArchiveOptions{}); err == nil || !strings.Contains(err.Error(), "absolute") {
+		t.Fatalf("expected absolute path rejection, got %v", err)
+	}
+}
+
+func TestFileWriteAtomicFunc_FileOpenRead(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "stream.bin")
+
+	err := FileWriteAtomicFunc(path, 0o600, func(w io.Writer) error {
+		for i := 0; i < 3; i++ {
+			if _, err := io.WriteString(w, "chunk;"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := FileOpenRead(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(f)
+	_ = f.Close()
+
+	if string(got) != "chunk;chunk;chunk;" {
+		t.Fatalf("read back = %q", got)
+	}
+
+	// a failing write leaves the previous content in place
+	failErr := errors.New("boom")
+	if err := FileWriteAtomicFunc(path, 0, func(w io.Writer) error {
+		_, _ = io.WriteString(w, "partial")
+		return failErr
+	}); !errors.Is(err, failErr) {
+		t.Fatalf("err = %v", err)
+	}
+	if s, _ := FileRead(path); s != "chunk;chunk;chunk;" {
+		t.Fatalf("content after failed write = %q", s)
+	}
+
+	if _, err := FileOpenRead(dir); err == nil {
+		t.Fatal("expected error opening a directory")
+	}
+}
@@ -0,0 +1,346 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/aldelo/common/ascii"
+)
+
+// =====================================================================================================================
+// Sensitive Data Masking
+// =====================================================================================================================
+
+// mask kinds accepted by MaskValue and the `mask:"..."` struct tag
+const (
+	MaskKindPAN    = "pan"    // keep BIN (first 6) and last 4 digits
+	MaskKindTrack1 = "track1" // magnetic stripe track 1 (%B...^NAME^YYMM...?)
+	MaskKindTrack2 = "track2" // magnetic stripe track 2 (;PAN=YYMM...?) or emv tag 57 / 9F6B hex (PAN D YYMM ... F)
+	MaskKindTrack  = "track"  // track 1 or track 2, detected from the value
+	MaskKindSecret = "secret" // passwords, api keys, tokens: fixed width mask, last 4 kept when long enough
+	MaskKindText   = "text"   // free text: card numbers and track data found anywhere in the value are masked
+	MaskKindFull   = "full"   // every character masked
+)
+
+// maskChar replaces masked characters
+const maskChar = '*'
+
+var (
+	// maskTrack1Pattern matches track 1 data embedded in text, format code B, PAN, name, then expiry and discretionary data
+	maskTrack1Pattern = regexp.MustCompile(`%?B\d{12,19}\^[^^\r\n]{0,26}\^[^?\s]*\??`)
+
+	// maskTrack2Pattern matches track 2 data embedded in text, with = (magnetic stripe) or D (emv hex) field separator
+	maskTrack2Pattern = regexp.MustCompile(`;?\d{12,19}[=Dd]\d{4}[0-9A-Fa-f]*\??`)
+
+	// maskPANPattern matches 13 to 19 digit card number candidates (group 1), optionally grouped by spaces or dashes,
+	// bounded by non-digits rather than word boundaries so pan_4111111111111111 is found too;
+	// any leading digit is accepted, the Luhn check in IsValidPAN filters the candidates
+	maskPANPattern = regexp.MustCompile(`(?:^|\D)(\d(?:[ -]?\d){12,18})(?:\D|$)`)
+)
+
+// IsValidPAN returns true when s is a 13 to 19 digit card number (spaces and dashes ignored)
+// that passes the mod 10 (Luhn) check
+func IsValidPAN(s string) bool {
+	digits := maskDigitsOnly(s)
+
+	if len(digits) < 13 || len(digits) > 19 || len(digits) != len(strings.NewReplacer(" ", "", "-", "").Replace(s)) {
+		return false
+	}
+
+	ok, err := ascii.IsCreditCardMod10Valid(digits)
+	return err == nil && ok
+}
+
+// MaskPAN masks a card number keeping the BIN (first 6) and last 4 digits, such as 411111******1111,
+// spacing and dashes are preserved; values shorter than 13 digits keep only the last 4 digits,
+// and values of 4 digits or less are fully masked
+func MaskPAN(pan string) string {
+	n := len(maskDigitsOnly(pan))
+
+	keepHead, keepTail := 6, 4
+	if n < 13 {
+		keepHead = 0
+	}
+	if n <= 4 {
+		keepTail = 0
+	}
+
+	b := []byte(pan)
+	pos := 0
+
+	for i, c := range b {
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		if pos >= keepHead && pos < n-keepTail {
+			b[i] = maskChar
+		}
+
+		pos++
+	}
+
+	return string(b)
+}
+
+// MaskTrack1 masks track 1 data, the card number keeps BIN and last 4, the name, expiry and discretionary data are masked,
+// the %, B, ^ and ? framing characters are kept; a value that does not look like track 1 is fully masked
+func MaskTrack1(track1 string) string {
+	start := 0
+	if strings.HasPrefix(track1, "%") {
+		start = 1
+	}
+
+	if len(track1) <= start || (track1[start] != 'B' && track1[start] != 'b') {
+		return MaskFull(track1)
+	}
+
+	caret := strings.IndexByte(track1, '^')
+	if caret < 0 || caret <= start+1 {
+		return MaskFull(track1)
+	}
+
+	return track1[:start+1] + MaskPAN(track1[start+1:caret]) + maskExcept(track1[caret:], "^?")
+}
+
+// MaskTrack2 masks track 2 data, either magnetic stripe form (;PAN=YYMM...?) or emv tag 57 / 9F6B hex form (PAN D YYMM ... F),
+// the card number keeps BIN and last 4, the expiry, service code and discretionary data are masked;
+// a value without a field separator after the card number digits is fully masked
+func MaskTrack2(track2 string) string {
+	start := 0
+	if strings.HasPrefix(track2, ";") {
+		start = 1
+	}
+
+	sep := strings.IndexAny(track2[start:], "=Dd")
+	if sep <= 0 {
+		return MaskFull(track2)
+	}
+	sep += start
+
+	if len(maskDigitsOnly(track2[start:sep])) != sep-start {
+		return MaskFull(track2)
+	}
+
+	return track2[:start] + MaskPAN(track2[start:sep]) + track2[sep:sep+1] + maskExcept(track2[sep+1:], "?")
+}
+
+// MaskSecret masks passwords, keys and tokens with a fixed width mask so the secret length is not revealed,
+// values of 16 characters or more keep their last 4 characters to tell keys apart, such as ********abcd
+func MaskSecret(secret string) string {
+	if len(secret) == 0 {
+		return ""
+	}
+
+	if len(secret) >= 16 {
+		return "********" + secret[len(secret)-4:]
+	}
+
+	return "********"
+}
+
+// MaskFull masks every character of s, one mask character per rune
+func MaskFull(s string) string {
+	return maskExcept(s, "")
+}
+
+// MaskSensitiveText masks track 1, track 2 and Luhn valid card numbers found anywhere in free text,
+// such as log messages, error strings and serialized payloads; other text is returned unchanged
+func MaskSensitiveText(s string) string {
+	if len(s) < 13 || !maskHasDigitRun(s, 12) {
+		return s
+	}
+
+	s = maskTrack1Pattern.ReplaceAllStringFunc(s, MaskTrack1)
+	s = maskTrack2Pattern.ReplaceAllStringFunc(s, MaskTrack2)
+
+	return maskPANCandidates(s)
+}
+
+// maskPANCandidates masks the Luhn valid maskPANPattern candidates of s, scanning resumes at the trailing boundary
+// so it can also lead the next candidate; a candidate failing Luhn is retried from its next digit group,
+// so "ref 12345 4111 1111 1111 1111" still finds the card number after the reference
+func maskPANCandidates(s string) string {
+	var sb strings.Builder
+	last := 0
+
+	for start := 0; start < len(s); {
+		loc := maskPANPattern.FindStringSubmatchIndex(s[start:])
+
+		if loc == nil {
+			break
+		}
+
+		i, j := start+loc[2], start+loc[3]
+
+		m := s[i:j]
+
+		if IsValidPAN(m) {
+			sb.WriteString(s[last:i])
+			sb.WriteString(MaskPAN(m))
+			last = j
+		} else if k := strings.IndexAny(m, " -"); k >= 0 {
+			j = i + k
+		}
+
+		start = j
+	}
+
+	if last == 0 {
+		return s
+	}
+
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// MaskValue masks s according to kind, one of the MaskKind constants (case insensitive),
+// blank or "none" returns s unchanged, and an unrecognized kind fully masks s so a typo never leaks data
+func MaskValue(kind string, s string) string {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", "none":
+		return s
+	case MaskKindPAN:
+		return MaskPAN(s)
+	case MaskKindTrack1:
+		return MaskTrack1(s)
+	case MaskKindTrack2:
+		return MaskTrack2(s)
+	case MaskKindTrack:
+		if strings.ContainsRune(s, '^') {
+			return MaskTrack1(s)
+		}
+		return MaskTrack2(s)
+	case MaskKindSecret:
+		return MaskSecret(s)
+	case MaskKindText:
+		return MaskSensitiveText(s)
+	default:
+		return MaskFull(s)
+	}
+}
+
+// emvSensitiveTagMaskKinds maps emv tags carrying cardholder data to the mask kind applied to their hex value
+var emvSensitiveTagMaskKinds = map[string]string{
+	"56":   MaskKindFull,   // track 1 data (hex of ascii)
+	"57":   MaskKindTrack2, // track 2 equivalent data
+	"5A":   MaskKindPAN,    // application PAN
+	"5F20": MaskKindFull,   // cardholder name
+	"99":   MaskKindFull,   // transaction PIN data
+	"9F0B": MaskKindFull,   // cardholder name extended
+	"9F1F": MaskKindFull,   // track 1 discretionary data
+	"9F20": MaskKindFull,   // track 2 discretionary data
+	"9F6B": MaskKindTrack2, // track 2 data (contactless)
+}
+
+// IsEmvTagSensitive returns true when the emv tag carries card or cardholder data that MaskEmvTagValue redacts
+func IsEmvTagSensitive(tagName string) bool {
+	_, ok := emvSensitiveTagMaskKinds[strings.ToUpper(tagName)]
+	return ok
+}
+
+// MaskEmvTagValue masks the value of emv tags carrying card data: 5A PAN keeps BIN and last 4,
+// 57 / 9F6B track 2 equivalent data keeps the masked PAN and separator, and names, track 1 and PIN data are fully masked;
+// values of other tags are returned unchanged
+func MaskEmvTagValue(tagName string, value string) string {
+	if kind, ok := emvSensitiveTagMaskKinds[strings.ToUpper(tagName)]; ok {
+		return MaskValue(kind, value)
+	}
+
+	return value
+}
+
+// MaskEmvTlvTags returns a copy of tags, such as the ParseEmvTlvTags result, with card data values masked
+// in both TagHexValue and TagDecodedValue, the input slice and its elements are not modified
+func MaskEmvTlvTags(tags []*EmvTlvTag) []*EmvTlvTag {
+	if tags == nil {
+		return nil
+	}
+
+	out := make([]*EmvTlvTag, len(tags))
+
+	for i, t := range tags {
+		if t == nil {
+			continue
+		}
+
+		c := *t
+		if IsEmvTagSensitive(c.TagName) {
+			c.TagHexValue = MaskEmvTagValue(c.TagName, c.TagHexValue)
+			c.TagDecodedValue = MaskEmvTagValue(c.TagName, c.TagDecodedValue)
+		}
+
+		out[i] = &c
+	}
+
+	return out
+}
+
+// maskExcept replaces every rune of s with the mask character, except runes listed in keep
+func maskExcept(s string, keep string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for _, r := range s {
+		if strings.ContainsRune(keep, r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte(maskChar)
+		}
+	}
+
+	return b.String()
+}
+
+// maskDigitsOnly returns the ascii digits of s
+func maskDigitsOnly(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+// maskHasDigitRun reports whether s holds at least n digits, allowing single space or dash separators between them,
+// a cheap pre-check so text without any card number candidate skips the regex passes
+func maskHasDigitRun(s string, n int) bool {
+	run := 0
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c >= '0' && c <= '9':
+			run++
+			if run >= n {
+				return true
+			}
+		case (c == ' ' || c == '-') && run > 0 && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			// grouping separator, run continues
+		default:
+			run = 0
+		}
+	}
+
+	return false
+}
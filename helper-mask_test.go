@@ -0,0 +1,176 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for the masking toolkit (helper-mask.go) and the mask struct tag of MarshalStructToJson.
+//
+// Card numbers are the public brand test PANs; the track samples are synthetic.
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsValidPAN(t *testing.T) {
+	cases := map[string]bool{
+		"4111111111111111":    true,
+		"4111 1111 1111 1111": true,
+		"4111-1111-1111-1111": true,
+		"378282246310005":     true,
+		"4111111111111112":    false,
+		"411111111111":        false,
+		"4111x111111111111":   false,
+		"":                    false,
+	}
+
+	for in, want := range cases {
+		if got := IsValidPAN(in); got != want {
+			t.Errorf("IsValidPAN(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestMaskPAN(t *testing.T) {
+	cases := map[string]string{
+		"4111111111111111":    "411111******1111",
+		"4111 1111 1111 1111": "4111 11** **** 1111",
+		"378282246310005":     "378282*****0005",
+		"476173900101010F":    "476173*****1010F",
+		"123456789":           "*****6789",
+		"1234":                "****",
+	}
+
+	for in, want := range cases {
+		if got := MaskPAN(in); got != want {
+			t.Errorf("MaskPAN(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMaskTrackData(t *testing.T) {
+	if got := MaskTrack1("%B4111111111111111^DOE/JOHN^2512101000000000000?"); got != "%B411111******1111^********^*******************?" {
+		t.Errorf("MaskTrack1 = %q", got)
+	}
+
+	if got := MaskTrack2(";4111111111111111=25121010000000000000?"); got != ";411111******1111=********************?" {
+		t.Errorf("MaskTrack2 = %q", got)
+	}
+
+	// emv tag 57 hex form uses D as separator and F padding
+	if got := MaskTrack2("4761739001010119D22122011143804400000F"); got != "476173******0119D*********************" {
+		t.Errorf("MaskTrack2 emv = %q", got)
+	}
+
+	if got := MaskTrack2("not track data"); got != strings.Repeat("*", 14) {
+		t.Errorf("MaskTrack2 fallback = %q", got)
+	}
+}
+
+func TestMaskSensitiveText(t *testing.T) {
+	in := "auth pan=4111111111111111 t2=;5500005555555559=25121010000000000000? " +
+		"t1=%B378282246310005^DOE/JOHN^2512101000000? id=1700000000000 order=4111111111111112"
+
+	got := MaskSensitiveText(in)
+
+	for _, leak := range []string{"4111111111111111", "5500005555555559", "378282246310005", "DOE/JOHN", "=2512"} {
+		if strings.Contains(got, leak) {
+			t.Errorf("leaked %q in %q", leak, got)
+		}
+	}
+
+	// epoch millis and non Luhn numbers are not card numbers
+	for _, keep := range []string{"id=1700000000000", "order=4111111111111112", "pan=411111******1111"} {
+		if !strings.Contains(got, keep) {
+			t.Errorf("expected %q in %q", keep, got)
+		}
+	}
+
+	if s := "nothing to see here 12345"; MaskSensitiveText(s) != s {
+		t.Error("plain text changed")
+	}
+
+	// any leading digit, and card numbers glued to word characters or each other by one separator
+	for in, want := range map[string]string{
+		"pan_4111111111111111":                  "pan_411111******1111",
+		"id4111111111111111,5500005555555559":   "id411111******1111,550000******5559",
+		"card 1234567812345670 ok":              "card 123456******5670 ok",
+		"8000000000000003 9000000000000001":     "800000******0003 900000******0001",
+		"ref 12345 4111 1111 1111 1111":         "ref 12345 4111 11** **** 1111",
+		"acct 41111111111111110000 is too long": "acct 41111111111111110000 is too long",
+	} {
+		if got := MaskSensitiveText(in); got != want {
+			t.Errorf("MaskSensitiveText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMaskValueAndSecret(t *testing.T) {
+	if got := MaskValue("secret", "sk_live_0123456789abcd"); got != "********abcd" {
+		t.Errorf("secret = %q", got)
+	}
+
+	if got := MaskSecret("short"); got != "********" {
+		t.Errorf("short secret = %q", got)
+	}
+
+	if got := MaskValue("", "keep"); got != "keep" {
+		t.Errorf("blank kind = %q", got)
+	}
+
+	if got := MaskValue("typo", "leak"); got != "****" {
+		t.Errorf("unknown kind should fully mask, got %q", got)
+	}
+}
+
+func TestMaskEmvTlvTags(t *testing.T) {
+	tags := []*EmvTlvTag{
+		{TagName: "5A", TagHexValue: "4761739001010119", TagDecodedValue: "4761739001010119"},
+		{TagName: "57", TagHexValue: "4761739001010119D22122011143804400000F"},
+		{TagName: "5F20", TagHexValue: "444F452F4A4F484E", TagDecodedValue: "DOE/JOHN"},
+		{TagName: "9F02", TagHexValue: "000000001000"},
+	}
+
+	masked := MaskEmvTlvTags(tags)
+
+	if masked[0].TagHexValue != "476173******0119" || masked[0].TagDecodedValue != "476173******0119" {
+		t.Errorf("5A = %+v", masked[0])
+	}
+
+	if !strings.HasPrefix(masked[1].TagHexValue, "476173******0119D***") {
+		t.Errorf("57 = %+v", masked[1])
+	}
+
+	if masked[2].TagDecodedValue != "********" {
+		t.Errorf("5F20 = %+v", masked[2])
+	}
+
+	if masked[3].TagHexValue != "000000001000" {
+		t.Errorf("9F02 should be unchanged, got %+v", masked[3])
+	}
+
+	if tags[0].TagHexValue != "4761739001010119" {
+		t.Error("input tags must not be modified")
+	}
+}
+
+func TestMarshalStructToJson_MaskTag(t *testing.T) {
+	type payment struct {
+		PAN    string `json:"pan" mask:"pan"`
+		Token  string `json:"token" mask:"secret"`
+		Amount string `json:"amount"`
+	}
+
+	out, err := MarshalStructToJson(&payment{PAN: "4111111111111111", Token: "tok_0123456789abcdef", Amount: "10.00"}, "json", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out, `"pan":"411111******1111"`) || !strings.Contains(out, `"token":"********cdef"`) || !strings.Contains(out, `"amount":"10.00"`) {
+		t.Fatalf("unexpected json: %s", out)
+	}
+}
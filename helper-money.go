@@ -0,0 +1,891 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact monetary amount held as int64 minor units (e.g. cents) of an ISO-4217 currency.
+//
+// Money is the arithmetic-safe companion to the display-only float helpers in helper-conv.go
+// (Float64ToIntCents, CentsToFloat64, Float32ToStringCents, Float64ToCurrencyString):
+// compute with Money, then render with Format / DecimalString at the final step.
+//
+// Money is an immutable value type; every operation returns a new Money. Operations that
+// combine two amounts return ErrMoneyCurrencyMismatch when the currencies differ, and any
+// operation whose result does not fit in int64 minor units returns ErrMoneyOverflow instead
+// of silently wrapping. Fractional results (Percent, MultiplyDecimal, NewMoneyFromFloat64)
+// are rounded half-to-even (banker's rounding) to the currency's minor unit.
+//
+// The zero value is an amount of 0 with no currency; use NewMoney or ParseMoney to construct.
+type Money struct {
+	amount   int64
+	currency string
+}
+
+// CurrencyInfo describes an ISO-4217 currency.
+type CurrencyInfo struct {
+	// Code is the 3-letter alphabetic code, such as USD
+	Code string
+
+	// Numeric is the 3-digit numeric code, such as 840 (as used by EMV tag 5F2A)
+	Numeric string
+
+	// MinorUnits is the number of decimal places of the minor unit, such as 2 for USD, 0 for JPY
+	MinorUnits int
+
+	// Symbol is the common display symbol, such as $
+	Symbol string
+}
+
+// DefaultMoneyCurrency is the currency assumed by Money.Scan and Money.UnmarshalJSON
+// when the source value carries no currency and the receiver has none set.
+const DefaultMoneyCurrency = "USD"
+
+var (
+	// ErrMoneyCurrencyMismatch is returned when combining or comparing Money of different currencies
+	ErrMoneyCurrencyMismatch = errors.New("money currency mismatch")
+
+	// ErrMoneyOverflow is returned when a Money result does not fit in int64 minor units
+	ErrMoneyOverflow = errors.New("money amount overflows int64 minor units")
+)
+
+// currencyTable holds the ISO-4217 currencies supported by Money, keyed by alphabetic code
+var currencyTable = map[string]CurrencyInfo{
+	"AED": {"AED", "784", 2, "د.إ"},
+	"ARS": {"ARS", "032", 2, "$"},
+	"AUD": {"AUD", "036", 2, "$"},
+	"BHD": {"BHD", "048", 3, ".د.ب"},
+	"BRL": {"BRL", "986", 2, "R$"},
+	"CAD": {"CAD", "124", 2, "$"},
+	"CHF": {"CHF", "756", 2, "CHF"},
+	"CLP": {"CLP", "152", 0, "$"},
+	"CNY": {"CNY", "156", 2, "¥"},
+	"COP": {"COP", "170", 2, "$"},
+	"CZK": {"CZK", "203", 2, "Kč"},
+	"DKK": {"DKK", "208", 2, "kr"},
+	"EUR": {"EUR", "978", 2, "€"},
+	"GBP": {"GBP", "826", 2, "£"},
+	"HKD": {"HKD", "344", 2, "$"},
+	"HUF": {"HUF", "348", 2, "Ft"},
+	"IDR": {"IDR", "360", 2, "Rp"},
+	"ILS": {"ILS", "376", 2, "₪"},
+	"INR": {"INR", "356", 2, "₹"},
+	"ISK": {"ISK", "352", 0, "kr"},
+	"JOD": {"JOD", "400", 3, "د.ا"},
+	"JPY": {"JPY", "392", 0, "¥"},
+	"KRW": {"KRW", "410", 0, "₩"},
+	"KWD": {"KWD", "414", 3, "د.ك"},
+	"MXN": {"MXN", "484", 2, "$"},
+	"MYR": {"MYR", "458", 2, "RM"},
+	"NOK": {"NOK", "578", 2, "kr"},
+	"NZD": {"NZD", "554", 2, "$"},
+	"OMR": {"OMR", "512", 3, "ر.ع."},
+	"PHP": {"PHP", "608", 2, "₱"},
+	"PLN": {"PLN", "985", 2, "zł"},
+	"SAR": {"SAR", "682", 2, "﷼"},
+	"SEK": {"SEK", "752", 2, "kr"},
+	"SGD": {"SGD", "702", 2, "$"},
+	"THB": {"THB", "764", 2, "฿"},
+	"TND": {"TND", "788", 3, "د.ت"},
+	"TRY": {"TRY", "949", 2, "₺"},
+	"TWD": {"TWD", "901", 2, "$"},
+	"USD": {"USD", "840", 2, "$"},
+	"VND": {"VND", "704", 0, "₫"},
+	"ZAR": {"ZAR", "710", 2, "R"},
+}
+
+// currencyByNumeric indexes currencyTable by numeric code
+var currencyByNumeric = func() map[string]CurrencyInfo {
+	m := make(map[string]CurrencyInfo, len(currencyTable))
+	for _, c := range currencyTable {
+		m[c.Numeric] = c
+	}
+	return m
+}()
+
+// LookupCurrency returns the ISO-4217 currency info for alphabetic code (case-insensitive).
+func LookupCurrency(code string) (CurrencyInfo, bool) {
+	c, ok := currencyTable[strings.ToUpper(strings.TrimSpace(code))]
+	return c, ok
+}
+
+// LookupCurrencyByNumeric returns the ISO-4217 currency info for 3-digit numeric code,
+// leading zeros may be omitted, such as "36" for AUD, or "0840" as carried in EMV tag 5F2A.
+func LookupCurrencyByNumeric(numeric string) (CurrencyInfo, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(numeric))
+	if err != nil || n < 0 || n > 999 {
+		return CurrencyInfo{}, false
+	}
+
+	c, ok := currencyByNumeric[fmt.Sprintf("%03d", n)]
+	return c, ok
+}
+
+// pow10Int64 holds 10^0 .. 10^18, the range representable in int64
+var pow10Int64 = func() [19]int64 {
+	var p [19]int64
+	p[0] = 1
+	for i := 1; i < len(p); i++ {
+		p[i] = p[i-1] * 10
+	}
+	return p
+}()
+
+// ================================================================================================================
+// constructors and parsing
+// ================================================================================================================
+
+// NewMoney creates Money from minorUnits (e.g. cents) in ISO-4217 currency code.
+func NewMoney(minorUnits int64, currency string) (Money, error) {
+	c, ok := LookupCurrency(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("NewMoney failed: unsupported currency %q", currency)
+	}
+
+	return Money{amount: minorUnits, currency: c.Code}, nil
+}
+
+// ParseMoney parses a decimal amount string such as "1234.56", "-1,234.56", "$12.50" or "(12.50)" into Money of currency.
+//
+// Grouping commas, a leading currency symbol or code, and surrounding whitespace are accepted;
+// parentheses denote a negative amount. The amount must not carry more decimal places than the
+// currency's minor unit ("1.234" USD is an error) — use ParseMoneyRounded to round half-to-even instead.
+func ParseMoney(s string, currency string) (Money, error) {
+	return parseMoney(s, currency, false)
+}
+
+// ParseMoneyRounded is ParseMoney, except excess decimal places are rounded half-to-even to the currency's minor unit.
+func ParseMoneyRounded(s string, currency string) (Money, error) {
+	return parseMoney(s, currency, true)
+}
+
+// parseMoney is the shared implementation of ParseMoney and ParseMoneyRounded
+func parseMoney(s string, currency string, round bool) (Money, error) {
+	c, ok := LookupCurrency(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("ParseMoney failed: unsupported currency %q", currency)
+	}
+
+	src := strings.TrimSpace(s)
+	v := src
+	negative := false
+
+	if strings.HasPrefix(v, "(") && strings.HasSuffix(v, ")") {
+		negative = true
+		v = strings.TrimSpace(v[1 : len(v)-1])
+	}
+
+	if strings.HasPrefix(v, "-") {
+		negative = !negative
+		v = strings.TrimSpace(v[1:])
+	} else if strings.HasPrefix(v, "+") {
+		v = strings.TrimSpace(v[1:])
+	}
+
+	// strip leading / trailing currency code or symbol
+	for _, affix := range []string{c.Code, c.Symbol} {
+		if len(affix) > 0 {
+			v = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(v, affix), affix))
+		}
+	}
+
+	if strings.HasPrefix(v, "-") && !negative {
+		// "$-12.50" form
+		negative = true
+		v = strings.TrimSpace(v[1:])
+	}
+
+	v = strings.ReplaceAll(v, ",", "")
+
+	if len(v) == 0 || !isDecimalLiteral(v) {
+		return Money{}, fmt.Errorf("ParseMoney failed: %q is not a valid %s amount", src, c.Code)
+	}
+
+	r, ok := new(big.Rat).SetString(v)
+	if !ok {
+		return Money{}, fmt.Errorf("ParseMoney failed: %q is not a valid %s amount", src, c.Code)
+	}
+
+	if negative {
+		r.Neg(r)
+	}
+
+	if !round {
+		if dot := strings.IndexByte(v, '.'); dot >= 0 && len(v)-dot-1 > c.MinorUnits {
+			// allow trailing zeros beyond the minor unit, such as "1.500" USD
+			if strings.TrimRight(v[dot+1+c.MinorUnits:], "0") != "" {
+				return Money{}, fmt.Errorf("ParseMoney failed: %q has more than %d decimal places for %s", src, c.MinorUnits, c.Code)
+			}
+		}
+	}
+
+	minor, err := ratToMinorHalfEven(r, c.MinorUnits)
+	if err != nil {
+		return Money{}, fmt.Errorf("ParseMoney failed: %q: %w", src, err)
+	}
+
+	return Money{amount: minor, currency: c.Code}, nil
+}
+
+// isDecimalLiteral reports whether v is digits with at most one decimal point, and at least one digit
+func isDecimalLiteral(v string) bool {
+	digits := 0
+	dots := 0
+
+	for i := 0; i < len(v); i++ {
+		switch {
+		case v[i] >= '0' && v[i] <= '9':
+			digits++
+		case v[i] == '.':
+			dots++
+		default:
+			return false
+		}
+	}
+
+	return digits > 0 && dots <= 1
+}
+
+// NewMoneyFromFloat64 converts a legacy float64 amount (such as values produced by CentsToFloat64)
+// into Money, rounding half-to-even to the currency's minor unit.
+//
+// The float is converted via its shortest round-trip decimal representation, so 0.1+0.2 becomes
+// 0.30000000000000004 and rounds to 0.30 USD, rather than carrying binary floating point error.
+// NaN and Inf are rejected.
+func NewMoneyFromFloat64(f float64, currency string) (Money, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Money{}, fmt.Errorf("NewMoneyFromFloat64 failed: cannot convert NaN/Inf to money")
+	}
+
+	return ParseMoneyRounded(strconv.FormatFloat(f, 'f', -1, 64), currency)
+}
+
+// ratToMinorHalfEven converts r (in major units) to minor units of the given exponent, rounding half-to-even
+func ratToMinorHalfEven(r *big.Rat, minorUnits int) (int64, error) {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt64(pow10Int64[minorUnits]))
+	return roundRatHalfEven(scaled)
+}
+
+// roundRatHalfEven rounds r to the nearest integer, ties to even, and returns ErrMoneyOverflow if it does not fit in int64
+func roundRatHalfEven(r *big.Rat) (int64, error) {
+	num := r.Num()
+	den := r.Denom()
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	if rem.Sign() != 0 {
+		// compare 2*|rem| with den to decide rounding direction
+		twice := new(big.Int).Abs(rem)
+		twice.Lsh(twice, 1)
+
+		cmp := twice.Cmp(den)
+		if cmp > 0 || (cmp == 0 && q.Bit(0) == 1) {
+			if num.Sign() < 0 {
+				q.Sub(q, big.NewInt(1))
+			} else {
+				q.Add(q, big.NewInt(1))
+			}
+		}
+	}
+
+	if !q.IsInt64() {
+		return 0, ErrMoneyOverflow
+	}
+
+	return q.Int64(), nil
+}
+
+// ================================================================================================================
+// accessors
+// ================================================================================================================
+
+// MinorUnits returns the amount in minor units, such as cents.
+func (m Money) MinorUnits() int64 {
+	return m.amount
+}
+
+// Currency returns the ISO-4217 alphabetic currency code, blank for the zero value.
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// CurrencyInfo returns the ISO-4217 currency info of m.
+func (m Money) CurrencyInfo() CurrencyInfo {
+	c, _ := LookupCurrency(m.currency)
+	return c
+}
+
+// IsZero returns true if the amount is zero.
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// IsNegative returns true if the amount is less than zero.
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+// IsPositive returns true if the amount is greater than zero.
+func (m Money) IsPositive() bool {
+	return m.amount > 0
+}
+
+// Float64 returns the amount in major units as float64, for DISPLAY interop with
+// Float64ToCurrencyString only; never feed the result back into arithmetic.
+func (m Money) Float64() float64 {
+	return float64(m.amount) / float64(pow10Int64[m.CurrencyInfo().MinorUnits])
+}
+
+// ================================================================================================================
+// arithmetic
+// ================================================================================================================
+
+// sameCurrency returns ErrMoneyCurrencyMismatch if m and o are of different currencies
+func (m Money) sameCurrency(o Money) error {
+	if m.currency != o.currency {
+		return fmt.Errorf("%w: %s vs %s", ErrMoneyCurrencyMismatch, m.currency, o.currency)
+	}
+	return nil
+}
+
+// Add returns m + o.
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+
+	sum := m.amount + o.amount
+	if (o.amount > 0 && sum < m.amount) || (o.amount < 0 && sum > m.amount) {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return Money{amount: sum, currency: m.currency}, nil
+}
+
+// Sub returns m - o.
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+
+	diff := m.amount - o.amount
+	if (o.amount < 0 && diff < m.amount) || (o.amount > 0 && diff > m.amount) {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return Money{amount: diff, currency: m.currency}, nil
+}
+
+// Sum returns the total of amounts, all of which must share one currency; no amounts is an error.
+func Sum(amounts ...Money) (Money, error) {
+	if len(amounts) == 0 {
+		return Money{}, fmt.Errorf("Sum failed: at least one amount is required")
+	}
+
+	total := amounts[0]
+	for _, a := range amounts[1:] {
+		var err error
+		if total, err = total.Add(a); err != nil {
+			return Money{}, err
+		}
+	}
+
+	return total, nil
+}
+
+// Neg returns -m.
+func (m Money) Neg() (Money, error) {
+	if m.amount == math.MinInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+	return Money{amount: -m.amount, currency: m.currency}, nil
+}
+
+// Abs returns |m|.
+func (m Money) Abs() (Money, error) {
+	if m.amount < 0 {
+		return m.Neg()
+	}
+	return m, nil
+}
+
+// Multiply returns m * qty, such as unit price times quantity.
+func (m Money) Multiply(qty int64) (Money, error) {
+	if m.amount == 0 || qty == 0 {
+		return Money{amount: 0, currency: m.currency}, nil
+	}
+
+	product := m.amount * qty
+	if product/qty != m.amount || (m.amount == -1 && qty == math.MinInt64) || (qty == -1 && m.amount == math.MinInt64) {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return Money{amount: product, currency: m.currency}, nil
+}
+
+// MultiplyDecimal returns m * factor, where factor is an exact decimal string such as "1.5" or "0.0875",
+// rounded half-to-even to the currency's minor unit.
+func (m Money) MultiplyDecimal(factor string) (Money, error) {
+	f, ok := new(big.Rat).SetString(strings.TrimSpace(factor))
+	if !ok || !isDecimalLiteral(strings.TrimPrefix(strings.TrimSpace(factor), "-")) {
+		return Money{}, fmt.Errorf("MultiplyDecimal failed: %q is not a valid decimal factor", factor)
+	}
+
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(m.amount), f)
+
+	minor, err := roundRatHalfEven(product)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{amount: minor, currency: m.currency}, nil
+}
+
+// Percent returns percent % of m, such as m.Percent("8.875") for sales tax,
+// rounded half-to-even to the currency's minor unit.
+func (m Money) Percent(percent string) (Money, error) {
+	p, ok := new(big.Rat).SetString(strings.TrimSpace(percent))
+	if !ok || !isDecimalLiteral(strings.TrimPrefix(strings.TrimSpace(percent), "-")) {
+		return Money{}, fmt.Errorf("Percent failed: %q is not a valid decimal percent", percent)
+	}
+
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(m.amount), p)
+	product.Quo(product, big.NewRat(100, 1))
+
+	minor, err := roundRatHalfEven(product)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{amount: minor, currency: m.currency}, nil
+}
+
+// RoundToIncrement rounds m half-to-even to a multiple of increment minor units,
+// such as 5 for Swiss cash rounding to CHF 0.05, or 100 for whole USD.
+func (m Money) RoundToIncrement(increment int64) (Money, error) {
+	if increment <= 0 {
+		return Money{}, fmt.Errorf("RoundToIncrement failed: increment must be positive")
+	}
+
+	units, err := roundRatHalfEven(big.NewRat(m.amount, increment))
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{amount: units, currency: m.currency}.Multiply(increment)
+}
+
+// Allocate splits m into len(ratios) parts proportional to ratios, without losing or creating a minor unit:
+// the parts always sum exactly to m. Leftover minor units from integer division are distributed one at a
+// time to the parts in order, so Allocate(1, 1, 1) of $100.00 yields $33.34, $33.33, $33.33.
+//
+// Ratios must be non-negative with a positive total.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, fmt.Errorf("Allocate failed: at least one ratio is required")
+	}
+
+	total := new(big.Int)
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, fmt.Errorf("Allocate failed: ratios must not be negative")
+		}
+		total.Add(total, big.NewInt(r))
+	}
+
+	if total.Sign() == 0 {
+		return nil, fmt.Errorf("Allocate failed: ratios must not all be zero")
+	}
+
+	amount := big.NewInt(m.amount)
+	parts := make([]Money, len(ratios))
+	remainder := m.amount
+
+	for i, r := range ratios {
+		// truncate toward zero so the remainder carries the amount's sign
+		share := new(big.Int).Mul(amount, big.NewInt(r))
+		share.Quo(share, total)
+
+		parts[i] = Money{amount: share.Int64(), currency: m.currency}
+		remainder -= share.Int64()
+	}
+
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].amount += step
+		remainder -= step
+	}
+
+	return parts, nil
+}
+
+// Split divides m into n parts as evenly as possible, see Allocate.
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("Split failed: n must be positive")
+	}
+
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+
+	return m.Allocate(ratios...)
+}
+
+// Cmp compares m and o, returning -1, 0 or +1; different currencies return ErrMoneyCurrencyMismatch.
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.amount < o.amount:
+		return -1, nil
+	case m.amount > o.amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Equal returns true if m and o have the same currency and amount.
+func (m Money) Equal(o Money) bool {
+	return m.currency == o.currency && m.amount == o.amount
+}
+
+// ================================================================================================================
+// formatting
+// ================================================================================================================
+
+// DecimalString returns the amount in major units with exactly the currency's minor unit decimals,
+// such as "1234.56" or "-0.05", without grouping or symbol; suitable for storage and APIs.
+func (m Money) DecimalString() string {
+	return formatMinorUnits(m.amount, m.CurrencyInfo().MinorUnits, ".", "", nil)
+}
+
+// String returns the amount and currency code, such as "1234.56 USD".
+func (m Money) String() string {
+	if len(m.currency) == 0 {
+		return m.DecimalString()
+	}
+	return m.DecimalString() + " " + m.currency
+}
+
+// moneyLocale describes locale-specific currency formatting conventions
+type moneyLocale struct {
+	decimalSep   string
+	groupSep     string
+	grouping     []int // group sizes from the right, last size repeats; nil means no grouping
+	symbolSuffix bool  // symbol after the number, such as "1.234,56 €"
+	symbolSpace  bool  // no-break space between number and symbol
+}
+
+// moneyLocales holds the formatting conventions supported by Money.Format, keyed by lower-case BCP 47 tag
+var moneyLocales = map[string]moneyLocale{
+	"en-us": {".", ",", []int{3}, false, false},
+	"en-gb": {".", ",", []int{3}, false, false},
+	"en-ca": {".", ",", []int{3}, false, false},
+	"en-au": {".", ",", []int{3}, false, false},
+	"en-in": {".", ",", []int{3, 2}, false, false},
+	"fr-ca": {",", "\u00a0", []int{3}, true, true},
+	"fr-fr": {",", "\u202f", []int{3}, true, true},
+	"de-de": {",", ".", []int{3}, true, true},
+	"de-ch": {".", "’", []int{3}, false, true},
+	"es-es": {",", ".", []int{3}, true, true},
+	"es-mx": {".", ",", []int{3}, false, false},
+	"it-it": {",", ".", []int{3}, true, true},
+	"nl-nl": {",", ".", []int{3}, false, true},
+	"pt-br": {",", ".", []int{3}, false, true},
+	"ja-jp": {".", ",", []int{3}, false, false},
+	"zh-cn": {".", ",", []int{3}, false, false},
+	"ko-kr": {".", ",", []int{3}, false, false},
+}
+
+// Format renders m for display using locale conventions (BCP 47 tag such as "en-US", "de-DE", "fr-FR", "en-IN"),
+// such as "$1,234.56", "1.234,56 €" or "₹1,23,456.78"; negative amounts are prefixed with "-".
+//
+// Spaces between number and symbol (and French digit grouping) are no-break spaces (U+00A0 / U+202F),
+// so receipts and emails never wrap an amount across lines.
+//
+// Unknown locales fall back to the language-only match (e.g. "de-AT" uses "de-DE" conventions),
+// then to en-US conventions.
+func (m Money) Format(locale string) string {
+	loc := lookupMoneyLocale(locale)
+	c := m.CurrencyInfo()
+
+	number := formatMinorUnits(m.amount, c.MinorUnits, loc.decimalSep, loc.groupSep, loc.grouping)
+	negative := strings.HasPrefix(number, "-")
+	number = strings.TrimPrefix(number, "-")
+
+	symbol := c.Symbol
+	if len(symbol) == 0 {
+		symbol = m.currency
+	}
+
+	sep := ""
+	if loc.symbolSpace {
+		sep = "\u00a0"
+	}
+
+	var out string
+	if loc.symbolSuffix {
+		out = number + sep + symbol
+	} else {
+		out = symbol + sep + number
+	}
+
+	if negative {
+		return "-" + out
+	}
+	return out
+}
+
+// moneyLanguageDefaults maps a language subtag to the locale used when the full tag is not in moneyLocales
+var moneyLanguageDefaults = map[string]string{
+	"en": "en-us",
+	"fr": "fr-fr",
+	"de": "de-de",
+	"es": "es-es",
+	"it": "it-it",
+	"nl": "nl-nl",
+	"pt": "pt-br",
+	"ja": "ja-jp",
+	"zh": "zh-cn",
+	"ko": "ko-kr",
+}
+
+// lookupMoneyLocale resolves locale to its formatting conventions, see Format
+func lookupMoneyLocale(locale string) moneyLocale {
+	tag := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+
+	if loc, ok := moneyLocales[tag]; ok {
+		return loc
+	}
+
+	if def, ok := moneyLanguageDefaults[strings.SplitN(tag, "-", 2)[0]]; ok {
+		return moneyLocales[def]
+	}
+
+	return moneyLocales["en-us"]
+}
+
+// formatMinorUnits renders minor units as a decimal number with the given separators and grouping
+func formatMinorUnits(amount int64, minorUnits int, decimalSep string, groupSep string, grouping []int) string {
+	negative := amount < 0
+
+	// use uint64 magnitude so math.MinInt64 is rendered correctly
+	mag := uint64(amount)
+	if negative {
+		mag = -mag
+	}
+
+	digits := strconv.FormatUint(mag, 10)
+	if len(digits) <= minorUnits {
+		digits = strings.Repeat("0", minorUnits-len(digits)+1) + digits
+	}
+
+	intPart := digits[:len(digits)-minorUnits]
+	fracPart := digits[len(digits)-minorUnits:]
+
+	if len(grouping) > 0 && len(groupSep) > 0 {
+		intPart = groupDigits(intPart, groupSep, grouping)
+	}
+
+	var b strings.Builder
+	if negative {
+		b.WriteString("-")
+	}
+	b.WriteString(intPart)
+	if minorUnits > 0 {
+		b.WriteString(decimalSep)
+		b.WriteString(fracPart)
+	}
+
+	return b.String()
+}
+
+// groupDigits inserts sep into digits from the right according to grouping sizes, the last size repeats
+func groupDigits(digits string, sep string, grouping []int) string {
+	var groups []string
+	gi := 0
+
+	for len(digits) > 0 {
+		size := grouping[gi]
+		if gi < len(grouping)-1 {
+			gi++
+		}
+
+		if size <= 0 || len(digits) <= size {
+			groups = append(groups, digits)
+			break
+		}
+
+		groups = append(groups, digits[len(digits)-size:])
+		digits = digits[:len(digits)-size]
+	}
+
+	// reverse into most-significant-first order
+	for i, j := 0, len(groups)-1; i < j; i, j = i+1, j-1 {
+		groups[i], groups[j] = groups[j], groups[i]
+	}
+
+	return strings.Join(groups, sep)
+}
+
+// ================================================================================================================
+// json and sql
+// ================================================================================================================
+
+// moneyJSON is the wire format of Money, amount is a decimal string to avoid float precision loss in json consumers
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes m as {"amount":"1234.56","currency":"USD"}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.DecimalString(), Currency: m.currency})
+}
+
+// UnmarshalJSON decodes {"amount":"1234.56","currency":"USD"}, amount may also be a json number,
+// which is parsed from its literal text (never through float64). A missing currency falls back to
+// the receiver's currency, then DefaultMoneyCurrency. json null leaves m unchanged.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if m == nil {
+		return fmt.Errorf("UnmarshalJSON failed: Money receiver is nil")
+	}
+
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	var raw struct {
+		Amount   json.RawMessage `json:"amount"`
+		Currency string          `json:"currency"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("UnmarshalJSON failed: %w", err)
+	}
+
+	amount := strings.TrimSpace(string(raw.Amount))
+	if strings.HasPrefix(amount, `"`) {
+		if err := json.Unmarshal(raw.Amount, &amount); err != nil {
+			return fmt.Errorf("UnmarshalJSON failed: %w", err)
+		}
+	}
+
+	if len(amount) == 0 || amount == "null" {
+		return fmt.Errorf("UnmarshalJSON failed: amount is required")
+	}
+
+	parsed, err := ParseMoney(amount, m.currencyOr(raw.Currency))
+	if err != nil {
+		return fmt.Errorf("UnmarshalJSON failed: %w", err)
+	}
+
+	*m = parsed
+	return nil
+}
+
+// currencyOr returns code if not blank, else the receiver's currency, else DefaultMoneyCurrency
+func (m *Money) currencyOr(code string) string {
+	switch {
+	case LenTrim(code) > 0:
+		return code
+	case len(m.currency) > 0:
+		return m.currency
+	default:
+		return DefaultMoneyCurrency
+	}
+}
+
+// Value implements driver.Valuer, storing the amount as a decimal string suitable for DECIMAL / NUMERIC columns.
+//
+// The currency is NOT stored; persist it in its own column (or use a single-currency schema).
+func (m Money) Value() (driver.Value, error) {
+	return m.DecimalString(), nil
+}
+
+// Scan implements sql.Scanner, reading a DECIMAL / NUMERIC, integer, or text column.
+//
+// Text values may carry a currency code ("1234.56 USD" or "USD 1234.56"); otherwise the receiver's
+// currency is used, then DefaultMoneyCurrency. Set the currency on the receiver before scanning to
+// read single-currency columns of other currencies. float64 sources (FLOAT / REAL columns) are
+// rounded half-to-even via NewMoneyFromFloat64. SQL NULL is an error; scan nullable columns into sql.NullString first.
+func (m *Money) Scan(src interface{}) error {
+	if m == nil {
+		return fmt.Errorf("Scan failed: Money receiver is nil")
+	}
+
+	var text string
+
+	switch v := src.(type) {
+	case nil:
+		return fmt.Errorf("Scan failed: cannot scan NULL into Money")
+	case int64:
+		text = strconv.FormatInt(v, 10)
+	case float64:
+		parsed, err := NewMoneyFromFloat64(v, m.currencyOr(""))
+		if err != nil {
+			return fmt.Errorf("Scan failed: %w", err)
+		}
+		*m = parsed
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("Scan failed: unsupported source type %T", src)
+	}
+
+	currency := ""
+	if fields := strings.Fields(text); len(fields) == 2 {
+		if _, ok := LookupCurrency(fields[1]); ok {
+			text, currency = fields[0], fields[1]
+		} else if _, ok := LookupCurrency(fields[0]); ok {
+			text, currency = fields[1], fields[0]
+		}
+	}
+
+	parsed, err := ParseMoney(text, m.currencyOr(currency))
+	if err != nil {
+		return fmt.Errorf("Scan failed: %w", err)
+	}
+
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,397 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Executable specification for Money (helper-money.go).
+//
+// The float helpers in helper-conv.go are display-only (see
+// TestFloat64ToCurrencyString_* in helper-conv_test.go); these tests pin
+// that Money arithmetic is exact, rounds half-to-even, never loses a minor
+// unit in Allocate, and refuses to mix currencies or wrap on overflow.
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func mustMoney(t *testing.T, s string, currency string) Money {
+	t.Helper()
+	m, err := ParseMoney(s, currency)
+	if err != nil {
+		t.Fatalf("ParseMoney(%q, %s) failed: %v", s, currency, err)
+	}
+	return m
+}
+
+func TestParseMoney(t *testing.T) {
+	cases := []struct {
+		s        string
+		currency string
+		want     int64
+	}{
+		{"1234.56", "USD", 123456},
+		{"-1,234.56", "USD", -123456},
+		{"$12.50", "USD", 1250},
+		{"12.5 USD", "usd", 1250},
+		{"(12.50)", "USD", -1250},
+		{"$-3", "USD", -300},
+		{".05", "USD", 5},
+		{"1.500", "USD", 150}, // trailing zeros beyond minor unit are exact
+		{"1500", "JPY", 1500},
+		{"1.234", "KWD", 1234},
+	}
+
+	for _, c := range cases {
+		got, err := ParseMoney(c.s, c.currency)
+		if err != nil {
+			t.Errorf("ParseMoney(%q, %s) failed: %v", c.s, c.currency, err)
+			continue
+		}
+		if got.MinorUnits() != c.want {
+			t.Errorf("ParseMoney(%q, %s) = %d, want %d", c.s, c.currency, got.MinorUnits(), c.want)
+		}
+	}
+}
+
+func TestParseMoney_Rejects(t *testing.T) {
+	for _, s := range []string{"", "abc", "1.2.3", "1.234", "--1", "1e5", "$"} {
+		if _, err := ParseMoney(s, "USD"); err == nil {
+			t.Errorf("ParseMoney(%q, USD) expected error", s)
+		}
+	}
+
+	if _, err := ParseMoney("1", "XXX"); err == nil {
+		t.Error("unsupported currency expected error")
+	}
+
+	if _, err := ParseMoney("1.5", "JPY"); err == nil {
+		t.Error("JPY has no minor unit, 1.5 expected error")
+	}
+
+	if _, err := ParseMoney("99999999999999999999", "USD"); !errors.Is(err, ErrMoneyOverflow) {
+		t.Errorf("expected ErrMoneyOverflow, got %v", err)
+	}
+}
+
+func TestParseMoneyRounded_HalfEven(t *testing.T) {
+	cases := map[string]int64{
+		"1.005":  100, // tie, round to even
+		"1.015":  102, // tie, round to even
+		"1.025":  102,
+		"1.0051": 101,
+		"-1.005": -100,
+		"-1.015": -102,
+	}
+
+	for s, want := range cases {
+		got, err := ParseMoneyRounded(s, "USD")
+		if err != nil {
+			t.Fatalf("ParseMoneyRounded(%q) failed: %v", s, err)
+		}
+		if got.MinorUnits() != want {
+			t.Errorf("ParseMoneyRounded(%q) = %d, want %d", s, got.MinorUnits(), want)
+		}
+	}
+}
+
+func TestNewMoneyFromFloat64(t *testing.T) {
+	got, err := NewMoneyFromFloat64(0.1+0.2, "USD")
+	if err != nil || got.MinorUnits() != 30 {
+		t.Fatalf("NewMoneyFromFloat64(0.1+0.2) = %v, %v; want 30 cents", got.MinorUnits(), err)
+	}
+
+	if _, err = NewMoneyFromFloat64(math.NaN(), "USD"); err == nil {
+		t.Error("NaN expected error")
+	}
+}
+
+func TestMoney_AddSub(t *testing.T) {
+	a := mustMoney(t, "10.10", "USD")
+	b := mustMoney(t, "0.20", "USD")
+
+	sum, err := a.Add(b)
+	if err != nil || sum.DecimalString() != "10.30" {
+		t.Fatalf("Add = %s, %v; want 10.30", sum, err)
+	}
+
+	diff, err := b.Sub(a)
+	if err != nil || diff.DecimalString() != "-9.90" {
+		t.Fatalf("Sub = %s, %v; want -9.90", diff, err)
+	}
+
+	if _, err = a.Add(mustMoney(t, "1", "EUR")); !errors.Is(err, ErrMoneyCurrencyMismatch) {
+		t.Errorf("expected ErrMoneyCurrencyMismatch, got %v", err)
+	}
+
+	huge, _ := NewMoney(math.MaxInt64, "USD")
+	one, _ := NewMoney(1, "USD")
+	if _, err = huge.Add(one); !errors.Is(err, ErrMoneyOverflow) {
+		t.Errorf("expected ErrMoneyOverflow, got %v", err)
+	}
+
+	tiny, _ := NewMoney(math.MinInt64, "USD")
+	if _, err = tiny.Sub(one); !errors.Is(err, ErrMoneyOverflow) {
+		t.Errorf("expected ErrMoneyOverflow, got %v", err)
+	}
+	if _, err = tiny.Neg(); !errors.Is(err, ErrMoneyOverflow) {
+		t.Errorf("expected ErrMoneyOverflow on Neg(MinInt64), got %v", err)
+	}
+
+	total, err := Sum(a, b, one)
+	if err != nil || total.MinorUnits() != 1031 {
+		t.Errorf("Sum = %d, %v; want 1031", total.MinorUnits(), err)
+	}
+}
+
+func TestMoney_Multiply(t *testing.T) {
+	price := mustMoney(t, "2.99", "USD")
+
+	got, err := price.Multiply(3)
+	if err != nil || got.DecimalString() != "8.97" {
+		t.Fatalf("Multiply = %s, %v; want 8.97", got, err)
+	}
+
+	huge, _ := NewMoney(math.MaxInt64/2+1, "USD")
+	if _, err = huge.Multiply(2); !errors.Is(err, ErrMoneyOverflow) {
+		t.Errorf("expected ErrMoneyOverflow, got %v", err)
+	}
+
+	got, err = price.MultiplyDecimal("1.5")
+	if err != nil || got.MinorUnits() != 448 { // 4.485 -> 4.48 half-even
+		t.Errorf("MultiplyDecimal(1.5) = %d, %v; want 448", got.MinorUnits(), err)
+	}
+
+	if _, err = price.MultiplyDecimal("abc"); err == nil {
+		t.Error("invalid factor expected error")
+	}
+}
+
+func TestMoney_Percent(t *testing.T) {
+	subtotal := mustMoney(t, "19.99", "USD")
+
+	tax, err := subtotal.Percent("8.875")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 19.99 * 0.08875 = 1.7741125 -> 1.77
+	if tax.DecimalString() != "1.77" {
+		t.Errorf("Percent(8.875) = %s, want 1.77", tax.DecimalString())
+	}
+
+	// tie: 0.50 * 5% = 0.025 -> 0.02 (half-even)
+	half := mustMoney(t, "0.50", "USD")
+	if got, _ := half.Percent("5"); got.MinorUnits() != 2 {
+		t.Errorf("Percent tie = %d, want 2", got.MinorUnits())
+	}
+}
+
+func TestMoney_RoundToIncrement(t *testing.T) {
+	// Swiss cash rounding to CHF 0.05
+	for in, want := range map[string]string{"1.02": "1.00", "1.03": "1.05", "1.07": "1.05", "1.08": "1.10"} {
+		got, err := mustMoney(t, in, "CHF").RoundToIncrement(5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.DecimalString() != want {
+			t.Errorf("RoundToIncrement(%s, 5) = %s, want %s", in, got.DecimalString(), want)
+		}
+	}
+
+	// exact half-even ties on whole units
+	for amount, want := range map[int64]int64{250: 200, 350: 400, -250: -200, 149: 100} {
+		m, _ := NewMoney(amount, "USD")
+		got, err := m.RoundToIncrement(100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.MinorUnits() != want {
+			t.Errorf("RoundToIncrement(%d, 100) = %d, want %d", amount, got.MinorUnits(), want)
+		}
+	}
+
+	if _, err := mustMoney(t, "1", "USD").RoundToIncrement(0); err == nil {
+		t.Error("zero increment expected error")
+	}
+}
+
+func TestMoney_Allocate(t *testing.T) {
+	total := mustMoney(t, "100.00", "USD")
+
+	parts, err := total.Split(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"33.34", "33.33", "33.33"}
+	for i, p := range parts {
+		if p.DecimalString() != want[i] {
+			t.Errorf("Split(3)[%d] = %s, want %s", i, p.DecimalString(), want[i])
+		}
+	}
+
+	cases := []struct {
+		amount string
+		ratios []int64
+	}{
+		{"0.05", []int64{3, 7}},
+		{"-10.00", []int64{1, 1, 1}},
+		{"1.00", []int64{0, 1, 2}},
+		{"99.99", []int64{70, 20, 10}},
+	}
+
+	for _, c := range cases {
+		m := mustMoney(t, c.amount, "USD")
+		parts, err = m.Allocate(c.ratios...)
+		if err != nil {
+			t.Fatalf("Allocate(%s, %v) failed: %v", c.amount, c.ratios, err)
+		}
+
+		sum, err := Sum(parts...)
+		if err != nil || !sum.Equal(m) {
+			t.Errorf("Allocate(%s, %v) parts %v sum to %s, want %s", c.amount, c.ratios, parts, sum, m)
+		}
+
+		for i, r := range c.ratios {
+			if r == 0 && !parts[i].IsZero() {
+				t.Errorf("zero ratio received %s", parts[i])
+			}
+		}
+	}
+
+	if _, err = total.Allocate(); err == nil {
+		t.Error("no ratios expected error")
+	}
+	if _, err = total.Allocate(0, 0); err == nil {
+		t.Error("all zero ratios expected error")
+	}
+	if _, err = total.Allocate(1, -1); err == nil {
+		t.Error("negative ratio expected error")
+	}
+}
+
+func TestMoney_Format(t *testing.T) {
+	usd := mustMoney(t, "1234567.89", "USD")
+	eur := mustMoney(t, "1234.56", "EUR")
+	inr := mustMoney(t, "123456.78", "INR")
+	jpy := mustMoney(t, "1234", "JPY")
+	neg := mustMoney(t, "-5.00", "USD")
+
+	cases := []struct {
+		m      Money
+		locale string
+		want   string
+	}{
+		{usd, "en-US", "$1,234,567.89"},
+		{usd, "en_us", "$1,234,567.89"},
+		{eur, "de-DE", "1.234,56\u00a0€"},
+		{eur, "de-AT", "1.234,56\u00a0€"}, // language fallback
+		{eur, "fr-FR", "1\u202f234,56\u00a0€"},
+		{inr, "en-IN", "₹1,23,456.78"},
+		{jpy, "ja-JP", "¥1,234"},
+		{neg, "en-US", "-$5.00"},
+		{usd, "xx-YY", "$1,234,567.89"}, // unknown locale falls back to en-US
+	}
+
+	for _, c := range cases {
+		if got := c.m.Format(c.locale); got != c.want {
+			t.Errorf("Format(%s, %s) = %q, want %q", c.m, c.locale, got, c.want)
+		}
+	}
+
+	if got := mustMoney(t, "0.05", "USD").DecimalString(); got != "0.05" {
+		t.Errorf("DecimalString = %q, want 0.05", got)
+	}
+	if got := mustMoney(t, "-0.05", "USD").String(); got != "-0.05 USD" {
+		t.Errorf("String = %q, want -0.05 USD", got)
+	}
+
+	minMoney, _ := NewMoney(math.MinInt64, "USD")
+	if got := minMoney.DecimalString(); got != "-92233720368547758.08" {
+		t.Errorf("DecimalString(MinInt64) = %q", got)
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	type order struct {
+		Total Money `json:"total"`
+	}
+
+	in := order{Total: mustMoney(t, "12.34", "EUR")}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"total":{"amount":"12.34","currency":"EUR"}}` {
+		t.Fatalf("Marshal = %s", data)
+	}
+
+	var out order
+	if err = json.Unmarshal(data, &out); err != nil || !out.Total.Equal(in.Total) {
+		t.Fatalf("round trip = %v, %v", out.Total, err)
+	}
+
+	// numeric amount is parsed from its literal, not through float64
+	if err = json.Unmarshal([]byte(`{"total":{"amount":0.3,"currency":"USD"}}`), &out); err != nil || out.Total.MinorUnits() != 30 {
+		t.Fatalf("numeric amount = %v, %v", out.Total, err)
+	}
+
+	// missing currency falls back to DefaultMoneyCurrency
+	out = order{}
+	if err = json.Unmarshal([]byte(`{"total":{"amount":"1"}}`), &out); err != nil || out.Total.Currency() != DefaultMoneyCurrency {
+		t.Fatalf("default currency = %v, %v", out.Total, err)
+	}
+
+	if err = json.Unmarshal([]byte(`{"total":{"amount":"1.234","currency":"USD"}}`), &out); err == nil {
+		t.Error("excess precision expected error")
+	}
+}
+
+func TestMoney_SQL(t *testing.T) {
+	m := mustMoney(t, "-12.30", "USD")
+
+	v, err := m.Value()
+	if err != nil || v != "-12.30" {
+		t.Fatalf("Value = %v, %v", v, err)
+	}
+
+	var scanned Money
+	for _, src := range []interface{}{"-12.30", []byte("-12.30"), "-12.30 USD", "USD -12.30", float64(-12.3)} {
+		if err = scanned.Scan(src); err != nil || !scanned.Equal(m) {
+			t.Errorf("Scan(%#v) = %v, %v; want %v", src, scanned, err, m)
+		}
+	}
+
+	jpy := Money{currency: "JPY"}
+	if err = jpy.Scan(int64(500)); err != nil || jpy.Currency() != "JPY" || jpy.MinorUnits() != 500 {
+		t.Errorf("Scan into preset currency = %v, %v", jpy, err)
+	}
+
+	if err = scanned.Scan(nil); err == nil {
+		t.Error("Scan(nil) expected error")
+	}
+}
+
+func TestLookupCurrencyByNumeric(t *testing.T) {
+	for _, code := range []string{"840", "0840", " 840 "} {
+		c, ok := LookupCurrencyByNumeric(code)
+		if !ok || c.Code != "USD" {
+			t.Errorf("LookupCurrencyByNumeric(%q) = %v, %v", code, c, ok)
+		}
+	}
+
+	if c, ok := LookupCurrencyByNumeric("36"); !ok || c.Code != "AUD" {
+		t.Errorf("LookupCurrencyByNumeric(36) = %v, %v", c, ok)
+	}
+
+	if _, ok := LookupCurrencyByNumeric("999"); ok {
+		t.Error("999 expected not found")
+	}
+}
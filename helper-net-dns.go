@@ -0,0 +1,734 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// =====================================================================================================================
+// Context-Aware Cached DNS Resolution
+// =====================================================================================================================
+
+const (
+	dnsDefaultTTL        = 30 * time.Second // cache ttl when the resolver cannot report record ttls (system resolver)
+	dnsDefaultMaxEntries = 4096             // default DnsCache size bound
+	dnsMaxMessageBytes   = 65535            // largest dns message over udp or tcp
+)
+
+// DnsResolver resolves host addresses and SRV records, reporting how long the answer may be cached;
+// SystemDnsResolver and DnsServerResolver are provided, tests and callers may plug in their own
+type DnsResolver interface {
+	// LookupIP returns the IPv4 and IPv6 addresses of host
+	LookupIP(ctx context.Context, host string) (ips []net.IP, ttl time.Duration, err error)
+
+	// LookupSRV returns the SRV records of _service._proto.name, in any order
+	LookupSRV(ctx context.Context, service string, proto string, name string) (srvs []*net.SRV, ttl time.Duration, err error)
+}
+
+// SystemDnsResolver resolves through a net.Resolver (the operating system or go resolver),
+// which does not expose record ttls, so every answer is reported with the fixed TTL
+type SystemDnsResolver struct {
+	Resolver *net.Resolver // nil = net.DefaultResolver
+	TTL      time.Duration // 0 = 30 seconds
+}
+
+// LookupIP returns the addresses of host from the net.Resolver
+func (r *SystemDnsResolver) LookupIP(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	addrs, err := r.resolver().LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ips := make([]net.IP, 0, len(addrs))
+	for _, a := range addrs {
+		ips = append(ips, a.IP)
+	}
+
+	return ips, r.ttl(), nil
+}
+
+// LookupSRV returns the SRV records of _service._proto.name from the net.Resolver
+func (r *SystemDnsResolver) LookupSRV(ctx context.Context, service string, proto string, name string) ([]*net.SRV, time.Duration, error) {
+	_, srvs, err := r.resolver().LookupSRV(ctx, service, proto, name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return srvs, r.ttl(), nil
+}
+
+func (r *SystemDnsResolver) resolver() *net.Resolver {
+	if r == nil || r.Resolver == nil {
+		return net.DefaultResolver
+	}
+	return r.Resolver
+}
+
+func (r *SystemDnsResolver) ttl() time.Duration {
+	if r == nil || r.TTL <= 0 {
+		return dnsDefaultTTL
+	}
+	return r.TTL
+}
+
+// DnsServerResolver queries one dns server directly, over udp with tcp retry when the answer is truncated,
+// and reports the smallest ttl among the answer records; useful to pin a specific recursive resolver,
+// or to point tests at a local fake dns server
+type DnsServerResolver struct {
+	Server  string        // host:port of the dns server, port defaults to 53 when omitted
+	Timeout time.Duration // per query timeout, 0 = 5 seconds
+}
+
+// LookupIP queries the A and AAAA records of host
+func (r *DnsServerResolver) LookupIP(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	name, err := dnsmessage.NewName(dnsFQDN(host))
+	if err != nil {
+		return nil, 0, &net.DNSError{Err: err.Error(), Name: host}
+	}
+
+	var (
+		ips      []net.IP
+		ttl      uint32 = math.MaxUint32
+		firstErr error
+	)
+
+	for _, qt := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		resp, err := r.exchange(ctx, host, dnsmessage.Question{Name: name, Type: qt, Class: dnsmessage.ClassINET})
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		for _, a := range resp.Answers {
+			switch body := a.Body.(type) {
+			case *dnsmessage.AResource:
+				ips = append(ips, net.IP(append([]byte(nil), body.A[:]...)))
+			case *dnsmessage.AAAAResource:
+				ips = append(ips, net.IP(append([]byte(nil), body.AAAA[:]...)))
+			default:
+				continue
+			}
+			ttl = min(ttl, a.Header.TTL)
+		}
+	}
+
+	if len(ips) == 0 {
+		if firstErr != nil {
+			return nil, 0, firstErr
+		}
+		return nil, 0, &net.DNSError{Err: "no such host", Name: host, Server: r.Server, IsNotFound: true}
+	}
+
+	return ips, time.Duration(ttl) * time.Second, nil
+}
+
+// LookupSRV queries the SRV records of _service._proto.name
+func (r *DnsServerResolver) LookupSRV(ctx context.Context, service string, proto string, name string) ([]*net.SRV, time.Duration, error) {
+	full := "_" + service + "._" + proto + "." + strings.TrimSuffix(name, ".")
+
+	qname, err := dnsmessage.NewName(dnsFQDN(full))
+	if err != nil {
+		return nil, 0, &net.DNSError{Err: err.Error(), Name: full}
+	}
+
+	resp, err := r.exchange(ctx, full, dnsmessage.Question{Name: qname, Type: dnsmessage.TypeSRV, Class: dnsmessage.ClassINET})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		srvs []*net.SRV
+		ttl  uint32 = math.MaxUint32
+	)
+
+	for _, a := range resp.Answers {
+		if body, ok := a.Body.(*dnsmessage.SRVResource); ok {
+			srvs = append(srvs, &net.SRV{
+				Target:   body.Target.String(),
+				Port:     body.Port,
+				Priority: body.Priority,
+				Weight:   body.Weight,
+			})
+			ttl = min(ttl, a.Header.TTL)
+		}
+	}
+
+	if len(srvs) == 0 {
+		return nil, 0, &net.DNSError{Err: "no such host", Name: full, Server: r.Server, IsNotFound: true}
+	}
+
+	return srvs, time.Duration(ttl) * time.Second, nil
+}
+
+// exchange sends question q and returns the server's successful answer, name is the query name used in errors
+func (r *DnsServerResolver) exchange(ctx context.Context, name string, q dnsmessage.Question) (*dnsmessage.Message, error) {
+	if r == nil || strings.TrimSpace(r.Server) == "" {
+		return nil, fmt.Errorf("DnsServerResolver Server is Required")
+	}
+
+	server := strings.TrimSpace(r.Server)
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = dnsLookupTimeout
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: uint16(rand.Uint32()), RecursionDesired: true},
+		Questions: []dnsmessage.Question{q},
+	}
+
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: name, Server: server}
+	}
+
+	resp, err := dnsExchange(ctx, "udp", server, packed, query.Header.ID, q)
+	if err == nil && resp.Header.Truncated {
+		resp, err = dnsExchange(ctx, "tcp", server, packed, query.Header.ID, q)
+	}
+
+	if err != nil {
+		var ne net.Error
+		timedOut := errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &ne) && ne.Timeout())
+		return nil, &net.DNSError{Err: err.Error(), Name: name, Server: server, IsTimeout: timedOut, IsTemporary: timedOut}
+	}
+
+	switch resp.Header.RCode {
+	case dnsmessage.RCodeSuccess:
+		return resp, nil
+	case dnsmessage.RCodeNameError:
+		return nil, &net.DNSError{Err: "no such host", Name: name, Server: server, IsNotFound: true}
+	default:
+		return nil, &net.DNSError{
+			Err:         "server misbehaving, " + resp.Header.RCode.String(),
+			Name:        name,
+			Server:      server,
+			IsTemporary: resp.Header.RCode == dnsmessage.RCodeServerFailure,
+		}
+	}
+}
+
+// dnsExchange performs one query / response round trip over network (udp or tcp),
+// udp responses not matching the query id and question are discarded as stray or spoofed
+func dnsExchange(ctx context.Context, network string, server string, query []byte, id uint16, q dnsmessage.Question) (*dnsmessage.Message, error) {
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	// unblock pending reads when ctx is cancelled before the deadline
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	if network == "tcp" {
+		buf := make([]byte, 2+len(query))
+		binary.BigEndian.PutUint16(buf, uint16(len(query)))
+		copy(buf[2:], query)
+
+		if _, err = conn.Write(buf); err != nil {
+			return nil, err
+		}
+
+		var size [2]byte
+		if _, err = io.ReadFull(conn, size[:]); err != nil {
+			return nil, err
+		}
+
+		resp := make([]byte, binary.BigEndian.Uint16(size[:]))
+		if _, err = io.ReadFull(conn, resp); err != nil {
+			return nil, err
+		}
+
+		msg, err := dnsParseResponse(resp, id, q)
+		if err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	if _, err = conn.Write(query); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, dnsMaxMessageBytes)
+
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+
+		if msg, err := dnsParseResponse(buf[:n], id, q); err == nil {
+			return msg, nil
+		}
+	}
+}
+
+// dnsParseResponse unpacks b and verifies it answers question q of query id
+func dnsParseResponse(b []byte, id uint16, q dnsmessage.Question) (*dnsmessage.Message, error) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(b); err != nil {
+		return nil, err
+	}
+
+	if !msg.Header.Response || msg.Header.ID != id {
+		return nil, fmt.Errorf("dns response id mismatch")
+	}
+
+	if len(msg.Questions) != 1 || msg.Questions[0].Type != q.Type || msg.Questions[0].Class != q.Class ||
+		!strings.EqualFold(msg.Questions[0].Name.String(), q.Name.String()) {
+		return nil, fmt.Errorf("dns response question mismatch")
+	}
+
+	return &msg, nil
+}
+
+// dnsFQDN returns name with a trailing dot
+func dnsFQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// DnsCache is a context-aware dns lookup cache in front of a DnsResolver, answers are kept for the record ttl
+// reported by the resolver, bounded by MinTTL and MaxTTL; not found answers are kept for NegativeTTL,
+// other failures are never cached; the zero value is ready to use with the system resolver,
+// and a DnsCache is safe for concurrent use
+type DnsCache struct {
+	Resolver    DnsResolver   // nil = SystemDnsResolver with default settings
+	MinTTL      time.Duration // floor applied to record ttls, 0 = none
+	MaxTTL      time.Duration // cap applied to record ttls, 0 = none
+	NegativeTTL time.Duration // how long not found answers are cached, 0 = not cached
+	MaxEntries  int           // cache size bound, 0 = 4096
+
+	mu      sync.Mutex
+	entries map[string]*dnsCacheEntry
+
+	now func() time.Time // test hook, nil = time.Now
+}
+
+type dnsCacheEntry struct {
+	ips     []net.IP
+	srvs    []*net.SRV
+	err     error
+	expires time.Time
+}
+
+// NewDnsCache returns a DnsCache in front of resolver, nil resolver uses the system resolver
+func NewDnsCache(resolver DnsResolver) *DnsCache {
+	return &DnsCache{Resolver: resolver}
+}
+
+// LookupIP returns the de-duplicated addresses of host, which may also be a url or an IP literal (returned as is),
+// served from cache while the answer's ttl has not expired
+func (c *DnsCache) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	target, literal, ok := parseIPLookupHost(host)
+	if !ok {
+		return nil, fmt.Errorf("DNS Lookup IP Failed: Invalid Host '%s'", host)
+	}
+
+	if literal != nil {
+		return []net.IP{literal}, nil
+	}
+
+	key := "ip:" + strings.ToLower(target)
+
+	if e, hit := c.get(key); hit {
+		if e.err != nil {
+			return nil, e.err
+		}
+		return dnsCopyIPs(e.ips), nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ips, ttl, err := c.resolver().LookupIP(ctx, target)
+	if err != nil {
+		c.putError(key, err)
+		return nil, err
+	}
+
+	out := make([]net.IP, 0, len(ips))
+	seen := make(map[string]struct{}, len(ips))
+	for _, ip := range ips {
+		if len(ip) == 0 {
+			continue
+		}
+		if _, exists := seen[ip.String()]; exists {
+			continue
+		}
+		seen[ip.String()] = struct{}{}
+		out = append(out, ip)
+	}
+
+	c.put(key, &dnsCacheEntry{ips: out}, ttl)
+	return dnsCopyIPs(out), nil
+}
+
+// LookupSRV returns the SRV records of _service._proto.name in RFC 2782 order (see OrderSrvRecords),
+// the records are cached for their ttl and re-ordered on every call so weighted load spreading still applies
+func (c *DnsCache) LookupSRV(ctx context.Context, service string, proto string, name string) ([]*net.SRV, error) {
+	service = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(service), "_"))
+	proto = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(proto), "_"))
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".")
+
+	if service == "" || proto == "" || !isValidHostName(name) {
+		return nil, fmt.Errorf("DNS Lookup SRV Failed: Invalid Service '%s', Proto '%s' or Name '%s'", service, proto, name)
+	}
+
+	key := "srv:_" + service + "._" + proto + "." + strings.ToLower(name)
+
+	if e, hit := c.get(key); hit {
+		if e.err != nil {
+			return nil, e.err
+		}
+		return dnsCopySRVs(OrderSrvRecords(e.srvs)), nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	srvs, ttl, err := c.resolver().LookupSRV(ctx, service, proto, name)
+	if err != nil {
+		c.putError(key, err)
+		return nil, err
+	}
+
+	// keep private copies so callers mutating returned records never alter the cache
+	cached := dnsCopySRVs(srvs)
+
+	c.put(key, &dnsCacheEntry{srvs: cached}, ttl)
+	return dnsCopySRVs(OrderSrvRecords(cached)), nil
+}
+
+// ResolveSRV resolves host in the DnsLookupSrvs forms (_service._proto.domain, scheme://domain or scheme+proto://domain)
+// to de-duplicated ip:port addresses, in RFC 2782 order of the SRV targets; targets that fail to resolve are skipped,
+// an error is returned only when the SRV lookup fails or no target resolves
+func (c *DnsCache) ResolveSRV(ctx context.Context, host string) ([]string, error) {
+	service, proto, target, err := parseSrvLookupHost(host)
+	if err != nil {
+		return nil, fmt.Errorf("DNS Lookup SRV Failed: %w", err)
+	}
+
+	srvs, err := c.LookupSRV(ctx, service, proto, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		addrs   []string
+		lastErr error
+	)
+
+	seen := make(map[string]struct{})
+
+	for _, s := range srvs {
+		if ctx != nil && ctx.Err() != nil {
+			return addrs, ctx.Err()
+		}
+
+		targetHost := strings.TrimSuffix(s.Target, ".")
+		if targetHost == "" || !isValidHostName(targetHost) {
+			continue
+		}
+
+		ips, err := c.LookupIP(ctx, targetHost)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		for _, ip := range ips {
+			entry := net.JoinHostPort(ip.String(), strconv.Itoa(int(s.Port)))
+			if _, exists := seen[entry]; exists {
+				continue
+			}
+			seen[entry] = struct{}{}
+			addrs = append(addrs, entry)
+		}
+	}
+
+	if len(addrs) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, &net.DNSError{Err: "no resolvable SRV targets", Name: host, IsNotFound: true}
+	}
+
+	return addrs, nil
+}
+
+// Flush removes every cached answer
+func (c *DnsCache) Flush() {
+	c.mu.Lock()
+	c.entries = nil
+	c.mu.Unlock()
+}
+
+func (c *DnsCache) resolver() DnsResolver {
+	if c.Resolver == nil {
+		return &SystemDnsResolver{}
+	}
+	return c.Resolver
+}
+
+func (c *DnsCache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// get returns the unexpired entry of key
+func (c *DnsCache) get(key string) (*dnsCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !c.clock().Before(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return e, true
+}
+
+// put caches e for ttl, clamped by MinTTL and MaxTTL
+func (c *DnsCache) put(key string, e *dnsCacheEntry, ttl time.Duration) {
+	if c.MinTTL > 0 && ttl < c.MinTTL {
+		ttl = c.MinTTL
+	}
+	if c.MaxTTL > 0 && ttl > c.MaxTTL {
+		ttl = c.MaxTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.store(key, e, ttl)
+}
+
+// putError caches not found errors for NegativeTTL, other errors are transient and not cached
+func (c *DnsCache) putError(key string, err error) {
+	var dnsErr *net.DNSError
+	if c.NegativeTTL <= 0 || !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		return
+	}
+
+	c.store(key, &dnsCacheEntry{err: err}, c.NegativeTTL)
+}
+
+// store sets e under key to expire after ttl, evicting entries to stay within MaxEntries
+func (c *DnsCache) store(key string, e *dnsCacheEntry, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock()
+	e.expires = now.Add(ttl)
+
+	if c.entries == nil {
+		c.entries = make(map[string]*dnsCacheEntry)
+	}
+
+	limit := c.MaxEntries
+	if limit <= 0 {
+		limit = dnsDefaultMaxEntries
+	}
+
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= limit {
+		// drop expired answers first, then arbitrary ones, to stay within the bound
+		for k, v := range c.entries {
+			if !now.Before(v.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < limit {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = e
+}
+
+func dnsCopyIPs(ips []net.IP) []net.IP {
+	out := make([]net.IP, len(ips))
+	for i, ip := range ips {
+		out[i] = append(net.IP(nil), ip...)
+	}
+	return out
+}
+
+func dnsCopySRVs(srvs []*net.SRV) []*net.SRV {
+	out := make([]*net.SRV, 0, len(srvs))
+	for _, s := range srvs {
+		if s != nil {
+			cp := *s
+			out = append(out, &cp)
+		}
+	}
+	return out
+}
+
+// OrderSrvRecords returns srvs in the order connections should be attempted per RFC 2782:
+// ascending priority, and within one priority a weighted random order where each remaining record
+// is picked next with probability proportional to its weight (weight 0 records keep a small chance);
+// the input slice is not modified, nil records are dropped
+func OrderSrvRecords(srvs []*net.SRV) []*net.SRV {
+	out := make([]*net.SRV, 0, len(srvs))
+	for _, s := range srvs {
+		if s != nil {
+			out = append(out, s)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Priority < out[j].Priority })
+
+	for i := 0; i < len(out); {
+		j := i + 1
+		for j < len(out) && out[j].Priority == out[i].Priority {
+			j++
+		}
+
+		orderSrvByWeight(out[i:j])
+		i = j
+	}
+
+	return out
+}
+
+// orderSrvByWeight applies the RFC 2782 weighted selection to records of one priority, in place
+func orderSrvByWeight(group []*net.SRV) {
+	// weight 0 records go first so a running sum pick of 0 can still select them
+	sort.SliceStable(group, func(i, j int) bool { return group[i].Weight == 0 && group[j].Weight != 0 })
+
+	for i := 0; i < len(group)-1; i++ {
+		sum := 0
+		for _, s := range group[i:] {
+			sum += int(s.Weight)
+		}
+
+		pick := rand.IntN(sum + 1)
+		running := 0
+
+		for k := i; k < len(group); k++ {
+			running += int(group[k].Weight)
+			if running >= pick {
+				// move the pick to position i, keeping the relative order of the rest
+				sel := group[k]
+				copy(group[i+1:k+1], group[i:k])
+				group[i] = sel
+				break
+			}
+		}
+	}
+}
+
+// defaultDnsCache backs DnsLookupIpsContext and DnsLookupSrvsContext
+var defaultDnsCache atomic.Pointer[DnsCache]
+
+func init() {
+	defaultDnsCache.Store(NewDnsCache(nil))
+}
+
+// SetDefaultDnsCache replaces the cache used by DnsLookupIpsContext and DnsLookupSrvsContext,
+// such as one with a DnsServerResolver or custom ttl bounds; nil restores a system resolver cache
+func SetDefaultDnsCache(cache *DnsCache) {
+	if cache == nil {
+		cache = NewDnsCache(nil)
+	}
+	defaultDnsCache.Store(cache)
+}
+
+// DnsLookupIpsContext is the context-aware, cached form of DnsLookupIps, returning the resolver error
+// instead of an empty list; a 5 second bound applies when ctx has no deadline
+func DnsLookupIpsContext(ctx context.Context, host string) ([]net.IP, error) {
+	ctx, cancel := dnsBoundedContext(ctx)
+	defer cancel()
+
+	return defaultDnsCache.Load().LookupIP(ctx, host)
+}
+
+// DnsLookupSrvsContext is the context-aware, cached form of DnsLookupSrvs, returning ip:port addresses
+// in RFC 2782 priority and weight order; a 5 second bound applies when ctx has no deadline
+func DnsLookupSrvsContext(ctx context.Context, host string) ([]string, error) {
+	ctx, cancel := dnsBoundedContext(ctx)
+	defer cancel()
+
+	return defaultDnsCache.Load().ResolveSRV(ctx, host)
+}
+
+func dnsBoundedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, dnsLookupTimeout)
+}
@@ -0,0 +1,282 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for DnsServerResolver, DnsCache and OrderSrvRecords (helper-net-dns.go),
+// run against a fake dns server on the loopback interface.
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// fakeDnsServer answers A, AAAA and SRV questions from fixed tables over udp, counting the queries received
+type fakeDnsServer struct {
+	conn    net.PacketConn
+	queries atomic.Int32
+	a       map[string][]net.IP
+	srv     map[string][]dnsmessage.SRVResource
+	ttl     uint32
+}
+
+func startFakeDnsServer(t *testing.T) *fakeDnsServer {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp loopback unavailable: %v", err)
+	}
+
+	s := &fakeDnsServer{
+		conn: conn,
+		ttl:  60,
+		a: map[string][]net.IP{
+			"api.example.test.":   {net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2"), net.ParseIP("fd00::1")},
+			"node1.example.test.": {net.ParseIP("10.0.1.1")},
+			"node2.example.test.": {net.ParseIP("10.0.1.2")},
+		},
+		srv: map[string][]dnsmessage.SRVResource{
+			"_grpc._tcp.example.test.": {
+				{Priority: 10, Weight: 0, Port: 9000, Target: dnsmessage.MustNewName("node2.example.test.")},
+				{Priority: 5, Weight: 10, Port: 8000, Target: dnsmessage.MustNewName("node1.example.test.")},
+				{Priority: 10, Weight: 5, Port: 9001, Target: dnsmessage.MustNewName("missing.example.test.")},
+			},
+		},
+	}
+
+	t.Cleanup(func() { _ = conn.Close() })
+	go s.serve()
+
+	return s
+}
+
+func (s *fakeDnsServer) addr() string { return s.conn.LocalAddr().String() }
+
+func (s *fakeDnsServer) serve() {
+	buf := make([]byte, 512)
+
+	for {
+		n, from, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		var req dnsmessage.Message
+		if req.Unpack(buf[:n]) != nil || len(req.Questions) != 1 {
+			continue
+		}
+		s.queries.Add(1)
+
+		q := req.Questions[0]
+		resp := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: req.Header.ID, Response: true, RCode: dnsmessage.RCodeNameError},
+			Questions: req.Questions,
+		}
+		hdr := dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: dnsmessage.ClassINET, TTL: s.ttl}
+		name := strings.ToLower(q.Name.String())
+
+		if ips, ok := s.a[name]; ok {
+			resp.Header.RCode = dnsmessage.RCodeSuccess
+			for _, ip := range ips {
+				if v4 := ip.To4(); v4 != nil && q.Type == dnsmessage.TypeA {
+					r := &dnsmessage.AResource{}
+					copy(r.A[:], v4)
+					resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: hdr, Body: r})
+				} else if ip.To4() == nil && q.Type == dnsmessage.TypeAAAA {
+					r := &dnsmessage.AAAAResource{}
+					copy(r.AAAA[:], ip)
+					resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: hdr, Body: r})
+				}
+			}
+		}
+
+		if srvs, ok := s.srv[name]; ok && q.Type == dnsmessage.TypeSRV {
+			resp.Header.RCode = dnsmessage.RCodeSuccess
+			for i := range srvs {
+				resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: hdr, Body: &srvs[i]})
+			}
+		}
+
+		if out, err := resp.Pack(); err == nil {
+			_, _ = s.conn.WriteTo(out, from)
+		}
+	}
+}
+
+func TestDnsServerResolver_LookupIPAndSRV(t *testing.T) {
+	srv := startFakeDnsServer(t)
+	r := &DnsServerResolver{Server: srv.addr(), Timeout: 2 * time.Second}
+
+	ips, ttl, err := r.LookupIP(context.Background(), "api.example.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ips) != 3 || ttl != 60*time.Second {
+		t.Fatalf("LookupIP = %v ttl %v", ips, ttl)
+	}
+
+	srvs, _, err := r.LookupSRV(context.Background(), "grpc", "tcp", "example.test")
+	if err != nil || len(srvs) != 3 {
+		t.Fatalf("LookupSRV = %v, %v", srvs, err)
+	}
+
+	_, _, err = r.LookupIP(context.Background(), "nope.example.test")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Fatalf("LookupIP missing host err = %v", err)
+	}
+}
+
+func TestDnsCache_TTLAndNegativeCaching(t *testing.T) {
+	srv := startFakeDnsServer(t)
+
+	now := time.Unix(1700000000, 0)
+	c := NewDnsCache(&DnsServerResolver{Server: srv.addr(), Timeout: 2 * time.Second})
+	c.NegativeTTL = 10 * time.Second
+	c.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if ips, err := c.LookupIP(ctx, "https://API.example.test/path"); err != nil || len(ips) != 3 {
+			t.Fatalf("LookupIP = %v, %v", ips, err)
+		}
+	}
+	if got := srv.queries.Load(); got != 2 { // one A and one AAAA query
+		t.Fatalf("queries after cached lookups = %d, want 2", got)
+	}
+
+	now = now.Add(61 * time.Second)
+	if _, err := c.LookupIP(ctx, "api.example.test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.queries.Load(); got != 4 {
+		t.Fatalf("queries after ttl expiry = %d, want 4", got)
+	}
+
+	if _, err := c.LookupIP(ctx, "nope.example.test"); err == nil {
+		t.Fatal("expected not found error")
+	}
+	before := srv.queries.Load()
+	if _, err := c.LookupIP(ctx, "nope.example.test"); err == nil {
+		t.Fatal("expected cached not found error")
+	}
+	if srv.queries.Load() != before {
+		t.Fatal("not found answer was not cached")
+	}
+
+	if ips, err := c.LookupIP(ctx, "10.9.9.9"); err != nil || len(ips) != 1 || !ips[0].Equal(net.ParseIP("10.9.9.9")) {
+		t.Fatalf("literal = %v, %v", ips, err)
+	}
+
+	c.Flush()
+	if _, err := c.LookupIP(ctx, "api.example.test"); err != nil {
+		t.Fatal(err)
+	}
+	if srv.queries.Load() == before {
+		t.Fatal("Flush did not clear the cache")
+	}
+}
+
+func TestDnsCache_NegativeEntriesAreBounded(t *testing.T) {
+	c := NewDnsCache(nil)
+	c.NegativeTTL = time.Minute
+	c.MaxEntries = 3
+
+	// a flood of distinct not found names cannot grow the cache past MaxEntries
+	for i := 0; i < 50; i++ {
+		name := "missing" + strings.Repeat("x", i) + ".example.test"
+		c.putError("ip|"+name, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true})
+	}
+
+	if n := len(c.entries); n > 3 {
+		t.Fatalf("cache entries = %d, want at most 3", n)
+	}
+}
+
+func TestDnsCache_ResolveSRV(t *testing.T) {
+	srv := startFakeDnsServer(t)
+	c := NewDnsCache(&DnsServerResolver{Server: srv.addr(), Timeout: 2 * time.Second})
+
+	for _, host := range []string{"_grpc._tcp.example.test", "grpc://example.test"} {
+		addrs, err := c.ResolveSRV(context.Background(), host)
+		if err != nil {
+			t.Fatalf("%s: %v", host, err)
+		}
+
+		// priority 5 first, then the priority 10 target that resolves; the missing target is skipped
+		want := []string{"10.0.1.1:8000", "10.0.1.2:9000"}
+		if strings.Join(addrs, ",") != strings.Join(want, ",") {
+			t.Fatalf("%s: ResolveSRV = %v, want %v", host, addrs, want)
+		}
+	}
+
+	if _, err := c.ResolveSRV(context.Background(), "example.test"); err == nil {
+		t.Fatal("expected error for host without service")
+	}
+}
+
+func TestDnsCache_CachedRecordsAreIsolated(t *testing.T) {
+	srv := startFakeDnsServer(t)
+	c := NewDnsCache(&DnsServerResolver{Server: srv.addr(), Timeout: 2 * time.Second})
+
+	srvs, err := c.LookupSRV(context.Background(), "grpc", "tcp", "example.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvs[0].Port = 1
+
+	again, _ := c.LookupSRV(context.Background(), "grpc", "tcp", "example.test")
+	for _, s := range again {
+		if s.Port == 1 {
+			t.Fatal("mutating a returned record changed the cache")
+		}
+	}
+}
+
+func TestOrderSrvRecords(t *testing.T) {
+	in := []*net.SRV{
+		{Target: "c", Priority: 20, Weight: 1},
+		{Target: "heavy", Priority: 10, Weight: 90},
+		{Target: "light", Priority: 10, Weight: 10},
+	}
+
+	heavyFirst := 0
+	const rounds = 2000
+
+	for i := 0; i < rounds; i++ {
+		out := OrderSrvRecords(in)
+		if len(out) != 3 || out[2].Target != "c" {
+			t.Fatalf("priority order broken: %v", out)
+		}
+		if out[0].Target == "heavy" {
+			heavyFirst++
+		}
+	}
+
+	// expected about 90%, allow a wide margin
+	if heavyFirst < rounds*80/100 || heavyFirst > rounds*97/100 {
+		t.Fatalf("heavy record first %d of %d rounds", heavyFirst, rounds)
+	}
+
+	if in[0].Target != "c" {
+		t.Fatal("input slice was modified")
+	}
+
+	zeros := OrderSrvRecords([]*net.SRV{{Target: "a"}, nil, {Target: "b"}})
+	if len(zeros) != 2 {
+		t.Fatalf("nil records not dropped: %v", zeros)
+	}
+}
@@ -0,0 +1,478 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =====================================================================================================================
+// Re-Readable Request Body
+// =====================================================================================================================
+
+// ErrHttpBodyTooLarge is returned when a request body exceeds the size limit given to BufferHttpRequestBody
+var ErrHttpBodyTooLarge = errors.New("http request body too large")
+
+// httpBufferedBody is the re-readable request body installed by BufferHttpRequestBody
+type httpBufferedBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (b *httpBufferedBody) Close() error { return nil }
+
+// BufferHttpRequestBody reads req.Body, up to maxBytes (0 or less = 10mb), and replaces it with an in-memory copy,
+// so the body can be read again by later handlers, signature checks or binders; req.GetBody is set as well,
+// and calling again on an already buffered request returns the buffered bytes without re-reading.
+//
+// a body larger than maxBytes returns ErrHttpBodyTooLarge, and req.Body is restored to the full original stream
+// (bytes already read followed by the unread remainder), so no content is lost or silently truncated
+func BufferHttpRequestBody(req *http.Request, maxBytes int64) ([]byte, error) {
+	if req == nil {
+		return nil, fmt.Errorf("Http Request Nil")
+	}
+
+	if maxBytes <= 0 {
+		maxBytes = maxRequestBodyBytes
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		setHttpBufferedBody(req, []byte{})
+		return []byte{}, nil
+	}
+
+	if bb, ok := req.Body.(*httpBufferedBody); ok {
+		if int64(len(bb.data)) > maxBytes {
+			return nil, fmt.Errorf("%w: limit %d bytes", ErrHttpBodyTooLarge, maxBytes)
+		}
+
+		setHttpBufferedBody(req, bb.data)
+		return bb.data, nil
+	}
+
+	if req.ContentLength > maxBytes {
+		return nil, fmt.Errorf("%w: content length %d exceeds limit %d bytes", ErrHttpBodyTooLarge, req.ContentLength, maxBytes)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBytes+1))
+
+	if int64(len(body)) > maxBytes {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+
+		return nil, fmt.Errorf("%w: limit %d bytes", ErrHttpBodyTooLarge, maxBytes)
+	}
+
+	if err != nil {
+		_ = req.Body.Close()
+		setHttpBufferedBody(req, body)
+		return body, fmt.Errorf("Http Request Body Read Failed: %w", err)
+	}
+
+	_ = req.Body.Close()
+	setHttpBufferedBody(req, body)
+
+	return body, nil
+}
+
+func setHttpBufferedBody(req *http.Request, data []byte) {
+	req.Body = &httpBufferedBody{Reader: bytes.NewReader(data), data: data}
+	req.ContentLength = int64(len(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return &httpBufferedBody{Reader: bytes.NewReader(data), data: data}, nil
+	}
+}
+
+// =====================================================================================================================
+// Client IP Behind Trusted Proxies
+// =====================================================================================================================
+
+// HttpClientIPResolver extracts the originating client ip of requests that pass through reverse proxies
+// or load balancers; forwarding headers are only believed when the connecting peer is a trusted proxy,
+// and the Forwarded (RFC 7239) or X-Forwarded-For chain is walked right to left, skipping trusted proxies,
+// until the first untrusted hop, which is the client; a resolver without trusted proxies always returns the peer address
+type HttpClientIPResolver struct {
+	trusted []*net.IPNet
+}
+
+// NewHttpClientIPResolver returns a resolver trusting the given proxies, each an ip (10.0.0.1, ::1) or cidr (10.0.0.0/8)
+func NewHttpClientIPResolver(trustedProxies ...string) (*HttpClientIPResolver, error) {
+	r := &HttpClientIPResolver{}
+
+	for _, p := range trustedProxies {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("Trusted Proxy '%s' is Not a Valid IP or CIDR", p)
+			}
+
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+
+			r.trusted = append(r.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("Trusted Proxy '%s' is Not a Valid IP or CIDR", p)
+		}
+
+		r.trusted = append(r.trusted, ipNet)
+	}
+
+	return r, nil
+}
+
+// IsTrustedProxy returns true when ip falls within one of the trusted proxies
+func (r *HttpClientIPResolver) IsTrustedProxy(ip net.IP) bool {
+	if r == nil || ip == nil {
+		return false
+	}
+
+	for _, n := range r.trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP returns the client ip of req, or blank when req has no parsable remote address;
+// when the peer is a trusted proxy, the Forwarded header is used if present, else X-Forwarded-For, else X-Real-IP,
+// a malformed or obfuscated hop stops the walk and the nearest validated hop is returned
+func (r *HttpClientIPResolver) ClientIP(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+
+	peer := parseHttpForwardedHop(req.RemoteAddr)
+	if peer == nil {
+		return ""
+	}
+
+	if !r.IsTrustedProxy(peer) {
+		return peer.String()
+	}
+
+	hops := httpForwardedChain(req.Header)
+	if len(hops) == 0 {
+		if ip := parseHttpForwardedHop(req.Header.Get("X-Real-IP")); ip != nil {
+			return ip.String()
+		}
+		return peer.String()
+	}
+
+	client := peer
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := parseHttpForwardedHop(hops[i])
+		if ip == nil {
+			break
+		}
+
+		client = ip
+		if !r.IsTrustedProxy(ip) {
+			break
+		}
+	}
+
+	return client.String()
+}
+
+// httpForwardedChain returns the forwarding hops, client first, from the Forwarded for= parameters,
+// or from X-Forwarded-For when no Forwarded header is present; multiple header lines are joined in order
+func httpForwardedChain(h http.Header) []string {
+	var hops []string
+
+	if fwd := h.Values("Forwarded"); len(fwd) > 0 {
+		for _, line := range fwd {
+			for _, elem := range strings.Split(line, ",") {
+				for _, pair := range strings.Split(elem, ";") {
+					k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+					if ok && strings.EqualFold(strings.TrimSpace(k), "for") {
+						hops = append(hops, v)
+					}
+				}
+			}
+		}
+		return hops
+	}
+
+	for _, line := range h.Values("X-Forwarded-For") {
+		for _, v := range strings.Split(line, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				hops = append(hops, v)
+			}
+		}
+	}
+
+	return hops
+}
+
+// parseHttpForwardedHop parses a hop address such as 192.0.2.1, 192.0.2.1:4711, "[2001:db8::1]:4711" or 2001:db8::1,
+// returning nil for malformed, "unknown" or obfuscated (_hidden) hops
+func parseHttpForwardedHop(s string) net.IP {
+	s = strings.Trim(strings.TrimSpace(s), `"`)
+	if s == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return nil
+		}
+		s = s[1:end]
+	} else if strings.Count(s, ":") == 1 {
+		s = s[:strings.IndexByte(s, ':')]
+	}
+
+	if i := strings.IndexByte(s, '%'); i >= 0 {
+		s = s[:i] // drop ipv6 zone
+	}
+
+	ip := net.ParseIP(s)
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+
+	return ip
+}
+
+// =====================================================================================================================
+// Webhook Signature Verification
+// =====================================================================================================================
+
+// webhook signature styles accepted by WebhookVerifier
+const (
+	// WebhookStyleStripe signs "timestamp.body", sent as Stripe-Signature: t=<unix>,v1=<hex hmac-sha256>[,v1=...]
+	WebhookStyleStripe = "stripe"
+
+	// WebhookStyleGitHub signs the body, sent as X-Hub-Signature-256: sha256=<hex hmac-sha256>
+	WebhookStyleGitHub = "github"
+)
+
+const webhookDefaultTolerance = 5 * time.Minute
+
+var (
+	// ErrWebhookSignatureMissing is returned when the signature header is absent or malformed
+	ErrWebhookSignatureMissing = errors.New("webhook signature missing")
+
+	// ErrWebhookSignatureMismatch is returned when no signature matches any of the secrets
+	ErrWebhookSignatureMismatch = errors.New("webhook signature mismatch")
+
+	// ErrWebhookTimestampExpired is returned when a signed timestamp is outside the tolerance window (replay protection)
+	ErrWebhookTimestampExpired = errors.New("webhook timestamp outside tolerance")
+)
+
+// WebhookVerifier verifies HMAC-SHA256 webhook signatures in Stripe or GitHub style,
+// comparisons are constant time, and more than one secret may be set to allow secret rotation
+type WebhookVerifier struct {
+	Style     string        // WebhookStyleStripe or WebhookStyleGitHub, blank = github
+	Secrets   []string      // signing secrets, any match is accepted
+	Header    string        // signature header override, blank = Stripe-Signature or X-Hub-Signature-256
+	Tolerance time.Duration // stripe style timestamp window (either direction), 0 = 5 minutes
+
+	now func() time.Time // test hook, nil = time.Now
+}
+
+// Verify checks the signature in header against body
+func (v *WebhookVerifier) Verify(header http.Header, body []byte) error {
+	if err := v.validateSecrets(); err != nil {
+		return err
+	}
+
+	value := strings.TrimSpace(header.Get(v.headerName()))
+	if value == "" {
+		return ErrWebhookSignatureMissing
+	}
+
+	switch v.style() {
+	case WebhookStyleStripe:
+		return v.verifyStripe(value, body)
+	case WebhookStyleGitHub:
+		sig, ok := strings.CutPrefix(value, "sha256=")
+		if !ok {
+			return ErrWebhookSignatureMissing
+		}
+
+		if v.matchAny(nil, body, []string{sig}) {
+			return nil
+		}
+		return ErrWebhookSignatureMismatch
+	default:
+		return fmt.Errorf("Webhook Style '%s' Not Supported", v.Style)
+	}
+}
+
+// VerifyRequest buffers the body of req with BufferHttpRequestBody (maxBytes 0 = 10mb), then verifies its signature;
+// the body stays readable by later handlers and is returned on success
+func (v *WebhookVerifier) VerifyRequest(req *http.Request, maxBytes int64) ([]byte, error) {
+	body, err := BufferHttpRequestBody(req, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = v.Verify(req.Header, body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// Sign returns the signature header name and value for body using the first secret, as a provider would send it,
+// timestamp applies to stripe style only; useful for outbound webhooks and tests
+func (v *WebhookVerifier) Sign(body []byte, timestamp time.Time) (headerName string, headerValue string, err error) {
+	if err = v.validateSecrets(); err != nil {
+		return "", "", err
+	}
+
+	switch v.style() {
+	case WebhookStyleStripe:
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		return v.headerName(), "t=" + ts + ",v1=" + hex.EncodeToString(webhookHmac(v.Secrets[0], []byte(ts+"."), body)), nil
+	case WebhookStyleGitHub:
+		return v.headerName(), "sha256=" + hex.EncodeToString(webhookHmac(v.Secrets[0], nil, body)), nil
+	default:
+		return "", "", fmt.Errorf("Webhook Style '%s' Not Supported", v.Style)
+	}
+}
+
+func (v *WebhookVerifier) verifyStripe(value string, body []byte) error {
+	var (
+		ts   string
+		sigs []string
+	)
+
+	for _, part := range strings.Split(value, ",") {
+		k, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+
+		switch k {
+		case "t":
+			ts = val
+		case "v1":
+			sigs = append(sigs, val)
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return ErrWebhookSignatureMissing
+	}
+
+	// signature first, so a forged header cannot probe the tolerance window
+	if !v.matchAny([]byte(ts+"."), body, sigs) {
+		return ErrWebhookSignatureMismatch
+	}
+
+	tolerance := v.Tolerance
+	if tolerance <= 0 {
+		tolerance = webhookDefaultTolerance
+	}
+
+	now := time.Now
+	if v.now != nil {
+		now = v.now
+	}
+
+	if age := now().Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: signed %s ago, tolerance %s", ErrWebhookTimestampExpired, age.Round(time.Second), tolerance)
+	}
+
+	return nil
+}
+
+// matchAny returns true when any hex signature in sigs equals hmac(secret, prefix + body) for any secret
+func (v *WebhookVerifier) matchAny(prefix []byte, body []byte, sigs []string) bool {
+	for _, secret := range v.Secrets {
+		expected := webhookHmac(secret, prefix, body)
+
+		for _, s := range sigs {
+			if got, err := hex.DecodeString(strings.TrimSpace(s)); err == nil && hmac.Equal(got, expected) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// validateSecrets requires at least one secret and no blank ones, an hmac with an empty key can be forged by anyone
+func (v *WebhookVerifier) validateSecrets() error {
+	if v == nil || len(v.Secrets) == 0 {
+		return fmt.Errorf("Webhook Verifier Requires At Least One Secret")
+	}
+
+	for i, secret := range v.Secrets {
+		if strings.TrimSpace(secret) == "" {
+			return fmt.Errorf("Webhook Verifier Secret %d Is Blank", i)
+		}
+	}
+
+	return nil
+}
+
+func (v *WebhookVerifier) style() string {
+	if s := strings.ToLower(strings.TrimSpace(v.Style)); s != "" {
+		return s
+	}
+	return WebhookStyleGitHub
+}
+
+func (v *WebhookVerifier) headerName() string {
+	if v.Header != "" {
+		return v.Header
+	}
+
+	if v.style() == WebhookStyleStripe {
+		return "Stripe-Signature"
+	}
+	return "X-Hub-Signature-256"
+}
+
+// webhookHmac returns hmac-sha256(secret, prefix + body)
+func webhookHmac(secret string, prefix []byte, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(prefix)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
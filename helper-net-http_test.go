@@ -0,0 +1,213 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for BufferHttpRequestBody, HttpClientIPResolver and WebhookVerifier (helper-net-http.go).
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBufferHttpRequestBody(t *testing.T) {
+	req := httptest.NewRequest("POST", "/hook", strings.NewReader("hello world"))
+
+	body, err := BufferHttpRequestBody(req, 64)
+	if err != nil || string(body) != "hello world" {
+		t.Fatalf("first buffer = %q, %v", body, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		again, _ := io.ReadAll(req.Body)
+		if string(again) != "hello world" {
+			t.Fatalf("read %d = %q", i, again)
+		}
+
+		if b, err := BufferHttpRequestBody(req, 64); err != nil || string(b) != "hello world" {
+			t.Fatalf("re-buffer = %q, %v", b, err)
+		}
+	}
+
+	rc, err := req.GetBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(rc); string(b) != "hello world" {
+		t.Fatalf("GetBody = %q", b)
+	}
+}
+
+func TestBufferHttpRequestBody_TooLarge(t *testing.T) {
+	req := httptest.NewRequest("POST", "/hook", io.NopCloser(strings.NewReader("0123456789abcdef")))
+	req.ContentLength = -1 // unknown length, forces the streaming check
+
+	if _, err := BufferHttpRequestBody(req, 8); !errors.Is(err, ErrHttpBodyTooLarge) {
+		t.Fatalf("err = %v, want ErrHttpBodyTooLarge", err)
+	}
+
+	// nothing is lost, the full original body is still readable
+	if b, _ := io.ReadAll(req.Body); string(b) != "0123456789abcdef" {
+		t.Fatalf("body after oversize = %q", b)
+	}
+
+	req = httptest.NewRequest("POST", "/hook", strings.NewReader("0123456789abcdef"))
+	if _, err := BufferHttpRequestBody(req, 8); !errors.Is(err, ErrHttpBodyTooLarge) {
+		t.Fatalf("content length err = %v", err)
+	}
+}
+
+func TestHttpClientIPResolver(t *testing.T) {
+	r, err := NewHttpClientIPResolver("10.0.0.0/8", "192.168.1.5", "::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewHttpClientIPResolver("not-an-ip"); err == nil {
+		t.Fatal("expected error for invalid proxy")
+	}
+
+	cases := []struct {
+		name   string
+		remote string
+		header map[string]string
+		want   string
+	}{
+		{"untrusted peer ignores headers", "203.0.113.9:5000", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "203.0.113.9"},
+		{"xff single hop", "10.1.1.1:5000", map[string]string{"X-Forwarded-For": "198.51.100.7"}, "198.51.100.7"},
+		{"xff skips trusted hops", "10.1.1.1:5000", map[string]string{"X-Forwarded-For": "6.6.6.6, 198.51.100.7, 10.2.2.2, 192.168.1.5"}, "198.51.100.7"},
+		{"xff all trusted returns leftmost", "10.1.1.1:5000", map[string]string{"X-Forwarded-For": "10.9.9.9, 10.2.2.2"}, "10.9.9.9"},
+		{"malformed hop stops walk", "10.1.1.1:5000", map[string]string{"X-Forwarded-For": "198.51.100.7, garbage, 10.2.2.2"}, "10.2.2.2"},
+		{"forwarded preferred", "10.1.1.1:5000", map[string]string{
+			"Forwarded":       `for=198.51.100.7;proto=https, for="[2001:db8::17]:4711"`,
+			"X-Forwarded-For": "1.2.3.4",
+		}, "2001:db8::17"},
+		{"forwarded obfuscated", "10.1.1.1:5000", map[string]string{"Forwarded": "for=198.51.100.7, for=_hidden"}, "10.1.1.1"},
+		{"x-real-ip fallback", "[::1]:5000", map[string]string{"X-Real-IP": "198.51.100.8"}, "198.51.100.8"},
+		{"no headers", "10.1.1.1:5000", nil, "10.1.1.1"},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tc.remote
+		for k, v := range tc.header {
+			req.Header.Set(k, v)
+		}
+
+		if got := r.ClientIP(req); got != tc.want {
+			t.Errorf("%s: ClientIP = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	var none *HttpClientIPResolver
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.1.1.1:1"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	if got := none.ClientIP(req); got != "10.1.1.1" {
+		t.Fatalf("nil resolver ClientIP = %q", got)
+	}
+}
+
+func TestWebhookVerifier_GitHub(t *testing.T) {
+	v := &WebhookVerifier{Style: WebhookStyleGitHub, Secrets: []string{"old", "new"}}
+	body := []byte(`{"action":"opened"}`)
+
+	signer := &WebhookVerifier{Secrets: []string{"new"}}
+	name, value, err := signer.Sign(body, time.Time{})
+	if err != nil || name != "X-Hub-Signature-256" || !strings.HasPrefix(value, "sha256=") {
+		t.Fatalf("Sign = %s %s %v", name, value, err)
+	}
+
+	h := http.Header{}
+	h.Set(name, value)
+	if err := v.Verify(h, body); err != nil {
+		t.Fatalf("rotated secret rejected: %v", err)
+	}
+
+	if err := v.Verify(h, []byte(`{"action":"closed"}`)); !errors.Is(err, ErrWebhookSignatureMismatch) {
+		t.Fatalf("tampered body err = %v", err)
+	}
+
+	if err := v.Verify(http.Header{}, body); !errors.Is(err, ErrWebhookSignatureMissing) {
+		t.Fatalf("missing header err = %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/hook", strings.NewReader(string(body)))
+	req.Header.Set(name, value)
+	if got, err := v.VerifyRequest(req, 0); err != nil || string(got) != string(body) {
+		t.Fatalf("VerifyRequest = %q, %v", got, err)
+	}
+	if b, _ := io.ReadAll(req.Body); string(b) != string(body) {
+		t.Fatal("body not readable after VerifyRequest")
+	}
+}
+
+func TestWebhookVerifier_BlankSecretRejected(t *testing.T) {
+	body := []byte(`{"action":"opened"}`)
+
+	// anyone can compute an hmac with an empty key, so a blank secret must never verify
+	forged := hmac.New(sha256.New, nil)
+	forged.Write(body)
+
+	h := http.Header{}
+	h.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(forged.Sum(nil)))
+
+	for _, secrets := range [][]string{{""}, {"  "}, {"real", ""}} {
+		v := &WebhookVerifier{Secrets: secrets}
+
+		if err := v.Verify(h, body); err == nil || errors.Is(err, ErrWebhookSignatureMismatch) {
+			t.Errorf("Verify with secrets %q err = %v", secrets, err)
+		}
+
+		if _, _, err := v.Sign(body, time.Time{}); err == nil {
+			t.Errorf("Sign with secrets %q expected error", secrets)
+		}
+	}
+}
+
+func TestWebhookVerifier_Stripe(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	v := &WebhookVerifier{Style: WebhookStyleStripe, Secrets: []string{"whsec_test"}, now: func() time.Time { return now }}
+	body := []byte(`{"id":"evt_1"}`)
+
+	name, value, err := v.Sign(body, now.Add(-time.Minute))
+	if err != nil || name != "Stripe-Signature" {
+		t.Fatalf("Sign = %s %v", name, err)
+	}
+
+	h := http.Header{}
+	h.Set(name, value+",v0=ignored")
+	if err := v.Verify(h, body); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+
+	_, old, _ := v.Sign(body, now.Add(-10*time.Minute))
+	h.Set(name, old)
+	if err := v.Verify(h, body); !errors.Is(err, ErrWebhookTimestampExpired) {
+		t.Fatalf("expired err = %v", err)
+	}
+
+	// timestamp swapped without re-signing must fail the signature, not pass the window check
+	h.Set(name, strings.Replace(old, "t="+strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10), "t="+strconv.FormatInt(now.Unix(), 10), 1))
+	if err := v.Verify(h, body); !errors.Is(err, ErrWebhookSignatureMismatch) {
+		t.Fatalf("forged timestamp err = %v", err)
+	}
+
+	h.Set(name, "v1=abcd")
+	if err := v.Verify(h, body); !errors.Is(err, ErrWebhookSignatureMissing) {
+		t.Fatalf("no timestamp err = %v", err)
+	}
+}
@@ -114,27 +114,15 @@ func DnsLookupIps(host string) (ipList []net.IP) {
 		return []net.IP{}
 	}
 
-	// early host validation to avoid resolver misuse
-	if !isValidHostInput(host) {
+	target, literal, ok := parseIPLookupHost(host)
+	if !ok {
 		log.Printf("DNS Lookup IP Failed for Host: invalid host '%s'", host)
 		return []net.IP{}
 	}
 
-	target := ParseHostFromURL(host)
-	if target == "" {
-		log.Printf("DNS Lookup IP Failed for Host: invalid host '%s'", host)
-		return []net.IP{}
-	}
-
-	target = strings.TrimSuffix(target, ".") // avoid trailing-dot queries that can fail on some resolvers
-
 	// short-circuit IP literals (including zone-stripped IPv6) to avoid needless DNS and zone failures
-	ipOnly := target
-	if zoneIdx := strings.Index(target, "%"); zoneIdx != -1 {
-		ipOnly = target[:zoneIdx]
-	}
-	if ip := net.ParseIP(ipOnly); ip != nil {
-		ipList = append(ipList, ip)
+	if literal != nil {
+		ipList = append(ipList, literal)
 		log.Printf("DNS Lookup IP Returned %v for Host: %s (literal)", ipList, host)
 		return ipList
 	}
@@ -191,72 +179,11 @@ func DnsLookupSrvs(host string) (ipList []string) {
 		return []string{}
 	}
 
-	raw := strings.TrimSpace(host)
-	if raw == "" || !isValidHostInput(raw) {
-		log.Printf("DNS Lookup SRV Failed for Host: invalid host '%s'", host)
-		return []string{}
-	}
-
-	var (
-		service string
-		proto   string
-		target  string
-	)
-
-	switch {
-	case strings.HasPrefix(raw, "_"):
-		// allow full SRV name (e.g., _ldap._tcp.example.com)
-		target = raw
-	case strings.Contains(raw, "://"):
-		// interpret scheme as service[+proto], default proto tcp (e.g., grpc://example.com or sip+udp://example.com)
-		parsed, err := url.Parse(raw)
-		if err != nil || parsed.Hostname() == "" {
-			log.Printf("DNS Lookup SRV Failed for Host: %v, %s", err, host)
-			return []string{}
-		}
-		schemeParts := strings.Split(parsed.Scheme, "+")
-		service = schemeParts[0]
-		proto = "tcp"
-		if len(schemeParts) > 1 && schemeParts[1] != "" {
-			proto = schemeParts[1]
-		}
-		target = parsed.Hostname()
-	default:
-		target = ParseHostFromURL(raw)
-	}
-
-	// parse _service._proto.domain before hostname validation so underscores don't invalidate full SRV inputs.
-	if service == "" && strings.HasPrefix(target, "_") {
-		parts := strings.SplitN(target, ".", 3)
-		if len(parts) >= 2 {
-			service = strings.TrimPrefix(parts[0], "_")
-			proto = strings.TrimPrefix(parts[1], "_")
-			if len(parts) == 3 {
-				target = parts[2]
-			} else {
-				target = ""
-			}
-		}
-	}
-
-	target = strings.TrimSuffix(target, ".") // prevent double-dot SRV queries
-
-	if target == "" || !isValidHostName(target) { // hostname validation (length/labels/control chars)
-		log.Printf("DNS Lookup SRV Failed for Host: invalid host '%s'", host)
-		return []string{}
-	}
-
-	// Validate service/proto after extraction.
-	if service == "" {
-		log.Printf("DNS Lookup SRV Failed for Host: service/proto missing for target '%s' (expected _service._proto.domain or scheme://host)", host)
+	service, proto, target, perr := parseSrvLookupHost(host)
+	if perr != nil {
+		log.Printf("DNS Lookup SRV Failed for Host: %v", perr)
 		return []string{}
 	}
-	if proto == "" {
-		proto = "tcp"
-	}
-
-	service = strings.ToLower(service) // normalize to avoid resolver mismatches
-	proto = strings.ToLower(proto)     // normalize to avoid resolver mismatches
 
 	// bounded, context-aware SRV lookup
 	_, addrs, err := net.DefaultResolver.LookupSRV(ctx, service, proto, target)
@@ -335,6 +262,92 @@ func DnsLookupSrvs(host string) (ipList []string) {
 	return ipList
 }
 
+// parseIPLookupHost validates host and extracts the name to resolve, trailing dot removed,
+// literal is set when host is an IP address (IPv6 zone stripped) that needs no DNS lookup
+func parseIPLookupHost(host string) (target string, literal net.IP, ok bool) {
+	// early host validation to avoid resolver misuse
+	if !isValidHostInput(host) {
+		return "", nil, false
+	}
+
+	target = ParseHostFromURL(host)
+	if target == "" {
+		return "", nil, false
+	}
+
+	target = strings.TrimSuffix(target, ".") // avoid trailing-dot queries that can fail on some resolvers
+
+	ipOnly := target
+	if zoneIdx := strings.Index(target, "%"); zoneIdx != -1 {
+		ipOnly = target[:zoneIdx]
+	}
+
+	return target, net.ParseIP(ipOnly), true
+}
+
+// parseSrvLookupHost splits an SRV lookup input into lower-cased service, proto (default tcp) and domain,
+// accepting _service._proto.domain or scheme[+proto]://domain forms
+func parseSrvLookupHost(host string) (service string, proto string, target string, err error) {
+	raw := strings.TrimSpace(host)
+	if raw == "" || !isValidHostInput(raw) {
+		return "", "", "", fmt.Errorf("invalid host '%s'", host)
+	}
+
+	switch {
+	case strings.HasPrefix(raw, "_"):
+		// allow full SRV name (e.g., _ldap._tcp.example.com)
+		target = raw
+	case strings.Contains(raw, "://"):
+		// interpret scheme as service[+proto], default proto tcp (e.g., grpc://example.com or sip+udp://example.com)
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Hostname() == "" {
+			return "", "", "", fmt.Errorf("%v, %s", err, host)
+		}
+		schemeParts := strings.Split(parsed.Scheme, "+")
+		service = schemeParts[0]
+		proto = "tcp"
+		if len(schemeParts) > 1 && schemeParts[1] != "" {
+			proto = schemeParts[1]
+		}
+		target = parsed.Hostname()
+	default:
+		target = ParseHostFromURL(raw)
+	}
+
+	// parse _service._proto.domain before hostname validation so underscores don't invalidate full SRV inputs.
+	if service == "" && strings.HasPrefix(target, "_") {
+		parts := strings.SplitN(target, ".", 3)
+		if len(parts) >= 2 {
+			service = strings.TrimPrefix(parts[0], "_")
+			proto = strings.TrimPrefix(parts[1], "_")
+			if len(parts) == 3 {
+				target = parts[2]
+			} else {
+				target = ""
+			}
+		}
+	}
+
+	target = strings.TrimSuffix(target, ".") // prevent double-dot SRV queries
+
+	if target == "" || !isValidHostName(target) { // hostname validation (length/labels/control chars)
+		return "", "", "", fmt.Errorf("invalid host '%s'", host)
+	}
+
+	// Validate service/proto after extraction.
+	if service == "" {
+		return "", "", "", fmt.Errorf("service/proto missing for target '%s' (expected _service._proto.domain or scheme://host)", host)
+	}
+	if proto == "" {
+		proto = "tcp"
+	}
+
+	service = strings.ToLower(service) // normalize to avoid resolver mismatches
+	proto = strings.ToLower(proto)     // normalize to avoid resolver mismatches
+
+	return service, proto, target, nil
+}
+
 // ParseHostFromURL will parse out the host name from url
 func ParseHostFromURL(u string) string {
 	trimmed := strings.TrimSpace(u)
@@ -302,7 +302,12 @@ func ReflectCall(o reflect.Value, methodName string, paramValue ...interface{})
 		return nil, true
 	}
 
-	method := o.MethodByName(methodName)
+	// method index lookups are cached per type, see lookupMethodIndex
+	var method reflect.Value
+
+	if idx := lookupMethodIndex(o.Type(), methodName); idx >= 0 {
+		method = o.Method(idx)
+	}
 
 	// guard invalid method to avoid panic on Kind()
 	if !method.IsValid() {
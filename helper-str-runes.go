@@ -0,0 +1,389 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+// Rune- and display-width-aware siblings of the byte-indexed string helpers.
+//
+// LenTrim, Left, Right, Mid and NextFixedLength are pinned to BYTE semantics
+// (see helper-str-contract_test.go and the README) and must stay that way for
+// crypto key derivation and fixed-width framing. The helpers in this file are
+// the additive companions for human-facing text (names, addresses, receipt
+// and email rendering), where slicing in the middle of a UTF-8 sequence
+// corrupts output:
+//
+//   - *Runes helpers count and slice by Unicode code point (rune)
+//   - *Display helpers and TruncateWithEllipsis measure in terminal / receipt
+//     columns, where East Asian Wide and Fullwidth characters (CJK, most emoji)
+//     occupy 2 columns and combining marks occupy 0, and never split a
+//     grapheme cluster (base character plus its combining marks, ZWJ emoji
+//     sequences, flags, skin-tone modifiers)
+//
+// Grapheme segmentation here is an approximation of UAX #29 extended grapheme
+// clusters that covers the sequences seen in payment and customer data; it
+// does not implement the full Indic conjunct or Hangul syllable rules.
+
+// ================================================================================================================
+// rune-indexed helpers
+// ================================================================================================================
+
+// LenTrimRunes returns the RUNE count of space trimmed string s.
+//
+// Rune sibling of LenTrim (which returns the byte length).
+func LenTrimRunes(s string) int {
+	return utf8.RuneCountInString(strings.TrimSpace(s))
+}
+
+// runeByteOffset returns the byte offset in s at which the n-th rune (0-based) starts,
+// if s holds n or fewer runes, len(s) is returned.
+func runeByteOffset(s string, n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	count := 0
+	for i := range s {
+		if count == n {
+			return i
+		}
+		count++
+	}
+
+	return len(s)
+}
+
+// LeftRunes returns the left side of string s holding l runes.
+//
+// Rune sibling of Left: l <= 0 returns blank, s holding l or fewer runes returns s.
+func LeftRunes(s string, l int) string {
+	if l <= 0 {
+		return ""
+	}
+
+	return s[:runeByteOffset(s, l)]
+}
+
+// RightRunes returns the right side of string s holding l runes.
+//
+// Rune sibling of Right: l <= 0 returns blank, s holding l or fewer runes returns s.
+func RightRunes(s string, l int) string {
+	if l <= 0 {
+		return ""
+	}
+
+	count := utf8.RuneCountInString(s)
+	if count <= l {
+		return s
+	}
+
+	return s[runeByteOffset(s, count-l):]
+}
+
+// MidRunes returns l runes of string s beginning at rune position start (0-based).
+//
+// Rune sibling of Mid: negative start or l <= 0 yields blank, start beyond the
+// rune count yields blank, and the end is clamped to the end of s.
+func MidRunes(s string, start int, l int) string {
+	if l <= 0 || start < 0 {
+		return ""
+	}
+
+	begin := runeByteOffset(s, start)
+	if begin >= len(s) {
+		return ""
+	}
+
+	rest := s[begin:]
+	return rest[:runeByteOffset(rest, l)]
+}
+
+// PadLeftRunes pads data with padChar (space if blank) to the left until it holds totalSize runes.
+func PadLeftRunes(data string, totalSize int, padChar ...string) string {
+	return Padding(data, totalSize, false, GetFirstStringOrDefault(" ", padChar...))
+}
+
+// PadRightRunes pads data with padChar (space if blank) to the right until it holds totalSize runes.
+func PadRightRunes(data string, totalSize int, padChar ...string) string {
+	return Padding(data, totalSize, true, GetFirstStringOrDefault(" ", padChar...))
+}
+
+// ================================================================================================================
+// grapheme and display-width helpers
+// ================================================================================================================
+
+const (
+	runeZWJ     = '\u200D'
+	runeVS15    = '\uFE0E'
+	runeVS16    = '\uFE0F'
+	runeKeycap  = '\u20E3'
+	runeCR      = '\r'
+	runeLF      = '\n'
+	runeTagBase = 0xE0020
+	runeTagEnd  = 0xE007F
+)
+
+// isRegionalIndicator reports whether r is one of the flag letters U+1F1E6..U+1F1FF.
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// isGraphemeExtend reports whether r attaches to the preceding rune rather than starting a new cluster.
+func isGraphemeExtend(r rune) bool {
+	switch {
+	case r == runeZWJ, r == runeKeycap:
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F: // variation selectors
+		return true
+	case r >= 0xE0100 && r <= 0xE01EF: // variation selectors supplement
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // emoji skin-tone modifiers
+		return true
+	case r >= runeTagBase && r <= runeTagEnd: // emoji tag sequence (subdivision flags)
+		return true
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+		return true
+	}
+
+	return false
+}
+
+// nextGrapheme returns the byte length of the grapheme cluster at the start of s.
+func nextGrapheme(s string) int {
+	if len(s) == 0 {
+		return 0
+	}
+
+	first, size := utf8.DecodeRuneInString(s)
+	pos := size
+
+	// CR LF is a single cluster
+	if first == runeCR && pos < len(s) && s[pos] == runeLF {
+		return pos + 1
+	}
+
+	prev := first
+	regionalCount := 0
+	if isRegionalIndicator(first) {
+		regionalCount = 1
+	}
+
+	for pos < len(s) {
+		r, n := utf8.DecodeRuneInString(s[pos:])
+
+		switch {
+		case prev == runeZWJ:
+			// rune joined by ZWJ belongs to the same emoji sequence
+		case isGraphemeExtend(r):
+		case regionalCount == 1 && isRegionalIndicator(r):
+			// second half of a flag pair
+			regionalCount = 2
+		default:
+			return pos
+		}
+
+		prev = r
+		pos += n
+	}
+
+	return pos
+}
+
+// Graphemes splits s into its user-perceived characters (grapheme clusters).
+func Graphemes(s string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+
+	var result []string
+	for len(s) > 0 {
+		n := nextGrapheme(s)
+		result = append(result, s[:n])
+		s = s[n:]
+	}
+
+	return result
+}
+
+// GraphemeCount returns the number of user-perceived characters (grapheme clusters) in s.
+func GraphemeCount(s string) int {
+	count := 0
+	for len(s) > 0 {
+		s = s[nextGrapheme(s):]
+		count++
+	}
+
+	return count
+}
+
+// runeDisplayWidth returns the display column width of a single rune.
+func runeDisplayWidth(r rune) int {
+	switch {
+	case r == 0:
+		return 0
+	case r < 0x20 || (r >= 0x7F && r < 0xA0):
+		// control characters occupy no column
+		return 0
+	case isGraphemeExtend(r):
+		return 0
+	case unicode.Is(unicode.Cf, r):
+		// format characters (soft hyphen, zero-width space, bidi marks) occupy no column
+		return 0
+	}
+
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+
+	return 1
+}
+
+// graphemeDisplayWidth returns the display column width of a single grapheme cluster g.
+func graphemeDisplayWidth(g string) int {
+	first, size := utf8.DecodeRuneInString(g)
+	w := runeDisplayWidth(first)
+
+	if isRegionalIndicator(first) {
+		// a flag pair renders as a single wide glyph
+		return 2
+	}
+
+	if w == 1 && size < len(g) {
+		// emoji presentation selector (VS16) promotes a narrow symbol to a wide emoji glyph,
+		// text presentation selector (VS15) keeps it narrow
+		rest := g[size:]
+		if strings.ContainsRune(rest, runeVS16) && !strings.ContainsRune(rest, runeVS15) {
+			return 2
+		}
+	}
+
+	return w
+}
+
+// DisplayWidth returns the number of terminal / receipt printer columns s occupies,
+// East Asian Wide and Fullwidth characters count as 2, combining marks and control characters count as 0.
+func DisplayWidth(s string) int {
+	total := 0
+	for len(s) > 0 {
+		n := nextGrapheme(s)
+		total += graphemeDisplayWidth(s[:n])
+		s = s[n:]
+	}
+
+	return total
+}
+
+// displayPrefix returns the longest prefix of s, cut on a grapheme boundary, whose display width
+// does not exceed maxWidth, along with that prefix's display width.
+func displayPrefix(s string, maxWidth int) (prefix string, prefixWidth int) {
+	pos := 0
+	for pos < len(s) {
+		n := nextGrapheme(s[pos:])
+		w := graphemeDisplayWidth(s[pos : pos+n])
+
+		if prefixWidth+w > maxWidth {
+			break
+		}
+
+		prefixWidth += w
+		pos += n
+	}
+
+	return s[:pos], prefixWidth
+}
+
+// LeftDisplay returns the left side of s that fits within maxWidth display columns,
+// never splitting a grapheme cluster or a wide character.
+func LeftDisplay(s string, maxWidth int) string {
+	if maxWidth <= 0 {
+		return ""
+	}
+
+	prefix, _ := displayPrefix(s, maxWidth)
+	return prefix
+}
+
+// paddingDisplay pads data with padChar until it occupies totalWidth display columns.
+func paddingDisplay(data string, totalWidth int, padRight bool, padChar []string) string {
+	pChar := " "
+	if c := GetFirstStringOrDefault("", padChar...); len(c) > 0 {
+		pChar = Graphemes(c)[0]
+	}
+
+	pWidth := graphemeDisplayWidth(pChar)
+	if pWidth <= 0 {
+		pChar, pWidth = " ", 1
+	}
+
+	diff := totalWidth - DisplayWidth(data)
+	if diff <= 0 {
+		return data
+	}
+
+	// a wide pad char that cannot fill an odd remainder is topped up with a single space
+	pad := strings.Repeat(pChar, diff/pWidth) + strings.Repeat(" ", diff%pWidth)
+	if padRight {
+		return data + pad
+	}
+
+	return pad + data
+}
+
+// PadLeftDisplay pads data with padChar (space if blank) to the left until it occupies totalWidth display columns,
+// use this instead of PadLeft when aligning CJK or emoji text in fixed-column receipt or console layouts.
+func PadLeftDisplay(data string, totalWidth int, padChar ...string) string {
+	return paddingDisplay(data, totalWidth, false, padChar)
+}
+
+// PadRightDisplay pads data with padChar (space if blank) to the right until it occupies totalWidth display columns,
+// use this instead of PadRight when aligning CJK or emoji text in fixed-column receipt or console layouts.
+func PadRightDisplay(data string, totalWidth int, padChar ...string) string {
+	return paddingDisplay(data, totalWidth, true, padChar)
+}
+
+// TruncateWithEllipsis shortens s so that it fits within maxWidth display columns,
+// appending ellipsis ("..." if not specified) when truncation occurs.
+//
+// The result never splits a grapheme cluster or a wide character, and its display width
+// never exceeds maxWidth; if maxWidth is too small to hold the ellipsis itself, the ellipsis
+// is truncated instead. s that already fits is returned unchanged.
+func TruncateWithEllipsis(s string, maxWidth int, ellipsis ...string) string {
+	if maxWidth <= 0 {
+		return ""
+	}
+
+	if DisplayWidth(s) <= maxWidth {
+		return s
+	}
+
+	e := GetFirstStringOrDefault("...", ellipsis...)
+	eWidth := DisplayWidth(e)
+
+	if eWidth >= maxWidth {
+		return LeftDisplay(e, maxWidth)
+	}
+
+	prefix, _ := displayPrefix(s, maxWidth-eWidth)
+	return prefix + e
+}
@@ -0,0 +1,254 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Contract tests for the rune / display-width string family in
+// helper-str-runes.go.
+//
+// These mirror helper-str-contract_test.go: for every byte-indexed helper
+// pinned there, the rune sibling is pinned here on the same inputs, so a
+// reader can see side by side where the two families agree (ASCII) and
+// where they deliberately diverge (multi-byte UTF-8). The byte-family
+// contracts themselves are NOT re-tested here and MUST NOT change.
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+// -----------------------------------------------------------------------
+// LenTrimRunes — counts runes; LenTrim keeps counting bytes.
+// -----------------------------------------------------------------------
+
+func TestLenTrimRunes_ASCII(t *testing.T) {
+	if got := LenTrimRunes("hello"); got != 5 {
+		t.Fatalf("LenTrimRunes(\"hello\") = %d, want 5", got)
+	}
+}
+
+func TestLenTrimRunes_MultibyteRune(t *testing.T) {
+	// "café" = 5 bytes, 4 runes. LenTrim returns 5; LenTrimRunes returns 4.
+	if got := LenTrimRunes("  café  "); got != 4 {
+		t.Fatalf("LenTrimRunes(\"  café  \") = %d, want 4", got)
+	}
+	if got := LenTrim("  café  "); got != 5 {
+		t.Fatalf("byte contract drifted: LenTrim(\"  café  \") = %d, want 5", got)
+	}
+}
+
+func TestLenTrimRunes_Empty(t *testing.T) {
+	if got := LenTrimRunes("   "); got != 0 {
+		t.Fatalf("LenTrimRunes(\"   \") = %d, want 0", got)
+	}
+}
+
+// -----------------------------------------------------------------------
+// LeftRunes / RightRunes / MidRunes — rune-indexed, never split UTF-8.
+// -----------------------------------------------------------------------
+
+func TestLeftRunes_MultibyteRune(t *testing.T) {
+	// Left("éabc", 2) returns "é" (2 bytes); LeftRunes returns 2 runes.
+	if got := LeftRunes("éabc", 2); got != "éa" {
+		t.Fatalf("LeftRunes(\"éabc\", 2) = %q, want \"éa\"", got)
+	}
+}
+
+func TestLeftRunes_NeverSplitsUTF8(t *testing.T) {
+	name := "José Müller 山田太郎"
+	for i := 0; i <= utf8.RuneCountInString(name)+1; i++ {
+		if got := LeftRunes(name, i); !utf8.ValidString(got) {
+			t.Fatalf("LeftRunes(%q, %d) = %q is not valid UTF-8", name, i, got)
+		}
+	}
+}
+
+func TestLeftRunes_Bounds(t *testing.T) {
+	if got := LeftRunes("山田", 0); got != "" {
+		t.Fatalf("LeftRunes(l=0) = %q, want \"\"", got)
+	}
+	if got := LeftRunes("山田", 10); got != "山田" {
+		t.Fatalf("LeftRunes(l>count) = %q, want original", got)
+	}
+}
+
+func TestRightRunes_MultibyteRune(t *testing.T) {
+	if got := RightRunes("abcé", 2); got != "cé" {
+		t.Fatalf("RightRunes(\"abcé\", 2) = %q, want \"cé\"", got)
+	}
+	if got := RightRunes("山田太郎", 2); got != "太郎" {
+		t.Fatalf("RightRunes(\"山田太郎\", 2) = %q, want \"太郎\"", got)
+	}
+	if got := RightRunes("ab", 5); got != "ab" {
+		t.Fatalf("RightRunes(l>count) = %q, want original", got)
+	}
+	if got := RightRunes("ab", -1); got != "" {
+		t.Fatalf("RightRunes(l<0) = %q, want \"\"", got)
+	}
+}
+
+func TestMidRunes_Contract(t *testing.T) {
+	cases := []struct {
+		s     string
+		start int
+		l     int
+		want  string
+	}{
+		{"日本語テキスト", 2, 3, "語テキ"},
+		{"café au lait", 3, 4, "é au"},
+		{"abc", 1, 99, "bc"},         // clamp end
+		{"abc", 3, 1, ""},            // start at end
+		{"abc", 10, 1, ""},           // start beyond end
+		{"abc", -1, 2, ""},           // negative start
+		{"abc", 0, 0, ""},            // zero length
+		{"", 0, 1, ""},               // empty input
+		{"éé", 1, 1, "é"},            // second rune
+		{"ééé", 0, 2, "éé"},          // first two runes
+		{"a山b", 1, 1, "山"},           // wide rune in middle
+		{"a\u0301b", 1, 1, "\u0301"}, // combining mark is its own rune
+	}
+
+	for _, c := range cases {
+		if got := MidRunes(c.s, c.start, c.l); got != c.want {
+			t.Errorf("MidRunes(%q, %d, %d) = %q, want %q", c.s, c.start, c.l, got, c.want)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------
+// PadLeftRunes / PadRightRunes — rune-count padding composes with LeftRunes.
+// -----------------------------------------------------------------------
+
+func TestPadRunes_ComposeWithLeftRunes(t *testing.T) {
+	// Documented cross-family caveat in Padding: Padding then Left may return
+	// fewer than N runes. With the rune family the composition is exact.
+	padded := PadRightRunes("café", 8)
+	if got := utf8.RuneCountInString(LeftRunes(padded, 8)); got != 8 {
+		t.Fatalf("LeftRunes(PadRightRunes(\"café\", 8), 8) rune count = %d, want 8", got)
+	}
+
+	if got := PadLeftRunes("42", 5, "0"); got != "00042" {
+		t.Fatalf("PadLeftRunes(\"42\", 5, \"0\") = %q, want \"00042\"", got)
+	}
+	if got := PadLeftRunes("é", 3); got != "  é" {
+		t.Fatalf("PadLeftRunes(\"é\", 3) = %q, want \"  é\"", got)
+	}
+}
+
+// -----------------------------------------------------------------------
+// Graphemes / DisplayWidth
+// -----------------------------------------------------------------------
+
+func TestGraphemeCount(t *testing.T) {
+	cases := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"e\u0301", 1},              // e + combining acute
+		{"\U0001F1FA\U0001F1F8", 1}, // US flag
+		{"\U0001F1FA\U0001F1F8\U0001F1EF\U0001F1F5", 2},   // US flag + JP flag
+		{"\U0001F44D\U0001F3FD", 1},                       // thumbs up + skin tone
+		{"\U0001F468\u200D\U0001F469\u200D\U0001F467", 1}, // family ZWJ sequence
+		{"1\uFE0F\u20E3", 1},                              // keycap 1
+		{"\r\n", 1},
+		{"山田", 2},
+	}
+
+	for _, c := range cases {
+		if got := GraphemeCount(c.s); got != c.want {
+			t.Errorf("GraphemeCount(%q) = %d, want %d", c.s, got, c.want)
+		}
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	cases := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{"café", 4},
+		{"e\u0301", 1},
+		{"山田太郎", 8},
+		{"ＡＢ", 4},                   // fullwidth latin
+		{"ｱｲ", 2},                   // halfwidth katakana
+		{"\U0001F600", 2},           // emoji
+		{"\U0001F1FA\U0001F1F8", 2}, // flag
+		{"❤\uFE0F", 2},              // heart with emoji presentation
+		{"a\u200Bb", 2},             // zero-width space
+		{"tab\t", 3},
+	}
+
+	for _, c := range cases {
+		if got := DisplayWidth(c.s); got != c.want {
+			t.Errorf("DisplayWidth(%q) = %d, want %d", c.s, got, c.want)
+		}
+	}
+}
+
+func TestPadDisplay_CJKAlignment(t *testing.T) {
+	// Receipt column alignment: "山田" occupies 4 columns, so padding to 6
+	// adds 2 spaces, not 4 (which rune-count padding would add).
+	if got := PadRightDisplay("山田", 6); got != "山田  " {
+		t.Fatalf("PadRightDisplay(\"山田\", 6) = %q, want \"山田  \"", got)
+	}
+	if got := PadLeftDisplay("abc", 6, "*"); got != "***abc" {
+		t.Fatalf("PadLeftDisplay(\"abc\", 6, \"*\") = %q, want \"***abc\"", got)
+	}
+	if got := PadLeftDisplay("abcdef", 3); got != "abcdef" {
+		t.Fatalf("PadLeftDisplay over width must return input, got %q", got)
+	}
+
+	// wide pad char with odd remainder is topped up with a space
+	got := PadRightDisplay("a", 4, "山")
+	if DisplayWidth(got) != 4 {
+		t.Fatalf("PadRightDisplay(\"a\", 4, \"山\") = %q, display width %d, want 4", got, DisplayWidth(got))
+	}
+}
+
+// -----------------------------------------------------------------------
+// TruncateWithEllipsis
+// -----------------------------------------------------------------------
+
+func TestTruncateWithEllipsis(t *testing.T) {
+	cases := []struct {
+		name     string
+		s        string
+		maxWidth int
+		ellipsis []string
+		want     string
+	}{
+		{"fits", "hello", 5, nil, "hello"},
+		{"ascii", "hello world", 8, nil, "hello..."},
+		{"custom", "hello world", 6, []string{"…"}, "hello…"},
+		{"cjk-no-half-char", "山田太郎様", 7, nil, "山田..."},
+		{"cjk-exact", "山田太郎様", 8, nil, "山田..."},
+		{"combining-kept", "e\u0301e\u0301e\u0301e\u0301", 3, []string{"."}, "e\u0301e\u0301."},
+		{"flag-not-split", "\U0001F1FA\U0001F1F8\U0001F1EF\U0001F1F5", 3, []string{"."}, "\U0001F1FA\U0001F1F8."},
+		{"tiny-width", "hello", 2, nil, ".."},
+		{"zero", "hello", 0, nil, ""},
+		{"empty-ellipsis", "hello", 3, []string{""}, "hel"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := TruncateWithEllipsis(c.s, c.maxWidth, c.ellipsis...)
+			if got != c.want {
+				t.Fatalf("TruncateWithEllipsis(%q, %d) = %q, want %q", c.s, c.maxWidth, got, c.want)
+			}
+			if DisplayWidth(got) > c.maxWidth {
+				t.Fatalf("result %q exceeds max width %d", got, c.maxWidth)
+			}
+			if !utf8.ValidString(got) {
+				t.Fatalf("result %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
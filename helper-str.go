@@ -205,9 +205,8 @@ func RightTrimLF(s string) string {
 //
 // Callers needing byte-counted padding should pad manually with
 // strings.Repeat against len(data). Callers needing rune-indexed
-// slicing (to pair with Padding cleanly) should operate on []rune(s)
-// directly — no rune-indexed Left/Right/Mid companion is provided in
-// v1.x.
+// slicing (to pair with Padding cleanly) should use the additive
+// LeftRunes/RightRunes/MidRunes companions in helper-str-runes.go.
 //
 // Rule #10 (workspace): preserve observable contracts across
 // minor-version bumps — the rune-count semantics here and the byte-index
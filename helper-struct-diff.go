@@ -0,0 +1,593 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// =====================================================================================================================
+// Struct Diff and Patch
+// =====================================================================================================================
+
+// StructFieldChange is a single field difference found by StructDiff, and the unit of change applied by ApplyStructPatch
+type StructFieldChange struct {
+	// Path is the JSON Pointer (RFC 6901) of the field built from its tag names, such as /address/zip
+	Path string `json:"path"`
+
+	// Field is the Go field path, such as Address.Zip
+	Field string `json:"field"`
+
+	// OldValue is the field value before the change, rendered as ReflectValueToString does (JSON for slices and maps)
+	OldValue string `json:"old"`
+
+	// NewValue is the field value after the change, rendered the same way as OldValue
+	NewValue string `json:"new"`
+}
+
+// structPatchOp is one RFC 6902 JSON Patch operation
+type structPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// structDiffTagName returns the name of field under tagName, ok is false if the field is excluded with "-"
+func structDiffTagName(field reflect.StructField, tagName string) (name string, ok bool) {
+	tag := field.Tag.Get(tagName)
+	if tag == "-" {
+		return "", false
+	}
+
+	if idx := strings.Index(tag, ","); idx >= 0 {
+		tag = tag[:idx]
+	}
+
+	if tag = Trim(tag); len(tag) == 0 {
+		return field.Name, true
+	}
+
+	return tag, true
+}
+
+// structDiffIsLeaf indicates if values of type t are compared and patched as a whole rather than field by field
+func structDiffIsLeaf(t reflect.Type) bool {
+	return !isValidateContainer(t)
+}
+
+// structDiffValueString renders o for a change record, slices, arrays and maps are rendered as JSON
+func structDiffValueString(o reflect.Value) (string, error) {
+	d := o
+	for d.Kind() == reflect.Ptr && !d.IsNil() {
+		d = d.Elem()
+	}
+
+	switch d.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if d.Kind() != reflect.Array && d.IsNil() {
+			return "", nil
+		}
+		b, err := json.Marshal(d.Interface())
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	s, _, err := ReflectValueToString(o, "", "", false, false, "", false)
+	return s, err
+}
+
+// escapeJSONPointer escapes a JSON Pointer reference token per RFC 6901
+func escapeJSONPointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+// StructDiff compares oldStruct to newStruct (both the same struct type, or pointers to it) field by field,
+// descending into nested structs and struct pointers, and returns one StructFieldChange per leaf field whose value differs,
+// suitable for audit trails written before a MySQL or DynamoDB update.
+//
+// tagName selects the struct tag naming each path element, json (default), db or dynamodbav for example;
+// fields tagged "-" and unexported fields are ignored, fields without the tag use the Go field name,
+// and embedded structs without a tag name are promoted into the parent path.
+//
+// Values are compared by their ReflectValueToString rendering, so a nil pointer and a pointer to a zero value
+// are reported as different only when they render differently; slices, arrays and maps are compared as whole values.
+// A nil oldStruct or newStruct pointer is treated as the zero value of the struct, to record creates and deletes.
+func StructDiff(oldStruct interface{}, newStruct interface{}, tagName ...string) ([]*StructFieldChange, error) {
+	tag := GetFirstStringOrDefault("json", tagName...)
+
+	ov, nv := reflect.ValueOf(oldStruct), reflect.ValueOf(newStruct)
+	if !ov.IsValid() || !nv.IsValid() {
+		return nil, fmt.Errorf("StructDiff Requires Old and New Struct Objects")
+	}
+
+	ot, nt := ov.Type(), nv.Type()
+	if ot.Kind() == reflect.Ptr {
+		ot = ot.Elem()
+	}
+	if nt.Kind() == reflect.Ptr {
+		nt = nt.Elem()
+	}
+
+	if ot != nt {
+		return nil, fmt.Errorf("StructDiff Requires Same Struct Type, Received %s and %s", ot, nt)
+	}
+	if ot.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("StructDiff Requires Struct Objects, Received %s", ot.Kind())
+	}
+
+	changes := make([]*StructFieldChange, 0)
+	visited := make(map[structDiffVisitKey]bool)
+	if err := structDiffWalk(structDiffDeref(ov, ot), structDiffDeref(nv, nt), ot, "", "", tag, &changes, visited); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// structDiffDeref dereferences v to a struct of type t, nil pointers yield the zero struct
+func structDiffDeref(v reflect.Value, t reflect.Type) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// structDiffVisitKey identifies an old / new struct pointer pair being walked, to break pointer cycles
+type structDiffVisitKey struct {
+	old, new uintptr
+	typ      reflect.Type
+}
+
+// structDiffPtr returns the address held by pointer v, 0 when v is not a pointer or is nil
+func structDiffPtr(v reflect.Value) uintptr {
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return 0
+	}
+	return v.Pointer()
+}
+
+// structDiffWalk appends the changes between struct values ov and nv of type t to changes,
+// visited holds the pointer pairs on the current path
+func structDiffWalk(ov reflect.Value, nv reflect.Value, t reflect.Type, path string, fieldPath string, tagName string, changes *[]*StructFieldChange, visited map[structDiffVisitKey]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !isExportedField(field) && !(field.Anonymous && field.Type.Kind() == reflect.Struct && !structDiffIsLeaf(field.Type)) {
+			continue // exported fields promoted from an unexported embedded struct remain reachable
+		}
+
+		name, ok := structDiffTagName(field, tagName)
+		if !ok {
+			continue
+		}
+
+		fPath := path + "/" + escapeJSONPointer(name)
+		fName := joinFieldPath(fieldPath, field.Name)
+
+		if !structDiffIsLeaf(field.Type) {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if field.Anonymous && len(Trim(strings.Split(field.Tag.Get(tagName), ",")[0])) == 0 {
+				fPath, fName = path, fieldPath
+			}
+
+			of, nf := ov.Field(i), nv.Field(i)
+
+			if field.Type.Kind() == reflect.Ptr {
+				key := structDiffVisitKey{old: structDiffPtr(of), new: structDiffPtr(nf), typ: ft}
+
+				// both nil has nothing to compare, and a pair already on the path is a cycle (type Node struct{ Next *Node })
+				if (key.old == 0 && key.new == 0) || visited[key] {
+					continue
+				}
+
+				visited[key] = true
+				err := structDiffWalk(structDiffDeref(of, ft), structDiffDeref(nf, ft), ft, fPath, fName, tagName, changes, visited)
+				delete(visited, key)
+
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := structDiffWalk(of, nf, ft, fPath, fName, tagName, changes, visited); err != nil {
+				return err
+			}
+			continue
+		}
+
+		oldStr, err := structDiffValueString(ov.Field(i))
+		if err != nil {
+			return fmt.Errorf("StructDiff %s Old Value: %w", fName, err)
+		}
+
+		newStr, err := structDiffValueString(nv.Field(i))
+		if err != nil {
+			return fmt.Errorf("StructDiff %s New Value: %w", fName, err)
+		}
+
+		if oldStr != newStr {
+			*changes = append(*changes, &StructFieldChange{
+				Path:     fPath,
+				Field:    fName,
+				OldValue: oldStr,
+				NewValue: newStr,
+			})
+		}
+	}
+
+	return nil
+}
+
+// ApplyStructPatch applies patch onto the struct pointed to by inputStructPtr, where patch is either:
+//
+//   - a change list as returned by StructDiff ([]*StructFieldChange or []StructFieldChange), each NewValue is set
+//     into the field at its Path
+//   - an RFC 6902 JSON Patch document (string, []byte or json.RawMessage), supporting add, remove, replace,
+//     move, copy and test operations
+//
+// Paths are JSON Pointers resolved by the tagName struct tag (json by default), exactly as StructDiff builds them;
+// slice elements are addressed by index, and add with index '-' appends. remove on a struct field resets it to its zero value.
+// Nil struct pointers along a path are allocated.
+//
+// Operations are applied in order, if an operation fails (including a failed test), its error is returned
+// and the operations before it remain applied.
+func ApplyStructPatch(inputStructPtr interface{}, patch interface{}, tagName ...string) error {
+	if inputStructPtr == nil {
+		return fmt.Errorf("InputStructPtr is Required")
+	}
+
+	s := reflect.ValueOf(inputStructPtr)
+	if s.Kind() != reflect.Ptr || s.IsNil() {
+		return fmt.Errorf("InputStructPtr Must Be Pointer")
+	}
+	s = s.Elem()
+	if s.Kind() != reflect.Struct {
+		return fmt.Errorf("InputStructPtr Must Be Struct")
+	}
+
+	tag := GetFirstStringOrDefault("json", tagName...)
+
+	switch p := patch.(type) {
+	case []*StructFieldChange:
+		for _, c := range p {
+			if c == nil {
+				continue
+			}
+			if err := applyStructFieldChange(s, c, tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []StructFieldChange:
+		for i := range p {
+			if err := applyStructFieldChange(s, &p[i], tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	case string:
+		return applyStructJSONPatch(s, []byte(p), tag)
+	case []byte:
+		return applyStructJSONPatch(s, p, tag)
+	case json.RawMessage:
+		return applyStructJSONPatch(s, p, tag)
+	case nil:
+		return fmt.Errorf("ApplyStructPatch Requires Patch")
+	default:
+		return fmt.Errorf("ApplyStructPatch Does Not Support Patch Type %T", patch)
+	}
+}
+
+// applyStructFieldChange sets c.NewValue into the field of s at c.Path
+func applyStructFieldChange(s reflect.Value, c *StructFieldChange, tagName string) error {
+	parent, token, err := resolveStructPatchParent(s, c.Path, tagName)
+	if err != nil {
+		return err
+	}
+
+	target, err := structPatchChild(parent, token, tagName, c.Path)
+	if err != nil {
+		return err
+	}
+
+	if err = setStructPatchString(target, c.NewValue); err != nil {
+		return fmt.Errorf("ApplyStructPatch %s: %w", c.Path, err)
+	}
+
+	return nil
+}
+
+// setStructPatchString assigns change value v to target, JSON for slices, arrays and maps, ReflectStringToField otherwise
+func setStructPatchString(target reflect.Value, v string) error {
+	d := target.Type()
+	for d.Kind() == reflect.Ptr {
+		d = d.Elem()
+	}
+
+	switch d.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if len(v) == 0 {
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		return setStructPatchJSON(target, []byte(v))
+	}
+
+	if len(v) == 0 && target.Kind() == reflect.Ptr {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+
+	return ReflectStringToField(target, v, "")
+}
+
+// setStructPatchJSON decodes JSON value raw into target
+func setStructPatchJSON(target reflect.Value, raw []byte) error {
+	nv := reflect.New(target.Type())
+	if err := json.Unmarshal(raw, nv.Interface()); err != nil {
+		return err
+	}
+
+	target.Set(nv.Elem())
+	return nil
+}
+
+// splitJSONPointer splits a JSON Pointer into its unescaped reference tokens
+func splitJSONPointer(pointer string) ([]string, error) {
+	if len(pointer) == 0 {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("JSON Pointer '%s' Must Start With '/'", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// resolveStructPatchParent walks s along all but the last token of pointer, allocating nil pointers,
+// and returns the container value with the last token
+func resolveStructPatchParent(s reflect.Value, pointer string, tagName string) (reflect.Value, string, error) {
+	tokens, err := splitJSONPointer(pointer)
+	if err != nil {
+		return reflect.Value{}, "", err
+	}
+	if len(tokens) == 0 {
+		return reflect.Value{}, "", fmt.Errorf("ApplyStructPatch Path '%s' Must Address a Field", pointer)
+	}
+
+	cur := s
+	for _, t := range tokens[:len(tokens)-1] {
+		if cur, err = structPatchChild(cur, t, tagName, pointer); err != nil {
+			return reflect.Value{}, "", err
+		}
+	}
+
+	return cur, tokens[len(tokens)-1], nil
+}
+
+// structPatchDeref dereferences v, allocating nil pointers
+func structPatchDeref(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// structPatchChild returns the child of container v addressed by token: a struct field by tag name, or a slice or array element
+func structPatchChild(v reflect.Value, token string, tagName string, pointer string) (reflect.Value, error) {
+	v = structPatchDeref(v)
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if f, ok := structPatchField(v, token, tagName); ok {
+			return f, nil
+		}
+	case reflect.Slice, reflect.Array:
+		if idx, err := strconv.Atoi(token); err == nil && idx >= 0 && idx < v.Len() {
+			return v.Index(idx), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("ApplyStructPatch Path '%s' Not Found at '%s'", pointer, token)
+}
+
+// structPatchField finds the field of struct v named token under tagName, including promoted embedded fields
+func structPatchField(v reflect.Value, token string, tagName string) (reflect.Value, bool) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !isExportedField(field) && !(field.Anonymous && field.Type.Kind() == reflect.Struct && !structDiffIsLeaf(field.Type)) {
+			continue // exported fields promoted from an unexported embedded struct remain reachable
+		}
+
+		name, ok := structDiffTagName(field, tagName)
+		if !ok {
+			continue
+		}
+
+		if field.Anonymous && !structDiffIsLeaf(field.Type) && len(Trim(strings.Split(field.Tag.Get(tagName), ",")[0])) == 0 {
+			if f, found := structPatchField(structPatchDeref(v.Field(i)), token, tagName); found {
+				return f, true
+			}
+			continue
+		}
+
+		if name == token {
+			return v.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// applyStructJSONPatch applies RFC 6902 JSON Patch document doc onto struct s
+func applyStructJSONPatch(s reflect.Value, doc []byte, tagName string) error {
+	var ops []structPatchOp
+	if err := json.Unmarshal(doc, &ops); err != nil {
+		return fmt.Errorf("ApplyStructPatch JSON Patch Not Valid: %w", err)
+	}
+
+	for i, op := range ops {
+		var err error
+
+		// op names are case sensitive per RFC 6902
+		switch op.Op {
+		case "add", "replace":
+			err = structPatchSet(s, op.Path, op.Value, tagName, op.Op == "add")
+		case "remove":
+			err = structPatchRemove(s, op.Path, tagName)
+		case "move", "copy":
+			var parent, src reflect.Value
+			var token string
+			if parent, token, err = resolveStructPatchParent(s, op.From, tagName); err == nil {
+				if src, err = structPatchChild(parent, token, tagName, op.From); err == nil {
+					// move is remove then add (RFC 6902 4.4), so array indexes in path apply after the removal
+					var raw []byte
+					if raw, err = json.Marshal(src.Interface()); err == nil && op.Op == "move" {
+						err = structPatchRemove(s, op.From, tagName)
+					}
+					if err == nil {
+						err = structPatchSet(s, op.Path, raw, tagName, true)
+					}
+				}
+			}
+		case "test":
+			err = structPatchTest(s, op.Path, op.Value, tagName)
+		default:
+			err = fmt.Errorf("op '%s' Not Supported", op.Op)
+		}
+
+		if err != nil {
+			return fmt.Errorf("ApplyStructPatch Operation %d (%s %s) Failed: %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// structPatchSet assigns JSON value raw at pointer, with add semantics inserting into slices
+func structPatchSet(s reflect.Value, pointer string, raw json.RawMessage, tagName string, add bool) error {
+	if len(raw) == 0 {
+		return fmt.Errorf("value is Required")
+	}
+
+	parent, token, err := resolveStructPatchParent(s, pointer, tagName)
+	if err != nil {
+		return err
+	}
+
+	container := structPatchDeref(parent)
+	if add && container.Kind() == reflect.Slice {
+		idx := container.Len()
+		if token != "-" {
+			if idx, err = strconv.Atoi(token); err != nil || idx < 0 || idx > container.Len() {
+				return fmt.Errorf("slice index '%s' Out of Range", token)
+			}
+		}
+
+		elem := reflect.New(container.Type().Elem()).Elem()
+		if err = setStructPatchJSON(elem, raw); err != nil {
+			return err
+		}
+
+		grown := reflect.Append(container, elem)
+		reflect.Copy(grown.Slice(idx+1, grown.Len()), grown.Slice(idx, grown.Len()-1))
+		grown.Index(idx).Set(elem)
+		container.Set(grown)
+		return nil
+	}
+
+	target, err := structPatchChild(parent, token, tagName, pointer)
+	if err != nil {
+		return err
+	}
+
+	return setStructPatchJSON(target, raw)
+}
+
+// structPatchRemove removes the slice element at pointer, or resets the struct field at pointer to its zero value
+func structPatchRemove(s reflect.Value, pointer string, tagName string) error {
+	parent, token, err := resolveStructPatchParent(s, pointer, tagName)
+	if err != nil {
+		return err
+	}
+
+	if container := structPatchDeref(parent); container.Kind() == reflect.Slice {
+		idx, err := strconv.Atoi(token)
+		if err != nil || idx < 0 || idx >= container.Len() {
+			return fmt.Errorf("slice index '%s' Out of Range", token)
+		}
+
+		container.Set(reflect.AppendSlice(container.Slice(0, idx), container.Slice(idx+1, container.Len())))
+		return nil
+	}
+
+	target, err := structPatchChild(parent, token, tagName, pointer)
+	if err != nil {
+		return err
+	}
+
+	target.Set(reflect.Zero(target.Type()))
+	return nil
+}
+
+// structPatchTest compares the value at pointer with JSON value raw
+func structPatchTest(s reflect.Value, pointer string, raw json.RawMessage, tagName string) error {
+	parent, token, err := resolveStructPatchParent(s, pointer, tagName)
+	if err != nil {
+		return err
+	}
+
+	target, err := structPatchChild(parent, token, tagName, pointer)
+	if err != nil {
+		return err
+	}
+
+	expected := reflect.New(target.Type())
+	if err = json.Unmarshal(raw, expected.Interface()); err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(target.Interface(), expected.Elem().Interface()) {
+		return fmt.Errorf("test Failed, Value Does Not Match")
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for StructDiff / ApplyStructPatch (helper-struct-diff.go).
+//
+// The key property pinned here is the audit round trip: applying the change
+// list StructDiff(old, new) onto a copy of old must yield new.
+
+import (
+	"reflect"
+	"testing"
+)
+
+type diffAddress struct {
+	Street string `json:"street" db:"street_line"`
+	Zip    string `json:"zip,omitempty" db:"zip_code"`
+}
+
+type diffAudit struct {
+	UpdatedBy string `json:"updated_by"`
+}
+
+type diffCustomer struct {
+	diffAudit
+	ID      int64        `json:"id" dynamodbav:"PK"`
+	Name    string       `json:"name" dynamodbav:"CustName"`
+	Active  bool         `json:"active"`
+	Balance float64      `json:"balance"`
+	Tags    []string     `json:"tags"`
+	Home    diffAddress  `json:"home"`
+	Work    *diffAddress `json:"work"`
+	Secret  string       `json:"-"`
+	NoTag   string
+}
+
+func TestStructDiff(t *testing.T) {
+	oldC := diffCustomer{ID: 1, Name: "Ann", Tags: []string{"a"}, Home: diffAddress{Street: "1 Main", Zip: "10001"}, Secret: "x"}
+	newC := oldC
+	newC.UpdatedBy = "ops"
+	newC.Name = "Anne"
+	newC.Active = true
+	newC.Tags = []string{"a", "b"}
+	newC.Home.Zip = "10002"
+	newC.Work = &diffAddress{Street: "9 Pier"}
+	newC.Secret = "y"
+	newC.NoTag = "z"
+
+	changes, err := StructDiff(&oldC, newC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []StructFieldChange{
+		{"/updated_by", "UpdatedBy", "", "ops"},
+		{"/name", "Name", "Ann", "Anne"},
+		{"/active", "Active", "false", "true"},
+		{"/tags", "Tags", `["a"]`, `["a","b"]`},
+		{"/home/zip", "Home.Zip", "10001", "10002"},
+		{"/work/street", "Work.Street", "", "9 Pier"},
+		{"/NoTag", "NoTag", "", "z"},
+	}
+
+	if len(changes) != len(want) {
+		t.Fatalf("StructDiff returned %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, w := range want {
+		if *changes[i] != w {
+			t.Errorf("change %d = %+v, want %+v", i, *changes[i], w)
+		}
+	}
+
+	// db tag names the path elements instead
+	changes, _ = StructDiff(oldC, newC, "db")
+	found := false
+	for _, c := range changes {
+		if c.Path == "/Home/zip_code" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("db tag path /Home/zip_code not found in %+v", changes)
+	}
+
+	if changes, _ = StructDiff(oldC, oldC); len(changes) != 0 {
+		t.Errorf("identical structs returned %+v", changes)
+	}
+
+	if _, err = StructDiff(oldC, diffAddress{}); err == nil {
+		t.Error("different struct types expected error")
+	}
+}
+
+func TestApplyStructPatch_ChangeListRoundTrip(t *testing.T) {
+	oldC := diffCustomer{ID: 1, Name: "Ann", Balance: 1.5, Home: diffAddress{Street: "1 Main"}}
+	newC := diffCustomer{ID: 2, Name: "Bob", Active: true, Balance: 20.25, Tags: []string{"vip"},
+		Home: diffAddress{Street: "2 Main", Zip: "94105"}, Work: &diffAddress{Zip: "10001"}}
+	newC.UpdatedBy = "ops"
+
+	for _, tag := range []string{"json", "dynamodbav"} {
+		changes, err := StructDiff(&oldC, &newC, tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		patched := oldC
+		if err = ApplyStructPatch(&patched, changes, tag); err != nil {
+			t.Fatalf("ApplyStructPatch(%s) failed: %v", tag, err)
+		}
+		if !reflect.DeepEqual(patched, newC) {
+			t.Errorf("ApplyStructPatch(%s) = %+v, want %+v", tag, patched, newC)
+		}
+	}
+}
+
+func TestApplyStructPatch_JSONPatch(t *testing.T) {
+	c := diffCustomer{Name: "Ann", Tags: []string{"a", "c"}, Home: diffAddress{Street: "1 Main", Zip: "10001"}}
+
+	patch := `[
+		{"op": "test", "path": "/name", "value": "Ann"},
+		{"op": "replace", "path": "/name", "value": "Anne"},
+		{"op": "add", "path": "/tags/1", "value": "b"},
+		{"op": "add", "path": "/tags/-", "value": "d"},
+		{"op": "remove", "path": "/tags/0"},
+		{"op": "copy", "from": "/home", "path": "/work"},
+		{"op": "replace", "path": "/work/street", "value": "9 Pier"},
+		{"op": "move", "from": "/home/zip", "path": "/updated_by"},
+		{"op": "add", "path": "/balance", "value": 12.5}
+	]`
+
+	if err := ApplyStructPatch(&c, patch); err != nil {
+		t.Fatalf("ApplyStructPatch failed: %v", err)
+	}
+
+	want := diffCustomer{Name: "Anne", Balance: 12.5, Tags: []string{"b", "c", "d"},
+		Home: diffAddress{Street: "1 Main"}, Work: &diffAddress{Street: "9 Pier", Zip: "10001"}}
+	want.UpdatedBy = "10001"
+
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("patched = %+v, want %+v", c, want)
+	}
+
+	for _, bad := range []string{
+		`[{"op": "test", "path": "/name", "value": "nope"}]`,
+		`[{"op": "replace", "path": "/missing", "value": 1}]`,
+		`[{"op": "replace", "path": "/name", "value": 1}]`,
+		`[{"op": "remove", "path": "/tags/9"}]`,
+		`[{"op": "bogus", "path": "/name"}]`,
+		`not json`,
+	} {
+		cp := want
+		if err := ApplyStructPatch(&cp, []byte(bad)); err == nil {
+			t.Errorf("ApplyStructPatch(%s) expected error", bad)
+		}
+	}
+
+	if err := ApplyStructPatch(c, patch); err == nil {
+		t.Error("non-pointer target expected error")
+	}
+	if err := ApplyStructPatch(&c, 42); err == nil {
+		t.Error("unsupported patch type expected error")
+	}
+}
+
+type diffNode struct {
+	Name string    `json:"name"`
+	Next *diffNode `json:"next"`
+}
+
+func TestStructDiff_SelfReferentialPointers(t *testing.T) {
+	// nil next pointers on both sides stop the walk
+	changes, err := StructDiff(&diffNode{Name: "a"}, &diffNode{Name: "b"})
+	if err != nil || len(changes) != 1 || changes[0].Path != "/name" {
+		t.Fatalf("changes = %+v, %v", changes, err)
+	}
+
+	// a one-sided chain is walked against zero values until it ends
+	changes, err = StructDiff(&diffNode{Name: "a"}, &diffNode{Name: "a", Next: &diffNode{Name: "b", Next: &diffNode{Name: "c"}}})
+	if err != nil || len(changes) != 2 || changes[0].Path != "/next/name" || changes[1].Path != "/next/next/name" {
+		t.Fatalf("chain changes = %+v, %v", changes, err)
+	}
+
+	// cycles are compared once along a path
+	o := &diffNode{Name: "a"}
+	o.Next = o
+	n := &diffNode{Name: "b"}
+	n.Next = n
+
+	changes, err = StructDiff(o, n)
+	if err != nil || len(changes) != 2 || changes[0].Path != "/name" || changes[1].Path != "/next/name" {
+		t.Fatalf("cycle changes = %+v, %v", changes, err)
+	}
+}
+
+func TestApplyStructPatch_JSONPatchOpCase(t *testing.T) {
+	// op names are case sensitive (RFC 6902), so other spellings are rejected
+	for _, patch := range []string{
+		`[{"op": "Add", "path": "/tags/1", "value": "b"}]`,
+		`[{"op": "MOVE", "from": "/tags/0", "path": "/name"}]`,
+	} {
+		c := diffCustomer{Tags: []string{"a", "c"}}
+		if err := ApplyStructPatch(&c, patch); err == nil {
+			t.Errorf("ApplyStructPatch(%s) expected error", patch)
+		}
+	}
+}
+
+func TestApplyStructPatch_JSONPatchArrayMove(t *testing.T) {
+	// RFC 6902 4.4: move removes from, then adds at path, so /tags/2 indexes the shortened array
+	c := diffCustomer{Tags: []string{"a", "b", "c"}}
+
+	if err := ApplyStructPatch(&c, `[{"op": "move", "from": "/tags/0", "path": "/tags/2"}]`); err != nil {
+		t.Fatalf("ApplyStructPatch failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(c.Tags, []string{"b", "c", "a"}) {
+		t.Fatalf("moved tags = %v, want [b c a]", c.Tags)
+	}
+
+	if err := ApplyStructPatch(&c, `[{"op": "move", "from": "/tags/2", "path": "/tags/0"}]`); err != nil || !reflect.DeepEqual(c.Tags, []string{"a", "b", "c"}) {
+		t.Fatalf("moved back tags = %v, %v", c.Tags, err)
+	}
+}
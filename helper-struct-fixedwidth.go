@@ -0,0 +1,482 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// =====================================================================================================================
+// Fixed Width Helpers
+// =====================================================================================================================
+
+// fixedWidthField is one positional field of a fixed width record layout
+type fixedWidthField struct {
+	index    int
+	field    reflect.StructField
+	cfg      csvFieldConfig
+	ucfg     csvUnmarshalConfig
+	offset   int
+	width    int
+	padRight bool
+	padChar  string
+	decimals int
+	truncate bool
+	numeric  bool
+	currency string
+}
+
+var reflectTypeMoney = reflect.TypeOf(Money{})
+
+// fixedWidthIsNumeric indicates if t (after pointer deref) renders as a number in fixed width records
+func fixedWidthIsNumeric(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == reflectTypeMoney {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// fixedWidthLayout builds the record layout of struct type t from its pos and size tags, ordered by pos
+func fixedWidthLayout(t reflect.Type) (layout []fixedWidthField, recordLen int, err error) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !isExportedField(field) {
+			continue
+		}
+
+		cfg, ok := csvParseFieldConfig(field)
+		if !ok {
+			continue
+		}
+		ucfg, _ := csvParseUnmarshalConfig(field)
+
+		if cfg.sizeMax <= 0 {
+			return nil, 0, fmt.Errorf("%s Requires size Tag For Fixed Width", field.Name)
+		}
+
+		f := fixedWidthField{
+			index:    i,
+			field:    field,
+			cfg:      cfg,
+			ucfg:     ucfg,
+			width:    cfg.sizeMax,
+			numeric:  cfg.tagType == "n" || fixedWidthIsNumeric(field.Type),
+			truncate: strings.ToLower(Trim(field.Tag.Get("truncate"))) == "true",
+			currency: GetFirstStringOrDefault(DefaultMoneyCurrency, Trim(field.Tag.Get("currency"))),
+		}
+
+		// numbers are right aligned and zero filled, text is left aligned and space filled, unless overridden
+		f.padRight = !f.numeric
+		switch strings.ToLower(Trim(field.Tag.Get("align"))) {
+		case "left":
+			f.padRight = true
+		case "right":
+			f.padRight = false
+		}
+
+		f.padChar = " "
+		if f.numeric {
+			f.padChar = "0"
+		}
+		if pc := field.Tag.Get("padchar"); len(pc) > 0 {
+			f.padChar = string([]rune(pc)[0])
+		}
+
+		if d := Trim(field.Tag.Get("decimals")); len(d) > 0 {
+			if f.decimals, ok = ParseInt32(d); !ok || f.decimals < 0 {
+				return nil, 0, fmt.Errorf("%s decimals Tag '%s' Is Not Valid", field.Name, d)
+			}
+		}
+
+		layout = append(layout, f)
+	}
+
+	if len(layout) == 0 {
+		return nil, 0, fmt.Errorf("Fixed Width Requires At Least One Field Tagged With pos and size")
+	}
+
+	sort.SliceStable(layout, func(i, j int) bool {
+		return layout[i].cfg.pos < layout[j].cfg.pos
+	})
+
+	for i := range layout {
+		if i > 0 && layout[i].cfg.pos == layout[i-1].cfg.pos {
+			return nil, 0, fmt.Errorf("%s and %s Share pos %d", layout[i-1].field.Name, layout[i].field.Name, layout[i].cfg.pos)
+		}
+
+		layout[i].offset = recordLen
+		recordLen += layout[i].width
+	}
+
+	return layout, recordLen, nil
+}
+
+// fixedWidthFormatValue renders field values that ReflectValueToString does not render positionally:
+// float values are scaled by decimals so the decimal point is implied, Money renders its minor units,
+// and time.Time honors timeFormat; handled is false for any other value
+func fixedWidthFormatValue(o reflect.Value, decimals int, timeFormat string) (v string, handled bool) {
+	for o.Kind() == reflect.Ptr {
+		if o.IsNil() {
+			return "", true
+		}
+		o = o.Elem()
+	}
+
+	switch o.Type() {
+	case reflectTypeMoney:
+		return Int64ToString(o.Interface().(Money).MinorUnits()), true
+	case reflectTypeTime:
+		tm := o.Interface().(time.Time)
+		if tm.IsZero() {
+			return "", true
+		}
+		if len(timeFormat) == 0 {
+			return "", false // RFC 3339 via encoding.TextMarshaler
+		}
+		return tm.Format(timeFormat), true
+	}
+
+	switch o.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if decimals > 0 {
+			return strings.Replace(strconv.FormatFloat(o.Float(), 'f', decimals, 64), ".", "", 1), true
+		}
+		return FloatToString(o.Float()), true
+	}
+
+	return "", false
+}
+
+// fixedWidthPad pads value v of layout field f to its width, returning an overflow error if v does not fit
+func fixedWidthPad(v string, f fixedWidthField) (string, error) {
+	l := utf8.RuneCountInString(v)
+
+	if l > f.width {
+		if f.truncate && !f.numeric {
+			return LeftRunes(v, f.width), nil
+		}
+		return "", fmt.Errorf("%s Value '%s' Exceeds Fixed Width of %d", f.field.Name, v, f.width)
+	}
+
+	// zero fill keeps the sign in front of the zeros
+	if !f.padRight && f.padChar == "0" && len(v) > 0 && (v[0] == '-' || v[0] == '+') {
+		return v[:1] + PadZero(v[1:], f.width-1), nil
+	}
+
+	return Padding(v, f.width, f.padRight, f.padChar), nil
+}
+
+// fixedWidthUnpad strips the padding of raw field value of layout field f, and restores the implied decimal point
+func fixedWidthUnpad(raw string, f fixedWidthField) string {
+	t := f.field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// zero filled text (type n routing or account numbers) keeps its leading zeros
+	if t.Kind() == reflect.String && f.padChar == "0" {
+		return Trim(raw)
+	}
+
+	v := raw
+	if f.padRight {
+		v = strings.TrimRight(v, f.padChar)
+	} else {
+		v = strings.TrimLeft(v, f.padChar)
+	}
+	v = Trim(v)
+
+	if !f.numeric {
+		return v
+	}
+
+	if len(v) == 0 {
+		if LenTrim(raw) > 0 {
+			return "0" // zero filled field of all zeros
+		}
+		return ""
+	}
+
+	sign := ""
+	if v[0] == '-' || v[0] == '+' {
+		sign, v = strings.TrimPrefix(v[:1], "+"), strings.TrimLeft(v[1:], f.padChar)
+	}
+	if len(v) == 0 {
+		v = "0" // field held only fill characters after the sign
+	}
+
+	if f.decimals > 0 && (t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64) {
+		v = PadZero(v, f.decimals+1)
+		v = v[:len(v)-f.decimals] + "." + v[len(v)-f.decimals:]
+	}
+
+	return sign + v
+}
+
+// MarshalStructToFixedWidth will serialize struct fields defined with struct tags below, to a fixed width (positional) record,
+// such as ACH / NACHA entries or processor settlement file records.
+//
+// Fields are laid out in ascending pos order, each occupying exactly size characters (x, or y of x..y),
+// numeric fields (int, uint, float, Money, or type n) default to right aligned with zero fill, other fields default to
+// left aligned with space fill; a value that does not fit its width fails with an overflow error rather than being cut.
+//
+// Width is counted in runes, consistent with Padding, so records holding only ASCII are also byte exact.
+//
+// Predefined Struct Tags Usable (in addition to MarshalStructToCSV tags type, regex, req, def, getter, booltrue, boolfalse,
+// timeformat, zeroblank and validate, which behave the same way):
+//  1. `pos:"1"`				// ordinal position of the field within the record, >= 0, must be unique
+//  2. `size:"x"`				// field width, required; for x..y the width is y, and x is the minimum value length
+//  3. `align:"left"`			// left or right, overrides the default alignment
+//  4. `padchar:"0"`			// fill character, overrides the default fill ('0' for numbers, space otherwise)
+//  5. `decimals:"2"`			// implied decimal places for float fields, 12.34 with decimals 2 renders as 1234;
+//     Money fields always render their minor units, int fields are rendered as is
+//  6. `truncate:"true"`		// for non-numeric fields, cut the value to the field width instead of failing with overflow error
+//  7. `currency:"EUR"`		// for Money fields, currency of the minor units when unmarshaling, DefaultMoneyCurrency if not set
+//
+// Negative numbers with zero fill keep the sign as the first character, such as -0001234
+func MarshalStructToFixedWidth(inputStructPtr interface{}) (record string, err error) {
+	if inputStructPtr == nil {
+		return "", fmt.Errorf("InputStructPtr is Required")
+	}
+
+	s := reflect.ValueOf(inputStructPtr)
+	if s.Kind() != reflect.Ptr {
+		return "", fmt.Errorf("InputStructPtr Must Be Pointer")
+	}
+	s = s.Elem()
+	if s.Kind() != reflect.Struct {
+		return "", fmt.Errorf("InputStructPtr Must Be Struct")
+	}
+
+	layout, recordLen, err := fixedWidthLayout(s.Type())
+	if err != nil {
+		return "", err
+	}
+
+	trueList := []string{"true", "yes", "on", "1", "enabled"}
+
+	var buf strings.Builder
+	buf.Grow(recordLen)
+
+	for _, f := range layout {
+		o := s.Field(f.index)
+		cfg := f.cfg
+
+		hasGetter := len(cfg.tagGetter) > 0
+		if hasGetter {
+			o = csvApplyGetter(s, cfg, o, cfg.boolTrue, cfg.boolFalse, false, false, cfg.timeFormat, cfg.zeroBlank)
+		}
+
+		fv, handled := fixedWidthFormatValue(o, f.decimals, cfg.timeFormat)
+		if !handled {
+			if fv, _, err = ReflectValueToString(o, cfg.boolTrue, cfg.boolFalse, false, false, cfg.timeFormat, cfg.zeroBlank); err != nil {
+				return "", fmt.Errorf("%s %s", f.field.Name, err.Error())
+			}
+		} else if cfg.zeroBlank && f.numeric {
+			if n, ok := ParseFloat64(fv); ok && n == 0 {
+				fv = ""
+			}
+		}
+
+		// width is enforced here as overflow, so normalization must not silently truncate at size max
+		normCfg := cfg
+		normCfg.sizeMax = 0
+		normCfg.tagModulo = 0
+
+		if fv, _, err = csvValidateAndNormalize(fv, normCfg, reflect.Value{}, hasGetter, trueList); err != nil {
+			return "", fmt.Errorf("%s %s", f.field.Name, err.Error())
+		}
+
+		if err = csvValidateCustom(fv, cfg, cfg.tagReq, s, f.field); err != nil {
+			return "", fmt.Errorf("%s %s", f.field.Name, err.Error())
+		}
+
+		if fv, err = fixedWidthPad(fv, f); err != nil {
+			return "", err
+		}
+
+		buf.WriteString(fv)
+	}
+
+	return buf.String(), nil
+}
+
+// UnmarshalFixedWidthToStruct will parse a fixed width (positional) record into struct fields,
+// using the same struct tags as MarshalStructToFixedWidth (pos, size, align, padchar, decimals),
+// plus UnmarshalCSVToStruct tags type, regex, req, def, setter, timeformat, booltrue, boolfalse, range and validate.
+//
+// Fill characters are stripped from the padded side of each field and surrounding spaces are trimmed,
+// a zero filled numeric field of all zeros yields 0, and float fields with decimals restore the implied decimal point;
+// Money fields receive the minor units in the currency given by `currency:"EUR"` tag, or DefaultMoneyCurrency.
+//
+// Trailing CR / LF is ignored, a record shorter than the layout is treated as space filled (right trimmed records),
+// a record longer than the layout fails; on any error, the struct fields are cleared.
+func UnmarshalFixedWidthToStruct(inputStructPtr interface{}, record string) error {
+	if inputStructPtr == nil {
+		return fmt.Errorf("InputStructPtr is Required")
+	}
+
+	s := reflect.ValueOf(inputStructPtr)
+	if s.Kind() != reflect.Ptr {
+		return fmt.Errorf("InputStructPtr Must Be Pointer")
+	}
+	s = s.Elem()
+	if s.Kind() != reflect.Struct {
+		return fmt.Errorf("InputStructPtr Must Be Struct")
+	}
+
+	layout, recordLen, err := fixedWidthLayout(s.Type())
+	if err != nil {
+		return err
+	}
+
+	record = strings.TrimRight(record, "\r\n")
+	if LenTrim(record) == 0 {
+		return fmt.Errorf("Fixed Width Record is Required")
+	}
+
+	if l := utf8.RuneCountInString(record); l > recordLen {
+		return fmt.Errorf("Fixed Width Record Length %d Exceeds Layout Length %d", l, recordLen)
+	}
+	record = PadRight(record, recordLen)
+
+	trueList := []string{"true", "yes", "on", "1", "enabled"}
+
+	StructClearFields(inputStructPtr)
+
+	fail := func(e error) error {
+		StructClearFields(inputStructPtr)
+		return e
+	}
+
+	for _, f := range layout {
+		o := s.Field(f.index)
+		if !o.CanSet() {
+			continue
+		}
+
+		cfg := f.ucfg
+		v := fixedWidthUnpad(MidRunes(record, f.offset, f.width), f)
+
+		if len(v) == 0 {
+			v = Trim(f.field.Tag.Get("def"))
+		}
+
+		// bool literal overrides
+		if LenTrim(cfg.boolTrue) > 0 && v == cfg.boolTrue {
+			v = "true"
+		} else if LenTrim(cfg.boolFalse) > 0 && v == cfg.boolFalse {
+			v = "false"
+		}
+
+		hasSetter := LenTrim(cfg.tagSetter) > 0
+		if v, err = csvPreprocessValue(v, cfg, hasSetter, trueList); err != nil {
+			return fail(fmt.Errorf("%s %s", f.field.Name, err.Error()))
+		}
+
+		newVal, setDone, err := csvApplySetter(s, cfg, o, v)
+		if err != nil {
+			return fail(err)
+		}
+		if setDone {
+			if v, _, err = ReflectValueToString(o, cfg.boolTrue, cfg.boolFalse, false, false, cfg.timeFormat, false); err != nil {
+				return fail(err)
+			}
+		} else {
+			v = newVal
+		}
+
+		if err = csvValidateValue(v, cfg, f.field.Name); err != nil {
+			return fail(err)
+		}
+		if err = csvValidateCustomUnmarshal(v, cfg, s, f.field.Name); err != nil {
+			return fail(err)
+		}
+
+		if setDone || len(v) == 0 {
+			continue
+		}
+
+		if err = fixedWidthSetField(o, v, cfg.timeFormat, f.currency); err != nil {
+			return fail(fmt.Errorf("%s %s", f.field.Name, err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// fixedWidthSetField assigns unpadded value v to field o, time.Time fields are parsed with timeFormat,
+// Money fields are set from minor units in currency
+func fixedWidthSetField(o reflect.Value, v string, timeFormat string, currency string) error {
+	target := o
+	for target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+
+	switch target.Type() {
+	case reflectTypeTime:
+		if len(timeFormat) == 0 {
+			return ReflectStringToField(o, v, timeFormat)
+		}
+
+		// time.Time is a TextUnmarshaler, which ReflectStringToField prefers over timeFormat
+		tm, err := time.Parse(timeFormat, v)
+		if err != nil {
+			return err
+		}
+
+		target.Set(reflect.ValueOf(tm))
+		return nil
+	case reflectTypeMoney:
+	default:
+		return ReflectStringToField(o, v, timeFormat)
+	}
+
+	minor, ok := ParseInt64(v)
+	if !ok {
+		return fmt.Errorf("Expects Minor Units, But Received '%s'", v)
+	}
+
+	m, err := NewMoney(minor, currency)
+	if err != nil {
+		return err
+	}
+
+	target.Set(reflect.ValueOf(m))
+	return nil
+}
@@ -0,0 +1,161 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for MarshalStructToFixedWidth / UnmarshalFixedWidthToStruct
+// (helper-struct-fixedwidth.go), modeled on a NACHA-style entry detail
+// record: every field must land at its exact offset, overflow must fail
+// rather than silently cut, and the record must round trip.
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type fixedWidthEntry struct {
+	RecordType  string    `pos:"0" size:"1" req:"true"`
+	Transaction int       `pos:"1" size:"2"`
+	Routing     string    `pos:"2" size:"9" type:"n" req:"true"`
+	Account     string    `pos:"3" size:"17"`
+	Amount      float64   `pos:"4" size:"10" decimals:"2"`
+	Name        string    `pos:"5" size:"22" truncate:"true"`
+	Fee         Money     `pos:"6" size:"6" currency:"USD"`
+	Adjust      int       `pos:"7" size:"5"`
+	Settle      time.Time `pos:"8" size:"6" timeformat:"060102"`
+	Code        string    `pos:"9" size:"4" align:"right" padchar:"*"`
+	Ignored     string
+}
+
+func TestFixedWidth_RoundTrip(t *testing.T) {
+	fee, _ := ParseMoney("1.25", "USD")
+
+	in := fixedWidthEntry{
+		RecordType:  "6",
+		Transaction: 22,
+		Routing:     "091000019",
+		Account:     "123456789",
+		Amount:      1234.56,
+		Name:        "Jane Q Customer With A Long Name",
+		Fee:         fee,
+		Adjust:      -42,
+		Settle:      time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC),
+		Code:        "AB",
+		Ignored:     "not in record",
+	}
+
+	rec, err := MarshalStructToFixedWidth(&in)
+	if err != nil {
+		t.Fatalf("MarshalStructToFixedWidth failed: %v", err)
+	}
+
+	want := "6" + "22" + "091000019" + "123456789        " + "0000123456" +
+		"Jane Q Customer With A" + "000125" + "-0042" + "260307" + "**AB"
+	if rec != want {
+		t.Fatalf("record mismatch\n got %q\nwant %q", rec, want)
+	}
+
+	var out fixedWidthEntry
+	if err = UnmarshalFixedWidthToStruct(&out, rec+"\r\n"); err != nil {
+		t.Fatalf("UnmarshalFixedWidthToStruct failed: %v", err)
+	}
+
+	if out.RecordType != "6" || out.Transaction != 22 || out.Routing != "091000019" || out.Account != "123456789" {
+		t.Errorf("text / int fields = %+v", out)
+	}
+	if out.Amount != 1234.56 {
+		t.Errorf("Amount = %v, want 1234.56", out.Amount)
+	}
+	if out.Name != "Jane Q Customer With A" {
+		t.Errorf("Name = %q", out.Name)
+	}
+	if !out.Fee.Equal(fee) {
+		t.Errorf("Fee = %v, want %v", out.Fee, fee)
+	}
+	if out.Adjust != -42 {
+		t.Errorf("Adjust = %d, want -42", out.Adjust)
+	}
+	if !out.Settle.Equal(in.Settle) {
+		t.Errorf("Settle = %v, want %v", out.Settle, in.Settle)
+	}
+	if out.Code != "AB" || out.Ignored != "" {
+		t.Errorf("Code = %q, Ignored = %q", out.Code, out.Ignored)
+	}
+}
+
+func TestFixedWidth_Overflow(t *testing.T) {
+	type rec struct {
+		ID     string  `pos:"0" size:"3"`
+		Amount float64 `pos:"1" size:"4" decimals:"2"`
+	}
+
+	if _, err := MarshalStructToFixedWidth(&rec{ID: "ABCD", Amount: 1}); err == nil || !strings.Contains(err.Error(), "Exceeds Fixed Width") {
+		t.Errorf("text overflow expected error, got %v", err)
+	}
+
+	// numbers are never truncated: 100.00 needs 5 digits
+	if _, err := MarshalStructToFixedWidth(&rec{ID: "A", Amount: 100}); err == nil {
+		t.Error("numeric overflow expected error")
+	}
+
+	if got, err := MarshalStructToFixedWidth(&rec{ID: "A", Amount: 0.05}); err != nil || got != "A  0005" {
+		t.Errorf("Marshal = %q, %v", got, err)
+	}
+}
+
+func TestFixedWidth_LayoutErrors(t *testing.T) {
+	type noSize struct {
+		ID string `pos:"0"`
+	}
+	if _, err := MarshalStructToFixedWidth(&noSize{ID: "x"}); err == nil {
+		t.Error("missing size expected error")
+	}
+
+	type dupPos struct {
+		A string `pos:"0" size:"1"`
+		B string `pos:"0" size:"1"`
+	}
+	if _, err := MarshalStructToFixedWidth(&dupPos{}); err == nil {
+		t.Error("duplicate pos expected error")
+	}
+
+	type noPos struct {
+		A string
+	}
+	if _, err := MarshalStructToFixedWidth(&noPos{}); err == nil {
+		t.Error("no positional fields expected error")
+	}
+}
+
+func TestFixedWidth_UnmarshalRecordLength(t *testing.T) {
+	type rec struct {
+		ID   string `pos:"0" size:"3" req:"true"`
+		Note string `pos:"1" size:"5"`
+		Qty  int    `pos:"2" size:"3"`
+	}
+
+	var out rec
+
+	// right trimmed record: missing tail is blank
+	if err := UnmarshalFixedWidthToStruct(&out, "AB"); err != nil || out.ID != "AB" || out.Note != "" || out.Qty != 0 {
+		t.Errorf("short record = %+v, %v", out, err)
+	}
+
+	if err := UnmarshalFixedWidthToStruct(&out, "ABCnote 000"); err != nil || out.Note != "note" || out.Qty != 0 {
+		t.Errorf("zero filled qty = %+v, %v", out, err)
+	}
+
+	if err := UnmarshalFixedWidthToStruct(&out, "ABCnote 0001"); err == nil {
+		t.Error("long record expected error")
+	}
+
+	out = rec{ID: "keep"}
+	if err := UnmarshalFixedWidthToStruct(&out, "   note 001"); err == nil || out.ID != "" {
+		t.Errorf("missing required field expected error and cleared struct, got %+v, %v", out, err)
+	}
+}
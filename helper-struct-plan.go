@@ -0,0 +1,232 @@
+package helper
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// =====================================================================================================================
+// Compiled Struct Plans
+// =====================================================================================================================
+
+// structPlan is the per-type metadata the struct marshal / unmarshal helpers used to rebuild on every call:
+// the struct fields, their raw tag values, and the parsed csv / json field configs.
+//
+// plans are compiled once per reflect.Type and shared by every helper and goroutine; each parsed config is
+// built lazily on first use so a type only ever marshaled to json never pays for (or logs) csv tag parsing.
+type structPlan struct {
+	fields []structFieldPlan
+
+	csvLenOnce sync.Once
+	csvLen     int
+	csvLenErr  error
+}
+
+// structFieldPlan holds the compiled metadata of a single top-level struct field.
+type structFieldPlan struct {
+	index int
+	field reflect.StructField
+	tags  map[string]string
+
+	csvOnce sync.Once
+	csvCfg  csvFieldConfig
+	csvOK   bool
+
+	csvUnmarshalOnce sync.Once
+	csvUnmarshalCfg  csvUnmarshalConfig
+	csvUnmarshalOK   bool
+
+	jsonOnce sync.Once
+	jsonCfg  jsonFieldConfig
+}
+
+// structPlanCache maps reflect.Type to *structPlan
+var structPlanCache sync.Map
+
+// structMethodCache maps structMethodKey to the method index, or -1 when the type has no such method
+var structMethodCache sync.Map
+
+type structMethodKey struct {
+	t    reflect.Type
+	name string
+}
+
+// getStructPlan returns the compiled plan for struct type t, compiling and caching it on first use.
+func getStructPlan(t reflect.Type) *structPlan {
+	if p, ok := structPlanCache.Load(t); ok {
+		return p.(*structPlan)
+	}
+
+	p := &structPlan{
+		fields: make([]structFieldPlan, t.NumField()),
+	}
+
+	for i := range p.fields {
+		f := t.Field(i)
+		p.fields[i].index = i
+		p.fields[i].field = f
+		p.fields[i].tags = parseStructTagValues(f.Tag)
+	}
+
+	actual, _ := structPlanCache.LoadOrStore(t, p)
+	return actual.(*structPlan)
+}
+
+// tag returns the value of the named struct tag, same as field.Tag.Get(name).
+func (pf *structFieldPlan) tag(name string) string {
+	return pf.tags[name]
+}
+
+// tagsValueSlice returns the named tag values with ",omitempty" removed, same as GetStructTagsValueSlice.
+func (pf *structFieldPlan) tagsValueSlice(names ...string) []string {
+	vs := make([]string, len(names))
+
+	for i, n := range names {
+		vs[i] = Replace(pf.tags[n], ",omitempty", "")
+	}
+
+	return vs
+}
+
+// csvConfig returns the cached csvParseFieldConfig result of the field.
+func (pf *structFieldPlan) csvConfig() (csvFieldConfig, bool) {
+	pf.csvOnce.Do(func() {
+		pf.csvCfg, pf.csvOK = csvParseFieldConfig(pf.field)
+	})
+
+	return pf.csvCfg, pf.csvOK
+}
+
+// csvUnmarshalConfig returns the cached csvParseUnmarshalConfig result of the field.
+func (pf *structFieldPlan) csvUnmarshalConfig() (csvUnmarshalConfig, bool) {
+	pf.csvUnmarshalOnce.Do(func() {
+		pf.csvUnmarshalCfg, pf.csvUnmarshalOK = csvParseUnmarshalConfig(pf.field)
+	})
+
+	return pf.csvUnmarshalCfg, pf.csvUnmarshalOK
+}
+
+// jsonConfig returns the cached jsonParseFieldConfig result of the field.
+func (pf *structFieldPlan) jsonConfig() jsonFieldConfig {
+	pf.jsonOnce.Do(func() {
+		pf.jsonCfg, _ = jsonParseFieldConfig(pf.field)
+	})
+
+	return pf.jsonCfg
+}
+
+// csvBufferLength returns the cached csvComputeBufferLength result of the plan's struct type.
+func (p *structPlan) csvBufferLength() (int, error) {
+	p.csvLenOnce.Do(func() {
+		maxPos := -1
+
+		for i := range p.fields {
+			if tagPos, ok := ParseInt32(p.fields[i].tag("pos")); ok && tagPos >= 0 && int(tagPos) > maxPos {
+				maxPos = int(tagPos)
+			}
+		}
+
+		if maxPos < 0 {
+			// fail fast when no positional tags are present to avoid silent empty csv output
+			p.csvLenErr = fmt.Errorf("csv marshal requires at least one field tagged with pos")
+		} else {
+			p.csvLen = maxPos + 1
+		}
+	})
+
+	return p.csvLen, p.csvLenErr
+}
+
+// lookupMethodIndex returns the index of the named method in t's method set, or -1 when not found;
+// lookups are cached so repeated getter / setter calls skip the method name search.
+func lookupMethodIndex(t reflect.Type, name string) int {
+	key := structMethodKey{t: t, name: name}
+
+	if idx, ok := structMethodCache.Load(key); ok {
+		return idx.(int)
+	}
+
+	idx := -1
+
+	if m, ok := t.MethodByName(name); ok {
+		idx = m.Index
+	}
+
+	structMethodCache.Store(key, idx)
+	return idx
+}
+
+// parseStructTagValues splits a struct tag into its key / value pairs, following the same rules as
+// reflect.StructTag.Lookup: the first occurrence of a key wins and parsing stops at the first malformed pair.
+func parseStructTagValues(tag reflect.StructTag) map[string]string {
+	m := make(map[string]string)
+
+	for tag != "" {
+		// skip leading space
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// scan to colon, a space, a quote or a control character is a syntax error
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		// scan quoted string to find value
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		qvalue := string(tag[:i+1])
+		tag = tag[i+1:]
+
+		if _, exists := m[name]; exists {
+			continue
+		}
+
+		// Lookup of a key whose value fails to unquote yields ""
+		value, err := strconv.Unquote(qvalue)
+		if err != nil {
+			value = ""
+		}
+
+		m[name] = value
+	}
+
+	return m
+}
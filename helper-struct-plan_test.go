@@ -0,0 +1,207 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests and benchmarks for the compiled struct plan cache (helper-struct-plan.go).
+//
+// The plan cache must be invisible in output: every test here compares the
+// cached helpers against their results on a type seen for the first time,
+// and the benchmarks measure the bulk CSV import / export path it speeds up.
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type planBenchRow struct {
+	MerchantID  string    `pos:"0" json:"merchant_id" type:"an" size:"1..20" req:"true"`
+	TerminalID  string    `pos:"1" json:"terminal_id" type:"an" size:"..16"`
+	BatchNo     int       `pos:"2" json:"batch_no" type:"n" range:"0..9999"`
+	SeqNo       int       `pos:"3" json:"seq_no" def:"1"`
+	CardType    string    `pos:"4" json:"card_type" validate:"==VISA||MC||AMEX||DISC"`
+	Last4       string    `pos:"5" json:"last4" type:"n" size:"4"`
+	Amount      float64   `pos:"6" json:"amount"`
+	Tip         float64   `pos:"7" json:"tip" zeroblank:"true"`
+	Approved    bool      `pos:"8" json:"approved" booltrue:"Y" boolfalse:"N"`
+	AuthCode    string    `pos:"9" json:"auth_code" type:"an" size:"..6"`
+	Reference   string    `pos:"10" json:"reference" type:"ans" size:"..32"`
+	Currency    string    `pos:"11" json:"currency" def:"USD" size:"3"`
+	EntryMode   string    `pos:"12" json:"entry_mode" def:"chip"`
+	Note        string    `pos:"13" json:"note" skipblank:"true"`
+	Settled     time.Time `pos:"14" json:"settled" timeformat:"20060102"`
+	StoreNumber int64     `pos:"15" json:"store_number"`
+	Region      string    `pos:"16" json:"region" type:"a"`
+	Clerk       string    `pos:"17" json:"clerk"`
+	Shift       int       `pos:"18" json:"shift" range:"1..3" def:"1"`
+	Flags       string    `pos:"19" json:"flags" type:"h"`
+}
+
+func newPlanBenchRow() *planBenchRow {
+	return &planBenchRow{
+		MerchantID: "M1234567", TerminalID: "T001", BatchNo: 42, SeqNo: 7, CardType: "VISA", Last4: "4242",
+		Amount: 12.34, Tip: 2, Approved: true, AuthCode: "A1B2C3", Reference: "ref-0001", Currency: "USD",
+		EntryMode: "chip", Note: "n", Settled: time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC), StoreNumber: 99,
+		Region: "West", Clerk: "ann", Shift: 2, Flags: "0f",
+	}
+}
+
+func BenchmarkMarshalStructToCSV(b *testing.B) {
+	row := newPlanBenchRow()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := MarshalStructToCSV(row, ","); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalCSVToStruct(b *testing.B) {
+	payload, err := MarshalStructToCSV(newPlanBenchRow(), ",")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	row := &planBenchRow{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := UnmarshalCSVToStruct(row, payload, ",", nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalStructToJson(b *testing.B) {
+	row := newPlanBenchRow()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := MarshalStructToJson(row, "json", ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalJsonToStruct(b *testing.B) {
+	payload, err := MarshalStructToJson(newPlanBenchRow(), "json", "")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	row := &planBenchRow{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := UnmarshalJsonToStruct(row, payload, "json", ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalStructToQueryParams(b *testing.B) {
+	row := newPlanBenchRow()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := MarshalStructToQueryParams(row, "json", ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSetStructFieldDefaultValues(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		row := &planBenchRow{}
+		if _, err := SetStructFieldDefaultValues(row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestStructPlan_OutputStable(t *testing.T) {
+	// first call compiles the plan, the remaining calls must produce identical payloads from the cache
+	var csv, js, qp []string
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			c, err1 := MarshalStructToCSV(newPlanBenchRow(), ",")
+			j, err2 := MarshalStructToJson(newPlanBenchRow(), "json", "")
+			q, err3 := MarshalStructToQueryParams(newPlanBenchRow(), "json", "")
+
+			if err1 != nil || err2 != nil || err3 != nil {
+				t.Errorf("marshal errors: %v, %v, %v", err1, err2, err3)
+			}
+
+			mu.Lock()
+			csv, js, qp = append(csv, c), append(js, j), append(qp, q)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for i := 1; i < len(csv); i++ {
+		if csv[i] != csv[0] || js[i] != js[0] || qp[i] != qp[0] {
+			t.Fatalf("cached output differs between calls")
+		}
+	}
+
+	if !strings.HasPrefix(csv[0], "M1234567,T001,42,7,VISA,4242,12.34,2,Y,") {
+		t.Fatalf("MarshalStructToCSV = %q", csv[0])
+	}
+
+	row := &planBenchRow{}
+	if err := UnmarshalCSVToStruct(row, csv[0], ",", nil); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := MarshalStructToCSV(row, ","); again != csv[0] {
+		t.Fatalf("csv round trip = %q, want %q", again, csv[0])
+	}
+
+	row = &planBenchRow{}
+	if err := UnmarshalJsonToStruct(row, js[0], "json", ""); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := MarshalStructToJson(row, "json", ""); again != js[0] {
+		t.Fatalf("json round trip = %q, want %q", again, js[0])
+	}
+
+	row = &planBenchRow{}
+	if _, err := SetStructFieldDefaultValues(row); err != nil || row.Currency != "USD" || row.Shift != 1 {
+		t.Fatalf("SetStructFieldDefaultValues = %+v, %v", row, err)
+	}
+}
+
+func TestParseStructTagValues_MatchesLookup(t *testing.T) {
+	tags := []reflect.StructTag{
+		``,
+		`json:"a,omitempty" pos:"1"`,
+		`json:"a" json:"b"`,
+		`  size:"..5"   def:"x y"`,
+		`esc:"a\"b" next:"c"`,
+		`bad:"\x" next:"c"`,
+		`json:"a" broken next:"c"`,
+		`json:"a"pos:"2"`,
+	}
+
+	names := []string{"json", "pos", "size", "def", "esc", "next", "bad", "broken"}
+
+	for _, tag := range tags {
+		m := parseStructTagValues(tag)
+
+		for _, n := range names {
+			if got, want := m[n], tag.Get(n); got != want {
+				t.Errorf("tag %q key %s = %q, want %q", tag, n, got, want)
+			}
+		}
+	}
+}
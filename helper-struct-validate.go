@@ -0,0 +1,423 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// =====================================================================================================================
+// Struct Validation
+// =====================================================================================================================
+
+// StructFieldError describes a single struct tag rule violation found by ValidateStruct
+type StructFieldError struct {
+	// Path is the field path from the root struct, such as Items[2].Sku
+	Path string
+
+	// Field is the struct field name of the violating field
+	Field string
+
+	// Rule is the struct tag that was violated: req, type, regex, size, range or validate
+	Rule string
+
+	// Value is the offending field value rendered as string
+	Value string
+
+	// Message describes the violation
+	Message string
+}
+
+// Error returns the field path followed by the violation message
+func (e *StructFieldError) Error() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.Path + " " + e.Message
+}
+
+// StructValidationErrors is the multi-error returned by ValidateStruct,
+// holding every rule violation found, in struct field order
+type StructValidationErrors []*StructFieldError
+
+// Error returns all violations joined by '; '
+func (e StructValidationErrors) Error() string {
+	if len(e) == 0 {
+		return ""
+	}
+
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Error())
+	}
+
+	return fmt.Sprintf("ValidateStruct Failed: %d Violation(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Unwrap exposes each violation to errors.Is / errors.As
+func (e StructValidationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, fe := range e {
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// ByPath returns the violations recorded against the given field path
+func (e StructValidationErrors) ByPath(path string) []*StructFieldError {
+	var result []*StructFieldError
+	for _, fe := range e {
+		if fe.Path == path {
+			result = append(result, fe)
+		}
+	}
+	return result
+}
+
+// ValidateStruct evaluates the validation struct tags shared by the Marshal / Unmarshal helpers
+// against the current field values of obj (struct or pointer to struct), descending into nested structs,
+// struct pointers, and slices or arrays; every violation is collected rather than stopping at the first one.
+//
+// nil is returned when obj is valid, StructValidationErrors is returned when one or more rules are violated,
+// any other error indicates obj itself could not be validated.
+//
+// Typical use is right after binding input, for example following gin BindPostForm or UnmarshalCSVToStruct,
+// so that the caller receives the complete list of offending field paths in one response.
+//
+// Struct tags evaluated (same syntax as MarshalStructToCSV / UnmarshalCSVToStruct):
+//  1. `req:"true"`			// value must not be blank; nil pointer and empty slice are blank, numeric 0 and false are not
+//  2. `def:""`				// blank value is evaluated as this default, matching marshal behavior
+//  3. `type:"xyz"`			// a, n, an, ans, h, b64: string value must only contain characters of the given type
+//     (where marshal silently extracts, ValidateStruct reports the value as a violation instead)
+//  4. `regex:"xyz"`			// with type regex, string value must not contain any regex match
+//  5. `size:"x..y"`			// length in bytes of string value, x, x.., ..y, x..y, and +%z modulo
+//  6. `range:"x..y"`			// numeric bounds, evaluated for type n and for int, uint and float fields
+//  7. `validate:"==x"`		// ==, !=, <=, <<, >=, >> and := rules, see UnmarshalCSVToStruct
+//
+// Rules other than req are skipped when the value is blank and req is not true; a struct field without
+// any of these tags is descended into, unless it renders as a value itself (time.Time, fmt.Stringer, encoding.TextMarshaler).
+// Slice or array elements are validated against the slice field's own tags, with the path suffixed by [index];
+// req applies to the slice itself rather than to each element.
+func ValidateStruct(obj interface{}) error {
+	if obj == nil {
+		return fmt.Errorf("ValidateStruct Requires Struct Object")
+	}
+
+	v := reflect.ValueOf(obj)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return fmt.Errorf("ValidateStruct Requires Struct Object, Received Nil Pointer")
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("ValidateStruct Requires Struct Object, Received %s", v.Kind().String())
+	}
+
+	if !v.CanAddr() {
+		// := validate methods are invoked on the struct pointer, so work on an addressable copy
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		v = cp
+	}
+
+	sv := &structValidator{visited: make(map[structVisitKey]bool)}
+	sv.validateStruct(v, "")
+
+	if len(sv.errs) > 0 {
+		return sv.errs
+	}
+
+	return nil
+}
+
+// structVisitKey identifies an already visited struct to break pointer cycles
+type structVisitKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// structValidator carries the accumulated violations during a single ValidateStruct walk
+type structValidator struct {
+	errs    StructValidationErrors
+	visited map[structVisitKey]bool
+}
+
+var (
+	reflectTypeTime          = reflect.TypeOf(time.Time{})
+	reflectTypeStringer      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	reflectTypeTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// add records a violation
+func (sv *structValidator) add(path string, field string, rule string, value string, format string, a ...interface{}) {
+	sv.errs = append(sv.errs, &StructFieldError{
+		Path:    path,
+		Field:   field,
+		Rule:    rule,
+		Value:   value,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// hasValidationTag indicates if field declares any tag evaluated by ValidateStruct
+func hasValidationTag(field reflect.StructField) bool {
+	for _, t := range []string{"req", "type", "size", "range", "validate"} {
+		if _, ok := field.Tag.Lookup(t); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidateContainer indicates if t is a struct type to be descended into rather than evaluated as a value
+func isValidateContainer(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || t == reflectTypeTime {
+		return false
+	}
+
+	if t.Implements(reflectTypeStringer) || reflect.PointerTo(t).Implements(reflectTypeStringer) {
+		return false
+	}
+
+	if t.Implements(reflectTypeTextMarshaler) || reflect.PointerTo(t).Implements(reflectTypeTextMarshaler) {
+		return false
+	}
+
+	return true
+}
+
+// joinFieldPath appends name to parent path using dot notation
+func joinFieldPath(parent string, name string) string {
+	if len(parent) == 0 {
+		return name
+	}
+	return parent + "." + name
+}
+
+// validateStruct walks the exported fields of struct s, s must be addressable
+func (sv *structValidator) validateStruct(s reflect.Value, path string) {
+	key := structVisitKey{addr: s.Addr().Pointer(), typ: s.Type()}
+	if sv.visited[key] {
+		return
+	}
+	sv.visited[key] = true
+
+	for i := 0; i < s.NumField(); i++ {
+		field := s.Type().Field(i)
+
+		if !isExportedField(field) {
+			continue
+		}
+
+		o := s.Field(i)
+		fieldPath := joinFieldPath(path, field.Name)
+
+		// embedded struct fields are promoted, so keep the parent path
+		if field.Anonymous && !hasValidationTag(field) && isValidateContainer(field.Type) {
+			if e, ok := derefValidateValue(o); ok {
+				sv.validateStruct(e, path)
+			}
+			continue
+		}
+
+		cfg, _ := jsonParseFieldConfig(field)
+		defVal := field.Tag.Get("def")
+
+		switch {
+		case (o.Kind() == reflect.Slice && o.Type().Elem().Kind() != reflect.Uint8) || o.Kind() == reflect.Array:
+			if cfg.tagReq == "true" && o.Len() == 0 {
+				sv.add(fieldPath, field.Name, "req", "", "is a Required Field")
+				continue
+			}
+
+			elemContainer := isValidateContainer(o.Type().Elem())
+			for j := 0; j < o.Len(); j++ {
+				elemPath := fmt.Sprintf("%s[%d]", fieldPath, j)
+
+				if elemContainer {
+					if e, ok := derefValidateValue(o.Index(j)); ok {
+						sv.validateStruct(e, elemPath)
+					}
+				} else if hasValidationTag(field) {
+					elemCfg := cfg
+					elemCfg.tagReq = "" // req is evaluated on the slice itself
+					sv.validateValue(s, o.Index(j), field, elemPath, elemCfg, defVal)
+				}
+			}
+
+		case isValidateContainer(field.Type):
+			e, ok := derefValidateValue(o)
+			if !ok {
+				if cfg.tagReq == "true" {
+					sv.add(fieldPath, field.Name, "req", "", "is a Required Field")
+				}
+				continue
+			}
+
+			sv.validateStruct(e, fieldPath)
+
+		case hasValidationTag(field):
+			sv.validateValue(s, o, field, fieldPath, cfg, defVal)
+		}
+	}
+}
+
+// derefValidateValue dereferences pointers, returning false when a nil pointer is encountered
+func derefValidateValue(o reflect.Value) (reflect.Value, bool) {
+	for o.Kind() == reflect.Ptr {
+		if o.IsNil() {
+			return reflect.Value{}, false
+		}
+		o = o.Elem()
+	}
+	return o, o.IsValid()
+}
+
+// validateValue evaluates the tag rules of a single value o belonging to struct s
+func (sv *structValidator) validateValue(s reflect.Value, o reflect.Value, field reflect.StructField, path string, cfg jsonFieldConfig, defVal string) {
+	val, _, err := ReflectValueToString(o, cfg.boolTrue, cfg.boolFalse, false, false, cfg.timeFormat, false)
+	if err != nil {
+		sv.add(path, field.Name, "type", "", "Value Could Not Be Read: %s", err.Error())
+		return
+	}
+
+	if e, ok := derefValidateValue(o); ok && e.Type() == reflectTypeTime && e.Interface().(time.Time).IsZero() {
+		val = "" // zero time is blank for req purposes
+	}
+
+	if len(val) == 0 && len(defVal) > 0 {
+		val = defVal
+	}
+
+	if len(val) == 0 {
+		if cfg.tagReq == "true" {
+			sv.add(path, field.Name, "req", val, "is a Required Field")
+		}
+		return
+	}
+
+	kind := o.Kind()
+	if kind == reflect.Ptr {
+		if e, ok := derefValidateValue(o); ok {
+			kind = e.Kind()
+		}
+	}
+
+	isNumericKind := false
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		isNumericKind = true
+	}
+
+	// type, regex and size apply to text values, numeric fields are already of the right shape
+	if !isNumericKind && kind != reflect.Bool {
+		sv.validateText(val, field, path, cfg)
+	}
+
+	if cfg.tagType == "n" || isNumericKind {
+		sv.validateRange(val, field, path, cfg)
+	}
+
+	// reuse the json validate rule evaluator, its message is prefixed with the blank field name
+	if err := jsonValidateCustom(val, cfg, s, ""); err != nil {
+		sv.add(path, field.Name, "validate", val, "%s", Trim(err.Error()))
+	}
+}
+
+// validateText evaluates the type, regex and size tag rules against text value val
+func (sv *structValidator) validateText(val string, field reflect.StructField, path string, cfg jsonFieldConfig) {
+	var extracted string
+	var err error
+
+	switch cfg.tagType {
+	case "a":
+		extracted, err = ExtractAlpha(val)
+	case "n":
+		extracted, err = ExtractNumeric(val)
+	case "an":
+		extracted, err = ExtractAlphaNumeric(val)
+	case "ans":
+		extracted, err = ExtractAlphaNumericPrintableSymbols(val)
+	case "h":
+		extracted, err = ExtractHex(val)
+	case "regex":
+		if extracted, err = ExtractByRegex(val, cfg.tagRegEx); err != nil {
+			sv.add(path, field.Name, "regex", val, "Regex '%s' Is Not Valid: %s", cfg.tagRegEx, err.Error())
+		} else if extracted != val {
+			sv.add(path, field.Name, "regex", val, "Contains Characters Matching Regex '%s'", cfg.tagRegEx)
+		}
+	case "b64":
+		if _, err = extractBase64Safe(val); err != nil {
+			sv.add(path, field.Name, "type", val, "Expects Base64 Value")
+		}
+	default:
+		extracted = val
+	}
+
+	switch cfg.tagType {
+	case "a", "n", "an", "ans", "h":
+		if err != nil || extracted != val {
+			sv.add(path, field.Name, "type", val, "Contains Characters Not Allowed By Type '%s'", cfg.tagType)
+		}
+	}
+
+	if cfg.sizeMin > 0 && len(val) < int(cfg.sizeMin) {
+		sv.add(path, field.Name, "size", val, "Min Length is %d", cfg.sizeMin)
+	}
+
+	if cfg.sizeMax > 0 && len(val) > int(cfg.sizeMax) {
+		sv.add(path, field.Name, "size", val, "Max Length is %d", cfg.sizeMax)
+	}
+
+	if cfg.tagModulo > 0 && len(val)%int(cfg.tagModulo) != 0 {
+		sv.add(path, field.Name, "size", val, "Expects Value In Blocks of %d Characters", cfg.tagModulo)
+	}
+}
+
+// validateRange evaluates the range tag rule against numeric value val
+func (sv *structValidator) validateRange(val string, field reflect.StructField, path string, cfg jsonFieldConfig) {
+	if !cfg.rangeMinSet && !cfg.rangeMaxSet {
+		return
+	}
+
+	n, ok := ParseFloat64(val)
+	if !ok {
+		// non-numeric text of type n is already reported by validateText
+		return
+	}
+
+	if cfg.rangeMinSet && n < float64(cfg.tagRangeMin) {
+		sv.add(path, field.Name, "range", val, "Range Minimum is %d", cfg.tagRangeMin)
+	}
+
+	if cfg.rangeMaxSet && n > float64(cfg.tagRangeMax) {
+		sv.add(path, field.Name, "range", val, "Range Maximum is %d", cfg.tagRangeMax)
+	}
+}
@@ -0,0 +1,195 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for ValidateStruct (helper-struct-validate.go).
+//
+// The tag rules evaluated here are the same ones the CSV / JSON marshal
+// helpers apply per field; these tests pin that ValidateStruct reports every
+// violation with its full field path instead of stopping at the first one.
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type validateLine struct {
+	Sku   string  `type:"an" size:"3..8" req:"true"`
+	Qty   int     `range:"1..99"`
+	Price float64 `validate:">>0"`
+}
+
+type validateAddress struct {
+	Zip   string `type:"n" size:"5"`
+	State string `validate:"==CA||NV||OR"`
+}
+
+type validateOrder struct {
+	ID       string `req:"true"`
+	Status   string `validate:"!=void&&deleted"`
+	Code     string `type:"regex" regex:"[^A-Z]+"`
+	Hex      string `type:"h" size:"..8+%2"`
+	Note     string `def:"none" size:"4.."`
+	Ship     validateAddress
+	Bill     *validateAddress `req:"true"`
+	Lines    []validateLine   `req:"true"`
+	Tags     []string         `size:"..5"`
+	Placed   time.Time        `req:"true"`
+	Checked  string           `validate:":=CheckSum"`
+	internal string           `req:"true"`
+}
+
+func (o *validateOrder) CheckSum() error {
+	if o.Checked != "ok" {
+		return fmt.Errorf("checksum mismatch")
+	}
+	return nil
+}
+
+func validValidateOrder() validateOrder {
+	return validateOrder{
+		ID:      "A1",
+		Status:  "open",
+		Code:    "ABC",
+		Hex:     "0aff",
+		Ship:    validateAddress{Zip: "94105", State: "ca"},
+		Bill:    &validateAddress{Zip: "89501", State: "NV"},
+		Lines:   []validateLine{{Sku: "SKU1", Qty: 2, Price: 1.5}},
+		Tags:    []string{"a", "bb"},
+		Placed:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Checked: "ok",
+	}
+}
+
+func TestValidateStruct_Valid(t *testing.T) {
+	o := validValidateOrder()
+
+	if err := ValidateStruct(&o); err != nil {
+		t.Fatalf("expected valid, got %v", err)
+	}
+
+	// by value works too, := methods run on an addressable copy
+	if err := ValidateStruct(o); err != nil {
+		t.Fatalf("expected valid by value, got %v", err)
+	}
+}
+
+func TestValidateStruct_CollectsAllViolations(t *testing.T) {
+	o := validValidateOrder()
+	o.ID = ""
+	o.Status = "VOID"
+	o.Code = "AB1"
+	o.Hex = "abc"
+	o.Ship.Zip = "9410x"
+	o.Ship.State = "TX"
+	o.Bill = nil
+	o.Lines = []validateLine{
+		{Sku: "SKU1", Qty: 2, Price: 1},
+		{Sku: "S-1", Qty: 100, Price: 0},
+	}
+	o.Tags = []string{"ok", "toolong"}
+	o.Placed = time.Time{}
+	o.Checked = "bad"
+
+	err := ValidateStruct(&o)
+
+	var verrs StructValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected StructValidationErrors, got %T %v", err, err)
+	}
+
+	want := []struct {
+		path string
+		rule string
+	}{
+		{"ID", "req"},
+		{"Status", "validate"},
+		{"Code", "regex"},
+		{"Hex", "size"},
+		{"Ship.Zip", "type"},
+		{"Ship.State", "validate"},
+		{"Bill", "req"},
+		{"Lines[1].Sku", "type"},
+		{"Lines[1].Qty", "range"},
+		{"Lines[1].Price", "validate"},
+		{"Tags[1]", "size"},
+		{"Placed", "req"},
+		{"Checked", "validate"},
+	}
+
+	if len(verrs) != len(want) {
+		t.Fatalf("expected %d violations, got %d: %v", len(want), len(verrs), verrs)
+	}
+
+	for i, w := range want {
+		if verrs[i].Path != w.path || verrs[i].Rule != w.rule {
+			t.Errorf("violation %d = %s/%s, want %s/%s", i, verrs[i].Path, verrs[i].Rule, w.path, w.rule)
+		}
+	}
+
+	if fe := verrs.ByPath("Lines[1].Qty"); len(fe) != 1 || fe[0].Value != "100" || fe[0].Field != "Qty" {
+		t.Errorf("ByPath(Lines[1].Qty) = %+v", fe)
+	}
+
+	var fe *StructFieldError
+	if !errors.As(err, &fe) || fe.Path != "ID" {
+		t.Errorf("errors.As to *StructFieldError = %v", fe)
+	}
+	if fe.Error() != "ID is a Required Field" {
+		t.Errorf("StructFieldError.Error() = %q", fe.Error())
+	}
+}
+
+func TestValidateStruct_OptionalBlankSkipsRules(t *testing.T) {
+	type optional struct {
+		Zip   string `type:"n" size:"5"`
+		Qty   *int   `range:"1..9"`
+		State string `validate:"==CA"`
+		Lines []validateLine
+	}
+
+	if err := ValidateStruct(&optional{}); err != nil {
+		t.Fatalf("blank optional fields must not be validated, got %v", err)
+	}
+}
+
+func TestValidateStruct_DefaultAndCycles(t *testing.T) {
+	type node struct {
+		Name string `def:"x" size:"2.."`
+		Next *node
+	}
+
+	n := &node{Name: "ok"}
+	n.Next = n
+
+	err := ValidateStruct(n)
+	if err != nil {
+		t.Fatalf("cycle must terminate without violations, got %v", err)
+	}
+
+	// blank value is evaluated as its def value, which is too short
+	var verrs StructValidationErrors
+	if err = ValidateStruct(&node{}); !errors.As(err, &verrs) || len(verrs) != 1 || verrs[0].Value != "x" {
+		t.Fatalf("expected def value size violation, got %v", err)
+	}
+}
+
+func TestValidateStruct_InvalidInput(t *testing.T) {
+	var nilPtr *validateOrder
+
+	for _, in := range []interface{}{nil, nilPtr, 5, "x"} {
+		err := ValidateStruct(in)
+
+		var verrs StructValidationErrors
+		if err == nil || errors.As(err, &verrs) {
+			t.Errorf("ValidateStruct(%#v) = %v, expected input error", in, err)
+		}
+	}
+}
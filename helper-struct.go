@@ -45,6 +45,11 @@ func parseInt32Logged(s string, tagName string, fieldContext string) int {
 
 // parseBoolLogged wraps ParseBool and logs a warning when the input is non-empty but unparseable.
 func parseBoolLogged(s string, tagName string, fieldContext string) bool {
+	if len(s) == 0 {
+		// most bool tags are absent, skip the ParseBool error allocation
+		return false
+	}
+
 	v, ok := ParseBool(s)
 	if !ok && LenTrim(s) > 0 {
 		log.Printf("[WARN] struct tag parse: ParseBool failed for %s=%q in %s, defaulting to false", tagName, s, fieldContext)
@@ -84,19 +89,7 @@ type csvFieldConfig struct {
 }
 
 func csvComputeBufferLength(s reflect.Value) (int, error) { // extracted
-	maxPos := -1
-	for i := 0; i < s.NumField(); i++ {
-		if tagPos, ok := ParseInt32(s.Type().Field(i).Tag.Get("pos")); ok && tagPos >= 0 {
-			if int(tagPos) > maxPos {
-				maxPos = int(tagPos)
-			}
-		}
-	}
-	// Fail fast when no positional tags are present to avoid silent empty CSV output.
-	if maxPos < 0 {
-		return 0, fmt.Errorf("csv marshal requires at least one field tagged with pos")
-	}
-	return maxPos + 1, nil
+	return getStructPlan(s.Type()).csvBufferLength()
 }
 
 func csvParseFieldConfig(field reflect.StructField) (cfg csvFieldConfig, ok bool) { // extracted
@@ -1388,14 +1381,17 @@ func MarshalStructToQueryParams(inputStructPtr interface{}, tagName string, excl
 	output := ""
 	uniqueMap := make(map[string]string)
 
+	plan := getStructPlan(s.Type())
+
 	for i := 0; i < s.NumField(); i++ {
-		field := s.Type().Field(i)
+		pf := &plan.fields[i]
+		field := pf.field
 		if !isExportedField(field) {
 			continue
 		}
 
-		if o := s.FieldByName(field.Name); o.IsValid() {
-			tagRaw := field.Tag.Get(tagName)
+		if o := s.Field(i); o.IsValid() {
+			tagRaw := pf.tag(tagName)
 			tagParts := strings.SplitN(tagRaw, ",", 2) // strip tag options (e.g., omitempty)
 			tag := tagParts[0]
 
@@ -1405,14 +1401,14 @@ func MarshalStructToQueryParams(inputStructPtr interface{}, tagName string, excl
 
 			if tag != "-" {
 				if LenTrim(excludeTagName) > 0 {
-					if Trim(field.Tag.Get(excludeTagName)) == "-" {
+					if Trim(pf.tag(excludeTagName)) == "-" {
 						continue
 					}
 				}
 
 				// Do not claim uniqueid until we know we will emit a value.
 				uniqueKey := ""
-				if tagUniqueId := Trim(field.Tag.Get("uniqueid")); len(tagUniqueId) > 0 {
+				if tagUniqueId := Trim(pf.tag("uniqueid")); len(tagUniqueId) > 0 {
 					uniqueKey = strings.ToLower(tagUniqueId)
 					if _, ok := uniqueMap[uniqueKey]; ok {
 						continue // already satisfied by another mutually-exclusive field
@@ -1423,7 +1419,7 @@ func MarshalStructToQueryParams(inputStructPtr interface{}, tagName string, excl
 				var skipBlank, skipZero, zeroblank bool
 				qpCtx := "MarshalStructToQueryParams(" + field.Name + ")"
 
-				if vs := GetStructTagsValueSlice(field, "booltrue", "boolfalse", "skipblank", "skipzero", "timeformat", "outprefix", "zeroblank"); len(vs) == 7 {
+				if vs := pf.tagsValueSlice("booltrue", "boolfalse", "skipblank", "skipzero", "timeformat", "outprefix", "zeroblank"); len(vs) == 7 {
 					boolTrue = vs[0]
 					boolFalse = vs[1]
 					skipBlank = parseBoolLogged(vs[2], "skipblank", qpCtx)
@@ -1433,7 +1429,7 @@ func MarshalStructToQueryParams(inputStructPtr interface{}, tagName string, excl
 					zeroblank = parseBoolLogged(vs[6], "zeroblank", qpCtx)
 				}
 
-				defVal := field.Tag.Get("def") // capture default early so it can be applied on skip
+				defVal := pf.tag("def") // capture default early so it can be applied on skip
 
 				// safely capture original value without panicking on nil pointers/interfaces
 				baseOldVal := o
@@ -1444,7 +1440,7 @@ func MarshalStructToQueryParams(inputStructPtr interface{}, tagName string, excl
 					baseOldVal = reflect.Value{}
 				}
 
-				if tagGetter := Trim(field.Tag.Get("getter")); len(tagGetter) > 0 {
+				if tagGetter := Trim(pf.tag("getter")); len(tagGetter) > 0 {
 					isBase := false
 					useParam := false
 					paramVal := ""
@@ -1519,7 +1515,7 @@ func MarshalStructToQueryParams(inputStructPtr interface{}, tagName string, excl
 					if LenTrim(defVal) > 0 {
 						buf = defVal
 					} else {
-						if strings.ToLower(field.Tag.Get("req")) == "true" {
+						if strings.ToLower(pf.tag("req")) == "true" {
 							return "", fmt.Errorf("%s is a Required Field", field.Name)
 						}
 						continue
@@ -1558,14 +1554,14 @@ func MarshalStructToQueryParams(inputStructPtr interface{}, tagName string, excl
 
 				// Respect skipblank/skipzero by skipping emission and releasing unique claims.
 				if skipBlank && LenTrim(buf) == 0 {
-					if strings.ToLower(field.Tag.Get("req")) == "true" { // required enforcement
+					if strings.ToLower(pf.tag("req")) == "true" { // required enforcement
 						return "", fmt.Errorf("%s is a Required Field", field.Name)
 					}
 					// we never claimed uniqueKey yet, so no need to delete
 					continue
 				}
 				if skipZero && buf == "0" {
-					if strings.ToLower(field.Tag.Get("req")) == "true" { // required enforcement
+					if strings.ToLower(pf.tag("req")) == "true" { // required enforcement
 						return "", fmt.Errorf("%s is a Required Field", field.Name)
 					}
 					// we never claimed uniqueKey yet, so no need to delete
@@ -1575,7 +1571,7 @@ func MarshalStructToQueryParams(inputStructPtr interface{}, tagName string, excl
 				buf = outPrefix + buf
 
 				// final required check after normalization
-				if strings.ToLower(field.Tag.Get("req")) == "true" && LenTrim(buf) == 0 {
+				if strings.ToLower(pf.tag("req")) == "true" && LenTrim(buf) == 0 {
 					return "", fmt.Errorf("%s is a Required Field", field.Name)
 				}
 
@@ -1626,6 +1622,7 @@ func MarshalStructToQueryParams(inputStructPtr interface{}, tagName string, excl
 //     05, 5 = second
 //     PM pm = AM PM
 //  8. `zeroblank:"false"`		// set true to set blank to data when value is 0, 0.00, or time.IsZero
+//  9. `mask:"pan"`				// redact the output value, see MaskValue for kinds: pan, track1, track2, track, secret, text, full
 func MarshalStructToJson(inputStructPtr interface{}, tagName string, excludeTagName string) (string, error) {
 	if inputStructPtr == nil {
 		return "", fmt.Errorf("MarshalStructToJson Requires Input Struct Variable Pointer")
@@ -1656,16 +1653,19 @@ func MarshalStructToJson(inputStructPtr interface{}, tagName string, excludeTagN
 	jsonMap := make(map[string]string)                        // build a map and let json.Marshal handle escaping
 	trueList := []string{"true", "yes", "on", "1", "enabled"} // used for type normalization
 
+	plan := getStructPlan(s.Type())
+
 	for i := 0; i < s.NumField(); i++ {
-		field := s.Type().Field(i)
+		pf := &plan.fields[i]
+		field := pf.field
 		if !isExportedField(field) {
 			continue
 		}
 
-		if o := s.FieldByName(field.Name); o.IsValid() {
-			cfg, _ := jsonParseFieldConfig(field) // reuse parsed config for validation
+		if o := s.Field(i); o.IsValid() {
+			cfg := pf.jsonConfig() // reuse parsed config for validation
 
-			tagRaw := field.Tag.Get(tagName)
+			tagRaw := pf.tag(tagName)
 			tagParts := strings.SplitN(tagRaw, ",", 2) // strip tag options (e.g., omitempty)
 			tag := tagParts[0]
 
@@ -1676,12 +1676,12 @@ func MarshalStructToJson(inputStructPtr interface{}, tagName string, excludeTagN
 			if tag == "-" {
 				continue
 			}
-			if LenTrim(excludeTagName) > 0 && Trim(field.Tag.Get(excludeTagName)) == "-" {
+			if LenTrim(excludeTagName) > 0 && Trim(pf.tag(excludeTagName)) == "-" {
 				continue
 			}
 
 			uniqueKey := ""
-			if tagUniqueId := Trim(field.Tag.Get("uniqueid")); len(tagUniqueId) > 0 {
+			if tagUniqueId := Trim(pf.tag("uniqueid")); len(tagUniqueId) > 0 {
 				uniqueKey = strings.ToLower(tagUniqueId)
 				if _, ok := uniqueMap[uniqueKey]; ok {
 					continue
@@ -1692,7 +1692,7 @@ func MarshalStructToJson(inputStructPtr interface{}, tagName string, excludeTagN
 			var skipBlank, skipZero, zeroBlank bool
 			jsCtx := "MarshalStructToJson(" + field.Name + ")"
 
-			if vs := GetStructTagsValueSlice(field, "booltrue", "boolfalse", "skipblank", "skipzero", "timeformat", "zeroblank"); len(vs) == 6 {
+			if vs := pf.tagsValueSlice("booltrue", "boolfalse", "skipblank", "skipzero", "timeformat", "zeroblank"); len(vs) == 6 {
 				boolTrue = vs[0]
 				boolFalse = vs[1]
 				skipBlank = parseBoolLogged(vs[2], "skipblank", jsCtx)
@@ -1701,10 +1701,10 @@ func MarshalStructToJson(inputStructPtr interface{}, tagName string, excludeTagN
 				zeroBlank = parseBoolLogged(vs[5], "zeroblank", jsCtx)
 			}
 
-			defVal := field.Tag.Get("def") // capture default regardless of getter presence
+			defVal := pf.tag("def") // capture default regardless of getter presence
 
 			// parse and honor getter output throughout marshal path
-			tagGetter := Trim(field.Tag.Get("getter"))
+			tagGetter := Trim(pf.tag("getter"))
 			hasGetter := len(tagGetter) > 0
 			oVal := o
 
@@ -1791,7 +1791,7 @@ func MarshalStructToJson(inputStructPtr interface{}, tagName string, excludeTagN
 			}
 
 			// honor required/default semantics when a value was skipped
-			req := strings.ToLower(field.Tag.Get("req"))
+			req := strings.ToLower(pf.tag("req"))
 
 			if skip {
 				if len(defVal) > 0 {
@@ -1835,7 +1835,12 @@ func MarshalStructToJson(inputStructPtr interface{}, tagName string, excludeTagN
 				return "", err
 			}
 
-			outPrefix := field.Tag.Get("outprefix")
+			// redact sensitive values after validation so rules still see the real data
+			if maskKind := Trim(pf.tag("mask")); len(maskKind) > 0 {
+				buf = MaskValue(maskKind, buf)
+			}
+
+			outPrefix := pf.tag("outprefix")
 
 			// honor booltrue=" " + outprefix by emitting prefix token for true values
 			if boolTrue == " " && len(outPrefix) > 0 && strings.EqualFold(buf, "true") {
@@ -1969,17 +1974,20 @@ func UnmarshalJsonToStruct(inputStructPtr interface{}, jsonPayload string, tagNa
 
 	trueList := []string{"true", "yes", "on", "1", "enabled"}
 
+	plan := getStructPlan(s.Type())
+
 	for i := 0; i < s.NumField(); i++ {
-		field := s.Type().Field(i)
+		pf := &plan.fields[i]
+		field := pf.field
 		if !isExportedField(field) {
 			continue
 		}
 
-		if o := s.FieldByName(field.Name); o.IsValid() && o.CanSet() {
-			cfg, _ := jsonParseFieldConfig(field)
+		if o := s.Field(i); o.IsValid() && o.CanSet() {
+			cfg := pf.jsonConfig()
 
 			// get json field name if defined
-			tagRaw := Trim(field.Tag.Get(tagName))
+			tagRaw := Trim(pf.tag(tagName))
 			tagParts := strings.SplitN(tagRaw, ",", 2) // strip tag options (e.g., omitempty)
 			jName := Trim(tagParts[0])
 
@@ -1988,7 +1996,7 @@ func UnmarshalJsonToStruct(inputStructPtr interface{}, jsonPayload string, tagNa
 			}
 
 			if LenTrim(excludeTagName) > 0 {
-				if Trim(field.Tag.Get(excludeTagName)) == "-" {
+				if Trim(pf.tag(excludeTagName)) == "-" {
 					continue
 				}
 			}
@@ -1999,7 +2007,7 @@ func UnmarshalJsonToStruct(inputStructPtr interface{}, jsonPayload string, tagNa
 
 			jRaw, ok := jsonMap[jName]
 			if !ok {
-				defVal := Trim(field.Tag.Get("def"))
+				defVal := Trim(pf.tag("def"))
 				// validate/apply defaults when required field is missing but a default exists
 				if cfg.tagReq == "true" && LenTrim(defVal) == 0 {
 					StructClearFields(inputStructPtr)
@@ -2162,13 +2170,16 @@ func StructClearFields(inputStructPtr interface{}) {
 		return
 	}
 
+	plan := getStructPlan(s.Type())
+
 	for i := 0; i < s.NumField(); i++ {
-		field := s.Type().Field(i)
+		pf := &plan.fields[i]
+		field := pf.field
 		if !isExportedField(field) {
 			continue
 		}
 
-		if o := s.FieldByName(field.Name); o.IsValid() && o.CanSet() {
+		if o := s.Field(i); o.IsValid() && o.CanSet() {
 			switch o.Kind() {
 			case reflect.String:
 				o.SetString("")
@@ -2246,15 +2257,18 @@ func StructNonDefaultRequiredFieldsCount(inputStructPtr interface{}) int {
 
 	count := 0
 
+	plan := getStructPlan(s.Type())
+
 	for i := 0; i < s.NumField(); i++ {
-		field := s.Type().Field(i)
+		pf := &plan.fields[i]
+		field := pf.field
 		if !isExportedField(field) {
 			continue
 		}
 
-		if o := s.FieldByName(field.Name); o.IsValid() && o.CanSet() {
-			tagDef := field.Tag.Get("def")
-			tagReq := field.Tag.Get("req")
+		if o := s.Field(i); o.IsValid() && o.CanSet() {
+			tagDef := pf.tag("def")
+			tagReq := pf.tag("req")
 
 			if len(tagDef) == 0 && strings.ToLower(tagReq) == "true" {
 				// required and no default value
@@ -2284,14 +2298,17 @@ func IsStructFieldSet(inputStructPtr interface{}) bool {
 		return false
 	}
 
+	plan := getStructPlan(s.Type())
+
 	for i := 0; i < s.NumField(); i++ {
-		field := s.Type().Field(i)
+		pf := &plan.fields[i]
+		field := pf.field
 		if !isExportedField(field) {
 			continue
 		}
 
-		if o := s.FieldByName(field.Name); o.IsValid() && o.CanSet() {
-			tagDef := field.Tag.Get("def")
+		if o := s.Field(i); o.IsValid() && o.CanSet() {
+			tagDef := pf.tag("def")
 
 			// Do not mutate struct; purely observe.
 			if len(tagDef) == 0 {
@@ -2378,7 +2395,7 @@ func IsStructFieldSet(inputStructPtr interface{}) bool {
 					}
 				case sql.NullTime:
 					if f.Valid {
-						tagTimeFormat := Trim(field.Tag.Get("timeformat"))
+						tagTimeFormat := Trim(pf.tag("timeformat"))
 						if LenTrim(tagTimeFormat) == 0 {
 							tagTimeFormat = DateTimeFormatString()
 						}
@@ -2388,7 +2405,7 @@ func IsStructFieldSet(inputStructPtr interface{}) bool {
 					}
 				case time.Time:
 					if !f.IsZero() {
-						tagTimeFormat := Trim(field.Tag.Get("timeformat"))
+						tagTimeFormat := Trim(pf.tag("timeformat"))
 						if LenTrim(tagTimeFormat) == 0 {
 							tagTimeFormat = DateTimeFormatString()
 						}
@@ -2429,14 +2446,17 @@ func SetStructFieldDefaultValues(inputStructPtr interface{}) (bool, error) {
 		return false, nil
 	}
 
+	plan := getStructPlan(s.Type())
+
 	for i := 0; i < s.NumField(); i++ {
-		field := s.Type().Field(i)
+		pf := &plan.fields[i]
+		field := pf.field
 		if !isExportedField(field) {
 			continue
 		}
 
-		if o := s.FieldByName(field.Name); o.IsValid() && o.CanSet() {
-			tagDef := field.Tag.Get("def")
+		if o := s.Field(i); o.IsValid() && o.CanSet() {
+			tagDef := pf.tag("def")
 
 			if len(tagDef) == 0 {
 				continue
@@ -2448,7 +2468,7 @@ func SetStructFieldDefaultValues(inputStructPtr interface{}) (bool, error) {
 			}
 
 			// normalize setter info (support base. prefix) and allocate pointer/interface targets before calling setters.
-			tagSetter := Trim(field.Tag.Get("setter"))
+			tagSetter := Trim(pf.tag("setter"))
 			setterBase := false
 			if len(tagSetter) > 0 {
 				lgs := strings.ToLower(tagSetter)
@@ -2602,7 +2622,7 @@ func SetStructFieldDefaultValues(inputStructPtr interface{}) (bool, error) {
 					}
 				case sql.NullTime:
 					if !f.Valid {
-						tagTimeFormat := Trim(field.Tag.Get("timeformat"))
+						tagTimeFormat := Trim(pf.tag("timeformat"))
 						if LenTrim(tagTimeFormat) == 0 {
 							tagTimeFormat = DateTimeFormatString()
 						}
@@ -2612,7 +2632,7 @@ func SetStructFieldDefaultValues(inputStructPtr interface{}) (bool, error) {
 					}
 				case time.Time:
 					if f.IsZero() {
-						tagTimeFormat := Trim(field.Tag.Get("timeformat"))
+						tagTimeFormat := Trim(pf.tag("timeformat"))
 						if LenTrim(tagTimeFormat) == 0 {
 							tagTimeFormat = DateTimeFormatString()
 						}
@@ -2632,6 +2652,8 @@ func SetStructFieldDefaultValues(inputStructPtr interface{}) (bool, error) {
 // and set parsed csv element value into struct fields based on Ordinal Position defined via struct tag,
 // additionally processes struct tag data validation and length / range (if not valid, will set to data type default)
 //
+// to obtain the full list of rule violations rather than the first failure, call ValidateStruct on inputStructPtr afterwards
+//
 // Predefined Struct Tags Usable:
 //  1. `pos:"1"`				// ordinal position of the field in relation to the csv parsed output expected (Zero-Based Index)
 //     NOTE: if field is mutually exclusive with one or more uniqueId, then pos # should be named the same for all uniqueIds,
@@ -2728,23 +2750,26 @@ func UnmarshalCSVToStruct(inputStructPtr interface{}, csvPayload string, csvDeli
 	// Preallocate slice with estimated capacity based on number of struct fields
 	virtualSetters := make([]virtualSetter, 0, s.NumField())
 
+	plan := getStructPlan(s.Type())
+
 	for i := 0; i < s.NumField(); i++ {
-		field := s.Type().Field(i)
+		pf := &plan.fields[i]
+		field := pf.field
 		if !isExportedField(field) {
 			continue
 		}
 
-		o := s.FieldByName(field.Name)
+		o := s.Field(i)
 		if !o.IsValid() || !o.CanSet() {
 			continue
 		}
 
-		cfg, ok := csvParseUnmarshalConfig(field)
+		cfg, ok := pf.csvUnmarshalConfig()
 		if !ok {
 			continue
 		}
 
-		defVal := Trim(field.Tag.Get("def")) // compute default early for virtual fields
+		defVal := Trim(pf.tag("def")) // compute default early for virtual fields
 
 		// capture virtual setters immediately and skip positional parsing
 		if cfg.pos < 0 {
@@ -3060,32 +3085,46 @@ func UnmarshalCSVToStruct(inputStructPtr interface{}, csvPayload string, csvDeli
 //     :=Xyz where Xyz is a parameterless function defined at struct level, that performs validation, returns bool or error where true or nil indicates validation success
 //     note: expected source data type for validate to be effective is string, int, float64; if field is blank and req = false, then validate will be skipped
 func MarshalStructToCSV(inputStructPtr interface{}, csvDelimiter string) (csvPayload string, err error) {
+	csvFields, err := MarshalStructToCSVFields(inputStructPtr)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(csvFields, csvDelimiter), nil
+}
+
+// MarshalStructToCSVFields performs the same serialization and validation as MarshalStructToCSV,
+// but returns the csv elements in ordinal position order instead of a delimited line,
+// so callers such as csv.StructWriter can apply their own quoting and delimiter rules
+//
+// struct tags usable are the same as MarshalStructToCSV
+func MarshalStructToCSVFields(inputStructPtr interface{}) (csvFields []string, err error) {
 	if inputStructPtr == nil {
-		return "", fmt.Errorf("InputStructPtr is Required")
+		return nil, fmt.Errorf("InputStructPtr is Required")
 	}
 
 	s := reflect.ValueOf(inputStructPtr)
 	if s.Kind() != reflect.Ptr {
-		return "", fmt.Errorf("InputStructPtr Must Be Pointer")
+		return nil, fmt.Errorf("InputStructPtr Must Be Pointer")
 	}
 	s = s.Elem()
 	if s.Kind() != reflect.Struct {
-		return "", fmt.Errorf("InputStructPtr Must Be Struct")
+		return nil, fmt.Errorf("InputStructPtr Must Be Struct")
 	}
 
 	// Apply defaults once so required fields that rely on def tags are honored before validation.
 	if _, err := SetStructFieldDefaultValues(inputStructPtr); err != nil {
-		return "", fmt.Errorf("MarshalStructToCSV default application failed: %w", err)
+		return nil, fmt.Errorf("MarshalStructToCSV default application failed: %w", err)
 	}
 
 	trueList := []string{"true", "yes", "on", "1", "enabled"}
 
 	csvLen, err := csvComputeBufferLength(s)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if csvLen == 0 {
-		return "", fmt.Errorf("MarshalStructToCSV requires at least one field tagged with pos")
+		return nil, fmt.Errorf("MarshalStructToCSV requires at least one field tagged with pos")
 	}
 
 	csvList := make([]string, csvLen)
@@ -3097,18 +3136,21 @@ func MarshalStructToCSV(inputStructPtr interface{}, csvDelimiter string) (csvPay
 	uniqueMap := make(map[string]string)
 	emitted := false
 
+	plan := getStructPlan(s.Type())
+
 	for i := 0; i < s.NumField(); i++ {
-		field := s.Type().Field(i)
+		pf := &plan.fields[i]
+		field := pf.field
 		if !isExportedField(field) {
 			continue
 		}
 
-		o := s.FieldByName(field.Name)
+		o := s.Field(i)
 		if !o.IsValid() || !o.CanSet() {
 			continue
 		}
 
-		cfg, ok := csvParseFieldConfig(field)
+		cfg, ok := pf.csvConfig()
 		if !ok || cfg.pos > len(csvList)-1 {
 			continue
 		}
@@ -3142,14 +3184,14 @@ func MarshalStructToCSV(inputStructPtr interface{}, csvDelimiter string) (csvPay
 
 		fv, skip, e := ReflectValueToString(o, cfg.boolTrue, cfg.boolFalse, cfg.skipBlank, cfg.skipZero, cfg.timeFormat, cfg.zeroBlank)
 		if e != nil {
-			return "", e
+			return nil, e
 		}
 		if skip {
 			// honor defaults on skipped fields before enforcing required
 			if LenTrim(cfg.defVal) > 0 {
 				fv = cfg.defVal
 			} else if strings.ToLower(cfg.tagReq) == "true" {
-				return "", fmt.Errorf("%s is a Required Field", field.Name)
+				return nil, fmt.Errorf("%s is a Required Field", field.Name)
 			} else {
 				continue
 			}
@@ -3172,12 +3214,12 @@ func MarshalStructToCSV(inputStructPtr interface{}, csvDelimiter string) (csvPay
 
 		fv, skipVal, errVal := csvValidateAndNormalize(fv, cfg, baseOldVal, hasGetter, trueList)
 		if errVal != nil {
-			return "", fmt.Errorf("%s %s", field.Name, errVal.Error())
+			return nil, fmt.Errorf("%s %s", field.Name, errVal.Error())
 		}
 		if skipVal {
 			// required fields must not be silently skipped
 			if strings.ToLower(cfg.tagReq) == "true" {
-				return "", fmt.Errorf("%s is a Required Field", field.Name)
+				return nil, fmt.Errorf("%s is a Required Field", field.Name)
 			}
 			csvList[cfg.pos] = ""
 			emitted = true
@@ -3185,20 +3227,20 @@ func MarshalStructToCSV(inputStructPtr interface{}, csvDelimiter string) (csvPay
 		}
 
 		if errVal = csvValidateCustom(fv, cfg, cfg.tagReq, s, field); errVal != nil {
-			return "", fmt.Errorf("%s %s", field.Name, errVal.Error())
+			return nil, fmt.Errorf("%s %s", field.Name, errVal.Error())
 		}
 
 		// ensure skipBlank/skipZero cannot bypass required enforcement
 		if cfg.skipBlank && LenTrim(fv) == 0 {
 			if strings.ToLower(cfg.tagReq) == "true" {
-				return "", fmt.Errorf("%s is a Required Field", field.Name)
+				return nil, fmt.Errorf("%s is a Required Field", field.Name)
 			}
 			csvList[cfg.pos] = ""
 			emitted = true
 			continue
 		} else if cfg.skipZero && fv == "0" {
 			if strings.ToLower(cfg.tagReq) == "true" {
-				return "", fmt.Errorf("%s is a Required Field", field.Name)
+				return nil, fmt.Errorf("%s is a Required Field", field.Name)
 			}
 			csvList[cfg.pos] = ""
 			emitted = true
@@ -3215,36 +3257,21 @@ func MarshalStructToCSV(inputStructPtr interface{}, csvDelimiter string) (csvPay
 
 	// fail fast if nothing was emitted to avoid silent empty CSV output
 	if !emitted {
-		return "", fmt.Errorf("MarshalStructToCSV Yielded Blank Output")
+		return nil, fmt.Errorf("MarshalStructToCSV Yielded Blank Output")
 	}
 
-	// emit variable-length CSV when all fields are outprefix-based (skip placeholders entirely)
-	if excludePlaceholders {
-		first := true
-		for _, v := range csvList {
-			if v == "{?}" {
+	// emit variable-length CSV when all fields are outprefix-based (skip placeholders entirely),
+	// otherwise preserve column positions by emitting placeholders as empty columns
+	csvFields = make([]string, 0, len(csvList))
+	for _, v := range csvList {
+		if v == "{?}" {
+			if excludePlaceholders {
 				continue
 			}
-			if !first {
-				csvPayload += csvDelimiter
-			}
-			csvPayload += v
-			first = false
-		}
-		return csvPayload, nil
-	}
-
-	// Preserve column positions even when placeholders are excluded to avoid collapsing columns.
-	for idx, v := range csvList {
-		if idx > 0 {
-			csvPayload += csvDelimiter
-		}
-		if v == "{?}" {
-			continue // empty column to keep position
+			v = ""
 		}
-		// if excludePlaceholders was true, this still preserves positional alignment
-		csvPayload += v
+		csvFields = append(csvFields, v)
 	}
 
-	return csvPayload, nil
+	return csvFields, nil
 }
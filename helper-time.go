@@ -17,10 +17,13 @@ package helper
  */
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -1223,3 +1226,571 @@ func GetLastDateOfMonth(t time.Time) time.Time {
 	firstOfNext := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc).AddDate(0, 1, 0)
 	return time.Date(firstOfNext.Year(), firstOfNext.Month(), 1, 0, 0, 0, 0, loc).AddDate(0, 0, -1)
 }
+
+// ---------------------------------------------------------------------------------------------------------------------
+// business calendar helpers
+// ---------------------------------------------------------------------------------------------------------------------
+
+// HolidayCalendar reports whether the calendar date of t (in t's own location) is a holiday,
+// implement HolidayCalendar to plug custom calendars into IsBusinessDay / AddBusinessDays
+type HolidayCalendar interface {
+	IsHoliday(t time.Time) bool
+}
+
+// HolidayCalendars combines several calendars, a date is a holiday if any of the calendars says so
+type HolidayCalendars []HolidayCalendar
+
+// IsHoliday implements HolidayCalendar
+func (c HolidayCalendars) IsHoliday(t time.Time) bool {
+	for _, v := range c {
+		if v != nil && v.IsHoliday(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Holiday is a single named holiday date
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+// USFederalCalendar is the built-in US federal holiday calendar (5 U.S.C. 6103),
+// holidays falling on Saturday are observed the Friday before, on Sunday the Monday after
+type USFederalCalendar struct{}
+
+// usFederalHolidayCache maps year to []Holiday
+var usFederalHolidayCache sync.Map
+
+// IsHoliday implements HolidayCalendar, matching observed dates
+func (USFederalCalendar) IsHoliday(t time.Time) bool {
+	// new year's day on a saturday is observed on december 31 of the prior year
+	for _, y := range []int{t.Year(), t.Year() + 1} {
+		for _, h := range USFederalHolidays(y) {
+			if h.Date.Year() == t.Year() && h.Date.Month() == t.Month() && h.Date.Day() == t.Day() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// USFederalHolidays returns the observed US federal holidays of the given year, in date order,
+// dates are midnight UTC; note the observed new year's day may fall on december 31 of the prior year
+func USFederalHolidays(year int) []Holiday {
+	if v, ok := usFederalHolidayCache.Load(year); ok {
+		return slices.Clone(v.([]Holiday))
+	}
+
+	fixed := func(month time.Month, day int) time.Time {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+		switch d.Weekday() {
+		case time.Saturday:
+			return d.AddDate(0, 0, -1)
+		case time.Sunday:
+			return d.AddDate(0, 0, 1)
+		default:
+			return d
+		}
+	}
+
+	list := []Holiday{
+		{fixed(time.January, 1), "New Year's Day"},
+		{nthWeekdayOfMonth(year, time.January, time.Monday, 3), "Birthday of Martin Luther King, Jr."},
+		{nthWeekdayOfMonth(year, time.February, time.Monday, 3), "Washington's Birthday"},
+		{nthWeekdayOfMonth(year, time.May, time.Monday, -1), "Memorial Day"},
+	}
+
+	if year >= 2021 {
+		list = append(list, Holiday{fixed(time.June, 19), "Juneteenth National Independence Day"})
+	}
+
+	list = append(list,
+		Holiday{fixed(time.July, 4), "Independence Day"},
+		Holiday{nthWeekdayOfMonth(year, time.September, time.Monday, 1), "Labor Day"},
+		Holiday{nthWeekdayOfMonth(year, time.October, time.Monday, 2), "Columbus Day"},
+		Holiday{fixed(time.November, 11), "Veterans Day"},
+		Holiday{nthWeekdayOfMonth(year, time.November, time.Thursday, 4), "Thanksgiving Day"},
+		Holiday{fixed(time.December, 25), "Christmas Day"},
+	)
+
+	usFederalHolidayCache.Store(year, list)
+	return slices.Clone(list)
+}
+
+// HolidayList is a custom holiday calendar of specific dates, typically loaded from config via ParseHolidayList
+type HolidayList struct {
+	mu    sync.RWMutex
+	dates map[string]string // yyyymmdd => name
+}
+
+// NewHolidayList creates a holiday list from the given holidays
+func NewHolidayList(holidays ...Holiday) *HolidayList {
+	h := &HolidayList{
+		dates: make(map[string]string),
+	}
+
+	for _, v := range holidays {
+		h.Add(v.Date, v.Name)
+	}
+
+	return h
+}
+
+// ParseHolidayList creates a holiday list from config entries formatted as yyyy-mm-dd or yyyymmdd,
+// optionally followed by =Name, for example "2026-11-27=Day After Thanksgiving"
+func ParseHolidayList(entries []string) (*HolidayList, error) {
+	h := NewHolidayList()
+
+	for _, e := range entries {
+		dt, name, _ := strings.Cut(strings.TrimSpace(e), "=")
+		dt = strings.TrimSpace(dt)
+
+		if len(dt) == 0 {
+			continue
+		}
+
+		var d time.Time
+
+		if strings.Contains(dt, "-") {
+			d = ParseDate(dt)
+		} else {
+			d = ParseDateFromYYYYMMDD(dt)
+		}
+
+		if d.IsZero() {
+			return nil, fmt.Errorf("ParseHolidayList Invalid Date Entry '%s'", e)
+		}
+
+		h.Add(d, strings.TrimSpace(name))
+	}
+
+	return h, nil
+}
+
+// Add adds the calendar date of t to the holiday list
+func (h *HolidayList) Add(t time.Time, name string) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.dates == nil {
+		h.dates = make(map[string]string)
+	}
+
+	h.dates[FormatDateToYYYYMMDD(t)] = name
+}
+
+// IsHoliday implements HolidayCalendar
+func (h *HolidayList) IsHoliday(t time.Time) bool {
+	_, ok := h.HolidayName(t)
+	return ok
+}
+
+// HolidayName returns the name of the holiday on the calendar date of t, ok is false if not a holiday
+func (h *HolidayList) HolidayName(t time.Time) (name string, ok bool) {
+	if h == nil {
+		return "", false
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	name, ok = h.dates[FormatDateToYYYYMMDD(t)]
+	return name, ok
+}
+
+// IsWeekend returns true if t falls on saturday or sunday (in t's own location)
+func IsWeekend(t time.Time) bool {
+	wd := t.Weekday()
+	return wd == time.Saturday || wd == time.Sunday
+}
+
+// maxNonBusinessDays bounds the consecutive non-business days searched before giving up,
+// so a calendar reporting every day as a holiday cannot loop forever
+const maxNonBusinessDays = 3660
+
+// IsBusinessDay returns true if t is not a weekend and not a holiday of calendar,
+// calendar may be nil to consider weekends only; convert t to the business location first (t.In(loc))
+func IsBusinessDay(t time.Time, calendar HolidayCalendar) bool {
+	if IsWeekend(t) {
+		return false
+	}
+
+	return calendar == nil || !calendar.IsHoliday(t)
+}
+
+// AddBusinessDays moves t forward (days > 0) or backward (days < 0) by the given number of business days,
+// keeping t's wall clock time and location, so the result is DST-safe;
+// when days is 0, t is returned unchanged even if t itself is not a business day;
+// if calendar yields no business day within maxNonBusinessDays, t is returned unchanged
+func AddBusinessDays(t time.Time, days int, calendar HolidayCalendar) time.Time {
+	step := 1
+
+	if days < 0 {
+		step = -1
+		days = -days
+	}
+
+	d := t
+	skipped := 0
+
+	for days > 0 {
+		d = AddDaysSameWallClock(d, step)
+
+		if IsBusinessDay(d, calendar) {
+			days--
+			skipped = 0
+		} else if skipped++; skipped > maxNonBusinessDays {
+			return t
+		}
+	}
+
+	return d
+}
+
+// NextBusinessDay returns t if t is a business day, otherwise the next business day at the same wall clock time;
+// if calendar yields no business day within maxNonBusinessDays, t is returned unchanged
+func NextBusinessDay(t time.Time, calendar HolidayCalendar) time.Time {
+	d := t
+
+	for i := 0; !IsBusinessDay(d, calendar); i++ {
+		if i >= maxNonBusinessDays {
+			return t
+		}
+
+		d = AddDaysSameWallClock(d, 1)
+	}
+
+	return d
+}
+
+// BusinessDaysBetween counts the business days after from, up to and including to (calendar dates),
+// the result is negative when to is before from
+func BusinessDaysBetween(from time.Time, to time.Time, calendar HolidayCalendar) int {
+	sign := 1
+
+	if to.Before(from) {
+		from, to = to, from
+		sign = -1
+	}
+
+	count := 0
+	end := StartOfDay(to, from.Location())
+
+	for d := AddDaysSameWallClock(StartOfDay(from, nil), 1); !d.After(end); d = AddDaysSameWallClock(d, 1) {
+		if IsBusinessDay(d, calendar) {
+			count++
+		}
+	}
+
+	return count * sign
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// zone aware period helpers
+// ---------------------------------------------------------------------------------------------------------------------
+
+// AddDaysSameWallClock returns the same wall clock time the given number of days away in t's location,
+// across a DST change the elapsed duration is 23 or 25 hours rather than 24;
+// if the wall clock time does not exist on the target day (spring forward gap), it is normalized forward
+func AddDaysSameWallClock(t time.Time, days int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+days, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// SameWallClockTomorrow returns the same wall clock time on the next day in t's location, see AddDaysSameWallClock
+func SameWallClockTomorrow(t time.Time) time.Time {
+	return AddDaysSameWallClock(t, 1)
+}
+
+// StartOfDay returns midnight of t's calendar date in loc (nil loc = t's location)
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	t = timeInLocation(t, loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// EndOfDay returns the last nanosecond of t's calendar date in loc (nil loc = t's location)
+func EndOfDay(t time.Time, loc *time.Location) time.Time {
+	return AddDaysSameWallClock(StartOfDay(t, loc), 1).Add(-time.Nanosecond)
+}
+
+// StartOfWeek returns midnight of the first day of t's week in loc (nil loc = t's location),
+// weekStart is the first day of the week, such as time.Sunday or time.Monday
+func StartOfWeek(t time.Time, loc *time.Location, weekStart time.Weekday) time.Time {
+	d := StartOfDay(t, loc)
+	back := (int(d.Weekday()) - int(weekStart) + 7) % 7
+	return AddDaysSameWallClock(d, -back)
+}
+
+// EndOfWeek returns the last nanosecond of t's week in loc (nil loc = t's location), see StartOfWeek
+func EndOfWeek(t time.Time, loc *time.Location, weekStart time.Weekday) time.Time {
+	return AddDaysSameWallClock(StartOfWeek(t, loc, weekStart), 7).Add(-time.Nanosecond)
+}
+
+// StartOfQuarter returns midnight of the first day of t's calendar quarter in loc (nil loc = t's location)
+func StartOfQuarter(t time.Time, loc *time.Location) time.Time {
+	t = timeInLocation(t, loc)
+	m := ((int(t.Month())-1)/3)*3 + 1
+	return time.Date(t.Year(), time.Month(m), 1, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfQuarter returns the last nanosecond of t's calendar quarter in loc (nil loc = t's location)
+func EndOfQuarter(t time.Time, loc *time.Location) time.Time {
+	return StartOfQuarter(t, loc).AddDate(0, 3, 0).Add(-time.Nanosecond)
+}
+
+// StartOfFiscalYear returns midnight of the first day of t's fiscal year in loc (nil loc = t's location),
+// fiscalStartMonth is the month the fiscal year begins, such as time.October
+func StartOfFiscalYear(t time.Time, loc *time.Location, fiscalStartMonth time.Month) time.Time {
+	t = timeInLocation(t, loc)
+	fiscalStartMonth = normalizeFiscalStartMonth(fiscalStartMonth)
+
+	y := t.Year()
+	if t.Month() < fiscalStartMonth {
+		y--
+	}
+
+	return time.Date(y, fiscalStartMonth, 1, 0, 0, 0, 0, t.Location())
+}
+
+// EndOfFiscalYear returns the last nanosecond of t's fiscal year in loc (nil loc = t's location)
+func EndOfFiscalYear(t time.Time, loc *time.Location, fiscalStartMonth time.Month) time.Time {
+	return StartOfFiscalYear(t, loc, fiscalStartMonth).AddDate(1, 0, 0).Add(-time.Nanosecond)
+}
+
+// FiscalYear returns the fiscal year number of t, named after the calendar year the fiscal year ends in,
+// for example with fiscalStartMonth = October, 2026-10-01 is in fiscal year 2027
+func FiscalYear(t time.Time, loc *time.Location, fiscalStartMonth time.Month) int {
+	return EndOfFiscalYear(t, loc, fiscalStartMonth).Year()
+}
+
+// FiscalQuarter returns the 1-based fiscal quarter of t
+func FiscalQuarter(t time.Time, loc *time.Location, fiscalStartMonth time.Month) int {
+	t = timeInLocation(t, loc)
+	months := (int(t.Month()) - int(normalizeFiscalStartMonth(fiscalStartMonth)) + 12) % 12
+	return months/3 + 1
+}
+
+// StartOfFiscalQuarter returns midnight of the first day of t's fiscal quarter in loc (nil loc = t's location)
+func StartOfFiscalQuarter(t time.Time, loc *time.Location, fiscalStartMonth time.Month) time.Time {
+	q := FiscalQuarter(t, loc, fiscalStartMonth)
+	return StartOfFiscalYear(t, loc, fiscalStartMonth).AddDate(0, (q-1)*3, 0)
+}
+
+// EndOfFiscalQuarter returns the last nanosecond of t's fiscal quarter in loc (nil loc = t's location)
+func EndOfFiscalQuarter(t time.Time, loc *time.Location, fiscalStartMonth time.Month) time.Time {
+	return StartOfFiscalQuarter(t, loc, fiscalStartMonth).AddDate(0, 3, 0).Add(-time.Nanosecond)
+}
+
+// timeInLocation converts t to loc, or returns t as is when loc is nil
+func timeInLocation(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return t
+	}
+
+	return t.In(loc)
+}
+
+// normalizeFiscalStartMonth treats an out of range month as january (calendar fiscal year)
+func normalizeFiscalStartMonth(m time.Month) time.Month {
+	if m < time.January || m > time.December {
+		return time.January
+	}
+
+	return m
+}
+
+// nthWeekdayOfMonth returns the date of the n-th weekday of the month (midnight UTC), n = -1 is the last one
+func nthWeekdayOfMonth(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	if n < 0 {
+		last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+		back := (int(last.Weekday()) - int(weekday) + 7) % 7
+		return last.AddDate(0, 0, -back)
+	}
+
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	ahead := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, ahead+(n-1)*7)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// multi-layout date time parser
+// ---------------------------------------------------------------------------------------------------------------------
+
+var (
+	// ErrDateTimeNoLayoutMatch is returned (wrapped) by ParseAnyDateTime when no layout parses the input
+	ErrDateTimeNoLayoutMatch = errors.New("date time matches no layout")
+
+	// ErrDateTimeAmbiguous is returned (wrapped) by ParseAnyDateTime when layouts parse the input into different times
+	ErrDateTimeAmbiguous = errors.New("date time is ambiguous")
+)
+
+// DateTimeLayout is one candidate format tried by ParseAnyDateTime
+type DateTimeLayout struct {
+	// Name identifies the layout, and is reported back by ParseAnyDateTime on match
+	Name string
+
+	// Zoned indicates Parse returns an absolute instant (offset or epoch based),
+	// otherwise the parsed wall clock time is placed into the loc given to ParseAnyDateTime
+	Zoned bool
+
+	// Parse returns the parsed time, or time.Time{} when s does not match the layout
+	Parse func(s string) time.Time
+}
+
+// NewDateTimeLayout creates a DateTimeLayout from a Go reference time layout such as "01/02/2006 15:04",
+// the layout is Zoned when it contains a zone offset or zone name element
+func NewDateTimeLayout(name string, layout string) DateTimeLayout {
+	zoned := strings.Contains(layout, "Z07") || strings.Contains(layout, "-07") || strings.Contains(layout, "MST")
+
+	return DateTimeLayout{
+		Name:  name,
+		Zoned: zoned,
+		Parse: func(s string) time.Time {
+			return ParseDateTimeCustom(s, layout)
+		},
+	}
+}
+
+// DefaultDateTimeLayouts returns a new copy of the ordered layout set ParseAnyDateTime uses by default,
+// covering every format of the ParseXyz helpers in this file, plus excel serial dates and unix epoch seconds / millis;
+// the slice may be reordered, trimmed or extended and passed to ParseAnyDateTime
+//
+// Note: excel serial dates are recognized with a 5 digit day serial (1927-05-18 to 2173-10-14), optionally with a fraction,
+// so they do not collide with the compact numeric layouts
+func DefaultDateTimeLayouts() []DateTimeLayout {
+	return []DateTimeLayout{
+		{Name: "ISO8601_RFC3339", Zoned: true, Parse: ParseDateTimeFrom_ISO8601_RFC3339},
+		{Name: "yyyy-mm-dd HH:mm:ss", Parse: ParseDateTime24Hr},
+		{Name: "yyyy-mm-dd hh:mm:ss tt", Parse: ParseDateTime},
+		{Name: "yyyy-mm-dd", Parse: ParseDate},
+		{Name: "hh:mm:ss tt", Parse: ParseTime},
+		{Name: "YYYYMMDDhhmmss", Parse: ParseDateTimeFromYYYYMMDDhhmmss},
+		{Name: "MMDDYYYYhhmmss", Parse: ParseDateTimeFromMMDDYYYYhhmmss},
+		{Name: "YYMMDDhhmmss", Parse: ParseDateTimeFromYYMMDDhhmmss},
+		{Name: "YYYYMMDD", Parse: ParseDateFromYYYYMMDD},
+		{Name: "MMDDYYYY", Parse: parseDateFromMMDDYYYY},
+		{Name: "DDMMYYYY", Parse: ParseDateFromDDMMYYYY},
+		{Name: "YYMMDD", Parse: ParseDateFromYYMMDD},
+		{Name: "hhmmss", Parse: ParseTimeFromhhmmss},
+		{Name: "YYMM", Parse: ParseDateFromYYMM},
+		{Name: "MMYY", Parse: ParseDateFromMMYY},
+		{Name: "MMDD", Parse: ParseDateFromMMDD},
+		{Name: "ExcelSerial", Parse: parseExcelSerialDate},
+		{Name: "EpochSeconds", Zoned: true, Parse: parseEpochSeconds},
+		{Name: "EpochMillis", Zoned: true, Parse: parseEpochMillis},
+	}
+}
+
+// ParseAnyDateTime parses s by trying every layout (DefaultDateTimeLayouts when none given),
+// and returns the parsed time with the Name of the first layout in order that matched;
+// wall clock layouts are interpreted in loc, zoned layouts are converted to loc (nil loc = UTC)
+//
+// if layouts parse s into different times, such as 03072026 as MMDDYYYY (March 7) versus DDMMYYYY (July 3),
+// the input is rejected with an error wrapping ErrDateTimeAmbiguous that names the conflicting layouts,
+// pass a narrowed layout set to resolve; no match returns an error wrapping ErrDateTimeNoLayoutMatch
+func ParseAnyDateTime(s string, loc *time.Location, layouts ...DateTimeLayout) (t time.Time, layoutName string, err error) {
+	s = strings.TrimSpace(s)
+
+	if len(s) == 0 {
+		return time.Time{}, "", fmt.Errorf("ParseAnyDateTime Input is Required")
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	if len(layouts) == 0 {
+		layouts = DefaultDateTimeLayouts()
+	}
+
+	var matched []string
+
+	for _, l := range layouts {
+		if l.Parse == nil {
+			continue
+		}
+
+		v := l.Parse(s)
+		if v.IsZero() {
+			continue
+		}
+
+		if l.Zoned {
+			v = v.In(loc)
+		} else {
+			v = time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), loc)
+		}
+
+		if len(matched) == 0 {
+			t, layoutName = v, l.Name
+		} else if !v.Equal(t) {
+			return time.Time{}, "", fmt.Errorf("ParseAnyDateTime '%s' Matches Layouts %s and %s With Different Results: %w",
+				s, strings.Join(matched, ", "), l.Name, ErrDateTimeAmbiguous)
+		}
+
+		matched = append(matched, l.Name)
+	}
+
+	if len(matched) == 0 {
+		return time.Time{}, "", fmt.Errorf("ParseAnyDateTime '%s': %w", s, ErrDateTimeNoLayoutMatch)
+	}
+
+	return t, layoutName, nil
+}
+
+// parseDateFromMMDDYYYY parses an 8 digit MMDDYYYY date, validated the same way as IsDateValidMMDDYYYY
+func parseDateFromMMDDYYYY(s string) time.Time {
+	if !IsDateValidMMDDYYYY(s) {
+		return time.Time{}
+	}
+
+	s = strings.TrimSpace(s)
+	return time.Date(Atoi(Right(s, 4)), time.Month(Atoi(Left(s, 2))), Atoi(Mid(s, 2, 2)), 0, 0, 0, 0, time.UTC)
+}
+
+// parseExcelSerialDate parses a 5 digit excel serial date with optional fraction of day
+func parseExcelSerialDate(s string) time.Time {
+	whole, frac, _ := strings.Cut(s, ".")
+
+	if len(whole) != 5 || !IsNumericIntOnly(whole) || (len(frac) > 0 && !IsNumericIntOnly(frac)) {
+		return time.Time{}
+	}
+
+	return ParseFromExcelDate(s, "")
+}
+
+// parseEpochSeconds parses a 10 digit unix epoch seconds value
+func parseEpochSeconds(s string) time.Time {
+	if len(s) != 10 || !IsNumericIntOnly(s) {
+		return time.Time{}
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(v, 0).UTC()
+}
+
+// parseEpochMillis parses a 13 digit unix epoch milliseconds value
+func parseEpochMillis(s string) time.Time {
+	if len(s) != 13 || !IsNumericIntOnly(s) {
+		return time.Time{}
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(v).UTC()
+}
@@ -0,0 +1,251 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for the business calendar, zone aware period and multi-layout parse helpers (helper-time.go).
+//
+// Dates are chosen around known US federal holidays and the 2026 US DST
+// transitions (March 8 and November 1 in America/New_York).
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+
+	return loc
+}
+
+func TestUSFederalHolidays(t *testing.T) {
+	want := map[string]string{
+		"20260101": "New Year's Day",
+		"20260119": "Birthday of Martin Luther King, Jr.",
+		"20260216": "Washington's Birthday",
+		"20260525": "Memorial Day",
+		"20260619": "Juneteenth National Independence Day",
+		"20260703": "Independence Day", // july 4 is a saturday
+		"20260907": "Labor Day",
+		"20261012": "Columbus Day",
+		"20261111": "Veterans Day",
+		"20261126": "Thanksgiving Day",
+		"20261225": "Christmas Day",
+	}
+
+	got := USFederalHolidays(2026)
+	if len(got) != len(want) {
+		t.Fatalf("USFederalHolidays(2026) returned %d holidays, want %d", len(got), len(want))
+	}
+
+	for _, h := range got {
+		if want[FormatDateToYYYYMMDD(h.Date)] != h.Name {
+			t.Errorf("unexpected holiday %s %s", FormatDateToYYYYMMDD(h.Date), h.Name)
+		}
+	}
+
+	cal := USFederalCalendar{}
+
+	// new year's day 2028 is a saturday, observed friday 2027-12-31
+	if !cal.IsHoliday(time.Date(2027, 12, 31, 9, 0, 0, 0, time.UTC)) {
+		t.Error("2027-12-31 should be the observed New Year's Day")
+	}
+
+	if cal.IsHoliday(time.Date(2026, 7, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Error("2026-07-04 is a saturday, only the observed friday is a holiday")
+	}
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	custom, err := ParseHolidayList([]string{"2026-11-27=Day After Thanksgiving", "20261224"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name, ok := custom.HolidayName(time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC)); !ok || name != "Day After Thanksgiving" {
+		t.Errorf("HolidayName = %q, %v", name, ok)
+	}
+
+	cal := HolidayCalendars{USFederalCalendar{}, custom}
+
+	// wednesday before thanksgiving + 1 business day skips thu (federal), fri (custom) and the weekend
+	start := time.Date(2026, 11, 25, 14, 30, 0, 0, time.UTC)
+
+	if got := AddBusinessDays(start, 1, cal); !got.Equal(time.Date(2026, 11, 30, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("AddBusinessDays(+1) = %v", got)
+	}
+
+	if got := AddBusinessDays(time.Date(2026, 11, 30, 14, 30, 0, 0, time.UTC), -1, cal); !got.Equal(start) {
+		t.Errorf("AddBusinessDays(-1) = %v", got)
+	}
+
+	if got := AddBusinessDays(start, 1, nil); got.Day() != 26 {
+		t.Errorf("AddBusinessDays without calendar = %v", got)
+	}
+
+	if got := NextBusinessDay(time.Date(2026, 12, 24, 8, 0, 0, 0, time.UTC), cal); got.Day() != 28 {
+		t.Errorf("NextBusinessDay = %v", got)
+	}
+
+	if n := BusinessDaysBetween(start, time.Date(2026, 12, 2, 0, 0, 0, 0, time.UTC), cal); n != 3 {
+		t.Errorf("BusinessDaysBetween = %d, want 3", n)
+	}
+
+	if _, err = ParseHolidayList([]string{"2026-02-30"}); err == nil {
+		t.Error("ParseHolidayList should reject invalid dates")
+	}
+
+	// a calendar without any business day returns the input instead of looping forever
+	if got := AddBusinessDays(start, 1, everyDayHoliday{}); !got.Equal(start) {
+		t.Errorf("AddBusinessDays with no business days = %v", got)
+	}
+
+	if got := NextBusinessDay(start, everyDayHoliday{}); !got.Equal(start) {
+		t.Errorf("NextBusinessDay with no business days = %v", got)
+	}
+
+	// the returned holidays are a copy of the cached list
+	USFederalHolidays(2026)[0].Name = "changed"
+	if USFederalHolidays(2026)[0].Name != "New Year's Day" {
+		t.Error("USFederalHolidays should not expose its cache")
+	}
+}
+
+type everyDayHoliday struct{}
+
+func (everyDayHoliday) IsHoliday(time.Time) bool { return true }
+
+func TestAddDaysSameWallClock_DST(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+
+	// the day before spring forward: same wall clock tomorrow is only 23 hours later
+	before := time.Date(2026, 3, 7, 9, 0, 0, 0, ny)
+	next := SameWallClockTomorrow(before)
+
+	if next.Hour() != 9 || next.Day() != 8 || next.Sub(before) != 23*time.Hour {
+		t.Errorf("SameWallClockTomorrow = %v (%v later)", next, next.Sub(before))
+	}
+
+	// business days across fall back keep 09:00
+	fri := time.Date(2026, 10, 30, 9, 0, 0, 0, ny)
+	if got := AddBusinessDays(fri, 1, nil); got.Hour() != 9 || got.Day() != 2 {
+		t.Errorf("AddBusinessDays across DST = %v", got)
+	}
+
+	if got := EndOfDay(time.Date(2026, 11, 1, 12, 0, 0, 0, ny), nil); got.Sub(StartOfDay(got, nil)) != 25*time.Hour-time.Nanosecond {
+		t.Errorf("EndOfDay on fall back day = %v", got)
+	}
+}
+
+func TestPeriodBoundaries(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+
+	// 2026-10-01 02:00 UTC is still september 30 in new york
+	ts := time.Date(2026, 10, 1, 2, 0, 0, 0, time.UTC)
+
+	if got := StartOfQuarter(ts, ny); !got.Equal(time.Date(2026, 7, 1, 0, 0, 0, 0, ny)) {
+		t.Errorf("StartOfQuarter = %v", got)
+	}
+
+	if got := StartOfQuarter(ts, nil); got.Month() != time.October {
+		t.Errorf("StartOfQuarter(nil loc) = %v", got)
+	}
+
+	if got := EndOfQuarter(ts, ny); !got.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, ny).Add(-time.Nanosecond)) {
+		t.Errorf("EndOfQuarter = %v", got)
+	}
+
+	// 2026-09-30 is a wednesday
+	if got := StartOfWeek(ts, ny, time.Monday); !got.Equal(time.Date(2026, 9, 28, 0, 0, 0, 0, ny)) {
+		t.Errorf("StartOfWeek(monday) = %v", got)
+	}
+
+	if got := EndOfWeek(ts, ny, time.Sunday); !got.Equal(time.Date(2026, 10, 4, 0, 0, 0, 0, ny).Add(-time.Nanosecond)) {
+		t.Errorf("EndOfWeek(sunday) = %v", got)
+	}
+
+	if fy := FiscalYear(ts, ny, time.October); fy != 2026 {
+		t.Errorf("FiscalYear in new york = %d, want 2026", fy)
+	}
+
+	if fy := FiscalYear(ts, time.UTC, time.October); fy != 2027 {
+		t.Errorf("FiscalYear in utc = %d, want 2027", fy)
+	}
+
+	if q := FiscalQuarter(ts, ny, time.October); q != 4 {
+		t.Errorf("FiscalQuarter = %d, want 4", q)
+	}
+
+	if got := StartOfFiscalQuarter(ts, time.UTC, time.October); !got.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("StartOfFiscalQuarter = %v", got)
+	}
+
+	if got := EndOfFiscalYear(ts, ny, time.July); !got.Equal(time.Date(2027, 7, 1, 0, 0, 0, 0, ny).Add(-time.Nanosecond)) {
+		t.Errorf("EndOfFiscalYear = %v", got)
+	}
+}
+
+func TestParseAnyDateTime(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+
+	cases := []struct {
+		in     string
+		layout string
+		want   time.Time
+	}{
+		{"20260307143005", "YYYYMMDDhhmmss", time.Date(2026, 3, 7, 14, 30, 5, 0, ny)},
+		{"12312026", "MMDDYYYY", time.Date(2026, 12, 31, 0, 0, 0, 0, ny)},
+		{"2026-03-07T14:30:05Z", "ISO8601_RFC3339", time.Date(2026, 3, 7, 14, 30, 5, 0, time.UTC)},
+		{"2026-03-07 14:30:05", "yyyy-mm-dd HH:mm:ss", time.Date(2026, 3, 7, 14, 30, 5, 0, ny)},
+		{"46088.5", "ExcelSerial", time.Date(2026, 3, 7, 12, 0, 0, 0, ny)},
+		{"1772893805", "EpochSeconds", time.Date(2026, 3, 7, 14, 30, 5, 0, time.UTC)},
+		{"1772893805123", "EpochMillis", time.Date(2026, 3, 7, 14, 30, 5, 123000000, time.UTC)},
+	}
+
+	for _, c := range cases {
+		got, layout, err := ParseAnyDateTime(c.in, ny)
+		if err != nil {
+			t.Errorf("ParseAnyDateTime(%q) error: %v", c.in, err)
+			continue
+		}
+
+		if layout != c.layout || !got.Equal(c.want) || got.Location() != ny {
+			t.Errorf("ParseAnyDateTime(%q) = %v, %s; want %v, %s", c.in, got, layout, c.want, c.layout)
+		}
+	}
+}
+
+func TestParseAnyDateTime_Rejects(t *testing.T) {
+	// march 7 as MMDDYYYY versus july 3 as DDMMYYYY
+	if _, _, err := ParseAnyDateTime("03072026", nil); !errors.Is(err, ErrDateTimeAmbiguous) {
+		t.Errorf("expected ambiguous error, got %v", err)
+	}
+
+	if _, _, err := ParseAnyDateTime("not a date", nil); !errors.Is(err, ErrDateTimeNoLayoutMatch) {
+		t.Errorf("expected no match error, got %v", err)
+	}
+
+	// a narrowed layout set resolves the ambiguity
+	dmy := NewDateTimeLayout("DD/MM/YYYY", "02/01/2006")
+	layouts := append([]DateTimeLayout{dmy}, DefaultDateTimeLayouts()[10]) // DDMMYYYY
+
+	got, layout, err := ParseAnyDateTime("03072026", nil, layouts...)
+	if err != nil || layout != "DDMMYYYY" || got.Month() != time.July {
+		t.Errorf("narrowed ParseAnyDateTime = %v, %s, %v", got, layout, err)
+	}
+
+	if got, layout, err = ParseAnyDateTime("03/07/2026", nil, layouts...); err != nil || layout != "DD/MM/YYYY" || got.Day() != 3 {
+		t.Errorf("custom layout ParseAnyDateTime = %v, %s, %v", got, layout, err)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"math"
 	"math/big"
@@ -242,6 +243,264 @@ func IsULIDValid(ulidStr string) bool {
 	}
 }
 
+// ================================================================================================================
+// UUIDv7 HELPERS
+// ================================================================================================================
+
+// UUIDv7Generator generates RFC 9562 UUID Version 7 values (unix millisecond timestamp + random),
+// which sort by creation time as strings or bytes, making them index-friendly primary / sort keys;
+// ids from one generator are strictly increasing even within the same millisecond (12 bit rand_a counter, RFC 9562 method 1),
+// and the generator is safe for concurrent use
+type UUIDv7Generator struct {
+	mu     sync.Mutex
+	lastMs int64
+	seq    uint16
+
+	now func() time.Time // test hook, defaults to time.Now
+}
+
+// defaultUUIDv7Generator backs GenerateUUIDv7 so ids are monotonic across the whole process
+var defaultUUIDv7Generator = NewUUIDv7Generator()
+
+// NewUUIDv7Generator creates a new monotonic UUIDv7 generator,
+// use the package level GenerateUUIDv7 unless a separate sequence is required
+func NewUUIDv7Generator() *UUIDv7Generator {
+	return &UUIDv7Generator{}
+}
+
+// Generate returns the next UUIDv7 in canonical 8-4-4-4-12 form
+func (g *UUIDv7Generator) Generate() (string, error) {
+	if g == nil {
+		return "", fmt.Errorf("UUIDv7Generator is Nil")
+	}
+
+	var b [16]byte
+
+	// random bytes for rand_b (and counter seed) are read before locking to keep the critical section short
+	if err := readRandomBytes(b[:]); err != nil {
+		return "", err
+	}
+
+	g.mu.Lock()
+
+	nowFn := g.now
+	if nowFn == nil {
+		nowFn = time.Now
+	}
+
+	ms := nowFn().UnixMilli()
+
+	if ms > g.lastMs {
+		// new millisecond, seed the counter randomly with its top bit clear to leave room for increments
+		g.lastMs = ms
+		g.seq = binary.BigEndian.Uint16(b[6:8]) & 0x07ff
+	} else if g.seq < 0x0fff {
+		// same millisecond, or clock moved backwards: keep the last timestamp and count up
+		g.seq++
+	} else {
+		// counter exhausted, borrow the next millisecond so ordering is preserved
+		g.lastMs++
+		g.seq = 0
+	}
+
+	ms, seq := g.lastMs, g.seq
+	g.mu.Unlock()
+
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	b[6] = 0x70 | byte(seq>>8) // version 7 + counter high bits
+	b[7] = byte(seq)
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 9562 variant
+
+	id, err := uuid.FromBytes(b[:])
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// GenerateUUIDv7 will generate a UUID Version 7 (time ordered) from the process wide monotonic generator
+func GenerateUUIDv7() (string, error) {
+	return defaultUUIDv7Generator.Generate()
+}
+
+// NewUUIDv7 will generate a UUID Version 7 and ignore error if any
+func NewUUIDv7() string {
+	id, _ := GenerateUUIDv7()
+	return id
+}
+
+// GetUUIDv7Timestamp will return the millisecond timestamp embedded in the uuid v7 string
+func GetUUIDv7Timestamp(uuidStr string) (time.Time, error) {
+	id, err := uuid.Parse(uuidStr)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if id.Version() != 7 {
+		return time.Time{}, fmt.Errorf("UUID Version 7 Expected, Version %d Given", id.Version())
+	}
+
+	if id.Variant() != uuid.RFC4122 {
+		return time.Time{}, fmt.Errorf("UUID Variant RFC 9562 Expected, %s Given", id.Variant())
+	}
+
+	ms := int64(id[0])<<40 | int64(id[1])<<32 | int64(id[2])<<24 | int64(id[3])<<16 | int64(id[4])<<8 | int64(id[5])
+	return time.UnixMilli(ms), nil
+}
+
+// IsUUIDv7Valid will check if the uuid string is a valid uuid version 7
+func IsUUIDv7Valid(uuidStr string) bool {
+	_, err := GetUUIDv7Timestamp(uuidStr)
+	return err == nil
+}
+
+// readRandomBytes fills b from crypto/rand, falling back to the reseeded fallback RNG during crypto/rand outages
+func readRandomBytes(b []byte) error {
+	if _, err := rand.Read(b); err == nil {
+		return nil
+	}
+
+	reseedFallbackRandLocked(fallbackSeed(), time.Now())
+
+	n, err := lockedFallbackReader{}.Read(b)
+	if err != nil {
+		return err
+	}
+
+	if n != len(b) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return nil
+}
+
+// ================================================================================================================
+// SNOWFLAKE ID HELPERS
+// ================================================================================================================
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeTimeBits     = 63 - snowflakeNodeBits - snowflakeSequenceBits
+
+	// SnowflakeMaxNodeID is the largest node id a SnowflakeGenerator accepts
+	SnowflakeMaxNodeID = 1<<snowflakeNodeBits - 1
+
+	snowflakeMaxSequence = 1<<snowflakeSequenceBits - 1
+	snowflakeMaxTime     = 1<<snowflakeTimeBits - 1
+)
+
+// SnowflakeDefaultEpoch is used when NewSnowflakeGenerator is given a zero epoch
+var SnowflakeDefaultEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// SnowflakeGenerator generates Snowflake-style positive int64 ids,
+// laid out as 41 bits milliseconds since epoch, 10 bits node id, 12 bits per millisecond sequence,
+// ids from one generator are strictly increasing, and the generator is safe for concurrent use;
+// each process (or pod) generating ids for the same table must use a distinct node id
+type SnowflakeGenerator struct {
+	mu      sync.Mutex
+	epochMs int64
+	nodeID  int64
+	lastMs  int64
+	seq     int64
+
+	now func() time.Time // test hook, defaults to time.Now
+}
+
+// NewSnowflakeGenerator creates a snowflake generator for nodeID (0 to SnowflakeMaxNodeID),
+// epoch is the custom epoch ids count from (zero time = SnowflakeDefaultEpoch), ids remain valid for ~69 years after it
+func NewSnowflakeGenerator(nodeID int64, epoch time.Time) (*SnowflakeGenerator, error) {
+	if nodeID < 0 || nodeID > SnowflakeMaxNodeID {
+		return nil, fmt.Errorf("Snowflake Node ID Must Be 0 to %d", SnowflakeMaxNodeID)
+	}
+
+	if epoch.IsZero() {
+		epoch = SnowflakeDefaultEpoch
+	}
+
+	if epoch.After(time.Now()) {
+		return nil, fmt.Errorf("Snowflake Epoch Must Not Be in the Future")
+	}
+
+	return &SnowflakeGenerator{
+		epochMs: epoch.UnixMilli(),
+		nodeID:  nodeID,
+		lastMs:  -1,
+	}, nil
+}
+
+// Generate returns the next snowflake id,
+// within a millisecond up to 4096 ids are issued, beyond that the next millisecond is borrowed so ids never repeat,
+// if the wall clock moves backwards the generator keeps counting from its last timestamp
+func (g *SnowflakeGenerator) Generate() (int64, error) {
+	if g == nil {
+		return 0, fmt.Errorf("SnowflakeGenerator is Nil")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	nowFn := g.now
+	if nowFn == nil {
+		nowFn = time.Now
+	}
+
+	ms := nowFn().UnixMilli() - g.epochMs
+
+	if ms < 0 {
+		return 0, fmt.Errorf("Snowflake Clock is Before Epoch")
+	}
+
+	if ms > g.lastMs {
+		g.lastMs = ms
+		g.seq = 0
+	} else if g.seq < snowflakeMaxSequence {
+		g.seq++
+	} else {
+		g.lastMs++
+		g.seq = 0
+	}
+
+	if g.lastMs > snowflakeMaxTime {
+		return 0, fmt.Errorf("Snowflake Timestamp Exceeds %d Bits Since Epoch", snowflakeTimeBits)
+	}
+
+	return g.lastMs<<(snowflakeNodeBits+snowflakeSequenceBits) | g.nodeID<<snowflakeSequenceBits | g.seq, nil
+}
+
+// NodeID returns the node id of the generator
+func (g *SnowflakeGenerator) NodeID() int64 {
+	if g == nil {
+		return 0
+	}
+
+	return g.nodeID
+}
+
+// ParseSnowflakeID splits a snowflake id into its timestamp, node id and sequence,
+// epoch must be the same epoch the generator was created with (zero time = SnowflakeDefaultEpoch)
+func ParseSnowflakeID(id int64, epoch time.Time) (ts time.Time, nodeID int64, seq int64, err error) {
+	if id < 0 {
+		return time.Time{}, 0, 0, fmt.Errorf("Snowflake ID Must Not Be Negative")
+	}
+
+	if epoch.IsZero() {
+		epoch = SnowflakeDefaultEpoch
+	}
+
+	ms := id >> (snowflakeNodeBits + snowflakeSequenceBits)
+	nodeID = (id >> snowflakeSequenceBits) & SnowflakeMaxNodeID
+	seq = id & snowflakeMaxSequence
+
+	return time.UnixMilli(epoch.UnixMilli() + ms), nodeID, seq, nil
+}
+
 // ================================================================================================================
 // Random Number Generator
 // ================================================================================================================
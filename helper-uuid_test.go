@@ -0,0 +1,149 @@
+package helper
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ */
+
+// Tests for the UUIDv7 and Snowflake id generators (helper-uuid.go).
+//
+// Both generators promise strictly increasing ids per generator, including
+// when many ids land in the same millisecond or the clock steps backwards,
+// so the tests drive them with a frozen / rewound clock.
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUUIDv7_TimestampAndFormat(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+
+	id, err := GenerateUUIDv7()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(id) != 36 || id[14] != '7' {
+		t.Fatalf("GenerateUUIDv7() = %q, expected canonical version 7 uuid", id)
+	}
+
+	ts, err := GetUUIDv7Timestamp(id)
+	if err != nil || ts.Before(before) || ts.After(time.Now()) {
+		t.Fatalf("GetUUIDv7Timestamp = %v, %v (generated after %v)", ts, err, before)
+	}
+
+	if !IsUUIDv7Valid(id) || IsUUIDv7Valid(NewUUID()) || IsUUIDv7Valid("not-a-uuid") {
+		t.Error("IsUUIDv7Valid must accept v7 only")
+	}
+}
+
+func TestUUIDv7Generator_Monotonic(t *testing.T) {
+	frozen := time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)
+	g := NewUUIDv7Generator()
+	g.now = func() time.Time { return frozen }
+
+	var prev string
+
+	// more ids than the 12 bit counter holds within one frozen millisecond
+	for i := 0; i < 6000; i++ {
+		if i == 3000 {
+			frozen = frozen.Add(-time.Second) // clock steps backwards
+		}
+
+		id, err := g.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id <= prev {
+			t.Fatalf("id %d %q not greater than previous %q", i, id, prev)
+		}
+		prev = id
+	}
+}
+
+func TestUUIDv7_Concurrent(t *testing.T) {
+	const workers, perWorker = 8, 500
+
+	ids := make(chan string, workers*perWorker)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				ids <- NewUUIDv7()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("duplicate uuid v7 %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestSnowflakeGenerator(t *testing.T) {
+	if _, err := NewSnowflakeGenerator(SnowflakeMaxNodeID+1, time.Time{}); err == nil {
+		t.Error("expected node id range error")
+	}
+
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	g, err := NewSnowflakeGenerator(37, epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frozen := time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return frozen }
+
+	var ids []int64
+
+	for i := 0; i < 5000; i++ {
+		if i == 2500 {
+			frozen = frozen.Add(-time.Minute)
+		}
+
+		id, err := g.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	if !sort.SliceIsSorted(ids, func(i, j int) bool { return ids[i] < ids[j] }) {
+		t.Fatal("snowflake ids are not increasing")
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] == ids[i-1] {
+			t.Fatalf("duplicate snowflake id %d", ids[i])
+		}
+	}
+
+	ts, node, seq, err := ParseSnowflakeID(ids[0], epoch)
+	if err != nil || !ts.Equal(time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC)) || node != 37 || seq != 0 {
+		t.Fatalf("ParseSnowflakeID = %v, %d, %d, %v", ts, node, seq, err)
+	}
+
+	// 4096 ids fill the first millisecond, the next borrows the following one
+	if ts, _, seq, _ = ParseSnowflakeID(ids[4096], epoch); seq != 0 || ts.Sub(frozen.Add(time.Minute)) != time.Millisecond {
+		t.Fatalf("borrowed id = %v seq %d", ts, seq)
+	}
+
+	g.now = func() time.Time { return epoch.Add(-time.Hour) }
+	g.lastMs = -1
+	if _, err = g.Generate(); err == nil {
+		t.Error("expected clock before epoch error")
+	}
+}
@@ -3,9 +3,14 @@ package aws
 import (
 	"errors"
 
+	util "github.com/aldelo/common"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 )
 
+func init() {
+	util.RegisterErrorAdapter(awsErrorAdapter)
+}
+
 func ToAwsError(err error) awserr.Error {
 	if err == nil {
 		return nil
@@ -18,3 +23,69 @@ func ToAwsError(err error) awserr.Error {
 		return awserr.New("UnknownError", err.Error(), err)
 	}
 }
+
+// ToStructuredError converts err into a util.StructuredError, aws sdk errors keep their code with a fixed client safe
+// message, the aws message and original error stay in the cause (logs only), and request failures add the
+// status_code and request_id log fields; other errors convert as in util.ToStructuredError
+func ToStructuredError(err error) *util.StructuredError {
+	if se := awsErrorAdapter(err); se != nil {
+		return se
+	}
+
+	return util.ToStructuredError(err)
+}
+
+// awsErrorAdapter is registered with util.RegisterErrorAdapter so util.ToStructuredError understands awserr.Error
+func awsErrorAdapter(err error) *util.StructuredError {
+	var e awserr.Error
+	if err == nil || !errors.As(err, &e) || e == nil {
+		return nil
+	}
+
+	se := util.NewError(e.Code(), awsClientMessage(e.Code()))
+	se.Cause = awsCause{err: e}
+
+	var rf awserr.RequestFailure
+	if errors.As(err, &rf) && rf != nil {
+		se.With("status_code", rf.StatusCode()).With("request_id", rf.RequestID())
+	}
+
+	return se
+}
+
+// awsCause is the cause of an aws structured error, its text is the full aws error (code, message and original
+// error) for logs, and it unwraps to the original error so errors.Is / errors.As reach it
+type awsCause struct {
+	err awserr.Error
+}
+
+// Error returns the full aws error text
+func (c awsCause) Error() string {
+	return c.err.Error()
+}
+
+// Unwrap returns the aws error and its original error, if any
+func (c awsCause) Unwrap() []error {
+	if orig := c.err.OrigErr(); orig != nil {
+		return []error{c.err, orig}
+	}
+	return []error{c.err}
+}
+
+// awsClientMessage returns the fixed client safe message of an aws error code,
+// the aws message may name resources, accounts or request detail and is not returned to clients
+func awsClientMessage(code string) string {
+	switch code {
+	case "Throttling", "ThrottlingException", "TooManyRequestsException", "RequestLimitExceeded",
+		"ProvisionedThroughputExceededException", "SlowDown":
+		return "Service Busy, Retry Later"
+	case "ResourceNotFoundException", "NotFound", "NoSuchKey", "NoSuchBucket":
+		return "Resource Not Found"
+	case "AccessDenied", "AccessDeniedException", "UnauthorizedOperation":
+		return "Access Denied"
+	case "ConditionalCheckFailedException", "TransactionCanceledException":
+		return "Condition Check Failed"
+	default:
+		return "Service Request Failed"
+	}
+}
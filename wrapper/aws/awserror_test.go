@@ -0,0 +1,48 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	util "github.com/aldelo/common"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// TestToStructuredError verifies aws sdk errors keep code, cause and request failure detail
+// with a client safe message, both directly and through util.ToStructuredError.
+func TestToStructuredError(t *testing.T) {
+	rf := awserr.NewRequestFailure(awserr.New("ThrottlingException", "Rate exceeded", io.ErrUnexpectedEOF), 400, "req-123")
+	err := fmt.Errorf("put item: %w", rf)
+
+	for name, se := range map[string]*util.StructuredError{
+		"aws":  ToStructuredError(err),
+		"util": util.ToStructuredError(err),
+	} {
+		if se.Code != "ThrottlingException" || se.Message != "Service Busy, Retry Later" {
+			t.Errorf("%s: code / message = %q / %q", name, se.Code, se.Message)
+		}
+
+		if !errors.Is(se, io.ErrUnexpectedEOF) {
+			t.Errorf("%s: cause not preserved", name)
+		}
+
+		if se.Fields["status_code"] != 400 || se.Fields["request_id"] != "req-123" {
+			t.Errorf("%s: fields = %v", name, se.Fields)
+		}
+
+		// the aws message stays in the cause for logs only
+		if !strings.Contains(se.Error(), "Rate exceeded") {
+			t.Errorf("%s: cause text lost: %s", name, se.Error())
+		}
+		if resp := util.ToErrorResponse(se); strings.Contains(resp.Message, "Rate exceeded") || resp.Fields != nil {
+			t.Errorf("%s: client response = %+v", name, resp)
+		}
+	}
+
+	if se := ToStructuredError(io.EOF); se.Code != util.ErrorCodeUnknown {
+		t.Errorf("plain error code = %q", se.Code)
+	}
+}
@@ -156,6 +156,31 @@ func (e *DynamoDBError) Unwrap() error {
 	return nil
 }
 
+// ToStructuredError converts e into a util.StructuredError for uniform logging and api responses,
+// the code names the failure kind with a fixed client safe message, e itself is the cause so its aws detail
+// is only logged, and the retry advice flags are kept as log fields;
+// the ErrResultSetTooLarge sentinel stays reachable through errors.Is on the result
+func (e *DynamoDBError) ToStructuredError() *util.StructuredError {
+	if e == nil {
+		return nil
+	}
+
+	code, msg := "DynamoDBError", "Data Service Request Failed"
+	if e.ResultSetTooLarge {
+		code, msg = "DynamoDBResultSetTooLarge", "Query Result Set Too Large"
+	} else if e.TransactionConditionalCheckFailed {
+		code, msg = "DynamoDBTransactionConditionalCheckFailed", "Transaction Condition Check Failed"
+	}
+
+	se := util.NewError(code, msg).
+		With("allow_retry", e.AllowRetry).
+		With("retry_needs_backoff", e.RetryNeedsBackOff).
+		With("suppress_error", e.SuppressError)
+	se.Cause = e
+
+	return se
+}
+
 // =====================================================================================================================
 // Query result-set fail-stop (latent O(N)/OOM guardrail)
 // =====================================================================================================================
@@ -33,12 +33,15 @@ package dynamodb
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 	"testing"
 	"time"
 
+	util "github.com/aldelo/common"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
@@ -719,3 +722,34 @@ func TestSetLastExecuteParamsPayload_DisabledSkipsAllocation_P1PERF2(t *testing.
 		t.Fatalf("LastExecuteParamsPayload = %q, want %q — disabled path must not mutate field", got, want)
 	}
 }
+
+// TestDynamoDBError_ToStructuredError pins the code and retry advice fields of the
+// util.StructuredError conversion, and that the fail-stop sentinel stays detectable.
+func TestDynamoDBError_ToStructuredError(t *testing.T) {
+	var nilErr *DynamoDBError
+	if nilErr.ToStructuredError() != nil {
+		t.Fatal("nil DynamoDBError must convert to nil")
+	}
+
+	se := util.ToStructuredError(fmt.Errorf("query: %w", &DynamoDBError{ErrorMessage: "too many", ResultSetTooLarge: true}))
+	if se.Code != "DynamoDBResultSetTooLarge" || se.Message != "Query Result Set Too Large" {
+		t.Fatalf("code / message = %q / %q", se.Code, se.Message)
+	}
+
+	// the raw error text stays in the cause for logs, clients get the fixed message and no retry advice fields
+	if b, _ := json.Marshal(se); !strings.Contains(string(b), "too many") {
+		t.Fatalf("log json lost the cause: %s", b)
+	}
+	if resp := util.ToErrorResponse(se); resp.Message != "Query Result Set Too Large" || resp.Fields != nil {
+		t.Fatalf("client response = %+v", resp)
+	}
+
+	if !errors.Is(se, ErrResultSetTooLarge) {
+		t.Fatal("expected ErrResultSetTooLarge through the structured error")
+	}
+
+	se = (&DynamoDBError{ErrorMessage: "throttled", AllowRetry: true, RetryNeedsBackOff: true}).ToStructuredError()
+	if se.Code != "DynamoDBError" || se.Fields["allow_retry"] != true || se.Fields["retry_needs_backoff"] != true || se.Fields["suppress_error"] != false {
+		t.Fatalf("unexpected conversion: %+v", se)
+	}
+}
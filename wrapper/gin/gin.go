@@ -590,6 +590,17 @@ func (g *Gin) BindPostForm(outputPtr interface{}, tagName string, c *gin.Context
 	return nil
 }
 
+// BindPostFormValidated will bind the post form data to outputPtr via BindPostForm,
+// then evaluate the struct tag rules of outputPtr via util.ValidateStruct,
+// returning util.StructValidationErrors listing every field path that violates its rules
+func (g *Gin) BindPostFormValidated(outputPtr interface{}, tagName string, c *gin.Context) error {
+	if err := g.BindPostForm(outputPtr, tagName, c); err != nil {
+		return err
+	}
+
+	return util.ValidateStruct(outputPtr)
+}
+
 // bindInput will attempt to bind input data to target binding output, for example json string to struct mapped to json elements
 //
 // bindObjPtr = pointer to the target object, cannot be nil
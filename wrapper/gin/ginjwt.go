@@ -17,17 +17,21 @@ package gin
  */
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	util "github.com/aldelo/common"
+	"github.com/aldelo/common/crypto"
 	"github.com/aldelo/common/wrapper/gin/ginbindtype"
 	"github.com/aldelo/common/wrapper/gin/ginjwtsignalgorithm"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v4"
 )
 
 // NewGinJwtMiddleware helper method returns a GinJwt struct object ready for setup and use
@@ -55,6 +59,14 @@ func NewGinJwtMiddleware(realm string, identityKey string, signingSecretKey stri
 // SigningAlgorithm = (required) HS256, HS384, HS512, RS256, RS384 or RS512 Optional, default is HS256
 // PrivateKeyFile = (optional) Private key file for asymmetric algorithms
 // PublicKeyFile = (optional) Public key file for asymmetric algorithms
+// VerifyKeySet = (optional) JWKS cache used to verify tokens carrying a kid header, such as partner issued tokens,
+//
+//	HS256, RS256, ES256 and EdDSA tokens are accepted when the kid's key matches the token alg,
+//	tokens without a kid continue to verify against SigningSecretKey or PublicKeyFile,
+//	tokens verified through the key set are never refreshed, the refresh route only serves tokens signed with the own key
+//
+// VerifyIssuer = (required if VerifyKeySet is set) iss claim that tokens verified through VerifyKeySet must carry
+// VerifyAudience = (required if VerifyKeySet is set) aud claim that tokens verified through VerifyKeySet must carry
 // TokenValidDuration = (required) Duration that a jwt token is valid. Optional, defaults to one hour. (aka Timeout)
 // TokenMaxRefreshDuration = (required) This field allows clients to refresh their token until MaxRefresh has passed,
 //
@@ -165,6 +177,15 @@ type GinJwt struct {
 	// Public key file for asymmetric algorithms
 	PublicKeyFile string
 
+	// JWKS cache used to verify tokens carrying a kid header, tokens without a kid use the signing key above
+	VerifyKeySet *crypto.JwksCache
+
+	// iss claim required on tokens verified through VerifyKeySet, required when VerifyKeySet is set
+	VerifyIssuer string
+
+	// aud claim required on tokens verified through VerifyKeySet, required when VerifyKeySet is set
+	VerifyAudience string
+
 	// Duration that a jwt token is valid. Optional, defaults to one hour. (aka Timeout)
 	TokenValidDuration time.Duration
 
@@ -557,6 +578,22 @@ func (j *GinJwt) BuildGinJwtMiddleware(g *Gin) error {
 		return fmt.Errorf("Init Gin Jwt Middleware Failed: %w", errInit)
 	}
 
+	// refresh re-signs the presented claims with the own key, so it is served by a middleware copy that only verifies own key tokens
+	refreshMiddleware := authMiddleware
+
+	// set after init, since a KeyFunc makes init skip loading the middleware's own keys used for login signing
+	if j.VerifyKeySet != nil {
+		keyFunc, refreshKeyFunc, errKey := j.keySetKeyFunc()
+		if errKey != nil {
+			return fmt.Errorf("Init Gin Jwt Middleware Failed: %w", errKey)
+		}
+		authMiddleware.KeyFunc = keyFunc
+
+		refreshCopy := *authMiddleware
+		refreshCopy.KeyFunc = refreshKeyFunc
+		refreshMiddleware = &refreshCopy
+	}
+
 	// setup login route for LoginHandler
 	if util.LenTrim(j.LoginRoutePath) > 0 {
 		log.Println("Jwt Auth Login Set: (Custom) " + j.LoginRoutePath)
@@ -578,10 +615,10 @@ func (j *GinJwt) BuildGinJwtMiddleware(g *Gin) error {
 	// setup refresh token route for RefreshHandler
 	if util.LenTrim(j.RefreshTokenRoutePath) > 0 {
 		log.Println("Jwt Token Refresh Set: (Custom) " + j.RefreshTokenRoutePath)
-		g._ginEngine.GET(j.RefreshTokenRoutePath, authMiddleware.RefreshHandler)
+		g._ginEngine.GET(j.RefreshTokenRoutePath, refreshMiddleware.RefreshHandler)
 	} else {
 		log.Println("Jwt Token Refresh Set: (Default) " + "/refreshtoken")
-		g._ginEngine.GET("/refreshtoken", authMiddleware.RefreshHandler)
+		g._ginEngine.GET("/refreshtoken", refreshMiddleware.RefreshHandler)
 	}
 
 	// setup no route handler
@@ -597,6 +634,73 @@ func (j *GinJwt) BuildGinJwtMiddleware(g *Gin) error {
 	return nil
 }
 
+// keySetKeyFunc returns the jwt key funcs used when VerifyKeySet is set,
+// verify resolves the key of kid tokens through the key set after checking their iss and aud claims, others use the middleware's own verification key,
+// refresh only accepts tokens without a kid, so tokens issued by a key set party are never re-signed with the own key
+func (j *GinJwt) keySetKeyFunc() (verify func(token *gojwt.Token) (interface{}, error), refresh func(token *gojwt.Token) (interface{}, error), err error) {
+	if util.LenTrim(j.VerifyIssuer) == 0 {
+		return nil, nil, fmt.Errorf("Verify Issuer is Required When Verify Key Set is Set")
+	}
+
+	if util.LenTrim(j.VerifyAudience) == 0 {
+		return nil, nil, fmt.Errorf("Verify Audience is Required When Verify Key Set is Set")
+	}
+
+	var ownKey interface{} = []byte(j.SigningSecretKey)
+
+	if strings.HasPrefix(j.SigningAlgorithm.Key(), "RS") {
+		data, e := util.FileReadBytes(j.PublicKeyFile)
+		if e != nil {
+			return nil, nil, fmt.Errorf("Read Public Key File Failed: %w", e)
+		}
+
+		if ownKey, e = gojwt.ParseRSAPublicKeyFromPEM(data); e != nil {
+			return nil, nil, fmt.Errorf("Parse Public Key File Failed: %w", e)
+		}
+	}
+
+	refresh = func(token *gojwt.Token) (interface{}, error) {
+		if kid, _ := token.Header["kid"].(string); kid != "" {
+			return nil, fmt.Errorf("Key Set Verified Token Cannot Be Refreshed")
+		}
+
+		if token.Method.Alg() != j.SigningAlgorithm.Key() {
+			return nil, jwt.ErrInvalidSigningAlgorithm
+		}
+
+		return ownKey, nil
+	}
+
+	verify = func(token *gojwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		if kid == "" {
+			return refresh(token)
+		}
+
+		claims, ok := token.Claims.(gojwt.MapClaims)
+		if !ok {
+			return nil, fmt.Errorf("Key Set Token Claims Not Supported")
+		}
+
+		if !claims.VerifyIssuer(j.VerifyIssuer, true) {
+			return nil, fmt.Errorf("Key Set Token Issuer Not Accepted")
+		}
+
+		if !claims.VerifyAudience(j.VerifyAudience, true) {
+			return nil, fmt.Errorf("Key Set Token Audience Not Accepted")
+		}
+
+		// the key func has no request context, bound a possible jwks refresh instead
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		return j.VerifyKeySet.VerificationKey(ctx, kid, token.Method.Alg())
+	}
+
+	return verify, refresh, nil
+}
+
 // AuthMiddleware returns the GinJwt Middleware HandlerFunc,
 // so that it can perform jwt related auth services,
 // for all route path defined within the same router group
@@ -0,0 +1,170 @@
+package gin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aldelo/common/crypto"
+	"github.com/aldelo/common/wrapper/gin/ginbindtype"
+	"github.com/aldelo/common/wrapper/gin/ginjwtsignalgorithm"
+	ginlib "github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v4"
+)
+
+// --------------------------------------------------------------------------
+// GinJwt VerifyKeySet
+// --------------------------------------------------------------------------
+
+func TestGinJwt_KeySetKeyFunc(t *testing.T) {
+	partnerPvk, _, _ := crypto.EcdsaP256CreateKey()
+	partnerKey, _ := crypto.JwkFromPrivateKey(partnerPvk, "partner-1", "sig")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&crypto.JwkSet{Keys: []*crypto.Jwk{partnerKey.Public()}})
+	}))
+	defer srv.Close()
+
+	j := &GinJwt{
+		SigningSecretKey: "own-secret",
+		SigningAlgorithm: ginjwtsignalgorithm.HS256,
+		VerifyKeySet:     crypto.NewJwksCache(srv.URL),
+		VerifyIssuer:     "https://partner.example.com",
+		VerifyAudience:   "own-app",
+	}
+
+	keyFunc, refreshKeyFunc, err := j.keySetKeyFunc()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// partner token with a kid verifies through the key set
+	partnerToken, err := partnerKey.SignJws([]byte(`{"sub":"partner-user","iss":"https://partner.example.com","aud":"own-app","exp":4102444800}`), &crypto.JoseHeader{Typ: "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok, err := gojwt.Parse(partnerToken, keyFunc); err != nil || !tok.Valid {
+		t.Fatalf("partner token = %v", err)
+	}
+
+	// but it is never accepted for refresh
+	if _, err := gojwt.Parse(partnerToken, refreshKeyFunc); err == nil {
+		t.Fatal("expected partner token to be rejected for refresh")
+	}
+
+	// partner tokens must carry the configured iss and aud
+	for _, claims := range []string{
+		`{"sub":"partner-user","iss":"https://other.example.com","aud":"own-app","exp":4102444800}`,
+		`{"sub":"partner-user","iss":"https://partner.example.com","aud":"other-app","exp":4102444800}`,
+		`{"sub":"partner-user","aud":"own-app","exp":4102444800}`,
+		`{"sub":"partner-user","iss":"https://partner.example.com","exp":4102444800}`,
+	} {
+		tok, err := partnerKey.SignJws([]byte(claims), &crypto.JoseHeader{Typ: "JWT"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := gojwt.Parse(tok, keyFunc); err == nil {
+			t.Fatalf("expected %s to be rejected", claims)
+		}
+	}
+
+	// own login token without a kid still verifies with the signing secret
+	ownToken, _ := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{"sub": "local-user"}).SignedString([]byte("own-secret"))
+	if tok, err := gojwt.Parse(ownToken, keyFunc); err != nil || !tok.Valid {
+		t.Fatalf("own token = %v", err)
+	}
+	if tok, err := gojwt.Parse(ownToken, refreshKeyFunc); err != nil || !tok.Valid {
+		t.Fatalf("own token refresh = %v", err)
+	}
+
+	// a kid token whose alg does not match the published key is rejected
+	forged := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{"sub": "admin"})
+	forged.Header["kid"] = "partner-1"
+	forgedToken, _ := forged.SignedString([]byte("own-secret"))
+	if _, err := gojwt.Parse(forgedToken, keyFunc); err == nil {
+		t.Fatal("expected forged token to be rejected")
+	}
+}
+
+func TestGinJwt_KeySetRequiresIssuerAndAudience(t *testing.T) {
+	j := &GinJwt{
+		SigningSecretKey: "own-secret",
+		SigningAlgorithm: ginjwtsignalgorithm.HS256,
+		VerifyKeySet:     crypto.NewJwksCache("http://127.0.0.1:0"),
+	}
+	if _, _, err := j.keySetKeyFunc(); err == nil {
+		t.Fatal("expected missing VerifyIssuer to fail")
+	}
+
+	j.VerifyIssuer = "https://partner.example.com"
+	if _, _, err := j.keySetKeyFunc(); err == nil {
+		t.Fatal("expected missing VerifyAudience to fail")
+	}
+
+	j.VerifyAudience = "own-app"
+	if _, _, err := j.keySetKeyFunc(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGinJwt_KeySetTokenCannotRefresh(t *testing.T) {
+	ginlib.SetMode(ginlib.TestMode)
+
+	partnerPvk, _, _ := crypto.EcdsaP256CreateKey()
+	partnerKey, _ := crypto.JwkFromPrivateKey(partnerPvk, "partner-1", "sig")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&crypto.JwkSet{Keys: []*crypto.Jwk{partnerKey.Public()}})
+	}))
+	defer srv.Close()
+
+	j := NewGinJwtMiddleware("test", "id", "own-secret", ginbindtype.BindJson)
+	j.AuthenticateHandler = func(loginRequestDataPtr interface{}) interface{} { return nil }
+	j.VerifyKeySet = crypto.NewJwksCache(srv.URL)
+	j.VerifyIssuer = "https://partner.example.com"
+	j.VerifyAudience = "own-app"
+
+	g := &Gin{_ginEngine: ginlib.New()}
+	if err := j.BuildGinJwtMiddleware(g); err != nil {
+		t.Fatal(err)
+	}
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/refreshtoken", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		g._ginEngine.ServeHTTP(w, req)
+		return w
+	}
+
+	// a partner token verified through the key set must not be re-signed with the own key
+	iat := time.Now().Unix()
+	partnerToken, err := partnerKey.SignJws([]byte(fmt.Sprintf(`{"sub":"partner-user","iss":"https://partner.example.com","aud":"own-app","exp":%d,"orig_iat":%d}`, iat+3600, iat)), &crypto.JoseHeader{Typ: "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := refresh(partnerToken); w.Code != http.StatusUnauthorized {
+		t.Fatalf("partner refresh status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	// without orig_iat the partner token is rejected as well instead of panicking
+	noIatToken, err := partnerKey.SignJws([]byte(fmt.Sprintf(`{"sub":"partner-user","iss":"https://partner.example.com","aud":"own-app","exp":%d}`, iat+3600)), &crypto.JoseHeader{Typ: "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := refresh(noIatToken); w.Code != http.StatusUnauthorized {
+		t.Fatalf("partner refresh without orig_iat status = %d", w.Code)
+	}
+
+	// own tokens keep refreshing
+	ownToken, _, err := j._ginJwtMiddleware.TokenGenerator(map[string]interface{}{"id": "local-user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := refresh(ownToken); w.Code != http.StatusOK {
+		t.Fatalf("own refresh status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
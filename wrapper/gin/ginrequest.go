@@ -0,0 +1,86 @@
+package gin
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"errors"
+	"fmt"
+
+	util "github.com/aldelo/common"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookBodyContextKey is the gin context key holding the verified webhook body set by WebhookSignatureMiddleware
+const WebhookBodyContextKey = "webhook_body"
+
+// ClientIP returns the client ip of c using the trusted proxy rules of resolver,
+// when resolver is nil, gin's own c.ClientIP() (Gin.TrustedProxies based) is returned
+func ClientIP(c *gin.Context, resolver *util.HttpClientIPResolver) string {
+	if c == nil {
+		return ""
+	}
+
+	if resolver == nil {
+		return c.ClientIP()
+	}
+
+	return resolver.ClientIP(c.Request)
+}
+
+// BufferRequestBody reads the request body up to maxBytes (0 = 10mb) and keeps it re-readable,
+// so the handler, binders and signature checks can each read the full body, see util.BufferHttpRequestBody
+func BufferRequestBody(c *gin.Context, maxBytes int64) ([]byte, error) {
+	if c == nil {
+		return nil, fmt.Errorf("Buffer Request Body Requires GIN Context")
+	}
+
+	return util.BufferHttpRequestBody(c.Request, maxBytes)
+}
+
+// WebhookSignatureMiddleware verifies webhook signatures before the handler runs,
+// aborting with 413 when the body exceeds maxBodyBytes (0 = 10mb), and with 401 when the signature
+// is missing, wrong or outside the timestamp tolerance, the response bodies hold fixed messages and the error detail
+// is added to the gin context errors (c.Errors) for logging; on success the body remains readable
+// by the handler and is also stored in the context under WebhookBodyContextKey
+func WebhookSignatureMiddleware(verifier *util.WebhookVerifier, maxBodyBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := verifier.VerifyRequest(c.Request, maxBodyBytes)
+
+		if err == nil {
+			c.Set(WebhookBodyContextKey, body)
+			c.Next()
+			return
+		}
+
+		// the detail (timestamp skew, verifier config, read failures) goes to c.Errors for the request logger,
+		// the client only gets a fixed message
+		_ = c.Error(err)
+
+		switch {
+		case errors.Is(err, util.ErrHttpBodyTooLarge):
+			writeGinErrorJSON(c, 413, "webhook-body-too-large", "Webhook Body Too Large")
+		case errors.Is(err, util.ErrWebhookSignatureMissing),
+			errors.Is(err, util.ErrWebhookSignatureMismatch),
+			errors.Is(err, util.ErrWebhookTimestampExpired):
+			writeGinErrorJSON(c, 401, "webhook-signature-invalid", "Webhook Signature Invalid")
+		default:
+			writeGinErrorJSON(c, 500, "webhook-verify-failed", util.ErrorResponseGenericMessage)
+		}
+
+		c.Abort()
+	}
+}
@@ -0,0 +1,102 @@
+package gin
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	util "github.com/aldelo/common"
+	ginlib "github.com/gin-gonic/gin"
+)
+
+// --------------------------------------------------------------------------
+// WebhookSignatureMiddleware / ClientIP
+// --------------------------------------------------------------------------
+
+func TestWebhookSignatureMiddleware(t *testing.T) {
+	v := &util.WebhookVerifier{Secrets: []string{"s3cret"}}
+	payload := `{"event":"push"}`
+	header, sig, _ := v.Sign([]byte(payload), time.Time{})
+
+	r := ginlib.New()
+	r.POST("/hook", WebhookSignatureMiddleware(v, 1024), func(c *ginlib.Context) {
+		raw, _ := io.ReadAll(c.Request.Body)
+		stored, _ := c.Get(WebhookBodyContextKey)
+		if string(raw) != payload || string(stored.([]byte)) != payload {
+			c.String(500, "body not readable")
+			return
+		}
+		c.String(200, "ok")
+	})
+
+	cases := []struct {
+		name   string
+		sig    string
+		body   string
+		status int
+	}{
+		{"valid", sig, payload, 200},
+		{"tampered", sig, `{"event":"fork"}`, 401},
+		{"missing", "", payload, 401},
+		{"too large", sig, strings.Repeat("x", 2048), 413},
+	}
+
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/hook", strings.NewReader(tc.body))
+		if tc.sig != "" {
+			req.Header.Set(header, tc.sig)
+		}
+
+		r.ServeHTTP(w, req)
+		if w.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d (%s)", tc.name, w.Code, tc.status, w.Body.String())
+		}
+	}
+
+	// verifier detail never reaches the client
+	stripe := &util.WebhookVerifier{Style: util.WebhookStyleStripe, Secrets: []string{"s3cret"}}
+	_, stale, _ := stripe.Sign([]byte(payload), time.Now().Add(-time.Hour))
+
+	for name, tc := range map[string]struct {
+		verifier *util.WebhookVerifier
+		sig      string
+		status   int
+		leak     string
+	}{
+		"expired":      {stripe, stale, 401, "tolerance"},
+		"blank secret": {&util.WebhookVerifier{Secrets: []string{""}}, sig, 500, "Secret"},
+	} {
+		rr := ginlib.New()
+		rr.POST("/hook", WebhookSignatureMiddleware(tc.verifier, 1024), func(c *ginlib.Context) { c.String(200, "ok") })
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/hook", strings.NewReader(payload))
+		req.Header.Set("Stripe-Signature", tc.sig)
+		req.Header.Set(header, tc.sig)
+
+		rr.ServeHTTP(w, req)
+		if w.Code != tc.status || strings.Contains(w.Body.String(), tc.leak) {
+			t.Errorf("%s: status = %d, body = %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestClientIP_WithResolver(t *testing.T) {
+	resolver, err := util.NewHttpClientIPResolver("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := ginlib.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	c.Request.RemoteAddr = "10.0.0.2:1234"
+	c.Request.Header.Set("X-Forwarded-For", "198.51.100.4, 10.0.0.3")
+
+	if got := ClientIP(c, resolver); got != "198.51.100.4" {
+		t.Fatalf("ClientIP = %q", got)
+	}
+}
@@ -1968,6 +1968,97 @@ func (k *KMS) DecryptWithDataKeyAes256(cipherText string, cipherKey string) (pla
 	return plainText, nil
 }
 
+// DecryptDataKey will decrypt cipherKey that was generated via GenerateDataKeyAes256(), returning the plaintext data key bytes
+//
+// cipherKey = encrypted data key in hex (must use KMS CMK to decrypt such key)
+//
+// unlike EncryptWithDataKeyAes256 the data key never becomes a Go string, so the caller owns the only copy
+// and can overwrite it (clear(key)) when done; this satisfies crypto.KeyringKeyDecrypter for crypto.Keyring.AddWrappedKey
+func (k *KMS) DecryptDataKey(cipherKey string) (dataKey []byte, err error) {
+	if k == nil {
+		return nil, errors.New("KMS receiver is nil")
+	}
+	var segCtx context.Context
+	segCtx = nil
+
+	seg := xray.NewSegmentNullable("KMS-DecryptDataKey", k.getParentSegment())
+
+	if seg != nil {
+		segCtx = seg.Ctx
+
+		defer seg.Close()
+		defer func() {
+			xray.LogXrayAddFailure("KMS", seg.SafeAddMetadata("KMS-DecryptDataKey-AES-KMS-KeyName", k.getAesKeyName()))
+			xray.LogXrayAddFailure("KMS", seg.SafeAddMetadata("KMS-DecryptDataKey-CipherKey-Length", len(cipherKey)))
+
+			if err != nil {
+				xray.LogXrayAddFailure("KMS", seg.SafeAddError(err))
+			}
+		}()
+	}
+
+	// validate
+	cli, cliErr := k.getClient()
+	if cliErr != nil {
+		err = fmt.Errorf("DecryptDataKey with KMS Failed: %w", cliErr)
+		return nil, err
+	}
+
+	keyName := k.getAesKeyName()
+	if len(keyName) <= 0 {
+		err = errors.New("DecryptDataKey with KMS Failed: " + "AES KMS Key Name is Required")
+		return nil, err
+	}
+
+	if len(cipherKey) <= 0 {
+		err = errors.New("DecryptDataKey with KMS Failed: " + "CipherKey is Required")
+		return nil, err
+	}
+
+	// prepare key info
+	keyId := "alias/" + keyName
+	cipherBytes, ce := util.HexToByte(cipherKey)
+
+	if ce != nil {
+		err = fmt.Errorf("DecryptDataKey with KMS Failed: (Unmarshal CipherKey Hex To Byte) %w", ce)
+		return nil, err
+	}
+
+	// decrypt cipherKey using kms cmk
+	// SP-008 P2-CMN-2: always use *WithContext — ensureKMSCtx yields a
+	// 30s timeout ctx when segCtx is nil.
+	kmsCtx, kmsCancel := ensureKMSCtx(segCtx)
+	dataKeyOutput, e := cli.DecryptWithContext(kmsCtx,
+		&kms.DecryptInput{
+			EncryptionAlgorithm: aws.String("SYMMETRIC_DEFAULT"),
+			KeyId:               aws.String(keyId),
+			CiphertextBlob:      cipherBytes,
+		})
+	kmsCancel()
+
+	if e != nil {
+		err = fmt.Errorf("DecryptDataKey with KMS Failed: (Decrypt Data Key) %w", e)
+		return nil, err
+	}
+
+	if dataKeyOutput == nil {
+		err = errors.New("DecryptDataKey Failed: output is nil")
+		return nil, err
+	}
+
+	if len(dataKeyOutput.Plaintext) != 32 {
+		clear(dataKeyOutput.Plaintext)
+		err = fmt.Errorf("DecryptDataKey with KMS Failed: Data Key Must Be 32 Bytes, Got %d", len(dataKeyOutput.Plaintext))
+		return nil, err
+	}
+
+	// hand the SDK-owned slice to the caller as the single plaintext copy
+	dataKey = dataKeyOutput.Plaintext
+	dataKeyOutput.SetPlaintext(nil)
+
+	return dataKey, nil
+}
+
 func (k *KMS) ImportECCP256SignVerifyKey(keyAlias, keyPolicyJson string, eccPvk *ecdsa.PrivateKey) (keyArn string, err error) {
 	if k == nil {
 		return "", errors.New("KMS receiver is nil")
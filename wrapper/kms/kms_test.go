@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aldelo/common/crypto"
 	"github.com/aldelo/common/wrapper/aws/awsregion"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -337,3 +338,47 @@ func TestKMS_DisconnectClearsPublishedClient(t *testing.T) {
 		t.Fatal("expected error after Disconnect, got nil")
 	}
 }
+
+// KMS must keep satisfying the crypto keyring's data key decrypter contract
+var _ crypto.KeyringKeyDecrypter = (*KMS)(nil)
+
+func TestDecryptDataKeyIntoKeyring(t *testing.T) {
+	if !localstackAvailable() {
+		t.Skip("LocalStack not available at localhost:4566")
+	}
+	os.Setenv("AWS_ACCESS_KEY_ID", "test")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	ensureAlias(t)
+
+	k := newTestKMS(t)
+	if err := k.Connect(); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer k.Disconnect()
+
+	cipherKey, err := k.GenerateDataKeyAes256()
+	if err != nil {
+		t.Fatalf("GenerateDataKeyAes256 failed: %v", err)
+	}
+
+	dataKey, err := k.DecryptDataKey(cipherKey)
+	if err != nil {
+		t.Fatalf("DecryptDataKey failed: %v", err)
+	}
+	if len(dataKey) != 32 {
+		t.Fatalf("data key length = %d, want 32", len(dataKey))
+	}
+
+	kr := crypto.NewKeyring()
+	if err := kr.AddWrappedKey("kms-1", cipherKey, k); err != nil {
+		t.Fatalf("AddWrappedKey failed: %v", err)
+	}
+
+	enc, err := kr.Encrypt("hello keyring", "")
+	if err != nil {
+		t.Fatalf("Keyring Encrypt failed: %v", err)
+	}
+	if dec, err := kr.Decrypt(enc, ""); err != nil || dec != "hello keyring" {
+		t.Fatalf("Keyring Decrypt = %q, %v", dec, err)
+	}
+}
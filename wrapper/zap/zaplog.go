@@ -32,6 +32,8 @@ import (
 // DisableLogger = disables the logger from operations, this allows code to be left in place while not performing logging action
 // OutputToConsole = redirects log output to console instead of file
 // AppName = required, this app's name
+//
+// card numbers and track data in messages and fields are masked before output (see NewMaskingCore)
 type ZapLog struct {
 	// operating var
 	DisableLogger bool
@@ -112,6 +114,9 @@ func (z *ZapLog) Init() error {
 		return fmt.Errorf("Init Logger Failed: %w", err)
 	}
 
+	// redact card numbers and track data before any entry is encoded
+	logger = logger.WithOptions(zap.WrapCore(NewMaskingCore))
+
 	// init zap sugared logger
 	z.zapLogger = logger
 	z.sugarLogger = logger.Sugar()
@@ -0,0 +1,209 @@
+package data
+
+/*
+ * Copyright 2020-2026 Aldelo, LP
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	util "github.com/aldelo/common"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maskingCore wraps a zapcore.Core and redacts card numbers and track data from entry messages and fields
+// before they reach the encoder
+type maskingCore struct {
+	zapcore.Core
+}
+
+// NewMaskingCore wraps core so every logged message and field passes through util.MaskSensitiveText,
+// ZapLog.Init installs it on all loggers, use with zap.WrapCore to protect loggers built outside ZapLog
+func NewMaskingCore(core zapcore.Core) zapcore.Core {
+	if core == nil {
+		return nil
+	}
+
+	if _, ok := core.(*maskingCore); ok {
+		return core
+	}
+
+	return &maskingCore{Core: core}
+}
+
+// With masks the context fields before they are bound to the child core
+func (c *maskingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &maskingCore{Core: c.Core.With(maskZapFields(fields))}
+}
+
+// Check registers the masking core, not the wrapped core, so Write is routed through masking
+func (c *maskingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+// Write masks the entry message and fields, then writes to the wrapped core
+func (c *maskingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = util.MaskSensitiveText(ent.Message)
+	return c.Core.Write(ent, maskZapFields(fields))
+}
+
+// maskZapFields returns fields with card data masked, the input slice is copied only when a field changes
+func maskZapFields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+
+	for i, f := range fields {
+		if m, changed := maskZapField(f); changed {
+			if out == nil {
+				out = make([]zapcore.Field, len(fields))
+				copy(out, fields)
+			}
+			out[i] = m
+		}
+	}
+
+	if out == nil {
+		return fields
+	}
+
+	return out
+}
+
+// zapCardKeyWords are key words marking an integer field as card data, such as pan, card_number or ccNum,
+// integer fields are otherwise left alone, since many 13 to 19 digit ids (such as snowflake ids) pass the Luhn check
+var zapCardKeyWords = map[string]bool{"pan": true, "card": true, "cc": true, "ccnum": true, "cardnum": true, "cardno": true}
+
+// maskZapField masks a single field, fields whose text holds card data are replaced by a masked string field;
+// integers are masked only when zapIsCardKey matches their key, and reflected, object and array fields are
+// rendered to json on every write to be scanned, which costs a marshal per such field when masking is installed
+func maskZapField(f zapcore.Field) (zapcore.Field, bool) {
+	var text string
+
+	switch f.Type {
+	case zapcore.StringType:
+		text = f.String
+	case zapcore.ByteStringType:
+		if b, ok := f.Interface.([]byte); ok {
+			text = string(b)
+		}
+	case zapcore.Int64Type:
+		if !zapIsCardKey(f.Key) {
+			return f, false
+		}
+		text = strconv.FormatInt(f.Integer, 10)
+	case zapcore.Uint64Type:
+		if !zapIsCardKey(f.Key) {
+			return f, false
+		}
+		text = strconv.FormatUint(uint64(f.Integer), 10)
+	case zapcore.ErrorType:
+		if e, ok := f.Interface.(error); ok && e != nil {
+			text = e.Error()
+		}
+	case zapcore.StringerType:
+		if s, ok := f.Interface.(fmt.Stringer); ok && s != nil {
+			text = safeZapStringer(s)
+		}
+	case zapcore.ReflectType:
+		if f.Interface != nil {
+			if b, err := json.Marshal(f.Interface); err == nil {
+				text = string(b)
+			}
+		}
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType:
+		text = zapMarshalerJSON(f)
+	default:
+		return f, false
+	}
+
+	masked := util.MaskSensitiveText(text)
+	if masked == text {
+		return f, false
+	}
+
+	return zap.String(f.Key, masked), true
+}
+
+// zapIsCardKey returns true when a word of key, split at non letters or digits and lower to upper case changes,
+// is one of zapCardKeyWords; so pan_num and cardNumber match, while span and company do not
+func zapIsCardKey(key string) bool {
+	word := make([]rune, 0, len(key))
+
+	check := func() bool {
+		hit := zapCardKeyWords[strings.ToLower(string(word))]
+		word = word[:0]
+		return hit
+	}
+
+	for i, r := range key {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if i > 0 && unicode.IsUpper(r) && len(word) > 0 && !unicode.IsUpper(word[len(word)-1]) && check() {
+				return true
+			}
+			word = append(word, r)
+		case check():
+			return true
+		}
+	}
+
+	return check()
+}
+
+// zapMarshalerJSON renders an object or array marshaler field to json via a map encoder, blank on failure
+func zapMarshalerJSON(f zapcore.Field) string {
+	enc := zapcore.NewMapObjectEncoder()
+
+	var err error
+
+	switch v := f.Interface.(type) {
+	case zapcore.ObjectMarshaler:
+		err = enc.AddObject(f.Key, v)
+	case zapcore.ArrayMarshaler:
+		err = enc.AddArray(f.Key, v)
+	default:
+		return ""
+	}
+
+	if err != nil {
+		return ""
+	}
+
+	b, err := json.Marshal(enc.Fields[f.Key])
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
+
+// safeZapStringer calls String, treating a panic (such as a nil pointer receiver) as empty text
+func safeZapStringer(s fmt.Stringer) (text string) {
+	defer func() {
+		if recover() != nil {
+			text = ""
+		}
+	}()
+
+	return s.String()
+}
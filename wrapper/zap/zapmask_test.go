@@ -0,0 +1,95 @@
+package data
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestMaskingCore verifies that card numbers are redacted from messages,
+// string / card keyed int / error / reflected / marshaler fields and bound context fields.
+func TestMaskingCore(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core).WithOptions(zap.WrapCore(NewMaskingCore))
+
+	logger.With(zap.String("ctx", "card 4111111111111111")).Info(
+		"charge 4111 1111 1111 1111 declined",
+		zap.String("pan", "4111111111111111"),
+		zap.Int64("pan_num", 4111111111111111),
+		zap.Error(errors.New("bad track ;4111111111111111=25121010000000000000?")),
+		zap.Any("req", map[string]string{"card": "5500005555555559"}),
+		zap.String("order", "1234567890123456"),
+		zap.Int64("order_id", 1234567812345670),
+		zap.Uint64("timespan", 1234567812345670),
+		zap.Object("payment", testZapPayment{pan: "5500005555555559"}),
+		zap.Strings("pans", []string{"4111111111111111"}),
+	)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+
+	e := entries[0]
+	if e.Message != "charge 4111 11** **** 1111 declined" {
+		t.Errorf("message = %q", e.Message)
+	}
+
+	fields := e.ContextMap()
+	for k, v := range fields {
+		s, _ := v.(string)
+		if strings.Contains(s, "4111111111111111") || strings.Contains(s, "5500005555555559") {
+			t.Errorf("field %s not masked: %v", k, v)
+		}
+	}
+
+	if fields["pan"] != "411111******1111" || fields["pan_num"] != "411111******1111" || fields["ctx"] != "card 411111******1111" {
+		t.Errorf("unexpected masked fields: %v", fields)
+	}
+
+	if fields["payment"] != `{"pan":"550000******5559"}` || fields["pans"] != `["411111******1111"]` {
+		t.Errorf("marshaler fields: payment = %v, pans = %v", fields["payment"], fields["pans"])
+	}
+
+	// non card numbers are left untouched, and Luhn valid integer ids keep their type unless the key names card data
+	if fields["order"] != "1234567890123456" {
+		t.Errorf("order = %v", fields["order"])
+	}
+	if fields["order_id"] != int64(1234567812345670) || fields["timespan"] != uint64(1234567812345670) {
+		t.Errorf("integer ids = %v, %v", fields["order_id"], fields["timespan"])
+	}
+}
+
+type testZapPayment struct {
+	pan string
+}
+
+func (p testZapPayment) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("pan", p.pan)
+	return nil
+}
+
+func TestZapIsCardKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"pan": true, "pan_num": true, "cardNumber": true, "card-no": true, "ccNum": true,
+		"span": false, "company": false, "order_id": false, "discard": false, "": false,
+	} {
+		if got := zapIsCardKey(key); got != want {
+			t.Errorf("zapIsCardKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+// TestNewMaskingCoreIdempotent verifies that wrapping twice does not double wrap.
+func TestNewMaskingCoreIdempotent(t *testing.T) {
+	core, _ := observer.New(zapcore.InfoLevel)
+
+	c1 := NewMaskingCore(core)
+	if c2 := NewMaskingCore(c1); c2 != c1 {
+		t.Error("expected already wrapped core to be returned as-is")
+	}
+}